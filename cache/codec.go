@@ -0,0 +1,47 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appCache "github.com/justinwongcn/hamster/internal/application/cache"
+	infraCache "github.com/justinwongcn/hamster/internal/infrastructure/cache"
+)
+
+// Codec 缓存值的序列化器
+// Marshal 将缓存值序列化为字节，Unmarshal 将字节反序列化到ptr指向的值
+type Codec = infraCache.Codec
+
+// JSONCodec 使用JSON的序列化器
+type JSONCodec = infraCache.JSONCodec
+
+// GobCodec 使用gob的序列化器，自定义类型需要先调用 gob.Register 注册
+type GobCodec = infraCache.GobCodec
+
+// SetTyped 设置指定类型的缓存值
+// 与 Service.Set 相同，通过类型参数约束写入的值，与 GetTyped 配对使用
+func SetTyped[T any](ctx context.Context, s *Service, key string, val T, expiration time.Duration) error {
+	return s.Set(ctx, key, val, expiration)
+}
+
+// GetTyped 获取指定类型的缓存值
+// 配置了 WithCodec 时将缓存值反序列化为T；未配置时直接断言缓存值的类型
+// 返回: 缓存值和错误信息，键不存在或缓存值不是T时返回错误
+func GetTyped[T any](ctx context.Context, s *Service, key string) (T, error) {
+	var res T
+	if s.codec != nil {
+		err := s.appService.GetCacheItemInto(ctx, appCache.CacheItemQuery{Key: key}, &res)
+		return res, err
+	}
+
+	val, err := s.Get(ctx, key)
+	if err != nil {
+		return res, err
+	}
+	typed, ok := val.(T)
+	if !ok {
+		return res, fmt.Errorf("键 %s 的缓存值类型为 %T，不是 %T", key, val, res)
+	}
+	return typed, nil
+}
@@ -3,6 +3,8 @@ package cache
 import (
 	"context"
 	"fmt"
+	"maps"
+	"slices"
 	"time"
 
 	appCache "github.com/justinwongcn/hamster/internal/application/cache"
@@ -13,6 +15,7 @@ import (
 // Config 缓存配置
 type Config struct {
 	// MaxMemory 最大内存使用量（字节）
+	// 注意: NewService 创建的内存缓存目前不按该值限制内存，设置 Shards 时也不会把该值分配到各个分片
 	MaxMemory int64
 
 	// DefaultExpiration 默认过期时间
@@ -29,6 +32,22 @@ type Config struct {
 
 	// BloomFilterFalsePositiveRate 布隆过滤器假阳性率
 	BloomFilterFalsePositiveRate float64
+
+	// MaxKeyLength 缓存键的最大长度
+	MaxKeyLength int
+
+	// Shards 内存缓存的分片数量，0和1都表示单个分片，不能为负数
+	// 键按哈希分布到各个分片，每个分片使用独立的锁，减少高并发下的锁竞争
+	// 分片只影响锁的粒度，与 MaxMemory 无关，不存在按分片划分的内存预算
+	Shards int
+
+	// Codec 缓存值的序列化器，nil表示直接保存缓存值
+	// 设置后缓存值序列化为字节保存，可以通过 GetTyped 按原始类型取回
+	Codec Codec
+
+	// OnEvicted 缓存项被删除时的回调函数，nil表示不设置
+	// 过期清理、Delete和LoadAndDelete都会触发回调
+	OnEvicted func(key string, val any)
 }
 
 // DefaultConfig 返回默认缓存配置
@@ -40,6 +59,8 @@ func DefaultConfig() *Config {
 		EvictionPolicy:               "lru",
 		EnableBloomFilter:            false,
 		BloomFilterFalsePositiveRate: 0.01,
+		MaxKeyLength:                 domainCache.DefaultMaxKeyLength,
+		Shards:                       1,
 	}
 }
 
@@ -82,9 +103,43 @@ func WithBloomFilter(enable bool, falsePositiveRate float64) Option {
 	}
 }
 
+// WithMaxKeyLength 设置缓存键的最大长度
+func WithMaxKeyLength(maxLength int) Option {
+	return func(c *Config) {
+		c.MaxKeyLength = maxLength
+	}
+}
+
+// WithShards 设置内存缓存的分片数量
+// n为0和1时都使用单个分片，为负数时 NewService 返回错误
+// 分片不会划分 MaxMemory 的内存预算
+func WithShards(n int) Option {
+	return func(c *Config) {
+		c.Shards = n
+	}
+}
+
+// WithCodec 设置缓存值的序列化器
+// 设置后缓存值序列化为字节保存，Get 返回反序列化为any的值，GetTyped 按原始类型取回
+func WithCodec(codec Codec) Option {
+	return func(c *Config) {
+		c.Codec = codec
+	}
+}
+
+// WithOnEvicted 设置缓存项被删除时的回调函数
+// 回调在持有缓存内部锁时同步调用，回调中不应再调用缓存服务的方法
+func WithOnEvicted(fn func(key string, val any)) Option {
+	return func(c *Config) {
+		c.OnEvicted = fn
+	}
+}
+
 // Service 缓存服务公共接口
 type Service struct {
 	appService *appCache.ApplicationService
+	repository domainCache.Repository
+	codec      Codec
 }
 
 // NewService 创建缓存服务
@@ -98,15 +153,36 @@ func NewService(options ...Option) (*Service, error) {
 	return NewServiceWithConfig(config)
 }
 
+// validateConfig 校验缓存配置，NewService 和 NewServiceWithConfig 使用相同的规则
+func validateConfig(config *Config) error {
+	if config == nil {
+		return fmt.Errorf("配置不能为空")
+	}
+	if config.Shards < 0 {
+		return fmt.Errorf("分片数量不能为负数: %d", config.Shards)
+	}
+	return nil
+}
+
 // NewServiceWithConfig 使用配置创建缓存服务
 func NewServiceWithConfig(config *Config) (*Service, error) {
-	if config == nil {
-		return nil, fmt.Errorf("配置不能为空")
+	if err := validateConfig(config); err != nil {
+		return nil, err
 	}
 
 	// 创建基础设施层
 	// 使用 BuildInMapCache 作为 Repository 实现
-	repository := infraCache.NewBuildInMapCache(config.CleanupInterval)
+	var repoOpts []infraCache.BuildInMapCacheOption
+	if config.Shards > 1 {
+		repoOpts = append(repoOpts, infraCache.BuildInMapCacheWithShards(config.Shards))
+	}
+	var repository domainCache.Repository = infraCache.NewBuildInMapCache(config.CleanupInterval, repoOpts...)
+	if config.Codec != nil {
+		repository = infraCache.NewCodecCache(repository, config.Codec)
+	}
+	if config.OnEvicted != nil {
+		repository.OnEvicted(config.OnEvicted)
+	}
 
 	// 创建领域服务
 	var evictionStrategy domainCache.EvictionStrategy
@@ -119,13 +195,15 @@ func NewServiceWithConfig(config *Config) (*Service, error) {
 		evictionStrategy = domainCache.NewLRUEvictionStrategy()
 	}
 
-	cacheService := domainCache.NewCacheService(evictionStrategy)
+	cacheService := domainCache.NewCacheService(evictionStrategy, domainCache.CacheServiceWithMaxKeyLength(config.MaxKeyLength))
 
 	// 创建应用服务
 	appService := appCache.NewApplicationService(repository, cacheService, nil)
 
 	return &Service{
 		appService: appService,
+		repository: repository,
+		codec:      config.Codec,
 	}, nil
 }
 
@@ -140,6 +218,30 @@ func (s *Service) Set(ctx context.Context, key string, value any, expiration tim
 	return s.appService.SetCacheItem(ctx, cmd)
 }
 
+// SetWithDeadline 设置缓存值，并指定绝对的过期时间点
+// deadline为零值表示永不过期，早于当前时间时视为已过期
+func (s *Service) SetWithDeadline(ctx context.Context, key string, value any, deadline time.Time) error {
+	cmd := appCache.CacheItemDeadlineCommand{
+		Key:      key,
+		Value:    value,
+		Deadline: deadline,
+	}
+
+	return s.appService.SetCacheItemWithDeadline(ctx, cmd)
+}
+
+// SetNX 仅当键不存在或已过期时设置缓存值
+// 返回: 是否设置成功和错误信息，键已存在时返回false并保留原值
+func (s *Service) SetNX(ctx context.Context, key string, value any, expiration time.Duration) (bool, error) {
+	cmd := appCache.CacheItemCommand{
+		Key:        key,
+		Value:      value,
+		Expiration: expiration,
+	}
+
+	return s.appService.SetCacheItemIfAbsent(ctx, cmd)
+}
+
 // Get 获取缓存值
 func (s *Service) Get(ctx context.Context, key string) (any, error) {
 	query := appCache.CacheItemQuery{Key: key}
@@ -156,6 +258,58 @@ func (s *Service) Get(ctx context.Context, key string) (any, error) {
 	return result.Value, nil
 }
 
+// SetMany 批量设置缓存值，所有缓存项使用相同的过期时间
+// 任一缓存键无效时不写入任何数据，返回的错误中包含每个无效的缓存键
+func (s *Service) SetMany(ctx context.Context, items map[string]any, expiration time.Duration) error {
+	cmds := make([]appCache.CacheItemCommand, 0, len(items))
+	// 按键排序，保证错误信息中的序号稳定
+	for _, key := range slices.Sorted(maps.Keys(items)) {
+		cmds = append(cmds, appCache.CacheItemCommand{
+			Key:        key,
+			Value:      items[key],
+			Expiration: expiration,
+		})
+	}
+
+	return s.appService.SetCacheItems(ctx, cmds)
+}
+
+// GetMany 批量获取缓存值
+// 不存在的键不出现在结果中，也不视为错误
+// 返回: 获取成功的键值对和错误信息，部分键获取失败时仍返回其他键的值
+func (s *Service) GetMany(ctx context.Context, keys []string) (map[string]any, error) {
+	queries := make([]appCache.CacheItemQuery, len(keys))
+	for i, key := range keys {
+		queries[i] = appCache.CacheItemQuery{Key: key}
+	}
+
+	results, err := s.appService.GetCacheItems(ctx, queries)
+	values := make(map[string]any, len(results))
+	for _, result := range results {
+		if result.Found {
+			values[result.Key] = result.Value
+		}
+	}
+	return values, err
+}
+
+// DeleteMany 批量删除缓存值
+// 任一缓存键无效时不删除任何数据，返回的错误中包含每个无效的缓存键
+func (s *Service) DeleteMany(ctx context.Context, keys []string) error {
+	queries := make([]appCache.CacheItemQuery, len(keys))
+	for i, key := range keys {
+		queries[i] = appCache.CacheItemQuery{Key: key}
+	}
+
+	return s.appService.DeleteCacheItems(ctx, queries)
+}
+
+// Exists 检查键是否存在且未过期
+func (s *Service) Exists(ctx context.Context, key string) (bool, error) {
+	query := appCache.CacheItemQuery{Key: key}
+	return s.appService.CacheItemExists(ctx, query)
+}
+
 // Delete 删除缓存值
 func (s *Service) Delete(ctx context.Context, key string) error {
 	query := appCache.CacheItemQuery{Key: key}
@@ -186,12 +340,13 @@ func (s *Service) LoadAndDelete(ctx context.Context, key string) (any, error) {
 }
 
 // OnEvicted 设置淘汰回调函数
+// 替换 WithOnEvicted 设置的回调
 func (s *Service) OnEvicted(fn func(key string, val any)) {
-	// 这里需要通过底层仓储设置回调
-	// 暂时留空，需要扩展基础设施层的接口
+	s.repository.OnEvicted(fn)
 }
 
 // Stats 获取缓存统计信息
+// 命中率由Get等读取操作的命中和未命中次数计算，键不存在计为未命中
 func (s *Service) Stats(ctx context.Context) (*Stats, error) {
 	result, err := s.appService.GetCacheStats(ctx)
 	if err != nil {
@@ -199,11 +354,12 @@ func (s *Service) Stats(ctx context.Context) (*Stats, error) {
 	}
 
 	return &Stats{
-		HitCount:    result.Hits,
-		MissCount:   result.Misses,
-		HitRate:     result.HitRate,
-		ItemCount:   result.Size,
-		MemoryUsage: 0, // 暂时不支持内存使用统计
+		HitCount:      result.Hits,
+		MissCount:     result.Misses,
+		HitRate:       result.HitRate,
+		ItemCount:     result.Size,
+		MemoryUsage:   0, // 暂时不支持内存使用统计
+		DirtyKeyCount: int64(len(result.DirtyKeys)),
 	}, nil
 }
 
@@ -220,6 +376,8 @@ type Stats struct {
 	HitRate     float64 `json:"hit_rate"`
 	ItemCount   int64   `json:"item_count"`
 	MemoryUsage int64   `json:"memory_usage"`
+	// DirtyKeyCount 尚未刷新的脏数据键数量，未配置写回缓存时为0
+	DirtyKeyCount int64 `json:"dirty_key_count"`
 }
 
 // ReadThroughService 读透缓存服务
@@ -267,3 +425,27 @@ func (s *ReadThroughService) GetWithLoader(
 
 	return loadedValue, nil
 }
+
+// GetWithLoaderTTL 使用加载器获取缓存项，过期时间由加载器返回
+// 适用于新鲜度各不相同的数据，每个缓存项使用加载器给出的过期时间
+func (s *ReadThroughService) GetWithLoaderTTL(
+	ctx context.Context,
+	key string,
+	loader func(ctx context.Context, key string) (any, time.Duration, error),
+) (any, error) {
+	// 先尝试从缓存获取
+	value, err := s.service.Get(ctx, key)
+	if err == nil {
+		return value, nil
+	}
+
+	// 缓存未命中，使用加载器加载数据和过期时间
+	loadedValue, expiration, err := loader(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	// 即使缓存设置失败，也返回加载的数据
+	_ = s.service.Set(ctx, key, loadedValue, expiration)
+	return loadedValue, nil
+}
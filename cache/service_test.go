@@ -2,6 +2,10 @@ package cache
 
 import (
 	"context"
+	"encoding/gob"
+	"fmt"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -18,6 +22,7 @@ func TestDefaultConfig(t *testing.T) {
 	assert.Equal(t, "lru", config.EvictionPolicy)
 	assert.False(t, config.EnableBloomFilter)
 	assert.Equal(t, 0.01, config.BloomFilterFalsePositiveRate)
+	assert.Equal(t, 250, config.MaxKeyLength)
 }
 
 func TestWithMaxMemory(t *testing.T) {
@@ -61,6 +66,60 @@ func TestWithBloomFilter(t *testing.T) {
 	assert.Equal(t, 0.05, config.BloomFilterFalsePositiveRate)
 }
 
+func TestWithMaxKeyLength(t *testing.T) {
+	config := DefaultConfig()
+	option := WithMaxKeyLength(16)
+	option(config)
+
+	assert.Equal(t, 16, config.MaxKeyLength)
+}
+
+func TestService_MaxKeyLength(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []Option
+		key     string
+		wantErr bool
+	}{
+		{
+			name:    "自定义长度边界内",
+			options: []Option{WithMaxKeyLength(16)},
+			key:     strings.Repeat("k", 16),
+		},
+		{
+			name:    "超过自定义长度",
+			options: []Option{WithMaxKeyLength(16)},
+			key:     strings.Repeat("k", 17),
+			wantErr: true,
+		},
+		{
+			name:    "放宽默认长度",
+			options: []Option{WithMaxKeyLength(1000)},
+			key:     strings.Repeat("k", 1000),
+		},
+		{
+			name:    "超过默认长度",
+			key:     strings.Repeat("k", 251),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, err := NewService(tt.options...)
+			require.NoError(t, err)
+
+			err = service.Set(context.Background(), tt.key, "value", time.Hour)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), "无效的缓存键")
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
 func TestNewService(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -124,6 +183,11 @@ func TestNewServiceWithConfig(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name:    "negative shards",
+			config:  &Config{Shards: -1},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -161,6 +225,73 @@ func TestService_SetAndGet(t *testing.T) {
 	assert.Equal(t, value, retrievedValue)
 }
 
+func TestService_SetNX(t *testing.T) {
+	service, err := NewService()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	ok, err := service.SetNX(ctx, "nx_key", "first", time.Hour)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = service.SetNX(ctx, "nx_key", "second", time.Hour)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	value, err := service.Get(ctx, "nx_key")
+	assert.NoError(t, err)
+	assert.Equal(t, "first", value)
+}
+
+func TestService_SetWithDeadline(t *testing.T) {
+	service, err := NewService()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	require.NoError(t, service.SetWithDeadline(ctx, "future_key", "value", time.Now().Add(time.Hour)))
+	value, err := service.Get(ctx, "future_key")
+	assert.NoError(t, err)
+	assert.Equal(t, "value", value)
+
+	require.NoError(t, service.SetWithDeadline(ctx, "past_key", "value", time.Now().Add(-time.Second)))
+	ok, err := service.Exists(ctx, "past_key")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestService_Exists(t *testing.T) {
+	service, err := NewService()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, service.Set(ctx, "exists_key", "value", time.Hour))
+
+	ok, err := service.Exists(ctx, "exists_key")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = service.Exists(ctx, "missing_key")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestService_NilValue(t *testing.T) {
+	service, err := NewService()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, service.Set(ctx, "nil_key", nil, time.Hour))
+
+	value, err := service.Get(ctx, "nil_key")
+	assert.NoError(t, err)
+	assert.Nil(t, value)
+
+	_, err = service.Get(ctx, "absent_key")
+	assert.Error(t, err)
+}
+
 func TestService_GetNonExistentKey(t *testing.T) {
 	service, err := NewService()
 	require.NoError(t, err)
@@ -193,6 +324,73 @@ func TestService_Delete(t *testing.T) {
 	assert.Error(t, err)
 }
 
+// TestService_Many 测试批量设置、获取和删除缓存值
+func TestService_Many(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("全部成功", func(t *testing.T) {
+		service, err := NewService()
+		require.NoError(t, err)
+
+		items := map[string]any{"key1": "value1", "key2": 2, "key3": nil}
+		require.NoError(t, service.SetMany(ctx, items, time.Hour))
+
+		values, err := service.GetMany(ctx, []string{"key1", "key2", "key3"})
+		require.NoError(t, err)
+		assert.Equal(t, items, values)
+
+		require.NoError(t, service.DeleteMany(ctx, []string{"key1", "key3"}))
+		_, err = service.Get(ctx, "key1")
+		assert.Error(t, err)
+		value, err := service.Get(ctx, "key2")
+		require.NoError(t, err)
+		assert.Equal(t, 2, value)
+	})
+
+	t.Run("部分无效时报告失败的项且不写入", func(t *testing.T) {
+		service, err := NewService(WithMaxKeyLength(8))
+		require.NoError(t, err)
+
+		err = service.SetMany(ctx, map[string]any{
+			"":             "empty",
+			"key1":         "value1",
+			"too_long_key": "value",
+		}, time.Hour)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `第0项（键: ""）`)
+		assert.Contains(t, err.Error(), `第2项（键: "too_long_key"）`)
+		assert.NotContains(t, err.Error(), `"key1"`)
+		_, err = service.Get(ctx, "key1")
+		assert.Error(t, err)
+
+		require.NoError(t, service.Set(ctx, "key1", "value1", time.Hour))
+		err = service.DeleteMany(ctx, []string{"key1", ""})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `第1项（键: ""）`)
+		value, err := service.Get(ctx, "key1")
+		require.NoError(t, err)
+		assert.Equal(t, "value1", value)
+
+		_, err = service.GetMany(ctx, []string{"key1", "too_long_key"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `第1项（键: "too_long_key"）`)
+	})
+
+	t.Run("部分键不存在时返回其他键的值，不存在不视为错误", func(t *testing.T) {
+		service, err := NewService()
+		require.NoError(t, err)
+		require.NoError(t, service.Set(ctx, "key1", "value1", time.Hour))
+
+		values, err := service.GetMany(ctx, []string{"key1", "absent"})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"key1": "value1"}, values)
+
+		values, err = service.GetMany(ctx, []string{"absent", "absent2"})
+		require.NoError(t, err)
+		assert.Empty(t, values)
+	})
+}
+
 func TestService_LoadAndDelete(t *testing.T) {
 	service, err := NewService()
 	require.NoError(t, err)
@@ -239,6 +437,36 @@ func TestService_Stats(t *testing.T) {
 	assert.LessOrEqual(t, stats.HitRate, 1.0)
 }
 
+// TestService_Stats_Counts 测试统计信息反映实际的读写操作
+func TestService_Stats_Counts(t *testing.T) {
+	service, err := NewService()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, service.Set(ctx, "key1", "value1", time.Hour))
+	require.NoError(t, service.Set(ctx, "key2", "value2", time.Hour))
+	require.NoError(t, service.Set(ctx, "key3", "value3", time.Hour))
+	require.NoError(t, service.Delete(ctx, "key3"))
+
+	_, err = service.Get(ctx, "key1")
+	require.NoError(t, err)
+	_, err = service.Get(ctx, "key1")
+	require.NoError(t, err)
+	_, err = service.Get(ctx, "key2")
+	require.NoError(t, err)
+	_, err = service.Get(ctx, "absent")
+	require.Error(t, err)
+
+	stats, err := service.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &Stats{
+		HitCount:  3,
+		MissCount: 1,
+		HitRate:   0.75,
+		ItemCount: 2,
+	}, stats)
+}
+
 func TestService_Clear(t *testing.T) {
 	service, err := NewService()
 	require.NoError(t, err)
@@ -250,6 +478,222 @@ func TestService_Clear(t *testing.T) {
 	assert.Contains(t, err.Error(), "暂未实现")
 }
 
+func TestWithShards(t *testing.T) {
+	config := DefaultConfig()
+	option := WithShards(8)
+	option(config)
+
+	assert.Equal(t, 8, config.Shards)
+
+	// NewService 与 NewServiceWithConfig 使用相同的校验规则
+	for _, shards := range []int{0, 1} {
+		_, err := NewService(WithShards(shards))
+		assert.NoError(t, err)
+		_, err = NewServiceWithConfig(&Config{Shards: shards})
+		assert.NoError(t, err)
+	}
+	_, err := NewService(WithShards(-1))
+	assert.Error(t, err)
+	_, err = NewServiceWithConfig(&Config{Shards: -1})
+	assert.Error(t, err)
+}
+
+// TestService_Shards 测试分片后的服务与单个分片的服务行为一致
+func TestService_Shards(t *testing.T) {
+	ctx := context.Background()
+	single, err := NewService()
+	require.NoError(t, err)
+	sharded, err := NewService(WithShards(8))
+	require.NoError(t, err)
+
+	type result struct {
+		value any
+		err   bool
+	}
+	run := func(s *Service) []result {
+		var res []result
+		record := func(val any, err error) {
+			res = append(res, result{value: val, err: err != nil})
+		}
+		for i := range 50 {
+			record(nil, s.Set(ctx, fmt.Sprintf("key%d", i), i, time.Hour))
+		}
+		for i := range 50 {
+			if i%3 == 0 {
+				record(nil, s.Delete(ctx, fmt.Sprintf("key%d", i)))
+			}
+		}
+		ok, err := s.SetNX(ctx, "key1", "new", time.Hour)
+		record(ok, err)
+		ok, err = s.SetNX(ctx, "key3", "new", time.Hour)
+		record(ok, err)
+		for i := range 60 {
+			record(s.Get(ctx, fmt.Sprintf("key%d", i)))
+		}
+		record(s.LoadAndDelete(ctx, "key2"))
+		record(s.GetMany(ctx, []string{"key1", "key2", "key4"}))
+		stats, err := s.Stats(ctx)
+		record(stats, err)
+		return res
+	}
+
+	assert.Equal(t, run(single), run(sharded))
+}
+
+// TestService_Shards_Concurrent 测试分片服务的并发读写
+func TestService_Shards_Concurrent(t *testing.T) {
+	ctx := context.Background()
+	service, err := NewService(WithShards(16))
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 200 {
+				key := fmt.Sprintf("key%d", (g*200+i)%300)
+				assert.NoError(t, service.Set(ctx, key, i, time.Hour))
+				_, _ = service.Get(ctx, key)
+				if i%10 == 0 {
+					assert.NoError(t, service.Delete(ctx, key))
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	stats, err := service.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(8*200), stats.HitCount+stats.MissCount)
+	assert.LessOrEqual(t, stats.ItemCount, int64(300))
+}
+
+func TestWithCodec(t *testing.T) {
+	config := DefaultConfig()
+	assert.Nil(t, config.Codec)
+	option := WithCodec(JSONCodec{})
+	option(config)
+
+	assert.Equal(t, JSONCodec{}, config.Codec)
+}
+
+// typedUser 用于测试类型化读写的结构体
+type typedUser struct {
+	Name    string            `json:"name"`
+	Age     int               `json:"age"`
+	Profile map[string]string `json:"profile"`
+}
+
+// TestService_Typed 测试通过JSON序列化器读写结构体
+func TestService_Typed(t *testing.T) {
+	ctx := context.Background()
+	service, err := NewService(WithCodec(JSONCodec{}))
+	require.NoError(t, err)
+
+	want := typedUser{Name: "Tom", Age: 18, Profile: map[string]string{"city": "Shanghai"}}
+	require.NoError(t, SetTyped(ctx, service, "user:1", want, time.Hour))
+
+	got, err := GetTyped[typedUser](ctx, service, "user:1")
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	// Get 返回反序列化为any的值
+	val, err := service.Get(ctx, "user:1")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"name": "Tom", "age": float64(18), "profile": map[string]any{"city": "Shanghai"}}, val)
+
+	_, err = GetTyped[typedUser](ctx, service, "absent")
+	assert.Error(t, err)
+	_, err = GetTyped[typedUser](ctx, service, "")
+	assert.Error(t, err)
+
+	stats, err := service.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), stats.HitCount)
+	assert.Equal(t, int64(1), stats.MissCount)
+
+	// 无法序列化的值
+	assert.Error(t, service.Set(ctx, "chan", make(chan int), time.Hour))
+}
+
+// TestService_Typed_GobCodec 测试通过gob序列化器读写结构体，Get 能够还原原始类型
+func TestService_Typed_GobCodec(t *testing.T) {
+	ctx := context.Background()
+	gob.Register(typedUser{})
+	service, err := NewService(WithCodec(GobCodec{}))
+	require.NoError(t, err)
+
+	want := typedUser{Name: "Tom", Age: 18}
+	require.NoError(t, SetTyped(ctx, service, "user:1", want, time.Hour))
+	got, err := GetTyped[typedUser](ctx, service, "user:1")
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	val, err := service.Get(ctx, "user:1")
+	require.NoError(t, err)
+	assert.Equal(t, want, val)
+
+	_, err = GetTyped[string](ctx, service, "user:1")
+	assert.Error(t, err)
+}
+
+// TestService_Typed_NoCodec 测试未配置序列化器时直接断言缓存值的类型
+func TestService_Typed_NoCodec(t *testing.T) {
+	ctx := context.Background()
+	service, err := NewService()
+	require.NoError(t, err)
+
+	want := &typedUser{Name: "Tom"}
+	require.NoError(t, SetTyped(ctx, service, "user:1", want, time.Hour))
+	got, err := GetTyped[*typedUser](ctx, service, "user:1")
+	require.NoError(t, err)
+	assert.Same(t, want, got)
+
+	_, err = GetTyped[string](ctx, service, "user:1")
+	assert.Error(t, err)
+}
+
+func TestWithOnEvicted(t *testing.T) {
+	config := DefaultConfig()
+	called := false
+	option := WithOnEvicted(func(string, any) { called = true })
+	option(config)
+
+	require.NotNil(t, config.OnEvicted)
+	config.OnEvicted("key", "value")
+	assert.True(t, called)
+}
+
+// TestService_WithOnEvicted 测试通过选项设置的回调在缓存项过期和删除时触发
+func TestService_WithOnEvicted(t *testing.T) {
+	var mu sync.Mutex
+	evicted := make(map[string]any)
+	service, err := NewService(
+		WithCleanupInterval(10*time.Millisecond),
+		WithOnEvicted(func(key string, val any) {
+			mu.Lock()
+			defer mu.Unlock()
+			evicted[key] = val
+		}),
+	)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, service.Set(ctx, "expiring", "value1", 20*time.Millisecond))
+	require.NoError(t, service.Set(ctx, "deleted", "value2", time.Hour))
+	require.NoError(t, service.Delete(ctx, "deleted"))
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(evicted) == 2
+	}, time.Second, 10*time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, map[string]any{"expiring": "value1", "deleted": "value2"}, evicted)
+}
+
 func TestService_OnEvicted(t *testing.T) {
 	service, err := NewService()
 	require.NoError(t, err)
@@ -258,6 +702,15 @@ func TestService_OnEvicted(t *testing.T) {
 	service.OnEvicted(func(key string, val any) {
 		// 回调函数
 	})
+
+	var deleted []string
+	service.OnEvicted(func(key string, val any) {
+		deleted = append(deleted, key)
+	})
+	ctx := context.Background()
+	require.NoError(t, service.Set(ctx, "key1", "value1", time.Hour))
+	require.NoError(t, service.Delete(ctx, "key1"))
+	assert.Equal(t, []string{"key1"}, deleted)
 }
 
 func TestNewReadThroughService(t *testing.T) {
@@ -328,3 +781,38 @@ func TestReadThroughService_GetWithLoaderError(t *testing.T) {
 	_, err = service.GetWithLoader(ctx, key, loader, time.Hour)
 	assert.Error(t, err)
 }
+
+func TestReadThroughService_GetWithLoaderTTL(t *testing.T) {
+	service, err := NewReadThroughService()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	ttls := map[string]time.Duration{
+		"short": 50 * time.Millisecond,
+		"long":  time.Hour,
+	}
+	loadCount := map[string]int{}
+	loader := func(ctx context.Context, key string) (any, time.Duration, error) {
+		loadCount[key]++
+		return "value_" + key, ttls[key], nil
+	}
+
+	for key := range ttls {
+		value, err := service.GetWithLoaderTTL(ctx, key, loader)
+		require.NoError(t, err)
+		assert.Equal(t, "value_"+key, value)
+	}
+
+	// 短过期时间的缓存项过期后重新加载，长过期时间的仍然命中
+	time.Sleep(100 * time.Millisecond)
+	for key := range ttls {
+		_, err := service.GetWithLoaderTTL(ctx, key, loader)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, map[string]int{"short": 2, "long": 1}, loadCount)
+
+	_, err = service.GetWithLoaderTTL(ctx, "error", func(ctx context.Context, key string) (any, time.Duration, error) {
+		return nil, 0, assert.AnError
+	})
+	assert.ErrorIs(t, err, assert.AnError)
+}
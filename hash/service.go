@@ -3,6 +3,7 @@ package hash
 import (
 	"context"
 	"fmt"
+	"math/rand/v2"
 
 	appHash "github.com/justinwongcn/hamster/internal/application/consistent_hash"
 	domainHash "github.com/justinwongcn/hamster/internal/domain/consistent_hash"
@@ -24,6 +25,13 @@ type Config struct {
 
 	// EnableSingleflight 是否启用单飞模式
 	EnableSingleflight bool
+
+	// WeightedNodes 是否按节点权重分配虚拟节点
+	// 启用后节点的虚拟节点数量为 Replicas*Weight，权重不大于0的节点视为1
+	WeightedNodes bool
+
+	// Rand 按权重选择副本节点使用的随机数生成器，为nil时使用全局随机源
+	Rand *rand.Rand
 }
 
 // DefaultConfig 返回默认配置
@@ -59,6 +67,21 @@ func WithSingleflight(enable bool) Option {
 	}
 }
 
+// WithWeightedNodes 设置是否按节点权重分配虚拟节点，默认关闭
+func WithWeightedNodes(enable bool) Option {
+	return func(c *Config) {
+		c.WeightedNodes = enable
+	}
+}
+
+// WithRand 设置按权重选择副本节点使用的随机数生成器
+// 注入固定种子的生成器可以得到确定的选择结果，便于测试
+func WithRand(rng *rand.Rand) Option {
+	return func(c *Config) {
+		c.Rand = rng
+	}
+}
+
 // NewService 创建一致性哈希服务
 func NewService(options ...Option) (*Service, error) {
 	config := DefaultConfig()
@@ -79,16 +102,14 @@ func NewServiceWithConfig(config *Config) (*Service, error) {
 	hashMap := infraHash.NewConsistentHashMap(config.Replicas, config.HashFunction)
 
 	// 创建节点选择器
-	var peerPicker domainHash.PeerPicker
-	if config.EnableSingleflight {
-		peerPicker = infraHash.NewSingleflightPeerPicker(hashMap)
-	} else {
-		// 暂时只支持 singleflight 模式
-		peerPicker = infraHash.NewSingleflightPeerPicker(hashMap)
-	}
+	// 暂时只支持 singleflight 模式
+	picker := infraHash.NewSingleflightPeerPicker(hashMap)
+	picker.SetWeighted(config.WeightedNodes)
+	var peerPicker domainHash.PeerPicker = picker
 
 	// 创建应用服务
 	appService := appHash.NewConsistentHashApplicationService(peerPicker)
+	appService.SetRand(config.Rand)
 
 	return &Service{
 		appService: appService,
@@ -190,7 +211,65 @@ func (s *Service) SelectPeers(ctx context.Context, key string, count int) ([]Pee
 	return peers, nil
 }
 
+// SelectPeerWithFallback 根据键选择按哈希环顺序排列的候选节点
+// 第一个为键的首选节点，之后为哈希环上的后继节点，已标记为失效的节点会被跳过，
+// 调用方在节点失败时可以沿列表依次重试
+func (s *Service) SelectPeerWithFallback(ctx context.Context, key string) ([]Peer, error) {
+	cmd := appHash.PeerSelectionCommand{Key: key}
+
+	result, err := s.appService.SelectFallbackPeers(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	peers := make([]Peer, len(result.Peers))
+	for i, peer := range result.Peers {
+		peers[i] = Peer{
+			ID:      peer.ID,
+			Address: peer.Address,
+			Weight:  peer.Weight,
+			IsAlive: peer.IsAlive,
+		}
+	}
+
+	return peers, nil
+}
+
+// SelectWeightedReplica 按权重从键的副本节点中随机选择一个节点
+// 副本节点为哈希环上键的前replicas个存活节点，失效节点不占用副本名额，节点被选中的概率与其 Weight 成正比，
+// 用于在副本之间分摊读请求；Weight不大于0的节点按1计算
+func (s *Service) SelectWeightedReplica(ctx context.Context, key string, replicas int) (Peer, error) {
+	cmd := appHash.MultiplePeerSelectionCommand{
+		Key:   key,
+		Count: replicas,
+	}
+
+	result, err := s.appService.SelectWeightedReplica(ctx, cmd)
+	if err != nil {
+		return Peer{}, err
+	}
+
+	return Peer{
+		ID:      result.Peer.ID,
+		Address: result.Peer.Address,
+		Weight:  result.Peer.Weight,
+		IsAlive: result.Peer.IsAlive,
+	}, nil
+}
+
+// SetPeerAlive 标记节点是否存活
+// 失效的节点不会被选中，直到重新标记为存活
+func (s *Service) SetPeerAlive(ctx context.Context, peerID string, alive bool) error {
+	cmd := appHash.UpdatePeerStatusCommand{
+		PeerID: peerID,
+		Alive:  alive,
+	}
+
+	return s.appService.UpdatePeerStatus(ctx, cmd)
+}
+
 // GetStats 获取哈希统计信息
+// KeyDistribution 为每个节点的虚拟节点数量，按实际的键统计分布使用 GetStatsForKeys
 func (s *Service) GetStats(ctx context.Context) (*Stats, error) {
 	result, err := s.appService.GetHashStats(ctx)
 	if err != nil {
@@ -206,6 +285,24 @@ func (s *Service) GetStats(ctx context.Context) (*Stats, error) {
 	}, nil
 }
 
+// GetStatsForKeys 按样本键获取哈希统计信息
+// 对每个样本键计算其在哈希环上归属的节点并计数，KeyDistribution 为每个节点分到的样本键数量，
+// LoadBalance 为各节点键数量的标准差，用于按有代表性的键集合评估负载和规划容量
+func (s *Service) GetStatsForKeys(ctx context.Context, sampleKeys []string) (*Stats, error) {
+	result, err := s.appService.GetHashStatsForKeys(ctx, sampleKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Stats{
+		TotalPeers:      result.TotalPeers,
+		VirtualNodes:    result.VirtualNodes,
+		Replicas:        result.Replicas,
+		KeyDistribution: result.KeyDistribution,
+		LoadBalance:     result.LoadBalance,
+	}, nil
+}
+
 // HealthCheck 健康检查
 func (s *Service) HealthCheck(ctx context.Context) (*HealthStatus, error) {
 	result, err := s.appService.CheckHealth(ctx)
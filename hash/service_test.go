@@ -2,6 +2,9 @@ package hash
 
 import (
 	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -344,3 +347,233 @@ func TestHealthStatusStruct(t *testing.T) {
 	assert.True(t, status.IsHealthy)
 	assert.Equal(t, "All systems operational", status.Message)
 }
+
+func TestWithWeightedNodes(t *testing.T) {
+	config := DefaultConfig()
+	assert.False(t, config.WeightedNodes)
+	option := WithWeightedNodes(true)
+	option(config)
+
+	assert.True(t, config.WeightedNodes)
+}
+
+// TestService_WeightedNodes 测试启用权重后虚拟节点分布与节点权重成正比
+func TestService_WeightedNodes(t *testing.T) {
+	ctx := context.Background()
+	peers := []Peer{
+		{ID: "server1", Address: "192.168.1.1:8080", Weight: 1},
+		{ID: "server2", Address: "192.168.1.2:8080", Weight: 2},
+	}
+
+	service, err := NewService(WithReplicas(20), WithWeightedNodes(true))
+	require.NoError(t, err)
+	require.NoError(t, service.AddPeers(ctx, peers))
+	require.NoError(t, service.AddPeer(ctx, Peer{ID: "server3", Address: "192.168.1.3:8080", Weight: 4}))
+
+	stats, err := service.GetStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"server1": 20, "server2": 40, "server3": 80}, stats.KeyDistribution)
+	assert.Equal(t, 140, stats.VirtualNodes)
+
+	// 默认关闭，所有节点的虚拟节点数量相同
+	service, err = NewService(WithReplicas(20))
+	require.NoError(t, err)
+	require.NoError(t, service.AddPeers(ctx, peers))
+	stats, err = service.GetStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"server1": 20, "server2": 20}, stats.KeyDistribution)
+}
+
+// TestService_SelectPeerWithFallback 测试候选节点按哈希环顺序排列并跳过失效节点
+func TestService_SelectPeerWithFallback(t *testing.T) {
+	ctx := context.Background()
+	service, err := NewService(WithReplicas(10))
+	require.NoError(t, err)
+
+	_, err = service.SelectPeerWithFallback(ctx, "user:123")
+	assert.Error(t, err)
+
+	require.NoError(t, service.AddPeers(ctx, []Peer{
+		{ID: "server1", Address: "192.168.1.1:8080", Weight: 1},
+		{ID: "server2", Address: "192.168.1.2:8080", Weight: 1},
+		{ID: "server3", Address: "192.168.1.3:8080", Weight: 1},
+	}))
+
+	peers, err := service.SelectPeerWithFallback(ctx, "user:123")
+	require.NoError(t, err)
+	require.Len(t, peers, 3)
+	primary, err := service.SelectPeer(ctx, "user:123")
+	require.NoError(t, err)
+	assert.Equal(t, primary.ID, peers[0].ID)
+
+	// 与按数量选择副本节点的顺序一致
+	replicas, err := service.SelectPeers(ctx, "user:123", 3)
+	require.NoError(t, err)
+	assert.Equal(t, replicas, peers)
+
+	// 首选节点失效后，列表从下一个存活节点开始
+	require.NoError(t, service.SetPeerAlive(ctx, peers[0].ID, false))
+	fallback, err := service.SelectPeerWithFallback(ctx, "user:123")
+	require.NoError(t, err)
+	assert.Equal(t, peers[1:], fallback)
+
+	// 节点恢复后重新成为首选节点
+	require.NoError(t, service.SetPeerAlive(ctx, peers[0].ID, true))
+	fallback, err = service.SelectPeerWithFallback(ctx, "user:123")
+	require.NoError(t, err)
+	assert.Equal(t, peers, fallback)
+
+	_, err = service.SelectPeerWithFallback(ctx, "")
+	assert.Error(t, err)
+	assert.Error(t, service.SetPeerAlive(ctx, "unknown", false))
+}
+
+func TestWithRand(t *testing.T) {
+	config := DefaultConfig()
+	assert.Nil(t, config.Rand)
+	rng := rand.New(rand.NewPCG(1, 2))
+	option := WithRand(rng)
+	option(config)
+
+	assert.Same(t, rng, config.Rand)
+}
+
+// TestService_SelectWeightedReplica 测试副本节点按权重被选中
+func TestService_SelectWeightedReplica(t *testing.T) {
+	ctx := context.Background()
+	service, err := NewService(WithReplicas(10), WithRand(rand.New(rand.NewPCG(1, 2))))
+	require.NoError(t, err)
+	replicas := []Peer{
+		{ID: "server1", Address: "192.168.1.1:8080", Weight: 2},
+		{ID: "server2", Address: "192.168.1.2:8080", Weight: 1},
+	}
+	require.NoError(t, service.AddPeers(ctx, replicas))
+
+	const calls = 3000
+	counts := make(map[string]int)
+	for range calls {
+		peer, err := service.SelectWeightedReplica(ctx, "user:123", 2)
+		require.NoError(t, err)
+		counts[peer.ID]++
+	}
+
+	// 选中比例接近2:1的权重之比
+	assert.InDelta(t, 2.0/3, float64(counts["server1"])/calls, 0.05)
+	assert.InDelta(t, 1.0/3, float64(counts["server2"])/calls, 0.05)
+
+	// 只在前N个副本节点中选择
+	require.NoError(t, service.AddPeer(ctx, Peer{ID: "server3", Address: "192.168.1.3:8080", Weight: 1}))
+	primary, err := service.SelectPeer(ctx, "user:123")
+	require.NoError(t, err)
+	for range 20 {
+		peer, err := service.SelectWeightedReplica(ctx, "user:123", 1)
+		require.NoError(t, err)
+		assert.Equal(t, primary.ID, peer.ID)
+	}
+
+	// 相同种子得到相同的选择序列
+	first, err := NewService(WithReplicas(10), WithRand(rand.New(rand.NewPCG(7, 7))))
+	require.NoError(t, err)
+	second, err := NewService(WithReplicas(10), WithRand(rand.New(rand.NewPCG(7, 7))))
+	require.NoError(t, err)
+	for _, s := range []*Service{first, second} {
+		require.NoError(t, s.AddPeers(ctx, replicas))
+	}
+	for range 20 {
+		a, err := first.SelectWeightedReplica(ctx, "user:123", 2)
+		require.NoError(t, err)
+		b, err := second.SelectWeightedReplica(ctx, "user:123", 2)
+		require.NoError(t, err)
+		assert.Equal(t, a, b)
+	}
+
+	_, err = service.SelectWeightedReplica(ctx, "user:123", 0)
+	assert.Error(t, err)
+}
+
+// TestService_SelectWeightedReplica_DeadPeer 测试副本范围内的节点失效时沿哈希环补足存活的副本节点
+func TestService_SelectWeightedReplica_DeadPeer(t *testing.T) {
+	ctx := context.Background()
+	service, err := NewService(WithReplicas(10), WithRand(rand.New(rand.NewPCG(1, 2))))
+	require.NoError(t, err)
+	require.NoError(t, service.AddPeers(ctx, []Peer{
+		{ID: "server1", Address: "192.168.1.1:8080", Weight: 1},
+		{ID: "server2", Address: "192.168.1.2:8080", Weight: 1},
+		{ID: "server3", Address: "192.168.1.3:8080", Weight: 1},
+	}))
+
+	// 主节点失效
+	primary, err := service.SelectPeer(ctx, "user:123")
+	require.NoError(t, err)
+	require.NoError(t, service.SetPeerAlive(ctx, primary.ID, false))
+
+	candidates, err := service.SelectPeerWithFallback(ctx, "user:123")
+	require.NoError(t, err)
+	require.Len(t, candidates, 2)
+
+	// 两个副本都是存活节点，都会被选中，失效的主节点不会被选中
+	counts := make(map[string]int)
+	for range 200 {
+		peer, err := service.SelectWeightedReplica(ctx, "user:123", 2)
+		require.NoError(t, err)
+		counts[peer.ID]++
+	}
+	assert.Len(t, counts, 2)
+	assert.Zero(t, counts[primary.ID])
+	for _, peer := range candidates {
+		assert.Positive(t, counts[peer.ID])
+	}
+}
+
+// TestService_GetStatsForKeys 测试按样本键统计的分布与逐个选择节点的结果一致
+func TestService_GetStatsForKeys(t *testing.T) {
+	ctx := context.Background()
+	service, err := NewService(WithReplicas(50))
+	require.NoError(t, err)
+	require.NoError(t, service.AddPeers(ctx, []Peer{
+		{ID: "server1", Address: "192.168.1.1:8080", Weight: 100},
+		{ID: "server2", Address: "192.168.1.2:8080", Weight: 100},
+		{ID: "server3", Address: "192.168.1.3:8080", Weight: 100},
+	}))
+
+	keys := make([]string, 1000)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("user:%d", i)
+	}
+
+	// 逐个选择节点计数
+	want := map[string]int{"server1": 0, "server2": 0, "server3": 0}
+	for _, key := range keys {
+		peer, err := service.SelectPeer(ctx, key)
+		require.NoError(t, err)
+		want[peer.ID]++
+	}
+	mean := float64(len(keys)) / float64(len(want))
+	variance := 0.0
+	for _, count := range want {
+		variance += (float64(count) - mean) * (float64(count) - mean)
+	}
+	wantStdDev := math.Sqrt(variance / float64(len(want)))
+
+	stats, err := service.GetStatsForKeys(ctx, keys)
+	require.NoError(t, err)
+	assert.Equal(t, want, stats.KeyDistribution)
+	assert.InDelta(t, wantStdDev, stats.LoadBalance, 1e-9)
+	assert.Equal(t, 3, stats.TotalPeers)
+	assert.Equal(t, 150, stats.VirtualNodes)
+	assert.Equal(t, 50, stats.Replicas)
+
+	// 没有分到样本键的节点计为0
+	stats, err = service.GetStatsForKeys(ctx, keys[:1])
+	require.NoError(t, err)
+	assert.Len(t, stats.KeyDistribution, 3)
+	total := 0
+	for _, count := range stats.KeyDistribution {
+		total += count
+	}
+	assert.Equal(t, 1, total)
+	assert.InDelta(t, math.Sqrt(2.0/9.0), stats.LoadBalance, 1e-9)
+
+	_, err = service.GetStatsForKeys(ctx, nil)
+	assert.Error(t, err)
+}
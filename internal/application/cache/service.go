@@ -2,10 +2,13 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/justinwongcn/hamster/internal/domain/cache"
+	infraCache "github.com/justinwongcn/hamster/internal/infrastructure/cache"
 )
 
 // ApplicationService 缓存应用服务
@@ -14,6 +17,8 @@ type ApplicationService struct {
 	repository    cache.Repository
 	cacheService  *cache.CacheService
 	writeBackRepo cache.WriteBackRepository
+	stats         cache.CacheStats // 通过GetCacheItem读取的命中统计
+	statsMutex    sync.Mutex
 }
 
 // NewApplicationService 创建缓存应用服务
@@ -35,6 +40,13 @@ type CacheItemCommand struct {
 	Expiration time.Duration
 }
 
+// CacheItemDeadlineCommand 指定过期时间点的缓存项命令
+type CacheItemDeadlineCommand struct {
+	Key      string
+	Value    any
+	Deadline time.Time // 零值表示永不过期
+}
+
 // CacheItemQuery 缓存项查询
 type CacheItemQuery struct {
 	Key string
@@ -75,6 +87,53 @@ func (s *ApplicationService) SetCacheItem(ctx context.Context, cmd CacheItemComm
 	return nil
 }
 
+// SetCacheItemWithDeadline 设置缓存项，并指定绝对的过期时间点
+// 用例：调度器知道数据项确切的过期时间，希望避免换算成相对时长带来的误差
+// 返回: 错误信息，仓储不支持SetWithDeadline时返回错误
+func (s *ApplicationService) SetCacheItemWithDeadline(ctx context.Context, cmd CacheItemDeadlineCommand) error {
+	// 验证输入
+	if err := s.cacheService.ValidateKey(cmd.Key); err != nil {
+		return fmt.Errorf("验证缓存项命令失败: 无效的缓存键: %w", err)
+	}
+
+	repo, ok := s.repository.(interface {
+		SetWithDeadline(ctx context.Context, key string, val any, deadline time.Time) error
+	})
+	if !ok {
+		return fmt.Errorf("缓存仓储不支持SetWithDeadline操作")
+	}
+
+	if err := repo.SetWithDeadline(ctx, cmd.Key, cmd.Value, cmd.Deadline); err != nil {
+		return fmt.Errorf("设置缓存项失败: %w", err)
+	}
+
+	return nil
+}
+
+// SetCacheItemIfAbsent 仅当缓存项不存在时设置
+// 用例：用户想要幂等地初始化一个数据项，已存在时保留原值
+// 返回: 是否设置成功和错误信息，仓储不支持SetNX时返回错误
+func (s *ApplicationService) SetCacheItemIfAbsent(ctx context.Context, cmd CacheItemCommand) (bool, error) {
+	// 验证输入
+	if err := s.validateCacheItemCommand(cmd); err != nil {
+		return false, fmt.Errorf("验证缓存项命令失败: %w", err)
+	}
+
+	repo, ok := s.repository.(interface {
+		SetNX(ctx context.Context, key string, val any, expiration time.Duration) (bool, error)
+	})
+	if !ok {
+		return false, fmt.Errorf("缓存仓储不支持SetNX操作")
+	}
+
+	set, err := repo.SetNX(ctx, cmd.Key, cmd.Value, cmd.Expiration)
+	if err != nil {
+		return false, fmt.Errorf("设置缓存项失败: %w", err)
+	}
+
+	return set, nil
+}
+
 // GetCacheItem 获取缓存项
 // 用例：用户想要获取一个缓存的数据项
 func (s *ApplicationService) GetCacheItem(ctx context.Context, query CacheItemQuery) (*CacheItemResult, error) {
@@ -85,6 +144,7 @@ func (s *ApplicationService) GetCacheItem(ctx context.Context, query CacheItemQu
 
 	// 获取缓存
 	value, err := s.repository.Get(ctx, query.Key)
+	s.recordRead(err == nil)
 	if err != nil {
 		if err == cache.ErrKeyNotFound {
 			return &CacheItemResult{
@@ -104,6 +164,56 @@ func (s *ApplicationService) GetCacheItem(ctx context.Context, query CacheItemQu
 	}, nil
 }
 
+// GetCacheItemInto 获取缓存项并反序列化到target
+// 用例：缓存值经过序列化保存，用户想要按原始类型取回
+// target: 目标值的指针
+// 返回: 错误信息，仓储不支持GetInto时返回错误
+func (s *ApplicationService) GetCacheItemInto(ctx context.Context, query CacheItemQuery, target any) error {
+	// 验证输入
+	if err := s.validateCacheItemQuery(query); err != nil {
+		return fmt.Errorf("验证缓存项查询失败: %w", err)
+	}
+
+	repo, ok := s.repository.(interface {
+		GetInto(ctx context.Context, key string, ptr any) error
+	})
+	if !ok {
+		return fmt.Errorf("缓存仓储不支持GetInto操作")
+	}
+
+	err := repo.GetInto(ctx, query.Key, target)
+	s.recordRead(err == nil)
+	if err != nil {
+		return fmt.Errorf("获取缓存项失败: %w", err)
+	}
+
+	return nil
+}
+
+// CacheItemExists 检查缓存项是否存在
+// 用例：用户只想知道数据项是否存在，而不需要获取值
+// 返回: 是否存在和错误信息，仓储不支持Exists时返回错误
+func (s *ApplicationService) CacheItemExists(ctx context.Context, query CacheItemQuery) (bool, error) {
+	// 验证输入
+	if err := s.validateCacheItemQuery(query); err != nil {
+		return false, fmt.Errorf("验证缓存项查询失败: %w", err)
+	}
+
+	repo, ok := s.repository.(interface {
+		Exists(ctx context.Context, key string) (bool, error)
+	})
+	if !ok {
+		return false, fmt.Errorf("缓存仓储不支持Exists操作")
+	}
+
+	exists, err := repo.Exists(ctx, query.Key)
+	if err != nil {
+		return false, fmt.Errorf("检查缓存项失败: %w", err)
+	}
+
+	return exists, nil
+}
+
 // DeleteCacheItem 删除缓存项
 // 用例：用户想要删除一个缓存的数据项
 func (s *ApplicationService) DeleteCacheItem(ctx context.Context, query CacheItemQuery) error {
@@ -121,6 +231,103 @@ func (s *ApplicationService) DeleteCacheItem(ctx context.Context, query CacheIte
 	return nil
 }
 
+// SetCacheItems 批量设置缓存项
+// 用例：用户想要一次缓存多个数据项，避免逐个调用
+// 先验证所有缓存项，任一缓存项无效时不写入任何数据；写入时某一项失败不影响其他项
+// 返回: 错误信息，包含每个失败项的序号和缓存键
+func (s *ApplicationService) SetCacheItems(ctx context.Context, cmds []CacheItemCommand) error {
+	var errs []error
+	for i, cmd := range cmds {
+		if err := s.validateCacheItemCommand(cmd); err != nil {
+			errs = append(errs, itemError(i, cmd.Key, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("验证缓存项命令失败: %w", errors.Join(errs...))
+	}
+
+	for i, cmd := range cmds {
+		if err := s.repository.Set(ctx, cmd.Key, cmd.Value, cmd.Expiration); err != nil {
+			errs = append(errs, itemError(i, cmd.Key, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("设置缓存项失败: %w", errors.Join(errs...))
+	}
+
+	return nil
+}
+
+// GetCacheItems 批量获取缓存项
+// 用例：用户想要一次获取多个缓存的数据项
+// 先验证所有查询，任一查询无效时不读取任何数据；读取时某一项失败不影响其他项
+// 键不存在不视为失败，对应结果的Found为false
+// 返回: 与查询顺序一致的结果和错误信息，失败项的Found为false，错误中包含每个失败项的序号和缓存键
+func (s *ApplicationService) GetCacheItems(ctx context.Context, queries []CacheItemQuery) ([]CacheItemResult, error) {
+	var errs []error
+	for i, query := range queries {
+		if err := s.validateCacheItemQuery(query); err != nil {
+			errs = append(errs, itemError(i, query.Key, err))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("验证缓存项查询失败: %w", errors.Join(errs...))
+	}
+
+	results := make([]CacheItemResult, len(queries))
+	for i, query := range queries {
+		result, err := s.GetCacheItem(ctx, query)
+		if err != nil {
+			results[i] = CacheItemResult{Key: query.Key}
+			if !isKeyNotFound(err) {
+				errs = append(errs, itemError(i, query.Key, err))
+			}
+			continue
+		}
+		results[i] = *result
+	}
+	if len(errs) > 0 {
+		return results, fmt.Errorf("获取缓存项失败: %w", errors.Join(errs...))
+	}
+
+	return results, nil
+}
+
+// isKeyNotFound 判断错误是否表示键不存在
+// 仓储实现可能返回领域层的 ErrKeyNotFound，也可能返回基础设施层包装后的哨兵错误
+func isKeyNotFound(err error) bool {
+	return errors.Is(err, cache.ErrKeyNotFound) ||
+		errors.Is(err, infraCache.ErrCacheKeyNotFound) ||
+		errors.Is(err, infraCache.ErrKeyNotFound)
+}
+
+// DeleteCacheItems 批量删除缓存项
+// 用例：用户想要一次删除多个缓存的数据项
+// 先验证所有查询，任一查询无效时不删除任何数据；删除时某一项失败不影响其他项
+// 返回: 错误信息，包含每个失败项的序号和缓存键
+func (s *ApplicationService) DeleteCacheItems(ctx context.Context, queries []CacheItemQuery) error {
+	var errs []error
+	for i, query := range queries {
+		if err := s.validateCacheItemQuery(query); err != nil {
+			errs = append(errs, itemError(i, query.Key, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("验证缓存项查询失败: %w", errors.Join(errs...))
+	}
+
+	for i, query := range queries {
+		if err := s.repository.Delete(ctx, query.Key); err != nil {
+			errs = append(errs, itemError(i, query.Key, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("删除缓存项失败: %w", errors.Join(errs...))
+	}
+
+	return nil
+}
+
 // SetDirtyCacheItem 设置脏缓存项（仅写回模式）
 // 用例：用户想要设置一个脏数据项，稍后批量写入持久化存储
 func (s *ApplicationService) SetDirtyCacheItem(ctx context.Context, cmd CacheItemCommand) error {
@@ -164,25 +371,45 @@ func (s *ApplicationService) FlushDirtyData(ctx context.Context, storer func(ctx
 
 // GetCacheStats 获取缓存统计信息
 // 用例：用户想要查看缓存的使用情况和性能指标
+// 命中统计来自GetCacheItem的调用，读取失败（包括键不存在）计为未命中；
+// 仓储实现了 Len() int 时返回缓存项数量，写回缓存仓储实现了 GetDirtyKeys() []string 时返回脏数据键
 func (s *ApplicationService) GetCacheStats(ctx context.Context) (*CacheStatsResult, error) {
-	// 这里应该从缓存实例获取统计信息
-	// 由于当前的Repository接口没有提供统计信息，我们返回基本信息
+	s.statsMutex.Lock()
+	stats := s.stats
+	s.statsMutex.Unlock()
+
 	result := &CacheStatsResult{
-		Hits:    0,
-		Misses:  0,
-		HitRate: 0.0,
-		Size:    0,
+		Hits:    stats.Hits(),
+		Misses:  stats.Misses(),
+		HitRate: stats.HitRate(),
+	}
+
+	if repo, ok := s.repository.(interface{ Len() int }); ok {
+		result.Size = int64(repo.Len())
 	}
 
 	// 如果是写回缓存，获取脏数据键
 	if s.writeBackRepo != nil {
-		// 这里需要扩展WriteBackRepository接口来获取脏数据键
-		result.DirtyKeys = []string{} // 暂时返回空列表
+		result.DirtyKeys = []string{}
+		if repo, ok := s.writeBackRepo.(interface{ GetDirtyKeys() []string }); ok {
+			result.DirtyKeys = repo.GetDirtyKeys()
+		}
 	}
 
 	return result, nil
 }
 
+// recordRead 记录一次读取的命中情况
+func (s *ApplicationService) recordRead(hit bool) {
+	s.statsMutex.Lock()
+	defer s.statsMutex.Unlock()
+	if hit {
+		s.stats = s.stats.IncrementHits()
+	} else {
+		s.stats = s.stats.IncrementMisses()
+	}
+}
+
 // validateCacheItemCommand 验证缓存项命令
 func (s *ApplicationService) validateCacheItemCommand(cmd CacheItemCommand) error {
 	if err := s.cacheService.ValidateKey(cmd.Key); err != nil {
@@ -193,13 +420,15 @@ func (s *ApplicationService) validateCacheItemCommand(cmd CacheItemCommand) erro
 		return fmt.Errorf("无效的过期时间: %w", err)
 	}
 
-	if cmd.Value == nil {
-		return fmt.Errorf("缓存值不能为空")
-	}
-
+	// 缓存值允许为nil，用于缓存“查询结果为空”，与键不存在区分
 	return nil
 }
 
+// itemError 为批量操作中单个缓存项的错误添加序号和缓存键
+func itemError(index int, key string, err error) error {
+	return fmt.Errorf("第%d项（键: %q）: %w", index, key, err)
+}
+
 // validateCacheItemQuery 验证缓存项查询
 func (s *ApplicationService) validateCacheItemQuery(query CacheItemQuery) error {
 	if err := s.cacheService.ValidateKey(query.Key); err != nil {
@@ -260,6 +489,36 @@ func (s *ReadThroughApplicationService) GetWithLoader(
 	}, nil
 }
 
+// GetWithLoaderTTL 使用加载器获取缓存项，过期时间由加载器返回
+// 用例：数据的新鲜度各不相同，由数据源决定每个缓存项的过期时间
+func (s *ReadThroughApplicationService) GetWithLoaderTTL(
+	ctx context.Context,
+	query CacheItemQuery,
+	loader func(ctx context.Context, key string) (any, time.Duration, error),
+) (*CacheItemResult, error) {
+	// 验证输入
+	if err := s.validateCacheItemQuery(query); err != nil {
+		return nil, fmt.Errorf("验证缓存项查询失败: %w", err)
+	}
+
+	if loader == nil {
+		return nil, fmt.Errorf("加载器函数不能为空")
+	}
+
+	value, err := s.readThroughRepo.GetWithLoaderTTL(ctx, query.Key, loader)
+	if err != nil {
+		return nil, fmt.Errorf("读透缓存获取失败: %w", err)
+	}
+
+	return &CacheItemResult{
+		Key:       query.Key,
+		Value:     value,
+		Found:     true,
+		CreatedAt: time.Now(),
+		IsDirty:   false,
+	}, nil
+}
+
 // WriteThroughApplicationService 写透缓存应用服务
 // 专门处理写透缓存的业务用例
 type WriteThroughApplicationService struct {
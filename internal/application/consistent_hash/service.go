@@ -3,6 +3,8 @@ package consistent_hash
 import (
 	"context"
 	"fmt"
+	"math/rand/v2"
+	"sync"
 
 	domainHash "github.com/justinwongcn/hamster/internal/domain/consistent_hash"
 )
@@ -11,6 +13,8 @@ import (
 // 协调领域服务和基础设施，实现具体的一致性哈希业务用例
 type ConsistentHashApplicationService struct {
 	peerPicker domainHash.PeerPicker
+	rand       *rand.Rand // 加权选择副本节点使用的随机数生成器，为nil时使用全局随机源
+	randMu     sync.Mutex // rand.Rand不是并发安全的
 }
 
 // NewConsistentHashApplicationService 创建一致性哈希应用服务
@@ -21,6 +25,14 @@ func NewConsistentHashApplicationService(peerPicker domainHash.PeerPicker) *Cons
 	}
 }
 
+// SetRand 设置加权选择副本节点使用的随机数生成器
+// rng: 随机数生成器，为nil时使用全局随机源；注入固定种子的生成器可以得到确定的选择结果
+func (s *ConsistentHashApplicationService) SetRand(rng *rand.Rand) {
+	s.randMu.Lock()
+	defer s.randMu.Unlock()
+	s.rand = rng
+}
+
 // PeerSelectionCommand 节点选择命令
 type PeerSelectionCommand struct {
 	Key string `json:"key"`
@@ -42,6 +54,12 @@ type RemovePeersCommand struct {
 	PeerIDs []string `json:"peer_ids"`
 }
 
+// UpdatePeerStatusCommand 更新节点状态命令
+type UpdatePeerStatusCommand struct {
+	PeerID string `json:"peer_id"`
+	Alive  bool   `json:"alive"`
+}
+
 // PeerRequest 节点请求
 type PeerRequest struct {
 	ID      string `json:"id"`
@@ -131,6 +149,100 @@ func (s *ConsistentHashApplicationService) SelectMultiplePeers(ctx context.Conte
 	}, nil
 }
 
+// SelectFallbackPeers 选择按哈希环顺序排列的候选节点
+// 用例：主节点失败时，用户想要沿着候选列表依次重试后继节点
+// 返回的节点均为存活节点，第一个为键的首选节点
+func (s *ConsistentHashApplicationService) SelectFallbackPeers(ctx context.Context, cmd PeerSelectionCommand) (*MultiplePeerSelectionResult, error) {
+	// 验证输入
+	if err := s.validatePeerSelectionCommand(cmd); err != nil {
+		return nil, fmt.Errorf("验证节点选择命令失败: %w", err)
+	}
+
+	// 沿哈希环取出全部节点，由节点选择器跳过已失效的节点
+	peers, err := s.peerPicker.PickPeers(cmd.Key, len(s.peerPicker.GetAllPeers()))
+	if err != nil {
+		return nil, fmt.Errorf("选择候选节点失败: %w", err)
+	}
+
+	peerResults := make([]PeerResult, len(peers))
+	for i, peer := range peers {
+		peerResults[i] = s.buildPeerResult(peer)
+	}
+
+	return &MultiplePeerSelectionResult{
+		Key:   cmd.Key,
+		Peers: peerResults,
+		Count: len(peerResults),
+	}, nil
+}
+
+// SelectWeightedReplica 按权重从键的副本节点中随机选择一个节点
+// 用例：用户想要在多个副本之间分摊读请求，权重越大的节点被选中的概率越高
+// 副本节点为哈希环上键的前Count个存活节点，权重不大于0的节点按1计算
+func (s *ConsistentHashApplicationService) SelectWeightedReplica(ctx context.Context, cmd MultiplePeerSelectionCommand) (*PeerSelectionResult, error) {
+	// 验证输入
+	if err := s.validateMultiplePeerSelectionCommand(cmd); err != nil {
+		return nil, fmt.Errorf("验证多节点选择命令失败: %w", err)
+	}
+
+	// 沿哈希环取出全部节点，由节点选择器跳过已失效的节点，再取前Count个存活节点，
+	// 失效节点位于副本范围内时继续使用环上更远的存活节点补足副本数
+	peers, err := s.peerPicker.PickPeers(cmd.Key, len(s.peerPicker.GetAllPeers()))
+	if err != nil {
+		return nil, fmt.Errorf("选择副本节点失败: %w", err)
+	}
+	peers = peers[:min(cmd.Count, len(peers))]
+
+	total := 0
+	for _, peer := range peers {
+		total += max(peer.Weight(), 1)
+	}
+
+	n := s.intN(total)
+	for _, peer := range peers {
+		n -= max(peer.Weight(), 1)
+		if n < 0 {
+			return &PeerSelectionResult{
+				Key:  cmd.Key,
+				Peer: s.buildPeerResult(peer),
+			}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("选择副本节点失败: %w", domainHash.ErrNoPeers)
+}
+
+// intN 返回 [0, n) 区间内的随机整数
+func (s *ConsistentHashApplicationService) intN(n int) int {
+	s.randMu.Lock()
+	defer s.randMu.Unlock()
+	if s.rand != nil {
+		return s.rand.IntN(n)
+	}
+	return rand.IntN(n)
+}
+
+// UpdatePeerStatus 更新节点存活状态
+// 用例：用户探测到节点失效或恢复，想要让节点选择跳过或重新使用该节点
+func (s *ConsistentHashApplicationService) UpdatePeerStatus(ctx context.Context, cmd UpdatePeerStatusCommand) error {
+	if cmd.PeerID == "" {
+		return fmt.Errorf("验证更新节点状态命令失败: 节点ID不能为空")
+	}
+
+	picker, ok := s.peerPicker.(interface {
+		UpdatePeerStatus(peerID string, alive bool) error
+	})
+	if !ok {
+		return fmt.Errorf("节点选择器不支持更新节点状态")
+	}
+
+	if err := picker.UpdatePeerStatus(cmd.PeerID, cmd.Alive); err != nil {
+		return fmt.Errorf("更新节点状态失败: %w", err)
+	}
+
+	return nil
+}
+
 // AddPeers 添加节点
 // 用例：用户想要向集群中添加新的节点
 func (s *ConsistentHashApplicationService) AddPeers(ctx context.Context, cmd AddPeersCommand) error {
@@ -208,8 +320,21 @@ func (s *ConsistentHashApplicationService) GetHashStats(ctx context.Context) (*H
 		return nil, fmt.Errorf("节点选择器不健康: %w", err)
 	}
 
-	// 获取统计信息（这里需要扩展PeerPicker接口或使用类型断言）
-	// 为了简化，我们返回基本信息
+	// 节点选择器提供统计信息时使用其中的虚拟节点分布
+	if picker, ok := s.peerPicker.(interface {
+		GetStats() domainHash.HashStats
+	}); ok {
+		stats := picker.GetStats()
+		return &HashStatsResult{
+			TotalPeers:      stats.TotalPeers(),
+			VirtualNodes:    stats.VirtualNodes(),
+			Replicas:        stats.Replicas(),
+			KeyDistribution: stats.KeyDistribution(),
+			LoadBalance:     stats.LoadBalance(),
+		}, nil
+	}
+
+	// 否则只返回基本信息
 	peers := s.peerPicker.GetAllPeers()
 	
 	return &HashStatsResult{
@@ -221,6 +346,66 @@ func (s *ConsistentHashApplicationService) GetHashStats(ctx context.Context) (*H
 	}, nil
 }
 
+// GetHashStatsForKeys 按样本键统计哈希环的键分布
+// 用例：用户想要用一组有代表性的键评估各节点实际分到的键数量，用于容量规划
+// sampleKeys: 样本键，重复的键按出现次数计入
+// 返回: 统计结果，KeyDistribution 为每个节点分到的样本键数量（包含没有分到键的节点），
+// LoadBalance 为各节点键数量的标准差
+func (s *ConsistentHashApplicationService) GetHashStatsForKeys(ctx context.Context, sampleKeys []string) (*HashStatsResult, error) {
+	if len(sampleKeys) == 0 {
+		return nil, fmt.Errorf("%w: 样本键不能为空", domainHash.ErrInvalidKey)
+	}
+
+	healthy, err := s.peerPicker.IsHealthy()
+	if !healthy {
+		return nil, fmt.Errorf("节点选择器不健康: %w", err)
+	}
+
+	distribution := make(map[string]int)
+	for _, peer := range s.peerPicker.GetAllPeers() {
+		distribution[peer.ID()] = 0
+	}
+	// 可以访问底层哈希环时直接统计哈希环上的归属，否则逐个选择节点
+	if picker, ok := s.peerPicker.(interface {
+		GetConsistentHash() domainHash.ConsistentHash
+	}); ok {
+		if m, ok := picker.GetConsistentHash().(interface {
+			GetLoadDistribution(testKeys []string) map[string]int
+		}); ok {
+			for peerID, count := range m.GetLoadDistribution(sampleKeys) {
+				distribution[peerID] += count
+			}
+			return s.buildSampleStatsResult(distribution), nil
+		}
+	}
+	for _, key := range sampleKeys {
+		if peer, err := s.peerPicker.PickPeer(key); err == nil {
+			distribution[peer.ID()]++
+		}
+	}
+	return s.buildSampleStatsResult(distribution), nil
+}
+
+// buildSampleStatsResult 根据样本键分布构建统计结果
+// 节点数和虚拟节点信息在节点选择器提供统计信息时从中获取
+func (s *ConsistentHashApplicationService) buildSampleStatsResult(distribution map[string]int) *HashStatsResult {
+	totalPeers, virtualNodes, replicas := len(distribution), 0, 0
+	if picker, ok := s.peerPicker.(interface {
+		GetStats() domainHash.HashStats
+	}); ok {
+		stats := picker.GetStats()
+		totalPeers, virtualNodes, replicas = stats.TotalPeers(), stats.VirtualNodes(), stats.Replicas()
+	}
+	stats := domainHash.NewHashStats(totalPeers, virtualNodes, replicas, distribution)
+	return &HashStatsResult{
+		TotalPeers:      stats.TotalPeers(),
+		VirtualNodes:    stats.VirtualNodes(),
+		Replicas:        stats.Replicas(),
+		KeyDistribution: stats.KeyDistribution(),
+		LoadBalance:     stats.LoadBalance(),
+	}
+}
+
 // CheckHealth 检查健康状态
 // 用例：用户想要检查一致性哈希系统是否健康
 func (s *ConsistentHashApplicationService) CheckHealth(ctx context.Context) (*HealthCheckResult, error) {
@@ -2,8 +2,12 @@ package lock
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"iter"
+	"math/rand/v2"
+	"slices"
+	"sync"
 	"time"
 
 	domainLock "github.com/justinwongcn/hamster/internal/domain/lock"
@@ -13,6 +17,19 @@ import (
 // 协调领域服务和基础设施，实现具体的分布式锁业务用例
 type DistributedLockApplicationService struct {
 	distributedLock domainLock.DistributedLock
+
+	mu           sync.Mutex
+	refreshTasks map[string]*autoRefreshTask // 按锁键记录正在运行的自动续约
+	held         map[string]domainLock.Lock  // 按锁键记录通过本服务获取的锁，用于 UnlockHeld 和 UnlockAll
+	pruneAt      int                         // held增长到该数量时清理已过期的锁
+}
+
+// heldPruneMinSize 记录的锁数量达到该值之前不清理已过期的锁
+const heldPruneMinSize = 64
+
+// autoRefreshTask 正在运行的自动续约任务
+type autoRefreshTask struct {
+	cancel context.CancelFunc
 }
 
 // NewDistributedLockApplicationService 创建分布式锁应用服务
@@ -20,6 +37,8 @@ type DistributedLockApplicationService struct {
 func NewDistributedLockApplicationService(distributedLock domainLock.DistributedLock) *DistributedLockApplicationService {
 	return &DistributedLockApplicationService{
 		distributedLock: distributedLock,
+		refreshTasks:    make(map[string]*autoRefreshTask),
+		held:            make(map[string]domainLock.Lock),
 	}
 }
 
@@ -28,9 +47,12 @@ type LockCommand struct {
 	Key        string        `json:"key"`
 	Expiration time.Duration `json:"expiration"`
 	Timeout    time.Duration `json:"timeout"`
-	RetryType  string        `json:"retry_type"` // "fixed", "exponential", "linear"
+	RetryType  string        `json:"retry_type"` // "fixed", "exponential", "linear", "decorrelated"
 	RetryCount int           `json:"retry_count"`
 	RetryBase  time.Duration `json:"retry_base"`
+	RetryCap   time.Duration `json:"retry_cap"` // 最大重试间隔，仅用于"decorrelated"，为0时取RetryBase的10倍
+	// RetryMaxElapsed 重试的总耗时预算，大于0时无论重试类型如何，超过预算都会停止重试
+	RetryMaxElapsed time.Duration `json:"retry_max_elapsed"`
 }
 
 // LockQuery 锁查询
@@ -45,6 +67,7 @@ type LockResult struct {
 	CreatedAt time.Time `json:"created_at"`
 	ExpiresAt time.Time `json:"expires_at"`
 	IsValid   bool      `json:"is_valid"`
+	Token     uint64    `json:"token"`
 }
 
 // RefreshCommand 续约命令
@@ -57,6 +80,20 @@ type AutoRefreshCommand struct {
 	Key      string        `json:"key"`
 	Interval time.Duration `json:"interval"`
 	Timeout  time.Duration `json:"timeout"`
+	// OnLost 续约失败（锁已丢失或过期）导致自动续约结束时调用，调用方应中止依赖该锁的工作
+	// 锁被主动释放或通过 StopAutoRefresh 停止时不会调用
+	OnLost func(err error) `json:"-"`
+}
+
+// LockStatsResult 锁统计结果
+type LockStatsResult struct {
+	TotalLocks   int64   `json:"total_locks"`
+	ActiveLocks  int64   `json:"active_locks"`
+	FailedLocks  int64   `json:"failed_locks"`
+	ExpiredLocks int64   `json:"expired_locks"`
+	RefreshCount int64   `json:"refresh_count"`
+	UnlockCount  int64   `json:"unlock_count"`
+	SuccessRate  float64 `json:"success_rate"`
 }
 
 // UnlockCommand 解锁命令
@@ -77,6 +114,7 @@ func (s *DistributedLockApplicationService) TryLock(ctx context.Context, cmd Loc
 	if err != nil {
 		return nil, fmt.Errorf("尝试获取锁失败: %w", err)
 	}
+	s.track(lock)
 
 	return s.buildLockResult(ctx, lock), nil
 }
@@ -100,6 +138,7 @@ func (s *DistributedLockApplicationService) Lock(ctx context.Context, cmd LockCo
 	if err != nil {
 		return nil, fmt.Errorf("获取锁失败: %w", err)
 	}
+	s.track(lock)
 
 	return s.buildLockResult(ctx, lock), nil
 }
@@ -123,6 +162,7 @@ func (s *DistributedLockApplicationService) SingleflightLock(ctx context.Context
 	if err != nil {
 		return nil, fmt.Errorf("singleflight获取锁失败: %w", err)
 	}
+	s.track(lock)
 
 	return s.buildLockResult(ctx, lock), nil
 }
@@ -154,6 +194,7 @@ func (s *DistributedLockApplicationService) RefreshLock(ctx context.Context, cmd
 
 // StartAutoRefresh 启动自动续约
 // 用例：用户想要自动续约锁，避免锁过期
+// 同一个锁键重复启动时会先停止之前的自动续约
 func (s *DistributedLockApplicationService) StartAutoRefresh(cmd AutoRefreshCommand, lock domainLock.Lock) error {
 	// 验证输入
 	if cmd.Key == "" {
@@ -176,14 +217,59 @@ func (s *DistributedLockApplicationService) StartAutoRefresh(cmd AutoRefreshComm
 		return fmt.Errorf("续约超时时间必须大于0")
 	}
 
+	ctx, cancel := context.WithCancel(context.Background())
+	task := &autoRefreshTask{cancel: cancel}
+
+	s.mu.Lock()
+	if previous, ok := s.refreshTasks[cmd.Key]; ok {
+		previous.cancel()
+	}
+	s.refreshTasks[cmd.Key] = task
+	s.mu.Unlock()
+
 	// 启动自动续约（异步）
 	go func() {
-		_ = lock.AutoRefresh(cmd.Interval, cmd.Timeout)
+		err := lock.AutoRefreshContext(ctx, cmd.Interval, cmd.Timeout)
+
+		// 续约结束后注销任务，避免误删同一锁键上新启动的任务
+		s.mu.Lock()
+		if s.refreshTasks[cmd.Key] == task {
+			delete(s.refreshTasks, cmd.Key)
+		}
+		s.mu.Unlock()
+		cancel()
+
+		// 被停止或被重新启动的续约不算丢失锁
+		if err != nil && !errors.Is(err, context.Canceled) && cmd.OnLost != nil {
+			cmd.OnLost(err)
+		}
 	}()
 
 	return nil
 }
 
+// StopAutoRefresh 停止自动续约
+// 用例：用户想要停止续约但继续持有锁，锁将在过期时间后自然失效
+func (s *DistributedLockApplicationService) StopAutoRefresh(key string) error {
+	if key == "" {
+		return fmt.Errorf("锁键不能为空")
+	}
+
+	s.mu.Lock()
+	task, ok := s.refreshTasks[key]
+	if ok {
+		delete(s.refreshTasks, key)
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("锁 %s 没有正在运行的自动续约", key)
+	}
+
+	task.cancel()
+	return nil
+}
+
 // UnlockLock 释放锁
 // 用例：用户想要释放持有的锁
 func (s *DistributedLockApplicationService) UnlockLock(ctx context.Context, cmd UnlockCommand, lock domainLock.Lock) error {
@@ -202,6 +288,7 @@ func (s *DistributedLockApplicationService) UnlockLock(ctx context.Context, cmd
 
 	// 释放锁
 	err := lock.Unlock(ctx)
+	s.untrack(lock)
 	if err != nil {
 		return fmt.Errorf("释放锁失败: %w", err)
 	}
@@ -209,6 +296,83 @@ func (s *DistributedLockApplicationService) UnlockLock(ctx context.Context, cmd
 	return nil
 }
 
+// UnlockHeld 按锁键释放通过本服务获取的锁
+// 用例：用户只保留了锁键，想要释放之前通过本服务获取的锁
+// 返回: 错误信息，该键没有通过本服务获取且尚未释放的锁时返回 ErrLockNotHold
+func (s *DistributedLockApplicationService) UnlockHeld(ctx context.Context, cmd UnlockCommand) error {
+	if cmd.Key == "" {
+		return fmt.Errorf("锁键不能为空")
+	}
+
+	s.mu.Lock()
+	lock, ok := s.held[cmd.Key]
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("释放锁失败: %w, key: %s", domainLock.ErrLockNotHold, cmd.Key)
+	}
+
+	return s.UnlockLock(ctx, cmd, lock)
+}
+
+// UnlockAll 释放通过本服务获取且尚未释放的所有锁
+// 用例：客户端优雅退出时释放持有的全部锁
+// 已过期的锁不再被持有，直接跳过，不视为释放失败
+// 返回: 每个释放失败的锁对应一个错误（如锁被他人持有），全部成功时为nil；
+// 无论成功与否，记录都会被清空
+func (s *DistributedLockApplicationService) UnlockAll(ctx context.Context) []error {
+	s.mu.Lock()
+	held := s.held
+	s.held = make(map[string]domainLock.Lock)
+	s.mu.Unlock()
+
+	keys := make([]string, 0, len(held))
+	for key := range held {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	var errs []error
+	now := time.Now()
+	for _, key := range keys {
+		if held[key].IsExpired(now) {
+			continue
+		}
+		if err := held[key].Unlock(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("释放锁 %s 失败: %w", key, err))
+		}
+	}
+	return errs
+}
+
+// track 记录获取到的锁
+// 同一个键只记录最近获取的锁，之前的锁已经释放或过期
+// 记录数量增长到上次清理后的两倍时清理已过期的锁，使不断更换锁键的客户端记录数量保持有界
+func (s *DistributedLockApplicationService) track(lock domainLock.Lock) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.held[lock.Key()] = lock
+	if len(s.held) < s.pruneAt {
+		return
+	}
+
+	now := time.Now()
+	for key, l := range s.held {
+		if l.IsExpired(now) {
+			delete(s.held, key)
+		}
+	}
+	s.pruneAt = max(2*len(s.held), heldPruneMinSize)
+}
+
+// untrack 移除已释放的锁的记录，该键已经记录了其他锁时保留
+func (s *DistributedLockApplicationService) untrack(lock domainLock.Lock) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.held[lock.Key()] == lock {
+		delete(s.held, lock.Key())
+	}
+}
+
 // CheckLockStatus 检查锁状态
 // 用例：用户想要检查锁是否仍然有效
 func (s *DistributedLockApplicationService) CheckLockStatus(ctx context.Context, query LockQuery, lock domainLock.Lock) (*LockResult, error) {
@@ -228,6 +392,29 @@ func (s *DistributedLockApplicationService) CheckLockStatus(ctx context.Context,
 	return s.buildLockResult(ctx, lock), nil
 }
 
+// GetLockStats 获取锁统计信息
+// 用例：用户想要观察锁的获取、释放、过期等情况
+// 底层分布式锁需要提供 GetStats 方法，否则返回错误
+func (s *DistributedLockApplicationService) GetLockStats(ctx context.Context) (*LockStatsResult, error) {
+	provider, ok := s.distributedLock.(interface {
+		GetStats() domainLock.LockStats
+	})
+	if !ok {
+		return nil, fmt.Errorf("分布式锁实现不支持统计信息")
+	}
+
+	stats := provider.GetStats()
+	return &LockStatsResult{
+		TotalLocks:   stats.TotalLocks(),
+		ActiveLocks:  stats.ActiveLocks(),
+		FailedLocks:  stats.FailedLocks(),
+		ExpiredLocks: stats.ExpiredLocks(),
+		RefreshCount: stats.RefreshCount(),
+		UnlockCount:  stats.UnlockCount(),
+		SuccessRate:  stats.SuccessRate(),
+	}, nil
+}
+
 // validateLockCommand 验证加锁命令
 func (s *DistributedLockApplicationService) validateLockCommand(cmd LockCommand) error {
 	if cmd.Key == "" {
@@ -250,11 +437,41 @@ func (s *DistributedLockApplicationService) validateLockCommand(cmd LockCommand)
 		return fmt.Errorf("重试基础时间必须大于0")
 	}
 
+	if cmd.RetryCap < 0 {
+		return fmt.Errorf("最大重试间隔不能为负数")
+	}
+
+	if cmd.RetryCap > 0 && cmd.RetryCap < cmd.RetryBase {
+		return fmt.Errorf("最大重试间隔不能小于重试基础时间")
+	}
+
+	if cmd.RetryMaxElapsed < 0 {
+		return fmt.Errorf("重试总耗时预算不能为负数")
+	}
+
 	return nil
 }
 
 // createRetryStrategy 创建重试策略
+// 设置了总耗时预算时，使用 MaxElapsedRetryStrategy 包装基础策略
 func (s *DistributedLockApplicationService) createRetryStrategy(cmd LockCommand) (domainLock.RetryStrategy, error) {
+	strategy, err := s.createBaseRetryStrategy(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	if cmd.RetryMaxElapsed > 0 {
+		return &MaxElapsedRetryStrategy{
+			strategy:   strategy,
+			maxElapsed: cmd.RetryMaxElapsed,
+		}, nil
+	}
+
+	return strategy, nil
+}
+
+// createBaseRetryStrategy 按重试类型创建基础重试策略
+func (s *DistributedLockApplicationService) createBaseRetryStrategy(cmd LockCommand) (domainLock.RetryStrategy, error) {
 	if cmd.RetryCount == 0 {
 		// 不重试
 		return &NoRetryStrategy{}, nil
@@ -278,6 +495,16 @@ func (s *DistributedLockApplicationService) createRetryStrategy(cmd LockCommand)
 			increment:       cmd.RetryBase,
 			maxRetry:        cmd.RetryCount,
 		}, nil
+	case "decorrelated":
+		retryCap := cmd.RetryCap
+		if retryCap == 0 {
+			retryCap = 10 * cmd.RetryBase
+		}
+		return &DecorrelatedJitterRetryStrategy{
+			base:     cmd.RetryBase,
+			cap:      retryCap,
+			maxRetry: cmd.RetryCount,
+		}, nil
 	default:
 		return nil, fmt.Errorf("不支持的重试类型: %s", cmd.RetryType)
 	}
@@ -293,6 +520,7 @@ func (s *DistributedLockApplicationService) buildLockResult(ctx context.Context,
 		CreatedAt: lock.CreatedAt(),
 		ExpiresAt: lock.CreatedAt().Add(lock.Expiration()),
 		IsValid:   isValid,
+		Token:     lock.Token(),
 	}
 }
 
@@ -362,3 +590,51 @@ func (s *LinearBackoffRetryStrategy) Iterator() iter.Seq[time.Duration] {
 		}
 	}
 }
+
+// DecorrelatedJitterRetryStrategy 去相关抖动重试策略
+type DecorrelatedJitterRetryStrategy struct {
+	base     time.Duration
+	cap      time.Duration
+	maxRetry int
+}
+
+// Iterator 返回重试间隔的迭代器
+// 每次的重试间隔为 min(cap, random_between(base, prev*3))
+func (s *DecorrelatedJitterRetryStrategy) Iterator() iter.Seq[time.Duration] {
+	return func(yield func(time.Duration) bool) {
+		prev := s.base
+		for i := 0; i < s.maxRetry; i++ {
+			interval := s.base
+			if upper := prev * 3; upper > s.base {
+				interval += time.Duration(rand.Int64N(int64(upper-s.base) + 1))
+			}
+			interval = min(s.cap, interval)
+			if !yield(interval) {
+				return
+			}
+			prev = interval
+		}
+	}
+}
+
+// MaxElapsedRetryStrategy 按总耗时限制的重试策略
+type MaxElapsedRetryStrategy struct {
+	strategy   domainLock.RetryStrategy
+	maxElapsed time.Duration
+}
+
+// Iterator 返回重试间隔的迭代器
+// 已耗时加上下一次间隔将超过预算时停止
+func (s *MaxElapsedRetryStrategy) Iterator() iter.Seq[time.Duration] {
+	return func(yield func(time.Duration) bool) {
+		start := time.Now()
+		for interval := range s.strategy.Iterator() {
+			if time.Since(start)+interval > s.maxElapsed {
+				return
+			}
+			if !yield(interval) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package lock
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domainLock "github.com/justinwongcn/hamster/internal/domain/lock"
+	infraLock "github.com/justinwongcn/hamster/internal/infrastructure/lock"
+)
+
+// TestDistributedLockApplicationService_AutoRefreshOnLost 测试续约失败时调用OnLost
+func TestDistributedLockApplicationService_AutoRefreshOnLost(t *testing.T) {
+	ctx := context.Background()
+	mdl := infraLock.NewMemoryDistributedLock()
+	service := NewDistributedLockApplicationService(mdl)
+
+	lock, err := mdl.TryLock(ctx, "lost_key", 10*time.Millisecond)
+	require.NoError(t, err)
+
+	lost := make(chan error, 1)
+	err = service.StartAutoRefresh(AutoRefreshCommand{
+		Key:      "lost_key",
+		Interval: 50 * time.Millisecond,
+		Timeout:  time.Second,
+		OnLost: func(err error) {
+			lost <- err
+		},
+	}, lock)
+	require.NoError(t, err)
+
+	// 在第一次续约之前锁已过期并被清理，续约失败
+	time.Sleep(20 * time.Millisecond)
+	require.Equal(t, 1, mdl.CleanExpiredLocks())
+
+	select {
+	case err := <-lost:
+		assert.ErrorIs(t, err, domainLock.ErrLockNotHold)
+	case <-time.After(time.Second):
+		t.Fatal("续约失败后没有调用OnLost")
+	}
+
+	// 续约结束后任务已注销
+	assert.Error(t, service.StopAutoRefresh("lost_key"))
+}
+
+// TestDistributedLockApplicationService_AutoRefreshStopped 测试主动停止续约和释放锁时不调用OnLost
+func TestDistributedLockApplicationService_AutoRefreshStopped(t *testing.T) {
+	ctx := context.Background()
+	mdl := infraLock.NewMemoryDistributedLock()
+	service := NewDistributedLockApplicationService(mdl)
+
+	lost := make(chan error, 2)
+	onLost := func(err error) {
+		lost <- err
+	}
+
+	stopped, err := mdl.TryLock(ctx, "stopped_key", time.Second)
+	require.NoError(t, err)
+	require.NoError(t, service.StartAutoRefresh(AutoRefreshCommand{
+		Key: "stopped_key", Interval: 10 * time.Millisecond, Timeout: time.Second, OnLost: onLost,
+	}, stopped))
+
+	unlocked, err := mdl.TryLock(ctx, "unlocked_key", time.Second)
+	require.NoError(t, err)
+	require.NoError(t, service.StartAutoRefresh(AutoRefreshCommand{
+		Key: "unlocked_key", Interval: 10 * time.Millisecond, Timeout: time.Second, OnLost: onLost,
+	}, unlocked))
+
+	time.Sleep(30 * time.Millisecond)
+	require.NoError(t, service.StopAutoRefresh("stopped_key"))
+	require.NoError(t, service.UnlockLock(ctx, UnlockCommand{Key: "unlocked_key"}, unlocked))
+
+	select {
+	case err := <-lost:
+		t.Fatalf("不应调用OnLost: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// TestDistributedLockApplicationService_UnlockAll 测试释放所有通过服务获取的锁并收集每个键的错误
+func TestDistributedLockApplicationService_UnlockAll(t *testing.T) {
+	ctx := context.Background()
+	mdl := infraLock.NewMemoryDistributedLock()
+	service := NewDistributedLockApplicationService(mdl)
+
+	for _, key := range []string{"key1", "key2", "key3", "released"} {
+		_, err := service.TryLock(ctx, LockCommand{Key: key, Expiration: time.Minute, Timeout: time.Second})
+		require.NoError(t, err)
+	}
+	for _, key := range []string{"expired", "taken"} {
+		_, err := service.TryLock(ctx, LockCommand{Key: key, Expiration: 10 * time.Millisecond, Timeout: time.Second})
+		require.NoError(t, err)
+	}
+
+	// 绕过服务释放的锁释放失败，错误中包含锁键
+	require.NoError(t, service.held["released"].Unlock(ctx))
+
+	// 已过期的锁无论是否已被他人获取都直接跳过，不视为释放失败
+	time.Sleep(20 * time.Millisecond)
+	_, err := mdl.TryLock(ctx, "taken", time.Minute)
+	require.NoError(t, err)
+
+	errs := service.UnlockAll(ctx)
+	require.Len(t, errs, 1)
+	assert.ErrorIs(t, errs[0], domainLock.ErrLockNotHold)
+	assert.Contains(t, errs[0].Error(), "released")
+	mdl.CleanExpiredLocks()
+	assert.Equal(t, int64(1), mdl.GetStats().ActiveLocks())
+	assert.Empty(t, service.held)
+
+	// 记录已清空，再次调用不会重复释放
+	assert.Empty(t, service.UnlockAll(ctx))
+}
+
+// TestDistributedLockApplicationService_UnlockHeld 测试按锁键释放通过服务获取的锁
+func TestDistributedLockApplicationService_UnlockHeld(t *testing.T) {
+	ctx := context.Background()
+	mdl := infraLock.NewMemoryDistributedLock()
+	service := NewDistributedLockApplicationService(mdl)
+
+	_, err := service.TryLock(ctx, LockCommand{Key: "key1", Expiration: time.Minute, Timeout: time.Second})
+	require.NoError(t, err)
+	require.NoError(t, service.UnlockHeld(ctx, UnlockCommand{Key: "key1"}))
+	assert.Empty(t, service.held)
+	_, err = mdl.TryLock(ctx, "key1", time.Minute)
+	require.NoError(t, err)
+
+	// 已释放或没有通过服务获取的锁
+	assert.ErrorIs(t, service.UnlockHeld(ctx, UnlockCommand{Key: "key1"}), domainLock.ErrLockNotHold)
+	assert.Error(t, service.UnlockHeld(ctx, UnlockCommand{}))
+
+	// 已过期的锁释放失败，记录同样被移除
+	_, err = service.TryLock(ctx, LockCommand{Key: "key2", Expiration: 10 * time.Millisecond, Timeout: time.Second})
+	require.NoError(t, err)
+	time.Sleep(20 * time.Millisecond)
+	require.Equal(t, 1, mdl.CleanExpiredLocks())
+	assert.ErrorIs(t, service.UnlockHeld(ctx, UnlockCommand{Key: "key2"}), domainLock.ErrLockNotHold)
+	assert.Empty(t, service.held)
+}
+
+// TestDistributedLockApplicationService_HeldBounded 测试不断更换锁键时已过期的锁记录会被清理
+func TestDistributedLockApplicationService_HeldBounded(t *testing.T) {
+	ctx := context.Background()
+	service := NewDistributedLockApplicationService(infraLock.NewMemoryDistributedLock())
+
+	for round := range 5 {
+		for i := range heldPruneMinSize {
+			_, err := service.TryLock(ctx, LockCommand{
+				Key:        fmt.Sprintf("order:%d:%d", round, i),
+				Expiration: 10 * time.Millisecond,
+				Timeout:    time.Second,
+			})
+			require.NoError(t, err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	service.mu.Lock()
+	defer service.mu.Unlock()
+	assert.LessOrEqual(t, len(service.held), 2*heldPruneMinSize)
+}
@@ -54,6 +54,14 @@ type ReadThroughRepository interface {
 	// expiration: 缓存过期时间
 	// 返回: 缓存值和错误信息
 	GetWithLoader(ctx context.Context, key string, loader func(ctx context.Context, key string) (any, error), expiration time.Duration) (any, error)
+
+	// GetWithLoaderTTL 使用加载器获取缓存值，过期时间由加载器返回
+	// 用于新鲜度各不相同的数据，每个缓存项使用加载器给出的过期时间
+	// ctx: 上下文
+	// key: 缓存键
+	// loader: 数据加载函数，返回值和该值的过期时间
+	// 返回: 缓存值和错误信息
+	GetWithLoaderTTL(ctx context.Context, key string, loader func(ctx context.Context, key string) (any, time.Duration, error)) (any, error)
 }
 
 // WriteThroughRepository 定义写透缓存仓储接口
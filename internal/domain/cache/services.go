@@ -109,13 +109,33 @@ func (s *FIFOEvictionStrategy) OnRemove(entry *Entry) {
 // 封装缓存的核心业务逻辑和规则
 type CacheService struct {
 	evictionStrategy EvictionStrategy
+	maxKeyLength     int
 }
 
+// CacheServiceOption 缓存服务配置选项函数类型
+type CacheServiceOption func(s *CacheService)
+
 // NewCacheService 创建缓存服务
 // evictionStrategy: 淘汰策略
-func NewCacheService(evictionStrategy EvictionStrategy) *CacheService {
-	return &CacheService{
+// opts: 可选配置项
+func NewCacheService(evictionStrategy EvictionStrategy, opts ...CacheServiceOption) *CacheService {
+	res := &CacheService{
 		evictionStrategy: evictionStrategy,
+		maxKeyLength:     DefaultMaxKeyLength,
+	}
+	for _, opt := range opts {
+		opt(res)
+	}
+	return res
+}
+
+// CacheServiceWithMaxKeyLength 设置缓存键的最大长度
+// maxLength: 最大长度，不大于0时忽略，使用 DefaultMaxKeyLength
+func CacheServiceWithMaxKeyLength(maxLength int) CacheServiceOption {
+	return func(s *CacheService) {
+		if maxLength > 0 {
+			s.maxKeyLength = maxLength
+		}
 	}
 }
 
@@ -123,7 +143,7 @@ func NewCacheService(evictionStrategy EvictionStrategy) *CacheService {
 // key: 要验证的键
 // 返回: 验证错误
 func (s *CacheService) ValidateKey(key string) error {
-	_, err := NewCacheKey(key)
+	_, err := NewCacheKeyWithMaxLength(key, s.maxKeyLength)
 	return err
 }
 
@@ -18,6 +18,9 @@ var (
 	ErrFailedToRefreshCache = errors.New("刷新缓存失败")
 )
 
+// DefaultMaxKeyLength 缓存键的默认最大长度
+const DefaultMaxKeyLength = 250
+
 // CacheKey 缓存键值对象
 // 封装缓存键的业务规则和验证逻辑
 type CacheKey struct {
@@ -28,7 +31,18 @@ type CacheKey struct {
 // key: 键值字符串
 // 返回: CacheKey实例和错误信息
 func NewCacheKey(key string) (CacheKey, error) {
-	if err := validateKey(key); err != nil {
+	return NewCacheKeyWithMaxLength(key, DefaultMaxKeyLength)
+}
+
+// NewCacheKeyWithMaxLength 使用指定的最大长度创建缓存键
+// key: 键值字符串
+// maxLength: 键的最大长度，不大于0时使用 DefaultMaxKeyLength
+// 返回: CacheKey实例和错误信息
+func NewCacheKeyWithMaxLength(key string, maxLength int) (CacheKey, error) {
+	if maxLength <= 0 {
+		maxLength = DefaultMaxKeyLength
+	}
+	if err := validateKey(key, maxLength); err != nil {
 		return CacheKey{}, fmt.Errorf("%w: %s", ErrInvalidCacheKey, err.Error())
 	}
 	return CacheKey{value: key}, nil
@@ -50,12 +64,12 @@ func (k CacheKey) Equals(other CacheKey) bool {
 }
 
 // validateKey 验证缓存键的有效性
-func validateKey(key string) error {
+func validateKey(key string, maxLength int) error {
 	if key == "" {
 		return errors.New("缓存键不能为空")
 	}
-	if len(key) > 250 {
-		return errors.New("缓存键长度不能超过250个字符")
+	if len(key) > maxLength {
+		return fmt.Errorf("缓存键长度不能超过%d个字符", maxLength)
 	}
 	if strings.Contains(key, "\n") || strings.Contains(key, "\r") {
 		return errors.New("缓存键不能包含换行符")
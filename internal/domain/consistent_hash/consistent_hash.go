@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"hash/crc32"
+	"math"
 	"sort"
 )
 
@@ -282,7 +283,7 @@ func (s HashStats) LoadBalance() float64 {
 	variance /= float64(len(s.keyDistribution))
 	
 	// 返回标准差
-	return variance
+	return math.Sqrt(variance)
 }
 
 // VirtualNodeConfig 虚拟节点配置值对象
@@ -66,6 +66,11 @@ type Lock interface {
 	// CreatedAt 获取锁的创建时间
 	CreatedAt() time.Time
 	
+	// Token 获取锁的栅栏令牌（fencing token）
+	// 每次成功获取锁都会得到一个严格递增的令牌
+	// 下游资源可以拒绝携带较旧令牌的写入，防止锁过期后的旧持有者破坏数据
+	Token() uint64
+	
 	// IsExpired 检查锁是否已过期
 	// now: 当前时间
 	// 返回: 是否已过期
@@ -82,6 +87,14 @@ type Lock interface {
 	// 返回: 操作错误
 	AutoRefresh(interval time.Duration, timeout time.Duration) error
 	
+	// AutoRefreshContext 自动续约锁，直到锁被释放或上下文被取消
+	// 取消上下文只停止续约，不会释放锁
+	// ctx: 控制续约生命周期的上下文
+	// interval: 续约间隔
+	// timeout: 每次续约的超时时间
+	// 返回: 操作错误，上下文取消时返回ctx.Err()
+	AutoRefreshContext(ctx context.Context, interval time.Duration, timeout time.Duration) error
+	
 	// Unlock 释放锁
 	// ctx: 上下文
 	// 返回: 操作错误
@@ -176,15 +189,26 @@ type LockExpiration struct {
 	duration time.Duration
 }
 
+// DefaultMaxLockExpiration 锁过期时间的默认上限
+const DefaultMaxLockExpiration = 24 * time.Hour
+
 // NewLockExpiration 创建新的锁过期时间
-// duration: 过期时间间隔
+// duration: 过期时间间隔，不能超过 DefaultMaxLockExpiration
 // 返回: LockExpiration实例和错误信息
 func NewLockExpiration(duration time.Duration) (LockExpiration, error) {
+	return NewLockExpirationWithMax(duration, DefaultMaxLockExpiration)
+}
+
+// NewLockExpirationWithMax 创建新的锁过期时间，并按指定的上限校验
+// duration: 过期时间间隔
+// maxDuration: 过期时间的上限，不大于0时不限制
+// 返回: LockExpiration实例和错误信息
+func NewLockExpirationWithMax(duration time.Duration, maxDuration time.Duration) (LockExpiration, error) {
 	if duration <= 0 {
 		return LockExpiration{}, fmt.Errorf("%w: 过期时间必须大于0", ErrInvalidExpiration)
 	}
-	if duration > 24*time.Hour {
-		return LockExpiration{}, fmt.Errorf("%w: 过期时间不能超过24小时", ErrInvalidExpiration)
+	if maxDuration > 0 && duration > maxDuration {
+		return LockExpiration{}, fmt.Errorf("%w: 过期时间不能超过%v", ErrInvalidExpiration, maxDuration)
 	}
 	return LockExpiration{duration: duration}, nil
 }
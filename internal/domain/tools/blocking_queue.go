@@ -0,0 +1,122 @@
+package tools
+
+import (
+	"context"
+	"sync"
+)
+
+// BlockingQueue 支持阻塞出队的泛型队列
+// 基于 Queue 实现，队列为空时出队阻塞到有元素入队或上下文结束，
+// 有界模式下队列已满时入队阻塞到有元素出队，用于生产者消费者流水线
+// 线程安全
+type BlockingQueue[T any] struct {
+	mu       sync.Mutex
+	queue    *Queue[T]
+	capacity int // 容量，不大于0表示无界
+
+	// notEmpty 队列由空变为非空时关闭并替换，唤醒所有等待出队的调用方
+	notEmpty chan struct{}
+	// notFull 有界队列由满变为未满时关闭并替换，唤醒所有等待入队的调用方
+	notFull chan struct{}
+}
+
+// NewBlockingQueue 创建阻塞队列
+// 参数:
+//   - capacity: 队列容量，不大于0表示无界，入队从不阻塞
+//
+// 返回值:
+//   - *BlockingQueue[T]: 新建的阻塞队列实例
+func NewBlockingQueue[T any](capacity int) *BlockingQueue[T] {
+	return &BlockingQueue[T]{
+		queue:    NewQueue[T](),
+		capacity: max(capacity, 0),
+		notEmpty: make(chan struct{}),
+		notFull:  make(chan struct{}),
+	}
+}
+
+// Enqueue 将元素加入队尾
+// 有界队列已满时一直阻塞到有元素出队，需要取消时使用 EnqueueContext
+// 参数:
+//   - t: 要入队的元素
+func (q *BlockingQueue[T]) Enqueue(t T) {
+	_ = q.EnqueueContext(context.Background(), t)
+}
+
+// EnqueueContext 将元素加入队尾
+// 有界队列已满时阻塞到有元素出队或上下文结束
+// 参数:
+//   - ctx: 上下文
+//   - t: 要入队的元素
+//
+// 返回值:
+//   - error: 上下文结束时返回ctx.Err()，元素没有入队
+func (q *BlockingQueue[T]) EnqueueContext(ctx context.Context, t T) error {
+	for {
+		q.mu.Lock()
+		if q.capacity == 0 || q.queue.Len() < q.capacity {
+			q.queue.Enqueue(t)
+			if q.queue.Len() == 1 {
+				close(q.notEmpty)
+				q.notEmpty = make(chan struct{})
+			}
+			q.mu.Unlock()
+			return nil
+		}
+		notFull := q.notFull
+		q.mu.Unlock()
+
+		select {
+		case <-notFull:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Dequeue 取出队首元素
+// 队列为空时阻塞到有元素入队或上下文结束
+// 参数:
+//   - ctx: 上下文
+//
+// 返回值:
+//   - T: 队首元素
+//   - error: 上下文结束时返回ctx.Err()
+func (q *BlockingQueue[T]) Dequeue(ctx context.Context) (T, error) {
+	for {
+		q.mu.Lock()
+		if t, err := q.queue.Dequeue(); err == nil {
+			if q.capacity > 0 && q.queue.Len() == q.capacity-1 {
+				close(q.notFull)
+				q.notFull = make(chan struct{})
+			}
+			q.mu.Unlock()
+			return t, nil
+		}
+		notEmpty := q.notEmpty
+		q.mu.Unlock()
+
+		select {
+		case <-notEmpty:
+		case <-ctx.Done():
+			var zeroValue T
+			return zeroValue, ctx.Err()
+		}
+	}
+}
+
+// Len 获取队列中元素数量
+// 返回值:
+//   - int: 元素数量
+func (q *BlockingQueue[T]) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.queue.Len()
+}
+
+// Cap 获取队列容量
+// 返回值:
+//   - int: 队列容量，0表示无界
+func (q *BlockingQueue[T]) Cap() int {
+	return q.capacity
+}
@@ -0,0 +1,165 @@
+package tools
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBlockingQueue_DequeueBlocks 测试空队列出队阻塞到有元素入队
+func TestBlockingQueue_DequeueBlocks(t *testing.T) {
+	ctx := context.Background()
+	queue := NewBlockingQueue[int](0)
+	assert.Equal(t, 0, queue.Cap())
+
+	got := make(chan int)
+	go func() {
+		v, err := queue.Dequeue(ctx)
+		assert.NoError(t, err)
+		got <- v
+	}()
+
+	select {
+	case <-got:
+		t.Fatal("空队列出队没有阻塞")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	queue.Enqueue(1)
+	select {
+	case v := <-got:
+		assert.Equal(t, 1, v)
+	case <-time.After(time.Second):
+		t.Fatal("入队后出队没有被唤醒")
+	}
+
+	// 有元素时直接按先进先出顺序返回
+	queue.Enqueue(2)
+	queue.Enqueue(3)
+	assert.Equal(t, 2, queue.Len())
+	for want := 2; want <= 3; want++ {
+		v, err := queue.Dequeue(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, want, v)
+	}
+}
+
+// TestBlockingQueue_DequeueContext 测试上下文结束时出队提前返回
+func TestBlockingQueue_DequeueContext(t *testing.T) {
+	queue := NewBlockingQueue[string](0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	v, err := queue.Dequeue(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, "", v)
+	assert.Less(t, time.Since(start), time.Second)
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	_, err = queue.Dequeue(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// 取消的出队不会取走之后入队的元素
+	queue.Enqueue("a")
+	v, err = queue.Dequeue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "a", v)
+}
+
+// TestBlockingQueue_Bounded 测试有界队列已满时入队阻塞到有元素出队
+func TestBlockingQueue_Bounded(t *testing.T) {
+	ctx := context.Background()
+	queue := NewBlockingQueue[int](2)
+	assert.Equal(t, 2, queue.Cap())
+	queue.Enqueue(1)
+	queue.Enqueue(2)
+
+	done := make(chan struct{})
+	go func() {
+		queue.Enqueue(3)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("队列已满时入队没有阻塞")
+	case <-time.After(50 * time.Millisecond):
+	}
+	assert.Equal(t, 2, queue.Len())
+
+	v, err := queue.Dequeue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, v)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("出队后入队没有被唤醒")
+	}
+	assert.Equal(t, 2, queue.Len())
+
+	// 队列已满时上下文结束，元素没有入队
+	timeoutCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, queue.EnqueueContext(timeoutCtx, 4), context.DeadlineExceeded)
+	for want := 2; want <= 3; want++ {
+		v, err := queue.Dequeue(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, want, v)
+	}
+	assert.Equal(t, 0, queue.Len())
+}
+
+// TestBlockingQueue_ProducerConsumer 测试多个生产者和消费者并发使用有界队列
+func TestBlockingQueue_ProducerConsumer(t *testing.T) {
+	ctx := context.Background()
+	queue := NewBlockingQueue[int](4)
+	const producers, perProducer = 4, 100
+
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < perProducer; i++ {
+				queue.Enqueue(p*perProducer + i)
+			}
+		}(p)
+	}
+
+	results := make(chan int, producers*perProducer)
+	var consumers sync.WaitGroup
+	for c := 0; c < 3; c++ {
+		consumers.Add(1)
+		go func() {
+			defer consumers.Done()
+			for {
+				v, err := queue.Dequeue(ctx)
+				if err != nil || v < 0 {
+					return
+				}
+				results <- v
+			}
+		}()
+	}
+
+	wg.Wait()
+	// 每个消费者收到一个结束标记
+	for c := 0; c < 3; c++ {
+		queue.Enqueue(-1)
+	}
+	consumers.Wait()
+	close(results)
+
+	seen := make(map[int]bool)
+	for v := range results {
+		assert.False(t, seen[v])
+		seen[v] = true
+	}
+	assert.Len(t, seen, producers*perProducer)
+}
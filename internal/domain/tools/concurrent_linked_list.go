@@ -0,0 +1,137 @@
+package tools
+
+import "sync"
+
+var _ List[any] = &ConcurrentLinkedList[any]{}
+
+// ConcurrentLinkedList 线程安全的双向循环链表
+// 使用读写锁包装 LinkedList，修改操作加写锁，读取操作加读锁
+type ConcurrentLinkedList[T any] struct {
+	mu   sync.RWMutex
+	list *LinkedList[T]
+}
+
+// NewConcurrentLinkedList 创建一个空的线程安全链表
+// 返回值:
+//   - *ConcurrentLinkedList[T]: 新建的链表实例
+func NewConcurrentLinkedList[T any]() *ConcurrentLinkedList[T] {
+	return &ConcurrentLinkedList[T]{
+		list: NewLinkedList[T](),
+	}
+}
+
+// NewConcurrentLinkedListOf 将切片转换为线程安全链表
+// 参数:
+//   - ts: 要转换的切片
+//
+// 返回值:
+//   - *ConcurrentLinkedList[T]: 包含切片元素的链表实例
+func NewConcurrentLinkedListOf[T any](ts []T) *ConcurrentLinkedList[T] {
+	return &ConcurrentLinkedList[T]{
+		list: NewLinkedListOf(ts),
+	}
+}
+
+// Get 获取链表中指定位置的元素
+// 参数:
+//   - index: 要获取的索引位置
+//
+// 返回值:
+//   - T: 找到的元素值
+//   - error: 索引越界错误
+func (c *ConcurrentLinkedList[T]) Get(index int) (T, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.list.Get(index)
+}
+
+// Append 往链表最后添加元素
+// 参数:
+//   - ts: 要添加的元素(可变参数)
+//
+// 返回值:
+//   - error: 操作错误信息
+func (c *ConcurrentLinkedList[T]) Append(ts ...T) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.list.Append(ts...)
+}
+
+// Add 在链表指定位置插入元素
+// 参数:
+//   - index: 要插入的位置索引
+//   - t: 要插入的元素
+//
+// 返回值:
+//   - error: 索引越界错误
+func (c *ConcurrentLinkedList[T]) Add(index int, t T) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.list.Add(index, t)
+}
+
+// Set 设置链表中指定位置的元素值
+// 参数:
+//   - index: 要设置的位置索引
+//   - t: 要设置的新值
+//
+// 返回值:
+//   - error: 索引越界错误
+func (c *ConcurrentLinkedList[T]) Set(index int, t T) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.list.Set(index, t)
+}
+
+// Delete 删除链表中指定位置的元素
+// 参数:
+//   - index: 要删除的位置索引
+//
+// 返回值:
+//   - T: 被删除的元素值
+//   - error: 索引越界错误
+func (c *ConcurrentLinkedList[T]) Delete(index int) (T, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.list.Delete(index)
+}
+
+// Len 获取链表的长度
+// 返回值:
+//   - int: 链表当前长度
+func (c *ConcurrentLinkedList[T]) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.list.Len()
+}
+
+// Cap 获取链表的容量(与长度相同)
+// 返回值:
+//   - int: 链表当前长度
+func (c *ConcurrentLinkedList[T]) Cap() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.list.Cap()
+}
+
+// Range 遍历链表中的每个元素
+// 遍历期间持有读锁，fn 中不能再调用该链表的方法，否则修改操作会导致死锁
+// 参数:
+//   - fn: 遍历函数，接收索引和元素值
+//
+// 返回值:
+//   - error: 遍历过程中遇到的错误
+func (c *ConcurrentLinkedList[T]) Range(fn func(index int, t T) error) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.list.Range(fn)
+}
+
+// AsSlice 将链表转换为切片
+// 返回值:
+//   - []T: 包含链表所有元素的切片
+func (c *ConcurrentLinkedList[T]) AsSlice() []T {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.list.AsSlice()
+}
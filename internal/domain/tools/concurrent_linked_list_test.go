@@ -0,0 +1,63 @@
+package tools
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/justinwongcn/hamster/internal/domain/errs"
+)
+
+// TestConcurrentLinkedList_API 测试线程安全链表保持原有API和错误返回
+func TestConcurrentLinkedList_API(t *testing.T) {
+	list := NewConcurrentLinkedListOf([]int{1, 2, 3})
+
+	require.NoError(t, list.Add(0, 0))
+	require.NoError(t, list.Append(4))
+	require.NoError(t, list.Set(2, 20))
+	val, err := list.Delete(4)
+	require.NoError(t, err)
+	assert.Equal(t, 4, val)
+
+	assert.Equal(t, []int{0, 1, 20, 3}, list.AsSlice())
+	assert.Equal(t, 4, list.Len())
+	assert.Equal(t, 4, list.Cap())
+
+	_, err = list.Get(4)
+	assert.Equal(t, errs.NewErrIndexOutOfRange(4, 4), err)
+	assert.Equal(t, errs.NewErrIndexOutOfRange(4, 5), list.Add(5, 5))
+}
+
+// TestConcurrentLinkedList_Concurrent 测试并发追加和读取
+// 需要配合 -race 运行以检测数据竞争
+func TestConcurrentLinkedList_Concurrent(t *testing.T) {
+	list := NewConcurrentLinkedList[int]()
+
+	const writers, perWriter = 8, 100
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				_ = list.Append(i)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				if n := list.Len(); n > 0 {
+					_, _ = list.Get(n - 1)
+				}
+				_ = list.AsSlice()
+				_ = list.Range(func(index int, t int) error { return nil })
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, writers*perWriter, list.Len())
+	assert.Len(t, list.AsSlice(), writers*perWriter)
+}
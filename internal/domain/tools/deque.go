@@ -0,0 +1,91 @@
+package tools
+
+import "errors"
+
+// ErrEmptyDeque 双端队列为空时弹出或查看元素返回的错误
+var ErrEmptyDeque = errors.New("双端队列为空")
+
+// Deque 基于双向循环链表的泛型双端队列
+// 链表带有头尾哨兵结点，两端的入队、出队和查看操作时间复杂度均为O(1)
+// 非线程安全
+type Deque[T any] struct {
+	list *LinkedList[T]
+}
+
+// NewDeque 创建一个空的双端队列
+// 返回值:
+//   - *Deque[T]: 新建的双端队列实例
+func NewDeque[T any]() *Deque[T] {
+	return &Deque[T]{
+		list: NewLinkedList[T](),
+	}
+}
+
+// PushFront 在队首插入元素
+// 参数:
+//   - t: 要插入的元素
+func (d *Deque[T]) PushFront(t T) {
+	_ = d.list.Add(0, t)
+}
+
+// PushBack 在队尾插入元素
+// 参数:
+//   - t: 要插入的元素
+func (d *Deque[T]) PushBack(t T) {
+	_ = d.list.Append(t)
+}
+
+// PopFront 弹出队首元素
+// 返回值:
+//   - T: 队首元素
+//   - error: 队列为空时返回 ErrEmptyDeque
+func (d *Deque[T]) PopFront() (T, error) {
+	if d.list.Len() == 0 {
+		var zeroValue T
+		return zeroValue, ErrEmptyDeque
+	}
+	return d.list.Delete(0)
+}
+
+// PopBack 弹出队尾元素
+// 返回值:
+//   - T: 队尾元素
+//   - error: 队列为空时返回 ErrEmptyDeque
+func (d *Deque[T]) PopBack() (T, error) {
+	if d.list.Len() == 0 {
+		var zeroValue T
+		return zeroValue, ErrEmptyDeque
+	}
+	return d.list.Delete(d.list.Len() - 1)
+}
+
+// Front 查看队首元素但不弹出
+// 返回值:
+//   - T: 队首元素
+//   - error: 队列为空时返回 ErrEmptyDeque
+func (d *Deque[T]) Front() (T, error) {
+	if d.list.Len() == 0 {
+		var zeroValue T
+		return zeroValue, ErrEmptyDeque
+	}
+	return d.list.Get(0)
+}
+
+// Back 查看队尾元素但不弹出
+// 返回值:
+//   - T: 队尾元素
+//   - error: 队列为空时返回 ErrEmptyDeque
+func (d *Deque[T]) Back() (T, error) {
+	if d.list.Len() == 0 {
+		var zeroValue T
+		return zeroValue, ErrEmptyDeque
+	}
+	return d.list.Get(d.list.Len() - 1)
+}
+
+// Len 获取双端队列中元素数量
+// 返回值:
+//   - int: 元素数量
+func (d *Deque[T]) Len() int {
+	return d.list.Len()
+}
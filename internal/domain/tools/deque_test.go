@@ -0,0 +1,81 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDeque_BothEnds 测试双端队列两端的插入和查看
+func TestDeque_BothEnds(t *testing.T) {
+	deque := NewDeque[int]()
+	deque.PushBack(2)
+	deque.PushFront(1)
+	deque.PushBack(3)
+	deque.PushFront(0)
+	assert.Equal(t, 4, deque.Len())
+
+	front, err := deque.Front()
+	require.NoError(t, err)
+	assert.Equal(t, 0, front)
+
+	back, err := deque.Back()
+	require.NoError(t, err)
+	assert.Equal(t, 3, back)
+	assert.Equal(t, 4, deque.Len())
+}
+
+// TestDeque_Drain 测试分别从两端清空双端队列
+func TestDeque_Drain(t *testing.T) {
+	testCases := []struct {
+		name string
+		pop  func(d *Deque[int]) (int, error)
+		want []int
+	}{
+		{
+			name: "从队首清空",
+			pop:  func(d *Deque[int]) (int, error) { return d.PopFront() },
+			want: []int{1, 2, 3},
+		},
+		{
+			name: "从队尾清空",
+			pop:  func(d *Deque[int]) (int, error) { return d.PopBack() },
+			want: []int{3, 2, 1},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			deque := NewDeque[int]()
+			deque.PushBack(2)
+			deque.PushFront(1)
+			deque.PushBack(3)
+
+			got := make([]int, 0)
+			for deque.Len() > 0 {
+				val, err := tc.pop(deque)
+				require.NoError(t, err)
+				got = append(got, val)
+			}
+			assert.Equal(t, tc.want, got)
+
+			_, err := tc.pop(deque)
+			assert.ErrorIs(t, err, ErrEmptyDeque)
+		})
+	}
+}
+
+// TestDeque_Empty 测试空双端队列返回错误
+func TestDeque_Empty(t *testing.T) {
+	deque := NewDeque[int]()
+
+	_, err := deque.PopFront()
+	assert.ErrorIs(t, err, ErrEmptyDeque)
+	_, err = deque.PopBack()
+	assert.ErrorIs(t, err, ErrEmptyDeque)
+	_, err = deque.Front()
+	assert.ErrorIs(t, err, ErrEmptyDeque)
+	_, err = deque.Back()
+	assert.ErrorIs(t, err, ErrEmptyDeque)
+}
@@ -0,0 +1,105 @@
+package tools
+
+import "errors"
+
+// ErrEmptyHeap 堆为空时弹出或查看堆顶返回的错误
+var ErrEmptyHeap = errors.New("堆为空")
+
+// Heap 基于切片的泛型二叉堆
+// 堆顶始终是按比较函数排在最前的元素，可用作优先队列
+// 非线程安全
+type Heap[T any] struct {
+	data []T
+	less func(a, b T) bool
+}
+
+// NewHeap 创建一个空堆
+// 参数:
+//   - less: 比较函数，a应排在b之前时返回true；使用小于得到最小堆，使用大于得到最大堆
+//
+// 返回值:
+//   - *Heap[T]: 新建的堆实例
+func NewHeap[T any](less func(a, b T) bool) *Heap[T] {
+	return &Heap[T]{
+		less: less,
+	}
+}
+
+// Push 插入元素，时间复杂度O(log n)
+// 参数:
+//   - t: 要插入的元素
+func (h *Heap[T]) Push(t T) {
+	h.data = append(h.data, t)
+	h.up(len(h.data) - 1)
+}
+
+// Pop 弹出堆顶元素，时间复杂度O(log n)
+// 返回值:
+//   - T: 堆顶元素
+//   - error: 堆为空时返回 ErrEmptyHeap
+func (h *Heap[T]) Pop() (T, error) {
+	var zeroValue T
+	if len(h.data) == 0 {
+		return zeroValue, ErrEmptyHeap
+	}
+
+	top := h.data[0]
+	last := len(h.data) - 1
+	h.data[0] = h.data[last]
+	h.data[last] = zeroValue // 避免保留已弹出元素的引用
+	h.data = h.data[:last]
+	if last > 0 {
+		h.down(0)
+	}
+	return top, nil
+}
+
+// Peek 查看堆顶元素但不弹出
+// 返回值:
+//   - T: 堆顶元素
+//   - error: 堆为空时返回 ErrEmptyHeap
+func (h *Heap[T]) Peek() (T, error) {
+	if len(h.data) == 0 {
+		var zeroValue T
+		return zeroValue, ErrEmptyHeap
+	}
+	return h.data[0], nil
+}
+
+// Len 获取堆中元素数量
+// 返回值:
+//   - int: 元素数量
+func (h *Heap[T]) Len() int {
+	return len(h.data)
+}
+
+// up 将位置i的元素上浮到合适位置
+func (h *Heap[T]) up(i int) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !h.less(h.data[i], h.data[parent]) {
+			return
+		}
+		h.data[i], h.data[parent] = h.data[parent], h.data[i]
+		i = parent
+	}
+}
+
+// down 将位置i的元素下沉到合适位置
+func (h *Heap[T]) down(i int) {
+	n := len(h.data)
+	for {
+		smallest := i
+		if left := 2*i + 1; left < n && h.less(h.data[left], h.data[smallest]) {
+			smallest = left
+		}
+		if right := 2*i + 2; right < n && h.less(h.data[right], h.data[smallest]) {
+			smallest = right
+		}
+		if smallest == i {
+			return
+		}
+		h.data[i], h.data[smallest] = h.data[smallest], h.data[i]
+		i = smallest
+	}
+}
@@ -0,0 +1,69 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHeap_Order 测试不同比较函数下的出堆顺序
+// 验证以下场景:
+// 1. 最小堆
+// 2. 最大堆
+func TestHeap_Order(t *testing.T) {
+	testCases := []struct {
+		name string
+		less func(a, b int) bool
+		want []int
+	}{
+		{
+			name: "最小堆",
+			less: func(a, b int) bool { return a < b },
+			want: []int{1, 2, 3, 3, 5, 8, 9},
+		},
+		{
+			name: "最大堆",
+			less: func(a, b int) bool { return a > b },
+			want: []int{9, 8, 5, 3, 3, 2, 1},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := NewHeap(tc.less)
+			for _, v := range []int{5, 3, 9, 1, 8, 3, 2} {
+				h.Push(v)
+			}
+			assert.Equal(t, 7, h.Len())
+
+			top, err := h.Peek()
+			require.NoError(t, err)
+			assert.Equal(t, tc.want[0], top)
+
+			got := make([]int, 0, h.Len())
+			for h.Len() > 0 {
+				v, err := h.Pop()
+				require.NoError(t, err)
+				got = append(got, v)
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+// TestHeap_Empty 测试空堆返回错误
+func TestHeap_Empty(t *testing.T) {
+	h := NewHeap(func(a, b int) bool { return a < b })
+
+	_, err := h.Pop()
+	assert.ErrorIs(t, err, ErrEmptyHeap)
+	_, err = h.Peek()
+	assert.ErrorIs(t, err, ErrEmptyHeap)
+
+	h.Push(1)
+	_, err = h.Pop()
+	require.NoError(t, err)
+	_, err = h.Pop()
+	assert.ErrorIs(t, err, ErrEmptyHeap)
+}
@@ -1,6 +1,16 @@
 package tools
 
-import "github.com/justinwongcn/hamster/internal/domain/errs"
+import (
+	"errors"
+	"iter"
+	"reflect"
+	"sort"
+
+	"github.com/justinwongcn/hamster/internal/domain/errs"
+)
+
+// ErrEmptyList 链表为空时查看或删除首尾元素返回的错误
+var ErrEmptyList = errors.New("链表为空")
 
 // List 通用链表接口
 // 定义了链表的基本操作方法
@@ -165,13 +175,20 @@ func (l *LinkedList[T]) Delete(index int) (T, error) {
 		return zeroValue, errs.NewErrIndexOutOfRange(l.Len(), index)
 	}
 	node := l.findNode(index)
-	node.prev.next = node.next
-	node.next.prev = node.prev
-	node.prev, node.next = nil, nil
-	l.length--
+	l.unlink(node)
 	return node.val, nil
 }
 
+// unlink 将结点从链表中摘除
+// 参数:
+//   - n: 要摘除的数据结点
+func (l *LinkedList[T]) unlink(n *node[T]) {
+	n.prev.next = n.next
+	n.next.prev = n.prev
+	n.prev, n.next = nil, nil
+	l.length--
+}
+
 // Len 获取链表的长度
 // 返回值:
 //   - int: 链表当前长度
@@ -214,3 +231,248 @@ func (l *LinkedList[T]) AsSlice() []T {
 	}
 	return slice
 }
+
+// IndexOf 查找第一个与给定值相等的元素的索引
+// 由于T可以是任意类型，使用 reflect.DeepEqual 判断相等，需要自定义相等规则时使用 IndexOfFunc
+// 参数:
+//   - val: 要查找的值
+//
+// 返回值:
+//   - int: 第一个匹配元素的索引，不存在时返回-1
+func (l *LinkedList[T]) IndexOf(val T) int {
+	return l.IndexOfFunc(func(t T) bool {
+		return reflect.DeepEqual(t, val)
+	})
+}
+
+// IndexOfFunc 查找第一个满足条件的元素的索引
+// 参数:
+//   - pred: 判断函数，返回true表示匹配
+//
+// 返回值:
+//   - int: 第一个匹配元素的索引，不存在时返回-1
+func (l *LinkedList[T]) IndexOfFunc(pred func(T) bool) int {
+	for cur, i := l.head.next, 0; i < l.length; i++ {
+		if pred(cur.val) {
+			return i
+		}
+		cur = cur.next
+	}
+	return -1
+}
+
+// Contains 判断链表中是否存在与给定值相等的元素
+// 相等判断规则与 IndexOf 相同
+// 参数:
+//   - val: 要查找的值
+//
+// 返回值:
+//   - bool: true表示存在
+func (l *LinkedList[T]) Contains(val T) bool {
+	return l.IndexOf(val) >= 0
+}
+
+// Reverse 原地反转链表中元素的顺序
+// 注意: 该方法会修改链表本身，时间复杂度O(n)
+func (l *LinkedList[T]) Reverse() {
+	if l.length < 2 {
+		return
+	}
+	first, last := l.head.next, l.tail.prev
+	// 交换每个数据结点的前驱和后继指针
+	for cur := first; cur != l.tail; {
+		next := cur.next
+		cur.prev, cur.next = cur.next, cur.prev
+		cur = next
+	}
+	// 重新连接哨兵结点
+	l.head.next, last.prev = last, l.head
+	l.tail.prev, first.next = first, l.tail
+}
+
+// Filter 返回由满足条件的元素组成的新链表
+// 原链表不会被修改
+// 参数:
+//   - pred: 判断函数，返回true表示保留
+//
+// 返回值:
+//   - *LinkedList[T]: 包含匹配元素的新链表
+func (l *LinkedList[T]) Filter(pred func(T) bool) *LinkedList[T] {
+	res := NewLinkedList[T]()
+	for cur := l.head.next; cur != l.tail; cur = cur.next {
+		if pred(cur.val) {
+			_ = res.Append(cur.val)
+		}
+	}
+	return res
+}
+
+// Map 将链表中的每个元素转换后组成新链表
+// Go的方法不能引入新的类型参数，因此定义为包级函数；原链表不会被修改
+// 参数:
+//   - l: 源链表
+//   - f: 转换函数
+//
+// 返回值:
+//   - *LinkedList[U]: 包含转换结果的新链表
+func Map[T, U any](l *LinkedList[T], f func(T) U) *LinkedList[U] {
+	res := NewLinkedList[U]()
+	for cur := l.head.next; cur != l.tail; cur = cur.next {
+		_ = res.Append(f(cur.val))
+	}
+	return res
+}
+
+// RemoveValue 删除第一个与给定值相等的元素
+// 相等判断规则与 IndexOf 相同，需要自定义相等规则时使用 RemoveValueFunc
+// 参数:
+//   - val: 要删除的值
+//
+// 返回值:
+//   - bool: true表示删除了元素，false表示元素不存在
+//   - error: 操作错误信息
+func (l *LinkedList[T]) RemoveValue(val T) (bool, error) {
+	return l.RemoveValueFunc(func(t T) bool {
+		return reflect.DeepEqual(t, val)
+	})
+}
+
+// RemoveValueFunc 删除第一个满足条件的元素
+// 参数:
+//   - pred: 判断函数，返回true表示匹配
+//
+// 返回值:
+//   - bool: true表示删除了元素，false表示没有匹配的元素
+//   - error: 操作错误信息
+func (l *LinkedList[T]) RemoveValueFunc(pred func(T) bool) (bool, error) {
+	for cur := l.head.next; cur != l.tail; cur = cur.next {
+		if pred(cur.val) {
+			l.unlink(cur)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Clone 复制链表
+// 复制结点结构，元素值按赋值复制，之后对副本和原链表的修改互不影响
+// 返回值:
+//   - *LinkedList[T]: 新的链表实例
+func (l *LinkedList[T]) Clone() *LinkedList[T] {
+	res := NewLinkedList[T]()
+	for cur := l.head.next; cur != l.tail; cur = cur.next {
+		_ = res.Append(cur.val)
+	}
+	return res
+}
+
+// Sort 按比较函数原地排序链表
+// 先收集元素稳定排序，再按顺序写回各结点，相等元素保持原有相对顺序
+// 时间复杂度O(n log n)，额外空间复杂度O(n)
+// 参数:
+//   - less: 比较函数，a应排在b之前时返回true
+func (l *LinkedList[T]) Sort(less func(a, b T) bool) {
+	vals := l.AsSlice()
+	sort.SliceStable(vals, func(i, j int) bool {
+		return less(vals[i], vals[j])
+	})
+	for cur, i := l.head.next, 0; i < l.length; i++ {
+		cur.val = vals[i]
+		cur = cur.next
+	}
+}
+
+// All 返回按顺序遍历索引和元素的迭代器
+// 使用Go 1.23+的迭代器特性，调用者提前结束循环时停止遍历
+// 返回值:
+//   - iter.Seq2[int, T]: 索引和元素的迭代器
+func (l *LinkedList[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for cur, i := l.head.next, 0; cur != l.tail; cur, i = cur.next, i+1 {
+			if !yield(i, cur.val) {
+				return
+			}
+		}
+	}
+}
+
+// Values 返回按顺序遍历元素的迭代器
+// 返回值:
+//   - iter.Seq[T]: 元素的迭代器
+func (l *LinkedList[T]) Values() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for cur := l.head.next; cur != l.tail; cur = cur.next {
+			if !yield(cur.val) {
+				return
+			}
+		}
+	}
+}
+
+// AddFirst 在链表头部添加元素，时间复杂度O(1)
+// 参数:
+//   - t: 要添加的元素
+func (l *LinkedList[T]) AddFirst(t T) {
+	node := &node[T]{prev: l.head, next: l.head.next, val: t}
+	node.prev.next, node.next.prev = node, node
+	l.length++
+}
+
+// AddLast 在链表尾部添加元素，时间复杂度O(1)
+// 参数:
+//   - t: 要添加的元素
+func (l *LinkedList[T]) AddLast(t T) {
+	_ = l.Append(t)
+}
+
+// PeekFirst 查看链表的第一个元素
+// 返回值:
+//   - T: 第一个元素
+//   - error: 链表为空时返回 ErrEmptyList
+func (l *LinkedList[T]) PeekFirst() (T, error) {
+	if l.length == 0 {
+		var zeroValue T
+		return zeroValue, ErrEmptyList
+	}
+	return l.head.next.val, nil
+}
+
+// PeekLast 查看链表的最后一个元素
+// 返回值:
+//   - T: 最后一个元素
+//   - error: 链表为空时返回 ErrEmptyList
+func (l *LinkedList[T]) PeekLast() (T, error) {
+	if l.length == 0 {
+		var zeroValue T
+		return zeroValue, ErrEmptyList
+	}
+	return l.tail.prev.val, nil
+}
+
+// RemoveFirst 删除并返回链表的第一个元素，时间复杂度O(1)
+// 返回值:
+//   - T: 被删除的元素
+//   - error: 链表为空时返回 ErrEmptyList
+func (l *LinkedList[T]) RemoveFirst() (T, error) {
+	if l.length == 0 {
+		var zeroValue T
+		return zeroValue, ErrEmptyList
+	}
+	n := l.head.next
+	l.unlink(n)
+	return n.val, nil
+}
+
+// RemoveLast 删除并返回链表的最后一个元素，时间复杂度O(1)
+// 返回值:
+//   - T: 被删除的元素
+//   - error: 链表为空时返回 ErrEmptyList
+func (l *LinkedList[T]) RemoveLast() (T, error) {
+	if l.length == 0 {
+		var zeroValue T
+		return zeroValue, ErrEmptyList
+	}
+	n := l.tail.prev
+	l.unlink(n)
+	return n.val, nil
+}
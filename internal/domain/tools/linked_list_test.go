@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -507,6 +508,404 @@ func TestLinkedList_Set(t *testing.T) {
 	}
 }
 
+// TestLinkedList_IndexOf 测试链表IndexOf、IndexOfFunc和Contains方法
+// 验证以下场景:
+// 1. 元素位于头部
+// 2. 元素位于中间
+// 3. 元素不存在
+// 4. 空链表
+func TestLinkedList_IndexOf(t *testing.T) {
+	testCases := []struct {
+		name      string
+		list      func() *LinkedList[int]
+		val       int
+		wantIndex int
+	}{
+		{
+			name:      "元素位于头部",
+			list:      func() *LinkedList[int] { return NewLinkedListOf([]int{1, 2, 3, 1}) },
+			val:       1,
+			wantIndex: 0,
+		},
+		{
+			name:      "元素位于中间",
+			list:      func() *LinkedList[int] { return NewLinkedListOf([]int{1, 2, 3, 2}) },
+			val:       2,
+			wantIndex: 1,
+		},
+		{
+			name:      "元素不存在",
+			list:      func() *LinkedList[int] { return NewLinkedListOf([]int{1, 2, 3}) },
+			val:       4,
+			wantIndex: -1,
+		},
+		{
+			name:      "空链表",
+			list:      func() *LinkedList[int] { return NewLinkedList[int]() },
+			val:       1,
+			wantIndex: -1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			list := tc.list()
+			assert.Equal(t, tc.wantIndex, list.IndexOf(tc.val))
+			assert.Equal(t, tc.wantIndex, list.IndexOfFunc(func(v int) bool { return v == tc.val }))
+			assert.Equal(t, tc.wantIndex >= 0, list.Contains(tc.val))
+		})
+	}
+
+	t.Run("不可比较的类型", func(t *testing.T) {
+		list := NewLinkedListOf([][]int{{1}, {2, 3}})
+		assert.Equal(t, 1, list.IndexOf([]int{2, 3}))
+		assert.False(t, list.Contains([]int{4}))
+		assert.Equal(t, 0, list.IndexOfFunc(func(v []int) bool { return len(v) == 1 }))
+	})
+}
+
+// TestLinkedList_Reverse 测试链表Reverse方法
+// 验证以下场景:
+// 1. 空链表
+// 2. 单个元素
+// 3. 多个元素
+func TestLinkedList_Reverse(t *testing.T) {
+	testCases := []struct {
+		name      string
+		list      func() *LinkedList[int]
+		wantSlice []int
+	}{
+		{
+			name:      "空链表",
+			list:      func() *LinkedList[int] { return NewLinkedList[int]() },
+			wantSlice: []int{},
+		},
+		{
+			name:      "单个元素",
+			list:      func() *LinkedList[int] { return NewLinkedListOf([]int{1}) },
+			wantSlice: []int{1},
+		},
+		{
+			name:      "多个元素",
+			list:      func() *LinkedList[int] { return NewLinkedListOf([]int{1, 2, 3, 4, 5}) },
+			wantSlice: []int{5, 4, 3, 2, 1},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			list := tc.list()
+			list.Reverse()
+			assert.Equal(t, tc.wantSlice, list.AsSlice())
+			assert.Equal(t, len(tc.wantSlice), list.Len())
+
+			// 反转后的前后指针保持一致，后续操作正常
+			for i, want := range tc.wantSlice {
+				got, err := list.Get(i)
+				assert.NoError(t, err)
+				assert.Equal(t, want, got)
+			}
+			assert.NoError(t, list.Append(100))
+			assert.NoError(t, list.Add(0, -100))
+			assert.Equal(t, append(append([]int{-100}, tc.wantSlice...), 100), list.AsSlice())
+		})
+	}
+}
+
+// TestLinkedList_Filter 测试链表Filter方法
+func TestLinkedList_Filter(t *testing.T) {
+	list := NewLinkedListOf([]int{1, 2, 3, 4})
+
+	evens := list.Filter(func(v int) bool { return v%2 == 0 })
+	assert.Equal(t, []int{2, 4}, evens.AsSlice())
+	assert.Equal(t, 2, evens.Len())
+
+	none := list.Filter(func(v int) bool { return v > 10 })
+	assert.Equal(t, []int{}, none.AsSlice())
+
+	// 原链表不受影响
+	assert.Equal(t, []int{1, 2, 3, 4}, list.AsSlice())
+	assert.NoError(t, evens.Append(6))
+	assert.Equal(t, 4, list.Len())
+}
+
+// TestMap 测试链表Map函数
+func TestMap(t *testing.T) {
+	list := NewLinkedListOf([]int{1, 2, 3, 4})
+
+	strs := Map(list, func(v int) string { return strconv.Itoa(v) })
+	assert.Equal(t, []string{"1", "2", "3", "4"}, strs.AsSlice())
+	assert.Equal(t, 4, strs.Len())
+
+	empty := Map(NewLinkedList[int](), func(v int) string { return strconv.Itoa(v) })
+	assert.Equal(t, []string{}, empty.AsSlice())
+
+	// 原链表不受影响
+	assert.Equal(t, []int{1, 2, 3, 4}, list.AsSlice())
+}
+
+// TestLinkedList_RemoveValue 测试链表RemoveValue和RemoveValueFunc方法
+// 验证以下场景:
+// 1. 删除头部元素
+// 2. 删除中间元素
+// 3. 删除尾部元素
+// 4. 元素不存在
+func TestLinkedList_RemoveValue(t *testing.T) {
+	testCases := []struct {
+		name        string
+		list        func() *LinkedList[int]
+		val         int
+		wantRemoved bool
+		wantSlice   []int
+	}{
+		{
+			name:        "删除头部元素",
+			list:        func() *LinkedList[int] { return NewLinkedListOf([]int{1, 2, 3}) },
+			val:         1,
+			wantRemoved: true,
+			wantSlice:   []int{2, 3},
+		},
+		{
+			name:        "删除中间元素",
+			list:        func() *LinkedList[int] { return NewLinkedListOf([]int{1, 2, 3, 2}) },
+			val:         2,
+			wantRemoved: true,
+			wantSlice:   []int{1, 3, 2},
+		},
+		{
+			name:        "删除尾部元素",
+			list:        func() *LinkedList[int] { return NewLinkedListOf([]int{1, 2, 3}) },
+			val:         3,
+			wantRemoved: true,
+			wantSlice:   []int{1, 2},
+		},
+		{
+			name:        "元素不存在",
+			list:        func() *LinkedList[int] { return NewLinkedListOf([]int{1, 2, 3}) },
+			val:         4,
+			wantRemoved: false,
+			wantSlice:   []int{1, 2, 3},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			list := tc.list()
+			removed, err := list.RemoveValue(tc.val)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantRemoved, removed)
+			assert.Equal(t, tc.wantSlice, list.AsSlice())
+			assert.Equal(t, len(tc.wantSlice), list.Len())
+			assert.Equal(t, len(tc.wantSlice), list.Cap())
+
+			// 删除后链表结构完整，可以继续操作
+			assert.NoError(t, list.Append(100))
+			got, err := list.Get(list.Len() - 1)
+			assert.NoError(t, err)
+			assert.Equal(t, 100, got)
+		})
+	}
+
+	t.Run("自定义相等规则", func(t *testing.T) {
+		list := NewLinkedListOf([]int{1, 2, 3, 4})
+		removed, err := list.RemoveValueFunc(func(v int) bool { return v%2 == 0 })
+		assert.NoError(t, err)
+		assert.True(t, removed)
+		assert.Equal(t, []int{1, 3, 4}, list.AsSlice())
+	})
+}
+
+// TestLinkedList_Clone 测试链表Clone方法
+func TestLinkedList_Clone(t *testing.T) {
+	list := NewLinkedListOf([]int{1, 2, 3})
+	clone := list.Clone()
+	assert.Equal(t, list.AsSlice(), clone.AsSlice())
+	assert.Equal(t, list.Len(), clone.Len())
+
+	// 修改副本不影响原链表
+	assert.NoError(t, clone.Add(0, 0))
+	assert.NoError(t, clone.Set(2, 20))
+	_, err := clone.Delete(3)
+	assert.NoError(t, err)
+
+	// 修改原链表不影响副本
+	assert.NoError(t, list.Append(4))
+	_, err = list.Delete(0)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []int{2, 3, 4}, list.AsSlice())
+	assert.Equal(t, []int{0, 1, 20}, clone.AsSlice())
+
+	assert.Equal(t, []int{}, NewLinkedList[int]().Clone().AsSlice())
+}
+
+// TestLinkedList_Sort 测试链表Sort方法
+// 验证以下场景:
+// 1. 升序排序
+// 2. 降序排序
+// 3. 相等元素保持原有顺序
+func TestLinkedList_Sort(t *testing.T) {
+	testCases := []struct {
+		name      string
+		list      func() *LinkedList[int]
+		less      func(a, b int) bool
+		wantSlice []int
+	}{
+		{
+			name:      "升序排序",
+			list:      func() *LinkedList[int] { return NewLinkedListOf([]int{5, 2, 8, 1, 9, 3}) },
+			less:      func(a, b int) bool { return a < b },
+			wantSlice: []int{1, 2, 3, 5, 8, 9},
+		},
+		{
+			name:      "降序排序",
+			list:      func() *LinkedList[int] { return NewLinkedListOf([]int{5, 2, 8, 1, 9, 3}) },
+			less:      func(a, b int) bool { return a > b },
+			wantSlice: []int{9, 8, 5, 3, 2, 1},
+		},
+		{
+			name:      "空链表",
+			list:      func() *LinkedList[int] { return NewLinkedList[int]() },
+			less:      func(a, b int) bool { return a < b },
+			wantSlice: []int{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			list := tc.list()
+			list.Sort(tc.less)
+			assert.Equal(t, tc.wantSlice, list.AsSlice())
+			assert.Equal(t, len(tc.wantSlice), list.Len())
+		})
+	}
+
+	t.Run("稳定排序", func(t *testing.T) {
+		type item struct {
+			key   int
+			order string
+		}
+		list := NewLinkedListOf([]item{{2, "a"}, {1, "b"}, {2, "c"}, {1, "d"}, {2, "e"}})
+		list.Sort(func(a, b item) bool { return a.key < b.key })
+		assert.Equal(t, []item{{1, "b"}, {1, "d"}, {2, "a"}, {2, "c"}, {2, "e"}}, list.AsSlice())
+	})
+}
+
+// TestLinkedList_Iterator 测试链表All和Values迭代器
+// 验证以下场景:
+// 1. 完整遍历
+// 2. 提前结束遍历
+// 3. 遍历空链表
+func TestLinkedList_Iterator(t *testing.T) {
+	t.Run("完整遍历", func(t *testing.T) {
+		list := NewLinkedListOf([]int{1, 2, 3})
+
+		indexes, vals := make([]int, 0), make([]int, 0)
+		for i, v := range list.All() {
+			indexes = append(indexes, i)
+			vals = append(vals, v)
+		}
+		assert.Equal(t, []int{0, 1, 2}, indexes)
+		assert.Equal(t, []int{1, 2, 3}, vals)
+
+		vals = vals[:0]
+		for v := range list.Values() {
+			vals = append(vals, v)
+		}
+		assert.Equal(t, []int{1, 2, 3}, vals)
+	})
+
+	t.Run("提前结束遍历", func(t *testing.T) {
+		list := NewLinkedListOf([]int{1, 2, 3, 4, 5})
+
+		vals := make([]int, 0)
+		for i, v := range list.All() {
+			if i == 2 {
+				break
+			}
+			vals = append(vals, v)
+		}
+		assert.Equal(t, []int{1, 2}, vals)
+
+		vals = vals[:0]
+		for v := range list.Values() {
+			if v > 3 {
+				break
+			}
+			vals = append(vals, v)
+		}
+		assert.Equal(t, []int{1, 2, 3}, vals)
+	})
+
+	t.Run("遍历空链表", func(t *testing.T) {
+		list := NewLinkedList[int]()
+		for range list.All() {
+			t.Fatal("空链表不应该产生元素")
+		}
+		for range list.Values() {
+			t.Fatal("空链表不应该产生元素")
+		}
+	})
+}
+
+// TestLinkedList_EndOperations 测试链表首尾操作
+// 验证以下场景:
+// 1. 在首尾添加元素
+// 2. 查看首尾元素
+// 3. 删除首尾元素
+// 4. 空链表返回错误
+func TestLinkedList_EndOperations(t *testing.T) {
+	t.Run("非空链表", func(t *testing.T) {
+		list := NewLinkedListOf([]int{2, 3})
+		list.AddFirst(1)
+		list.AddLast(4)
+		assert.Equal(t, []int{1, 2, 3, 4}, list.AsSlice())
+		assert.Equal(t, 4, list.Len())
+
+		first, err := list.PeekFirst()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, first)
+		last, err := list.PeekLast()
+		assert.NoError(t, err)
+		assert.Equal(t, 4, last)
+
+		first, err = list.RemoveFirst()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, first)
+		last, err = list.RemoveLast()
+		assert.NoError(t, err)
+		assert.Equal(t, 4, last)
+		assert.Equal(t, []int{2, 3}, list.AsSlice())
+		assert.Equal(t, 2, list.Len())
+	})
+
+	t.Run("空链表添加元素", func(t *testing.T) {
+		list := NewLinkedList[int]()
+		list.AddFirst(1)
+		assert.Equal(t, []int{1}, list.AsSlice())
+
+		list = NewLinkedList[int]()
+		list.AddLast(1)
+		assert.Equal(t, []int{1}, list.AsSlice())
+		val, err := list.RemoveFirst()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, val)
+		assert.Equal(t, 0, list.Len())
+	})
+
+	t.Run("空链表返回错误", func(t *testing.T) {
+		list := NewLinkedList[int]()
+		_, err := list.PeekFirst()
+		assert.ErrorIs(t, err, ErrEmptyList)
+		_, err = list.PeekLast()
+		assert.ErrorIs(t, err, ErrEmptyList)
+		_, err = list.RemoveFirst()
+		assert.ErrorIs(t, err, ErrEmptyList)
+		_, err = list.RemoveLast()
+		assert.ErrorIs(t, err, ErrEmptyList)
+	})
+}
+
 func BenchmarkLinkedList_Add(b *testing.B) {
 	l := NewLinkedListOf([]int{1, 2, 3})
 	testCase := make([]int, 0, b.N)
@@ -0,0 +1,147 @@
+package tools
+
+// lruEntry LRUMap中的链表结点
+type lruEntry[K comparable, V any] struct {
+	prev *lruEntry[K, V]
+	next *lruEntry[K, V]
+	key  K
+	val  V
+}
+
+// LRUMap 固定容量的泛型LRU映射
+// 使用哈希表+带哨兵结点的双向循环链表，链表头部是最近使用的元素，
+// 超过容量时淘汰链表尾部最久未使用的元素，Get、Put、Remove的时间复杂度均为O(1)
+// 非线程安全
+type LRUMap[K comparable, V any] struct {
+	capacity int
+	entries  map[K]*lruEntry[K, V]
+	root     lruEntry[K, V] // 哨兵结点，root.next为最近使用，root.prev为最久未使用
+	onEvict  func(key K, val V)
+}
+
+// NewLRUMap 创建指定容量的LRU映射
+// 参数:
+//   - capacity: 最多保存的元素数量，必须大于0，否则panic
+//
+// 返回值:
+//   - *LRUMap[K, V]: 新建的LRU映射实例
+func NewLRUMap[K comparable, V any](capacity int) *LRUMap[K, V] {
+	if capacity <= 0 {
+		panic("tools: LRU映射容量必须大于0")
+	}
+	m := &LRUMap[K, V]{
+		capacity: capacity,
+		entries:  make(map[K]*lruEntry[K, V], capacity),
+	}
+	m.root.next = &m.root
+	m.root.prev = &m.root
+	return m
+}
+
+// OnEvicted 设置元素因超过容量被淘汰时的回调函数
+// 调用 Remove 删除和 Put 覆盖元素时不触发回调
+// 参数:
+//   - fn: 回调函数，参数为被淘汰的键和值
+func (m *LRUMap[K, V]) OnEvicted(fn func(key K, val V)) {
+	m.onEvict = fn
+}
+
+// Get 获取键对应的值，并将其标记为最近使用
+// 参数:
+//   - key: 键
+//
+// 返回值:
+//   - V: 键对应的值，不存在时为零值
+//   - bool: 键是否存在
+func (m *LRUMap[K, V]) Get(key K) (V, bool) {
+	e, ok := m.entries[key]
+	if !ok {
+		var zeroValue V
+		return zeroValue, false
+	}
+	m.moveToFront(e)
+	return e.val, true
+}
+
+// Put 写入键值，并将其标记为最近使用
+// 键已存在时更新值，写入新键后超过容量时淘汰最久未使用的元素
+// 参数:
+//   - key: 键
+//   - val: 值
+func (m *LRUMap[K, V]) Put(key K, val V) {
+	if e, ok := m.entries[key]; ok {
+		e.val = val
+		m.moveToFront(e)
+		return
+	}
+
+	e := &lruEntry[K, V]{key: key, val: val}
+	m.entries[key] = e
+	m.insertFront(e)
+	if len(m.entries) > m.capacity {
+		oldest := m.root.prev
+		m.unlink(oldest)
+		delete(m.entries, oldest.key)
+		if m.onEvict != nil {
+			m.onEvict(oldest.key, oldest.val)
+		}
+	}
+}
+
+// Remove 删除键
+// 参数:
+//   - key: 键
+//
+// 返回值:
+//   - bool: 键是否存在
+func (m *LRUMap[K, V]) Remove(key K) bool {
+	e, ok := m.entries[key]
+	if !ok {
+		return false
+	}
+	m.unlink(e)
+	delete(m.entries, key)
+	return true
+}
+
+// Len 获取元素数量
+// 返回值:
+//   - int: 元素数量
+func (m *LRUMap[K, V]) Len() int {
+	return len(m.entries)
+}
+
+// Keys 按从最近使用到最久未使用的顺序返回所有键
+// 返回值:
+//   - []K: 键列表，不改变使用顺序
+func (m *LRUMap[K, V]) Keys() []K {
+	keys := make([]K, 0, len(m.entries))
+	for e := m.root.next; e != &m.root; e = e.next {
+		keys = append(keys, e.key)
+	}
+	return keys
+}
+
+// insertFront 将结点插入到链表头部
+func (m *LRUMap[K, V]) insertFront(e *lruEntry[K, V]) {
+	e.prev = &m.root
+	e.next = m.root.next
+	m.root.next.prev = e
+	m.root.next = e
+}
+
+// unlink 将结点从链表中摘除
+func (m *LRUMap[K, V]) unlink(e *lruEntry[K, V]) {
+	e.prev.next = e.next
+	e.next.prev = e.prev
+	e.prev, e.next = nil, nil
+}
+
+// moveToFront 将结点移动到链表头部
+func (m *LRUMap[K, V]) moveToFront(e *lruEntry[K, V]) {
+	if m.root.next == e {
+		return
+	}
+	m.unlink(e)
+	m.insertFront(e)
+}
@@ -0,0 +1,88 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLRUMap_EvictionOrder 测试超过容量时按最久未使用的顺序淘汰
+func TestLRUMap_EvictionOrder(t *testing.T) {
+	m := NewLRUMap[string, int](3)
+	m.Put("a", 1)
+	m.Put("b", 2)
+	m.Put("c", 3)
+	assert.Equal(t, []string{"c", "b", "a"}, m.Keys())
+
+	// 访问a后，最久未使用的是b
+	val, ok := m.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, val)
+
+	m.Put("d", 4)
+	assert.Equal(t, 3, m.Len())
+	_, ok = m.Get("b")
+	assert.False(t, ok)
+	assert.Equal(t, []string{"d", "a", "c"}, m.Keys())
+
+	m.Put("e", 5)
+	_, ok = m.Get("c")
+	assert.False(t, ok)
+	assert.Equal(t, []string{"e", "d", "a"}, m.Keys())
+}
+
+// TestLRUMap_UpdateMovesToFront 测试更新已有的键会移动到最近使用的位置且不淘汰元素
+func TestLRUMap_UpdateMovesToFront(t *testing.T) {
+	m := NewLRUMap[string, int](2)
+	var evicted []string
+	m.OnEvicted(func(key string, _ int) {
+		evicted = append(evicted, key)
+	})
+
+	m.Put("a", 1)
+	m.Put("b", 2)
+	m.Put("a", 10)
+	assert.Equal(t, 2, m.Len())
+	assert.Equal(t, []string{"a", "b"}, m.Keys())
+	assert.Empty(t, evicted)
+
+	val, ok := m.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 10, val)
+
+	// b是最久未使用的
+	m.Put("c", 3)
+	assert.Equal(t, []string{"b"}, evicted)
+	assert.Equal(t, []string{"c", "a"}, m.Keys())
+}
+
+// TestLRUMap_OnEvicted 测试淘汰回调只在超过容量时触发
+func TestLRUMap_OnEvicted(t *testing.T) {
+	m := NewLRUMap[int, string](2)
+	evicted := map[int]string{}
+	m.OnEvicted(func(key int, val string) {
+		evicted[key] = val
+	})
+
+	m.Put(1, "one")
+	m.Put(2, "two")
+	m.Put(3, "three")
+	m.Put(4, "four")
+	assert.Equal(t, map[int]string{1: "one", 2: "two"}, evicted)
+
+	// 删除不触发回调
+	assert.True(t, m.Remove(3))
+	assert.False(t, m.Remove(3))
+	assert.Equal(t, 1, m.Len())
+	assert.Len(t, evicted, 2)
+
+	m.Put(5, "five")
+	assert.Len(t, evicted, 2)
+	assert.Equal(t, []int{5, 4}, m.Keys())
+}
+
+// TestLRUMap_InvalidCapacity 测试容量不大于0时panic
+func TestLRUMap_InvalidCapacity(t *testing.T) {
+	assert.Panics(t, func() { NewLRUMap[string, int](0) })
+	assert.Panics(t, func() { NewLRUMap[string, int](-1) })
+}
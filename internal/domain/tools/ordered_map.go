@@ -0,0 +1,110 @@
+package tools
+
+// orderedEntry OrderedMap中的链表结点
+type orderedEntry[K comparable, V any] struct {
+	prev *orderedEntry[K, V]
+	next *orderedEntry[K, V]
+	key  K
+	val  V
+}
+
+// OrderedMap 保持插入顺序的泛型映射
+// 使用哈希表+带哨兵结点的双向循环链表，按键首次插入的顺序遍历，
+// 删除元素不影响其余键的顺序，Set、Get、Delete的时间复杂度均为O(1)
+// 非线程安全
+type OrderedMap[K comparable, V any] struct {
+	entries map[K]*orderedEntry[K, V]
+	root    orderedEntry[K, V] // 哨兵结点，root.next为最早插入，root.prev为最晚插入
+}
+
+// NewOrderedMap 创建一个空的有序映射
+// 返回值:
+//   - *OrderedMap[K, V]: 新建的有序映射实例
+func NewOrderedMap[K comparable, V any]() *OrderedMap[K, V] {
+	m := &OrderedMap[K, V]{
+		entries: make(map[K]*orderedEntry[K, V]),
+	}
+	m.root.next = &m.root
+	m.root.prev = &m.root
+	return m
+}
+
+// Set 写入键值
+// 新键追加到末尾，已有的键只更新值，保持原来的位置
+// 参数:
+//   - key: 键
+//   - val: 值
+func (m *OrderedMap[K, V]) Set(key K, val V) {
+	if e, ok := m.entries[key]; ok {
+		e.val = val
+		return
+	}
+	e := &orderedEntry[K, V]{key: key, val: val, prev: m.root.prev, next: &m.root}
+	m.root.prev.next = e
+	m.root.prev = e
+	m.entries[key] = e
+}
+
+// Get 获取键对应的值
+// 参数:
+//   - key: 键
+//
+// 返回值:
+//   - V: 键对应的值，不存在时为零值
+//   - bool: 键是否存在
+func (m *OrderedMap[K, V]) Get(key K) (V, bool) {
+	e, ok := m.entries[key]
+	if !ok {
+		var zeroValue V
+		return zeroValue, false
+	}
+	return e.val, true
+}
+
+// Delete 删除键
+// 参数:
+//   - key: 键
+//
+// 返回值:
+//   - bool: 键是否存在
+func (m *OrderedMap[K, V]) Delete(key K) bool {
+	e, ok := m.entries[key]
+	if !ok {
+		return false
+	}
+	e.prev.next = e.next
+	e.next.prev = e.prev
+	e.prev, e.next = nil, nil
+	delete(m.entries, key)
+	return true
+}
+
+// Len 获取元素数量
+// 返回值:
+//   - int: 元素数量
+func (m *OrderedMap[K, V]) Len() int {
+	return len(m.entries)
+}
+
+// Range 按插入顺序遍历所有键值
+// 参数:
+//   - fn: 遍历函数，返回false时停止遍历，遍历过程中不能修改映射
+func (m *OrderedMap[K, V]) Range(fn func(key K, val V) bool) {
+	for e := m.root.next; e != &m.root; e = e.next {
+		if !fn(e.key, e.val) {
+			return
+		}
+	}
+}
+
+// Keys 按插入顺序返回所有键
+// 返回值:
+//   - []K: 键列表
+func (m *OrderedMap[K, V]) Keys() []K {
+	keys := make([]K, 0, len(m.entries))
+	m.Range(func(key K, _ V) bool {
+		keys = append(keys, key)
+		return true
+	})
+	return keys
+}
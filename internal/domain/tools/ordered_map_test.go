@@ -0,0 +1,72 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestOrderedMap_Order 测试交替插入和删除后按插入顺序遍历
+func TestOrderedMap_Order(t *testing.T) {
+	m := NewOrderedMap[string, int]()
+	m.Set("c", 3)
+	m.Set("a", 1)
+	m.Set("b", 2)
+	assert.Equal(t, []string{"c", "a", "b"}, m.Keys())
+
+	// 删除中间的键不影响其余键的顺序
+	assert.True(t, m.Delete("a"))
+	assert.False(t, m.Delete("a"))
+	m.Set("d", 4)
+	assert.Equal(t, []string{"c", "b", "d"}, m.Keys())
+
+	// 更新已有的键保持原来的位置
+	m.Set("c", 30)
+	val, ok := m.Get("c")
+	assert.True(t, ok)
+	assert.Equal(t, 30, val)
+	assert.Equal(t, []string{"c", "b", "d"}, m.Keys())
+
+	// 删除后重新插入追加到末尾
+	assert.True(t, m.Delete("c"))
+	m.Set("a", 10)
+	m.Set("c", 300)
+	assert.Equal(t, []string{"b", "d", "a", "c"}, m.Keys())
+	assert.Equal(t, 4, m.Len())
+
+	// 删除首尾的键
+	assert.True(t, m.Delete("b"))
+	assert.True(t, m.Delete("c"))
+	assert.Equal(t, []string{"d", "a"}, m.Keys())
+
+	_, ok = m.Get("b")
+	assert.False(t, ok)
+}
+
+// TestOrderedMap_Range 测试按插入顺序遍历并提前停止
+func TestOrderedMap_Range(t *testing.T) {
+	m := NewOrderedMap[int, string]()
+	for i, val := range []string{"zero", "one", "two", "three"} {
+		m.Set(i, val)
+	}
+	m.Delete(1)
+
+	var vals []string
+	m.Range(func(_ int, val string) bool {
+		vals = append(vals, val)
+		return true
+	})
+	assert.Equal(t, []string{"zero", "two", "three"}, vals)
+
+	var keys []int
+	m.Range(func(key int, _ string) bool {
+		keys = append(keys, key)
+		return len(keys) < 2
+	})
+	assert.Equal(t, []int{0, 2}, keys)
+
+	// 空映射
+	empty := NewOrderedMap[int, string]()
+	assert.Empty(t, empty.Keys())
+	assert.Equal(t, 0, empty.Len())
+}
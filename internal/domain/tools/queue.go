@@ -0,0 +1,67 @@
+package tools
+
+import "errors"
+
+// ErrEmptyQueue 队列为空时出队或查看队首返回的错误
+var ErrEmptyQueue = errors.New("队列为空")
+
+// Queue 基于双向循环链表的泛型队列
+// 在链表尾部入队、头部出队，时间复杂度均为O(1)
+// 非线程安全
+type Queue[T any] struct {
+	list *LinkedList[T]
+}
+
+// NewQueue 创建一个空队列
+// 返回值:
+//   - *Queue[T]: 新建的队列实例
+func NewQueue[T any]() *Queue[T] {
+	return &Queue[T]{
+		list: NewLinkedList[T](),
+	}
+}
+
+// Enqueue 将元素加入队尾
+// 参数:
+//   - t: 要入队的元素
+func (q *Queue[T]) Enqueue(t T) {
+	_ = q.list.Append(t)
+}
+
+// Dequeue 取出队首元素
+// 返回值:
+//   - T: 队首元素
+//   - error: 队列为空时返回 ErrEmptyQueue
+func (q *Queue[T]) Dequeue() (T, error) {
+	if q.IsEmpty() {
+		var zeroValue T
+		return zeroValue, ErrEmptyQueue
+	}
+	return q.list.Delete(0)
+}
+
+// Peek 查看队首元素但不取出
+// 返回值:
+//   - T: 队首元素
+//   - error: 队列为空时返回 ErrEmptyQueue
+func (q *Queue[T]) Peek() (T, error) {
+	if q.IsEmpty() {
+		var zeroValue T
+		return zeroValue, ErrEmptyQueue
+	}
+	return q.list.Get(0)
+}
+
+// Len 获取队列中元素数量
+// 返回值:
+//   - int: 元素数量
+func (q *Queue[T]) Len() int {
+	return q.list.Len()
+}
+
+// IsEmpty 判断队列是否为空
+// 返回值:
+//   - bool: true表示队列为空
+func (q *Queue[T]) IsEmpty() bool {
+	return q.list.Len() == 0
+}
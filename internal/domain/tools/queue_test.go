@@ -0,0 +1,49 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestQueue_FIFO 测试队列的先进先出顺序
+func TestQueue_FIFO(t *testing.T) {
+	queue := NewQueue[int]()
+	for i := 1; i <= 3; i++ {
+		queue.Enqueue(i)
+	}
+	assert.Equal(t, 3, queue.Len())
+
+	head, err := queue.Peek()
+	require.NoError(t, err)
+	assert.Equal(t, 1, head)
+	assert.Equal(t, 3, queue.Len())
+
+	for want := 1; want <= 3; want++ {
+		got, err := queue.Dequeue()
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+	assert.True(t, queue.IsEmpty())
+
+	// 出队后继续入队，顺序不受影响
+	queue.Enqueue(4)
+	queue.Enqueue(5)
+	got, err := queue.Dequeue()
+	require.NoError(t, err)
+	assert.Equal(t, 4, got)
+}
+
+// TestQueue_Empty 测试空队列的出队和查看队首
+func TestQueue_Empty(t *testing.T) {
+	queue := NewQueue[string]()
+	assert.True(t, queue.IsEmpty())
+
+	val, err := queue.Dequeue()
+	assert.ErrorIs(t, err, ErrEmptyQueue)
+	assert.Equal(t, "", val)
+
+	_, err = queue.Peek()
+	assert.ErrorIs(t, err, ErrEmptyQueue)
+}
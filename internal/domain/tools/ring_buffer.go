@@ -0,0 +1,65 @@
+package tools
+
+// RingBuffer 固定容量的泛型环形缓冲区
+// 写满后继续写入会覆盖最旧的元素，适用于记录最近的N个事件
+// 非线程安全
+type RingBuffer[T any] struct {
+	data  []T // 底层存储
+	start int // 最旧元素的位置
+	size  int // 当前元素数量
+}
+
+// NewRingBuffer 创建指定容量的环形缓冲区
+// 参数:
+//   - capacity: 缓冲区容量，必须大于0，否则panic
+//
+// 返回值:
+//   - *RingBuffer[T]: 新建的环形缓冲区实例
+func NewRingBuffer[T any](capacity int) *RingBuffer[T] {
+	if capacity <= 0 {
+		panic("tools: 环形缓冲区容量必须大于0")
+	}
+	return &RingBuffer[T]{
+		data: make([]T, capacity),
+	}
+}
+
+// Push 写入元素
+// 缓冲区已满时覆盖最旧的元素
+// 参数:
+//   - t: 要写入的元素
+func (r *RingBuffer[T]) Push(t T) {
+	if r.size < len(r.data) {
+		r.data[(r.start+r.size)%len(r.data)] = t
+		r.size++
+		return
+	}
+	// 已满，覆盖最旧的元素并后移起点
+	r.data[r.start] = t
+	r.start = (r.start + 1) % len(r.data)
+}
+
+// Values 按写入顺序返回缓冲区中的元素，从最旧到最新
+// 返回值:
+//   - []T: 元素切片的副本
+func (r *RingBuffer[T]) Values() []T {
+	res := make([]T, r.size)
+	for i := 0; i < r.size; i++ {
+		res[i] = r.data[(r.start+i)%len(r.data)]
+	}
+	return res
+}
+
+// Len 获取缓冲区中的元素数量
+// 返回值:
+//   - int: 元素数量，不超过容量
+func (r *RingBuffer[T]) Len() int {
+	return r.size
+}
+
+// Cap 获取缓冲区容量
+// 返回值:
+//   - int: 缓冲区容量
+func (r *RingBuffer[T]) Cap() int {
+	return len(r.data)
+}
@@ -0,0 +1,71 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRingBuffer_Push 测试环形缓冲区的写入和覆盖
+// 验证以下场景:
+// 1. 未写满
+// 2. 恰好写满
+// 3. 超出容量时丢弃最旧的元素
+// 4. 多次绕圈写入
+func TestRingBuffer_Push(t *testing.T) {
+	testCases := []struct {
+		name       string
+		capacity   int
+		pushCount  int
+		wantValues []int
+	}{
+		{
+			name:       "未写满",
+			capacity:   3,
+			pushCount:  2,
+			wantValues: []int{1, 2},
+		},
+		{
+			name:       "恰好写满",
+			capacity:   3,
+			pushCount:  3,
+			wantValues: []int{1, 2, 3},
+		},
+		{
+			name:       "超出容量",
+			capacity:   3,
+			pushCount:  5,
+			wantValues: []int{3, 4, 5},
+		},
+		{
+			name:       "多次绕圈",
+			capacity:   3,
+			pushCount:  10,
+			wantValues: []int{8, 9, 10},
+		},
+		{
+			name:       "空缓冲区",
+			capacity:   3,
+			pushCount:  0,
+			wantValues: []int{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rb := NewRingBuffer[int](tc.capacity)
+			for i := 1; i <= tc.pushCount; i++ {
+				rb.Push(i)
+			}
+			assert.Equal(t, tc.wantValues, rb.Values())
+			assert.Equal(t, len(tc.wantValues), rb.Len())
+			assert.Equal(t, tc.capacity, rb.Cap())
+		})
+	}
+}
+
+// TestRingBuffer_InvalidCapacity 测试无效容量
+func TestRingBuffer_InvalidCapacity(t *testing.T) {
+	assert.Panics(t, func() { NewRingBuffer[int](0) })
+	assert.Panics(t, func() { NewRingBuffer[int](-1) })
+}
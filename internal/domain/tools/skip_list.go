@@ -0,0 +1,185 @@
+package tools
+
+import "math/rand/v2"
+
+const (
+	// skipListMaxLevel 跳表的最大层数
+	skipListMaxLevel = 32
+	// skipListP 结点晋升到上一层的概率
+	skipListP = 0.25
+)
+
+// skipListNode 跳表结点
+// next[i] 指向第i层的后继结点
+type skipListNode[K any, V any] struct {
+	key  K
+	val  V
+	next []*skipListNode[K, V]
+}
+
+// SkipList 泛型跳表
+// 按比较函数维护键的有序性，查找、插入和删除的期望时间复杂度为O(log n)，
+// 支持按键的区间遍历
+// 非线程安全
+type SkipList[K any, V any] struct {
+	head   *skipListNode[K, V] // 头结点(哨兵)
+	level  int                 // 当前最高层数
+	length int                 // 元素数量
+	less   func(a, b K) bool   // 键的比较函数
+	rand   *rand.Rand          // 随机数生成器，为nil时使用全局随机源
+}
+
+// NewSkipList 创建一个空跳表
+// 参数:
+//   - less: 键的比较函数，a应排在b之前时返回true
+//   - rng: 生成随机层数的随机数生成器，为nil时使用全局随机源；注入固定种子可得到确定的结构
+//
+// 返回值:
+//   - *SkipList[K, V]: 新建的跳表实例
+func NewSkipList[K any, V any](less func(a, b K) bool, rng *rand.Rand) *SkipList[K, V] {
+	return &SkipList[K, V]{
+		head:  &skipListNode[K, V]{next: make([]*skipListNode[K, V], skipListMaxLevel)},
+		level: 1,
+		less:  less,
+		rand:  rng,
+	}
+}
+
+// randomLevel 随机生成新结点的层数
+// 返回值:
+//   - int: 层数，范围为[1, skipListMaxLevel]
+func (s *SkipList[K, V]) randomLevel() int {
+	level := 1
+	for level < skipListMaxLevel && s.float64() < skipListP {
+		level++
+	}
+	return level
+}
+
+// float64 返回[0, 1)区间的随机数
+func (s *SkipList[K, V]) float64() float64 {
+	if s.rand != nil {
+		return s.rand.Float64()
+	}
+	return rand.Float64()
+}
+
+// equal 判断两个键是否相等
+func (s *SkipList[K, V]) equal(a, b K) bool {
+	return !s.less(a, b) && !s.less(b, a)
+}
+
+// findPrev 查找每一层中最后一个小于key的结点
+// 参数:
+//   - key: 要查找的键
+//
+// 返回值:
+//   - []*skipListNode[K, V]: 每一层的前驱结点
+func (s *SkipList[K, V]) findPrev(key K) []*skipListNode[K, V] {
+	prev := make([]*skipListNode[K, V], skipListMaxLevel)
+	cur := s.head
+	for i := s.level - 1; i >= 0; i-- {
+		for cur.next[i] != nil && s.less(cur.next[i].key, key) {
+			cur = cur.next[i]
+		}
+		prev[i] = cur
+	}
+	return prev
+}
+
+// Insert 插入键值对，键已存在时更新值
+// 参数:
+//   - key: 键
+//   - val: 值
+func (s *SkipList[K, V]) Insert(key K, val V) {
+	prev := s.findPrev(key)
+	if next := prev[0].next[0]; next != nil && s.equal(next.key, key) {
+		next.val = val
+		return
+	}
+
+	level := s.randomLevel()
+	if level > s.level {
+		for i := s.level; i < level; i++ {
+			prev[i] = s.head
+		}
+		s.level = level
+	}
+
+	node := &skipListNode[K, V]{key: key, val: val, next: make([]*skipListNode[K, V], level)}
+	for i := 0; i < level; i++ {
+		node.next[i] = prev[i].next[i]
+		prev[i].next[i] = node
+	}
+	s.length++
+}
+
+// Get 查找键对应的值
+// 参数:
+//   - key: 要查找的键
+//
+// 返回值:
+//   - V: 找到的值
+//   - bool: true表示键存在
+func (s *SkipList[K, V]) Get(key K) (V, bool) {
+	cur := s.head
+	for i := s.level - 1; i >= 0; i-- {
+		for cur.next[i] != nil && s.less(cur.next[i].key, key) {
+			cur = cur.next[i]
+		}
+	}
+	if next := cur.next[0]; next != nil && s.equal(next.key, key) {
+		return next.val, true
+	}
+	var zeroValue V
+	return zeroValue, false
+}
+
+// Delete 删除键
+// 参数:
+//   - key: 要删除的键
+//
+// 返回值:
+//   - bool: true表示删除了元素，false表示键不存在
+func (s *SkipList[K, V]) Delete(key K) bool {
+	prev := s.findPrev(key)
+	node := prev[0].next[0]
+	if node == nil || !s.equal(node.key, key) {
+		return false
+	}
+
+	for i := 0; i < len(node.next); i++ {
+		prev[i].next[i] = node.next[i]
+	}
+	for s.level > 1 && s.head.next[s.level-1] == nil {
+		s.level--
+	}
+	s.length--
+	return true
+}
+
+// Range 按键的顺序遍历区间 [from, to) 内的键值对
+// 参数:
+//   - from: 区间起点(包含)
+//   - to: 区间终点(不包含)
+//   - fn: 遍历函数，返回false时停止遍历
+func (s *SkipList[K, V]) Range(from, to K, fn func(K, V) bool) {
+	cur := s.head
+	for i := s.level - 1; i >= 0; i-- {
+		for cur.next[i] != nil && s.less(cur.next[i].key, from) {
+			cur = cur.next[i]
+		}
+	}
+	for cur = cur.next[0]; cur != nil && s.less(cur.key, to); cur = cur.next[0] {
+		if !fn(cur.key, cur.val) {
+			return
+		}
+	}
+}
+
+// Len 获取跳表中的元素数量
+// 返回值:
+//   - int: 元素数量
+func (s *SkipList[K, V]) Len() int {
+	return s.length
+}
@@ -0,0 +1,129 @@
+package tools
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newIntSkipList() *SkipList[int, string] {
+	return NewSkipList[int, string](func(a, b int) bool { return a < b }, rand.New(rand.NewPCG(1, 2)))
+}
+
+// collectSkipList 按顺序收集区间内的键
+func collectSkipList(s *SkipList[int, string], from, to int) []int {
+	keys := make([]int, 0)
+	s.Range(from, to, func(k int, _ string) bool {
+		keys = append(keys, k)
+		return true
+	})
+	return keys
+}
+
+// TestSkipList_Insert 测试跳表插入后保持有序
+func TestSkipList_Insert(t *testing.T) {
+	s := newIntSkipList()
+	for _, k := range []int{5, 1, 9, 3, 7, 2, 8} {
+		s.Insert(k, "v")
+	}
+	assert.Equal(t, 7, s.Len())
+	assert.Equal(t, []int{1, 2, 3, 5, 7, 8, 9}, collectSkipList(s, 0, 100))
+
+	// 重复插入更新值而不增加元素
+	s.Insert(5, "new")
+	assert.Equal(t, 7, s.Len())
+	val, ok := s.Get(5)
+	assert.True(t, ok)
+	assert.Equal(t, "new", val)
+
+	_, ok = s.Get(4)
+	assert.False(t, ok)
+}
+
+// TestSkipList_Range 测试跳表区间遍历
+// 验证以下场景:
+// 1. 区间内的部分元素
+// 2. 区间起点和终点不存在于跳表中
+// 3. 空区间
+// 4. 提前结束遍历
+func TestSkipList_Range(t *testing.T) {
+	s := newIntSkipList()
+	for k := 0; k < 100; k += 10 {
+		s.Insert(k, "v")
+	}
+
+	testCases := []struct {
+		name     string
+		from, to int
+		wantKeys []int
+	}{
+		{name: "区间内的部分元素", from: 20, to: 50, wantKeys: []int{20, 30, 40}},
+		{name: "端点不存在", from: 15, to: 55, wantKeys: []int{20, 30, 40, 50}},
+		{name: "空区间", from: 31, to: 39, wantKeys: []int{}},
+		{name: "超出范围", from: 100, to: 200, wantKeys: []int{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.wantKeys, collectSkipList(s, tc.from, tc.to))
+		})
+	}
+
+	t.Run("提前结束遍历", func(t *testing.T) {
+		keys := make([]int, 0)
+		s.Range(0, 100, func(k int, _ string) bool {
+			keys = append(keys, k)
+			return len(keys) < 3
+		})
+		assert.Equal(t, []int{0, 10, 20}, keys)
+	})
+}
+
+// TestSkipList_Delete 测试跳表删除
+func TestSkipList_Delete(t *testing.T) {
+	s := newIntSkipList()
+	for k := 1; k <= 100; k++ {
+		s.Insert(k, "v")
+	}
+
+	assert.True(t, s.Delete(1))
+	assert.True(t, s.Delete(50))
+	assert.True(t, s.Delete(100))
+	assert.False(t, s.Delete(50))
+	assert.False(t, s.Delete(101))
+	assert.Equal(t, 97, s.Len())
+
+	_, ok := s.Get(50)
+	assert.False(t, ok)
+	assert.Equal(t, []int{48, 49, 51, 52}, collectSkipList(s, 48, 53))
+
+	// 全部删除后跳表为空
+	for k := 1; k <= 100; k++ {
+		s.Delete(k)
+	}
+	assert.Equal(t, 0, s.Len())
+	assert.Equal(t, 1, s.level)
+	assert.Equal(t, []int{}, collectSkipList(s, 0, 200))
+}
+
+func BenchmarkSkipList_Get(b *testing.B) {
+	s := NewSkipList[int, int](func(a, b int) bool { return a < b }, nil)
+	for i := 0; i < 10000; i++ {
+		s.Insert(i, i)
+	}
+	for i := 0; b.Loop(); i++ {
+		_, _ = s.Get(i % 10000)
+	}
+}
+
+func BenchmarkLinkedList_OrderedLookup(b *testing.B) {
+	l := NewLinkedList[int]()
+	for i := 0; i < 10000; i++ {
+		_ = l.Append(i)
+	}
+	for i := 0; b.Loop(); i++ {
+		key := i % 10000
+		_ = l.IndexOfFunc(func(v int) bool { return v >= key })
+	}
+}
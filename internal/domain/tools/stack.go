@@ -0,0 +1,67 @@
+package tools
+
+import "errors"
+
+// ErrEmptyStack 栈为空时出栈或查看栈顶返回的错误
+var ErrEmptyStack = errors.New("栈为空")
+
+// Stack 基于双向循环链表的泛型栈
+// 在链表尾部入栈和出栈，时间复杂度均为O(1)
+// 非线程安全
+type Stack[T any] struct {
+	list *LinkedList[T]
+}
+
+// NewStack 创建一个空栈
+// 返回值:
+//   - *Stack[T]: 新建的栈实例
+func NewStack[T any]() *Stack[T] {
+	return &Stack[T]{
+		list: NewLinkedList[T](),
+	}
+}
+
+// Push 将元素压入栈顶
+// 参数:
+//   - t: 要入栈的元素
+func (s *Stack[T]) Push(t T) {
+	_ = s.list.Append(t)
+}
+
+// Pop 弹出栈顶元素
+// 返回值:
+//   - T: 栈顶元素
+//   - error: 栈为空时返回 ErrEmptyStack
+func (s *Stack[T]) Pop() (T, error) {
+	if s.IsEmpty() {
+		var zeroValue T
+		return zeroValue, ErrEmptyStack
+	}
+	return s.list.Delete(s.list.Len() - 1)
+}
+
+// Peek 查看栈顶元素但不弹出
+// 返回值:
+//   - T: 栈顶元素
+//   - error: 栈为空时返回 ErrEmptyStack
+func (s *Stack[T]) Peek() (T, error) {
+	if s.IsEmpty() {
+		var zeroValue T
+		return zeroValue, ErrEmptyStack
+	}
+	return s.list.Get(s.list.Len() - 1)
+}
+
+// Len 获取栈中元素数量
+// 返回值:
+//   - int: 元素数量
+func (s *Stack[T]) Len() int {
+	return s.list.Len()
+}
+
+// IsEmpty 判断栈是否为空
+// 返回值:
+//   - bool: true表示栈为空
+func (s *Stack[T]) IsEmpty() bool {
+	return s.list.Len() == 0
+}
@@ -0,0 +1,69 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestStack_LIFO 测试栈的后进先出顺序
+func TestStack_LIFO(t *testing.T) {
+	stack := NewStack[int]()
+	for i := 1; i <= 3; i++ {
+		stack.Push(i)
+	}
+	assert.Equal(t, 3, stack.Len())
+
+	top, err := stack.Peek()
+	require.NoError(t, err)
+	assert.Equal(t, 3, top)
+	assert.Equal(t, 3, stack.Len())
+
+	for want := 3; want >= 1; want-- {
+		got, err := stack.Pop()
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+	assert.True(t, stack.IsEmpty())
+}
+
+// TestStack_Empty 测试空栈的出栈和查看栈顶
+func TestStack_Empty(t *testing.T) {
+	stack := NewStack[string]()
+	assert.True(t, stack.IsEmpty())
+
+	val, err := stack.Pop()
+	assert.ErrorIs(t, err, ErrEmptyStack)
+	assert.Equal(t, "", val)
+
+	_, err = stack.Peek()
+	assert.ErrorIs(t, err, ErrEmptyStack)
+}
+
+// TestStack_Interleaved 测试交替入栈和出栈
+func TestStack_Interleaved(t *testing.T) {
+	stack := NewStack[int]()
+	popped := make([]int, 0)
+	pop := func() {
+		val, err := stack.Pop()
+		require.NoError(t, err)
+		popped = append(popped, val)
+	}
+
+	stack.Push(1)
+	stack.Push(2)
+	pop()
+	stack.Push(3)
+	stack.Push(4)
+	pop()
+	pop()
+	stack.Push(5)
+	pop()
+	pop()
+
+	assert.Equal(t, []int{2, 4, 3, 5, 1}, popped)
+	assert.True(t, stack.IsEmpty())
+	_, err := stack.Pop()
+	assert.ErrorIs(t, err, ErrEmptyStack)
+}
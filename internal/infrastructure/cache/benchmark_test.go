@@ -158,6 +158,26 @@ func BenchmarkMaxMemoryCache_Get(b *testing.B) {
 	}
 }
 
+// BenchmarkMaxMemoryCache_EvictBatch 测试不同淘汰批量下持续超出内存时的Set性能
+func BenchmarkMaxMemoryCache_EvictBatch(b *testing.B) {
+	ctx := context.Background()
+	value := make([]byte, 64)
+
+	for _, batch := range []int{1, 8, 64} {
+		b.Run(fmt.Sprintf("Batch%d", batch), func(b *testing.B) {
+			cache := NewMaxMemoryCache(64*1024, // 最多容纳1024个值
+				NewBuildInMapCache(time.Hour), // 使用长间隔避免清理干扰
+				NewLRUPolicy())
+			cache.SetEvictBatch(batch)
+
+			b.ResetTimer()
+			for i := 0; b.Loop(); i++ {
+				_ = cache.Set(ctx, fmt.Sprintf("key%d", i), value, 0)
+			}
+		})
+	}
+}
+
 // BenchmarkPolicyComparison 比较不同策略在混合操作下的性能
 func BenchmarkPolicyComparison(b *testing.B) {
 	ctx := context.Background()
@@ -239,3 +259,40 @@ func BenchmarkConcurrentAccess(b *testing.B) {
 		})
 	}
 }
+
+// BenchmarkBuildInMapCache_Shards 比较不同分片数量下的并发读写性能
+func BenchmarkBuildInMapCache_Shards(b *testing.B) {
+	ctx := context.Background()
+
+	keys := make([]string, 1024)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key%d", i)
+	}
+
+	for _, shards := range []int{1, 4, 16, 64} {
+		b.Run(fmt.Sprintf("shards=%d", shards), func(b *testing.B) {
+			c := NewBuildInMapCache(0, BuildInMapCacheWithShards(shards))
+			defer func() {
+				_ = c.Close()
+			}()
+			for _, key := range keys {
+				_ = c.Set(ctx, key, key, time.Minute)
+			}
+
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					key := keys[i%len(keys)]
+					// 读多写少：每4次操作中1次写入
+					if i%4 == 0 {
+						_ = c.Set(ctx, key, i, time.Minute)
+					} else {
+						_, _ = c.Get(ctx, key)
+					}
+					i++
+				}
+			})
+		})
+	}
+}
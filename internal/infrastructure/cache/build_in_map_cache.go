@@ -1,10 +1,14 @@
 package cache
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -13,44 +17,100 @@ const errKeyNotFoundFormat = "%w, key: %s"
 var (
 	ErrCacheKeyNotFound = errors.New("cache：键不存在")
 	ErrDuplicateClose   = errors.New("重复关闭")
+	ErrValueNotInteger  = errors.New("cache：缓存值不是整数")
 )
 
 // BuildInMapCacheOption 定义缓存配置选项函数类型
 type BuildInMapCacheOption func(cache *BuildInMapCache)
 
+// Clock 时钟接口，用于计算过期时间和判断缓存项是否过期
+// 测试中可以替换为可手动推进的时钟，避免依赖真实的等待
+type Clock interface {
+	// Now 返回当前时间
+	Now() time.Time
+}
+
+// realClock 基于系统时间的默认时钟
+type realClock struct{}
+
+// Now 返回系统当前时间
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
 // BuildInMapCache 基于内置map实现的缓存结构体
 // 该结构体包含了缓存操作所需的核心数据结构和控制元素。
 type BuildInMapCache struct {
-	// data 存储缓存项的映射，键为字符串类型，值为指向item结构体的指针
-	// 读写操作需通过互斥锁保护以确保并发安全
-	data  map[string]*item
-	mutex sync.RWMutex
+	// shards 分片存储，键通过FNV-1a哈希路由到分片，每个分片由独立的读写锁保护
+	// 默认只有一个分片，此时行为与单个map加锁相同
+	shards []*cacheShard
+	// shardCount 分片数量，由 BuildInMapCacheWithShards 配置
+	shardCount int
 	// close 用于关闭缓存的通道，发送信号后会停止后台清理goroutine
 	// 重复关闭会返回errDuplicateClose错误
 	close chan struct{}
 	// onEvicted 缓存项被驱逐时的回调函数
 	// 当缓存项因过期、删除或内存淘汰被移除时触发
 	onEvicted func(key string, val any)
+	// onEvictedBatch 一批缓存项被淘汰后的回调函数，nil表示未设置
+	// 后台清理、DeleteExpired和超出数量上限的淘汰各触发一次，在释放分片锁之后调用
+	onEvictedBatch func(items map[string]any)
+	// clock 时钟，默认使用系统时间
+	clock Clock
+	// maxEntries 缓存项数量上限，0表示不限制
+	maxEntries int
+	// policy 超过数量上限时使用的淘汰策略，未设置上限时为nil
+	// 对某个键的策略操作都在持有该键分片锁时进行，保证策略与存储一致
+	policy EvictionPolicy
+}
+
+// cacheShard 缓存分片，包含一部分键的缓存项
+type cacheShard struct {
+	// data 存储缓存项的映射，键为字符串类型，值为指向item结构体的指针
+	// 读写操作需通过互斥锁保护以确保并发安全
+	data  map[string]*item
+	mutex sync.RWMutex
 }
 
 // item 缓存项结构体，包含值和过期时间
 type item struct {
 	val      any
 	deadline time.Time
+	// accessCount 读取命中的次数，Get只持有读锁，因此使用原子操作
+	accessCount atomic.Int64
+	// accessedAt 最后一次读取命中的时间（UnixNano），未被读取时为写入时间
+	accessedAt atomic.Int64
+	// version 缓存项的版本号，每次写入递增，用于乐观并发控制
+	version uint64
+}
+
+// EntryMetadata 缓存项访问信息的只读快照
+// 可用于找出热点键、调整淘汰策略，修改快照不会影响缓存
+type EntryMetadata struct {
+	// Key 缓存键
+	Key string
+	// Deadline 过期时间点，零值表示永不过期
+	Deadline time.Time
+	// AccessCount 读取命中的次数，覆盖写入时保留
+	AccessCount int64
+	// LastAccessedAt 最后一次读取命中的时间，未被读取时为首次写入的时间
+	LastAccessedAt time.Time
 }
 
 // NewBuildInMapCache 创建新的内置map缓存实例，interval 为过期检查间隔时间，opts 为可选配置项。
-// 该函数会初始化一个新的 BuildInMapCache 实例，设置初始容量为 100 的 data 映射，创建关闭通道，
-// 并设置默认的驱逐回调函数，然后应用所有可选配置项，最后启动一个 goroutine 用于定期清理过期的缓存项。
+// 该函数会初始化一个新的 BuildInMapCache 实例，创建关闭通道，并设置默认的驱逐回调函数，
+// 然后应用所有可选配置项并按分片数量创建分片（总初始容量为 100），
+// 最后为每个分片启动一个 goroutine 用于定期清理过期的缓存项。
 func NewBuildInMapCache(interval time.Duration, opts ...BuildInMapCacheOption) *BuildInMapCache {
 	res := &BuildInMapCache{
-		data:  make(map[string]*item, 100),
-		close: make(chan struct{}), // 用于通知关闭的通道
+		shardCount: 1,
+		close:      make(chan struct{}), // 用于通知关闭的通道
 		onEvicted: func(key string, val any) {
 			// 默认的onEvicted回调为空函数
 			// 避免外部未设置回调时调用nil函数导致panic
 			// 如需监听驱逐事件，请使用 BuildInMapCacheWithEvictedCallback 配置选项设置具体逻辑。
 		},
+		clock: realClock{},
 	}
 
 	// 遍历并应用所有可选配置项到新创建的缓存实例上
@@ -58,49 +118,200 @@ func NewBuildInMapCache(interval time.Duration, opts ...BuildInMapCacheOption) *
 		opt(res)
 	}
 
-	// 启动 goroutine 定期清理过期缓存项（仅当interval > 0时）
+	res.shards = make([]*cacheShard, res.shardCount)
+	for i := range res.shards {
+		res.shards[i] = &cacheShard{data: make(map[string]*item, 100/res.shardCount+1)}
+	}
+
+	// 每个分片启动 goroutine 定期清理过期缓存项（仅当interval > 0时）
 	if interval > 0 {
-		go func() {
-			// 创建按指定间隔时间触发的定时器
-			ticker := time.NewTicker(interval)
-			defer ticker.Stop()
-			for {
-				select {
-				case t := <-ticker.C:
-					// 加写锁保证清理过程中缓存数据不被其他 goroutine 修改
-					res.mutex.Lock()
-					// 计数器限制每次清理检查的缓存项数量，避免长时间占用锁
-					i := 0
-					// 遍历缓存项，检查并删除过期项
-					for key, val := range res.data {
-						if i > 10000 {
-							break
-						}
-						if val.deadlineBefore(t) {
-							res.delete(key)
-						}
-						i++
-					}
-					// 解锁允许其他 goroutine 访问缓存数据
-					res.mutex.Unlock()
-				case <-res.close:
-					return
-				}
-			}
-		}()
+		for _, sh := range res.shards {
+			go res.cleanupLoop(sh, interval)
+		}
 	}
 
 	return res
 }
 
+// cleanupLoop 按指定间隔清理分片中的过期缓存项，直到缓存被关闭
+func (b *BuildInMapCache) cleanupLoop(sh *cacheShard, interval time.Duration) {
+	// 创建按指定间隔时间触发的定时器
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			// 加写锁保证清理过程中分片数据不被其他 goroutine 修改
+			sh.mutex.Lock()
+			fn, victims := b.newEvictedBatch()
+			// 限制每次清理检查的缓存项数量，避免长时间占用锁
+			b.deleteExpired(sh, 10000, victims)
+			// 解锁允许其他 goroutine 访问分片数据
+			sh.mutex.Unlock()
+			if len(victims) > 0 {
+				fn(victims)
+			}
+		case <-b.close:
+			return
+		}
+	}
+}
+
+// shard 返回键所在的分片
+func (b *BuildInMapCache) shard(key string) *cacheShard {
+	if len(b.shards) == 1 {
+		return b.shards[0]
+	}
+	// FNV-1a哈希，避免为每次访问分配hash.Hash32
+	h := uint32(2166136261)
+	for i := 0; i < len(key); i++ {
+		h ^= uint32(key[i])
+		h *= 16777619
+	}
+	return b.shards[h%uint32(len(b.shards))]
+}
+
+// lockAll 按顺序对所有分片加写锁
+func (b *BuildInMapCache) lockAll() {
+	for _, sh := range b.shards {
+		sh.mutex.Lock()
+	}
+}
+
+// unlockAll 释放所有分片的写锁
+func (b *BuildInMapCache) unlockAll() {
+	for _, sh := range b.shards {
+		sh.mutex.Unlock()
+	}
+}
+
+// keyAccessed 通知淘汰策略键被访问或写入
+// 注意: 此方法应在持有键所在分片锁的情况下调用
+func (b *BuildInMapCache) keyAccessed(key string) {
+	if b.policy != nil {
+		_ = b.policy.KeyAccessed(context.Background(), key)
+	}
+}
+
+// evictOverflow 缓存项数量超过上限时按淘汰策略删除缓存项
+// 被淘汰的键可能位于其他分片，因此必须在不持有任何分片锁时调用，
+// 写入方法通过在加锁之前defer调用，保证其在释放分片锁之后执行
+func (b *BuildInMapCache) evictOverflow() {
+	if b.policy == nil {
+		return
+	}
+	ctx := context.Background()
+	var fn func(items map[string]any)
+	var victims map[string]any
+	defer func() {
+		if len(victims) > 0 {
+			fn(victims)
+		}
+	}()
+	for {
+		size, err := b.policy.Size(ctx)
+		if err != nil || size <= b.maxEntries {
+			return
+		}
+		key, err := b.policy.Evict(ctx)
+		if err != nil || key == "" {
+			return
+		}
+		sh := b.shard(key)
+		sh.mutex.Lock()
+		if victims == nil {
+			fn, victims = b.newEvictedBatch()
+		}
+		if itm, ok := sh.data[key]; ok && victims != nil {
+			victims[key] = itm.val
+		}
+		b.delete(sh, key)
+		sh.mutex.Unlock()
+	}
+}
+
+// newEvictedBatch 准备收集一批被淘汰的缓存项
+// 注意: 此方法应在持有任一分片锁的情况下调用，OnEvictedBatch 修改回调时会锁住所有分片
+// 返回: 批量回调和用于收集缓存项的map，未设置批量回调时都为nil
+func (b *BuildInMapCache) newEvictedBatch() (func(items map[string]any), map[string]any) {
+	if b.onEvictedBatch == nil {
+		return nil, nil
+	}
+	return b.onEvictedBatch, make(map[string]any)
+}
+
+// lookup 获取缓存项，不检查是否过期
+func (b *BuildInMapCache) lookup(key string) (*item, bool) {
+	sh := b.shard(key)
+	sh.mutex.RLock()
+	defer sh.mutex.RUnlock()
+	itm, ok := sh.data[key]
+	return itm, ok
+}
+
 // BuildInMapCacheWithEvictedCallback 设置缓存项被删除时的回调函数
-// fn: 回调函数，当缓存项因过期被删除时调用
+// 后台清理、Get时的惰性过期删除、Delete和LoadAndDelete都会触发回调，
+// 可用于释放缓存值持有的资源（如文件句柄）
+// 回调在持有内部锁时同步调用，回调中不应再调用缓存的方法
+// fn: 回调函数，当缓存项因过期或删除被移除时调用
 func BuildInMapCacheWithEvictedCallback(fn func(key string, val any)) BuildInMapCacheOption {
 	return func(cache *BuildInMapCache) {
 		cache.onEvicted = fn
 	}
 }
 
+// BuildInMapCacheWithEvictedBatchCallback 设置一批缓存项被淘汰后的回调函数
+// 后台清理的每一轮、每次DeleteExpired调用以及每次超出数量上限的淘汰，
+// 将这一批被删除的所有缓存项一次性交给回调，适合需要批量持久化淘汰数据的场景
+// Delete、LoadAndDelete和Get时的惰性过期删除只触发逐个的onEvicted回调
+// 回调在释放分片锁之后调用，没有缓存项被删除时不调用
+// fn: 回调函数，参数为被删除的键值对
+func BuildInMapCacheWithEvictedBatchCallback(fn func(items map[string]any)) BuildInMapCacheOption {
+	return func(cache *BuildInMapCache) {
+		cache.onEvictedBatch = fn
+	}
+}
+
+// BuildInMapCacheWithShards 设置缓存的分片数量
+// 键按FNV-1a哈希分布到各个分片，每个分片使用独立的锁和后台清理goroutine，
+// 减少高并发下的锁竞争
+// n: 分片数量，不大于1时使用单个分片，与未分片时的行为相同
+func BuildInMapCacheWithShards(n int) BuildInMapCacheOption {
+	return func(cache *BuildInMapCache) {
+		if n > 1 {
+			cache.shardCount = n
+		}
+	}
+}
+
+// BuildInMapCacheWithMaxEntries 设置缓存项数量上限
+// 写入后缓存项数量超过上限时，按淘汰策略删除缓存项直到不超过上限，被淘汰的缓存项会触发onEvicted回调
+// 已过期但尚未清理的缓存项也计入数量
+// n: 缓存项数量上限，不大于0时忽略
+// policy: 淘汰策略，默认使用LRU；策略不应再设置自己的容量，否则策略会私自丢弃键
+func BuildInMapCacheWithMaxEntries(n int, policy ...EvictionPolicy) BuildInMapCacheOption {
+	return func(cache *BuildInMapCache) {
+		if n <= 0 {
+			return
+		}
+		cache.maxEntries = n
+		cache.policy = NewLRUPolicy()
+		if len(policy) > 0 && policy[0] != nil {
+			cache.policy = policy[0]
+		}
+	}
+}
+
+// BuildInMapCacheWithClock 设置缓存使用的时钟
+// clock: 时钟实现，为nil时忽略，继续使用系统时间
+func BuildInMapCacheWithClock(clock Clock) BuildInMapCacheOption {
+	return func(cache *BuildInMapCache) {
+		if clock != nil {
+			cache.clock = clock
+		}
+	}
+}
+
 // deadlineBefore 检查缓存项是否在指定时间前过期
 // t: 要比较的时间点
 // 返回: true表示已过期，false表示未过期
@@ -111,43 +322,270 @@ func (i *item) deadlineBefore(t time.Time) bool {
 // Set 设置缓存值
 // ctx: 上下文，可用于取消操作
 // key: 缓存键，必须是唯一标识
-// val: 要缓存的值，可以是任意类型
+// val: 要缓存的值，可以是任意类型，nil也会被作为有效的缓存项保存
 // expiration: 过期时间，0表示永不过期
 // 返回: 错误信息，nil表示成功
 func (b *BuildInMapCache) Set(_ context.Context, key string, val any, expiration time.Duration) error {
-	b.mutex.Lock()
-	defer b.mutex.Unlock()
-	return b.set(key, val, expiration)
+	sh := b.shard(key)
+	defer b.evictOverflow()
+	sh.mutex.Lock()
+	defer sh.mutex.Unlock()
+	return b.set(sh, key, val, expiration)
 }
 
 // set 内部实现方法，设置缓存值
-// 注意: 此方法应在持有锁的情况下调用
+// 注意: 此方法应在持有分片锁的情况下调用
+// sh: 键所在的分片
 // key: 缓存键
 // val: 缓存值
 // expiration: 过期时间
 // 返回: 错误信息，nil表示成功
-func (b *BuildInMapCache) set(key string, val any, expiration time.Duration) error {
+func (b *BuildInMapCache) set(sh *cacheShard, key string, val any, expiration time.Duration) error {
 	var dl time.Time
 	if expiration > 0 {
-		dl = time.Now().Add(expiration)
+		dl = b.clock.Now().Add(expiration)
 	}
-	b.data[key] = &item{
+	b.store(sh, key, &item{
 		val:      val,
 		deadline: dl,
+	})
+	return nil
+}
+
+// store 保存缓存项并通知淘汰策略
+// 覆盖已有的缓存项时保留其访问信息，否则以当前时间作为最后访问时间
+// 缓存项未指定版本号时在原版本号上加1，键不存在时为1
+// 注意: 此方法应在持有分片锁的情况下调用
+func (b *BuildInMapCache) store(sh *cacheShard, key string, itm *item) {
+	old, ok := sh.data[key]
+	if ok {
+		itm.accessCount.Store(old.accessCount.Load())
+		itm.accessedAt.Store(old.accessedAt.Load())
+	} else {
+		itm.accessedAt.Store(b.clock.Now().UnixNano())
+	}
+	if itm.version == 0 {
+		itm.version = 1
+		if ok {
+			itm.version = old.version + 1
+		}
 	}
+	sh.data[key] = itm
+	b.keyAccessed(key)
+}
+
+// touch 记录一次读取命中
+func (i *item) touch(now time.Time) {
+	i.accessCount.Add(1)
+	i.accessedAt.Store(now.UnixNano())
+}
+
+// SetWithDeadline 设置缓存值，并指定绝对的过期时间点
+// 直接保存过期时间点，避免调用方换算成相对时长带来的误差
+// ctx: 上下文，可用于取消操作
+// key: 缓存键
+// val: 要缓存的值
+// deadline: 过期时间点，零值表示永不过期，早于当前时间时视为已过期，只删除已有的缓存项
+// 返回: 错误信息，nil表示成功
+func (b *BuildInMapCache) SetWithDeadline(_ context.Context, key string, val any, deadline time.Time) error {
+	sh := b.shard(key)
+	defer b.evictOverflow()
+	sh.mutex.Lock()
+	defer sh.mutex.Unlock()
+
+	itm := &item{val: val, deadline: deadline}
+	if itm.deadlineBefore(b.clock.Now()) {
+		b.delete(sh, key)
+		return nil
+	}
+	b.store(sh, key, itm)
+	return nil
+}
+
+// TTL 获取缓存项的剩余存活时间
+// ctx: 上下文，可用于取消操作
+// key: 缓存键
+// 返回: 剩余存活时间和错误信息，0表示永不过期，键不存在或已过期时返回 ErrCacheKeyNotFound
+func (b *BuildInMapCache) TTL(_ context.Context, key string) (time.Duration, error) {
+	itm, ok := b.lookup(key)
+	now := b.clock.Now()
+	if !ok || itm.deadlineBefore(now) {
+		return 0, fmt.Errorf(errKeyNotFoundFormat, ErrCacheKeyNotFound, key)
+	}
+	if itm.deadline.IsZero() {
+		return 0, nil
+	}
+	return itm.deadline.Sub(now), nil
+}
+
+// Expire 重新设置缓存项的过期时间，不改变缓存值
+// ctx: 上下文，可用于取消操作
+// key: 缓存键
+// expiration: 从当前时间起的过期时间，0表示永不过期
+// 返回: 错误信息，键不存在或已过期时返回 ErrCacheKeyNotFound
+func (b *BuildInMapCache) Expire(_ context.Context, key string, expiration time.Duration) error {
+	sh := b.shard(key)
+	sh.mutex.Lock()
+	defer sh.mutex.Unlock()
+
+	now := b.clock.Now()
+	old, ok := sh.data[key]
+	if !ok || old.deadlineBefore(now) {
+		return fmt.Errorf(errKeyNotFoundFormat, ErrCacheKeyNotFound, key)
+	}
+	// 替换为新的缓存项，避免修改可能正在被无锁读取的过期时间，只修改过期时间不改变版本号
+	itm := &item{val: old.val, version: old.version}
+	if expiration > 0 {
+		itm.deadline = now.Add(expiration)
+	}
+	b.store(sh, key, itm)
 	return nil
 }
 
+// SetNX 仅当键不存在或已过期时设置缓存值
+// 检查和设置在同一把锁内完成，可用于幂等初始化和简单的协调
+// ctx: 上下文，可用于取消操作
+// key: 缓存键
+// val: 要缓存的值
+// expiration: 过期时间，0表示永不过期
+// 返回: 是否设置成功和错误信息，键已存在时返回false并保留原值
+func (b *BuildInMapCache) SetNX(_ context.Context, key string, val any, expiration time.Duration) (bool, error) {
+	sh := b.shard(key)
+	defer b.evictOverflow()
+	sh.mutex.Lock()
+	defer sh.mutex.Unlock()
+
+	if itm, ok := sh.data[key]; ok {
+		if !itm.deadlineBefore(b.clock.Now()) {
+			return false, nil
+		}
+		// 已过期的缓存项先淘汰，触发回调
+		b.delete(sh, key)
+	}
+
+	if err := b.set(sh, key, val, expiration); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// GetOrSet 获取缓存值，键不存在或已过期时存入给定的值
+// 获取和设置在同一把锁内完成，避免先读后写的竞争
+// ctx: 上下文，可用于取消操作
+// key: 缓存键
+// val: 键不存在时要缓存的值
+// expiration: 过期时间，0表示永不过期
+// 返回: 实际的缓存值、是否为已存在的值和错误信息
+func (b *BuildInMapCache) GetOrSet(_ context.Context, key string, val any, expiration time.Duration) (any, bool, error) {
+	sh := b.shard(key)
+	defer b.evictOverflow()
+	sh.mutex.Lock()
+	defer sh.mutex.Unlock()
+
+	if itm, ok := sh.data[key]; ok {
+		if now := b.clock.Now(); !itm.deadlineBefore(now) {
+			// 命中与Get相同，同时更新访问信息和淘汰策略中的访问顺序
+			b.keyAccessed(key)
+			itm.touch(now)
+			return itm.val, true, nil
+		}
+		// 已过期的缓存项先淘汰，触发回调
+		b.delete(sh, key)
+	}
+
+	if err := b.set(sh, key, val, expiration); err != nil {
+		return nil, false, err
+	}
+	return val, false, nil
+}
+
+// Increment 原子地将整数缓存值增加delta
+// 键不存在或已过期时以delta初始化，且永不过期；键存在时保留原有的过期时间
+// 缓存值统一以int64保存
+// ctx: 上下文，可用于取消操作
+// key: 缓存键
+// delta: 增量，可以为负数
+// 返回: 增加后的值和错误信息，原值不是整数时返回 ErrValueNotInteger
+func (b *BuildInMapCache) Increment(_ context.Context, key string, delta int64) (int64, error) {
+	sh := b.shard(key)
+	defer b.evictOverflow()
+	sh.mutex.Lock()
+	defer sh.mutex.Unlock()
+
+	itm, ok := sh.data[key]
+	if ok && itm.deadlineBefore(b.clock.Now()) {
+		// 已过期的缓存项先淘汰，触发回调
+		b.delete(sh, key)
+		ok = false
+	}
+	if !ok {
+		b.store(sh, key, &item{val: delta})
+		return delta, nil
+	}
+
+	cur, err := toInt64(itm.val)
+	if err != nil {
+		b.keyAccessed(key)
+		return 0, fmt.Errorf("%w, key: %s, type: %T", err, key, itm.val)
+	}
+	// 替换为新的缓存项，避免修改可能正在被无锁读取的缓存值，保留原有的过期时间
+	b.store(sh, key, &item{val: cur + delta, deadline: itm.deadline, version: itm.version + 1})
+	return cur + delta, nil
+}
+
+// Decrement 原子地将整数缓存值减少delta
+// 语义与 Increment 相同，键不存在时以-delta初始化
+// 返回: 减少后的值和错误信息，原值不是整数时返回 ErrValueNotInteger
+func (b *BuildInMapCache) Decrement(ctx context.Context, key string, delta int64) (int64, error) {
+	return b.Increment(ctx, key, -delta)
+}
+
+// toInt64 将整数类型的缓存值转换为int64
+func toInt64(val any) (int64, error) {
+	switch v := val.(type) {
+	case int:
+		return int64(v), nil
+	case int8:
+		return int64(v), nil
+	case int16:
+		return int64(v), nil
+	case int32:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	case uint:
+		return int64(v), nil
+	case uint8:
+		return int64(v), nil
+	case uint16:
+		return int64(v), nil
+	case uint32:
+		return int64(v), nil
+	case uint64:
+		return int64(v), nil
+	default:
+		return 0, ErrValueNotInteger
+	}
+}
+
 // Get 获取缓存值
 // ctx: 上下文，可用于取消操作
 // key: 缓存键
 // 返回: (缓存值, 错误信息)
 // 注意: 如果缓存项已过期会自动删除并返回错误
+// 缓存的nil值是一次命中，返回(nil, nil)；键不存在时返回 ErrCacheKeyNotFound，
+// 调用方可以据此缓存“查询结果为空”，避免重复查询数据源
 func (b *BuildInMapCache) Get(_ context.Context, key string) (any, error) {
 	// 加读锁以允许其他 goroutine 同时读取缓存数据，然后从缓存中获取指定键的值，最后释放读锁。
-	b.mutex.RLock()
-	res, ok := b.data[key]
-	b.mutex.RUnlock()
+	sh := b.shard(key)
+	sh.mutex.RLock()
+	res, ok := sh.data[key]
+	if now := b.clock.Now(); ok && !res.deadlineBefore(now) {
+		// 在分片锁内记录访问，避免与并发删除交错导致策略中残留已删除的键
+		b.keyAccessed(key)
+		res.touch(now)
+	}
+	sh.mutex.RUnlock()
 
 	// 如果缓存中不存在该键，返回错误。
 	if !ok {
@@ -155,17 +593,17 @@ func (b *BuildInMapCache) Get(_ context.Context, key string) (any, error) {
 	}
 
 	// 获取当前时间，检查缓存项是否已过期。
-	now := time.Now()
+	now := b.clock.Now()
 	if res.deadlineBefore(now) {
 		// 加写锁确保删除过期缓存项时数据一致性，函数返回时释放写锁。再次获取键值防止数据被修改，若不存在则返回错误，若仍过期则删除并返回错误。
-		b.mutex.Lock()
-		defer b.mutex.Unlock()
-		res, ok = b.data[key]
+		sh.mutex.Lock()
+		defer sh.mutex.Unlock()
+		res, ok = sh.data[key]
 		if !ok {
 			return nil, fmt.Errorf(errKeyNotFoundFormat, ErrCacheKeyNotFound, key)
 		}
 		if res.deadlineBefore(now) {
-			b.delete(key)
+			b.delete(sh, key)
 			return nil, fmt.Errorf(errKeyNotFoundFormat, ErrCacheKeyNotFound, key)
 		}
 	}
@@ -173,14 +611,222 @@ func (b *BuildInMapCache) Get(_ context.Context, key string) (any, error) {
 	return res.val, nil
 }
 
+// Exists 检查键是否存在且未过期
+// 不返回缓存值，也不会删除已过期的缓存项
+// ctx: 上下文，可用于取消操作
+// key: 缓存键
+// 返回: 是否存在和错误信息
+func (b *BuildInMapCache) Exists(_ context.Context, key string) (bool, error) {
+	itm, ok := b.lookup(key)
+	return ok && !itm.deadlineBefore(b.clock.Now()), nil
+}
+
+// SetWithVersion 设置缓存值，并指定版本号
+// 用于保存来自数据源的版本（如数据库行版本），之后可以通过 CompareAndSwap 基于该版本更新
+// ctx: 上下文，可用于取消操作
+// key: 缓存键
+// val: 要缓存的值
+// version: 版本号，0表示与 Set 相同在原版本号上加1
+// expiration: 过期时间，0表示永不过期
+// 返回: 错误信息，nil表示成功
+func (b *BuildInMapCache) SetWithVersion(_ context.Context, key string, val any, version uint64, expiration time.Duration) error {
+	sh := b.shard(key)
+	defer b.evictOverflow()
+	sh.mutex.Lock()
+	defer sh.mutex.Unlock()
+
+	itm := &item{val: val, version: version}
+	if expiration > 0 {
+		itm.deadline = b.clock.Now().Add(expiration)
+	}
+	b.store(sh, key, itm)
+	return nil
+}
+
+// GetWithVersion 获取缓存值及其版本号
+// 与 Get 相同记录一次读取命中，但不会删除已过期的缓存项
+// ctx: 上下文，可用于取消操作
+// key: 缓存键
+// 返回: 缓存值、版本号、是否存在和错误信息，键不存在或已过期时返回found为false
+func (b *BuildInMapCache) GetWithVersion(_ context.Context, key string) (any, uint64, bool, error) {
+	sh := b.shard(key)
+	sh.mutex.RLock()
+	defer sh.mutex.RUnlock()
+
+	itm, ok := sh.data[key]
+	now := b.clock.Now()
+	if !ok || itm.deadlineBefore(now) {
+		return nil, 0, false, nil
+	}
+	b.keyAccessed(key)
+	itm.touch(now)
+	return itm.val, itm.version, true, nil
+}
+
+// CompareAndSwap 仅当缓存项的当前版本号等于expectedVersion时设置新值
+// 比较和设置在同一把锁内完成，用于检测并发修改：读取值和版本号，修改后以读到的版本号写回，
+// 期间有其他写入时版本号已经变化，写回失败，调用方重新读取后重试
+// ctx: 上下文，可用于取消操作
+// key: 缓存键
+// expectedVersion: 期望的当前版本号，0表示期望键不存在或已过期
+// newVal: 新的缓存值
+// expiration: 过期时间，0表示永不过期
+// 返回: 是否设置成功和错误信息，成功时版本号变为expectedVersion+1，版本号不一致时返回false并保留原值
+func (b *BuildInMapCache) CompareAndSwap(_ context.Context, key string, expectedVersion uint64, newVal any, expiration time.Duration) (bool, error) {
+	sh := b.shard(key)
+	defer b.evictOverflow()
+	sh.mutex.Lock()
+	defer sh.mutex.Unlock()
+
+	now := b.clock.Now()
+	var version uint64
+	if itm, ok := sh.data[key]; ok {
+		if itm.deadlineBefore(now) {
+			// 已过期的缓存项先淘汰，触发回调
+			b.delete(sh, key)
+		} else {
+			version = itm.version
+		}
+	}
+	if version != expectedVersion {
+		return false, nil
+	}
+
+	itm := &item{val: newVal, version: expectedVersion + 1}
+	if expiration > 0 {
+		itm.deadline = now.Add(expiration)
+	}
+	b.store(sh, key, itm)
+	return true, nil
+}
+
+// Len 返回未过期的缓存项数量
+// 已过期但尚未被清理的缓存项不计入
+// 返回: 缓存项数量
+func (b *BuildInMapCache) Len() int {
+	now := b.clock.Now()
+	n := 0
+	for _, sh := range b.shards {
+		sh.mutex.RLock()
+		for _, itm := range sh.data {
+			if !itm.deadlineBefore(now) {
+				n++
+			}
+		}
+		sh.mutex.RUnlock()
+	}
+	return n
+}
+
+// Metadata 获取缓存项访问信息的快照
+// 只读取访问信息，本身不计为一次访问
+// key: 缓存键
+// 返回: 访问信息和是否存在，键不存在或已过期时返回false
+func (b *BuildInMapCache) Metadata(key string) (EntryMetadata, bool) {
+	sh := b.shard(key)
+	sh.mutex.RLock()
+	defer sh.mutex.RUnlock()
+	itm, ok := sh.data[key]
+	if !ok || itm.deadlineBefore(b.clock.Now()) {
+		return EntryMetadata{}, false
+	}
+	return itm.metadata(key), true
+}
+
+// HotKeys 返回读取命中次数最多的n个缓存项
+// 按访问次数从多到少排列，次数相同时按键排序；已过期的缓存项不计入
+// n: 返回的数量，不大于0时返回所有缓存项
+// 返回: 缓存项访问信息的快照
+func (b *BuildInMapCache) HotKeys(n int) []EntryMetadata {
+	now := b.clock.Now()
+	res := make([]EntryMetadata, 0)
+	for _, sh := range b.shards {
+		sh.mutex.RLock()
+		for key, itm := range sh.data {
+			if !itm.deadlineBefore(now) {
+				res = append(res, itm.metadata(key))
+			}
+		}
+		sh.mutex.RUnlock()
+	}
+	slices.SortFunc(res, func(x, y EntryMetadata) int {
+		if c := cmp.Compare(y.AccessCount, x.AccessCount); c != 0 {
+			return c
+		}
+		return strings.Compare(x.Key, y.Key)
+	})
+	if n > 0 && len(res) > n {
+		res = res[:n]
+	}
+	return res
+}
+
+// metadata 生成缓存项访问信息的快照
+func (i *item) metadata(key string) EntryMetadata {
+	return EntryMetadata{
+		Key:            key,
+		Deadline:       i.deadline,
+		AccessCount:    i.accessCount.Load(),
+		LastAccessedAt: time.Unix(0, i.accessedAt.Load()),
+	}
+}
+
+// DeleteExpired 删除所有已过期的缓存项
+// 每个被删除的缓存项都会触发onEvicted回调，所有分片清理完后触发一次批量回调
+// ctx: 上下文，可用于取消操作
+// 返回: 删除的缓存项数量
+func (b *BuildInMapCache) DeleteExpired(_ context.Context) int {
+	deleted := 0
+	var fn func(items map[string]any)
+	var victims map[string]any
+	for i, sh := range b.shards {
+		sh.mutex.Lock()
+		if i == 0 {
+			fn, victims = b.newEvictedBatch()
+		}
+		deleted += b.deleteExpired(sh, 0, victims)
+		sh.mutex.Unlock()
+	}
+	if len(victims) > 0 {
+		fn(victims)
+	}
+	return deleted
+}
+
+// deleteExpired 内部实现方法，删除分片中已过期的缓存项
+// 注意: 此方法应在持有分片锁的情况下调用
+// sh: 要清理的分片
+// limit: 最多检查的缓存项数量，不大于0表示不限制
+// victims: 收集被删除的缓存项，为nil时不收集
+// 返回: 删除的缓存项数量
+func (b *BuildInMapCache) deleteExpired(sh *cacheShard, limit int, victims map[string]any) int {
+	now := b.clock.Now()
+	checked, deleted := 0, 0
+	for key, val := range sh.data {
+		if limit > 0 && checked >= limit {
+			break
+		}
+		if val.deadlineBefore(now) {
+			if victims != nil {
+				victims[key] = val.val
+			}
+			b.delete(sh, key)
+			deleted++
+		}
+		checked++
+	}
+	return deleted
+}
+
 // Delete 删除缓存值
 // ctx: 上下文，可用于取消操作
 // key: 缓存键
 // 返回: 错误信息，nil表示成功
 func (b *BuildInMapCache) Delete(_ context.Context, key string) error {
-	b.mutex.Lock()
-	defer b.mutex.Unlock()
-	b.delete(key)
+	sh := b.shard(key)
+	sh.mutex.Lock()
+	defer sh.mutex.Unlock()
+	b.delete(sh, key)
 	return nil
 }
 
@@ -189,38 +835,40 @@ func (b *BuildInMapCache) Delete(_ context.Context, key string) error {
 // key: 缓存键
 // 返回: (被删除的缓存值, 错误信息)
 func (b *BuildInMapCache) LoadAndDelete(_ context.Context, key string) (any, error) {
-	b.mutex.Lock()
-	defer b.mutex.Unlock()
-	val, ok := b.data[key]
+	sh := b.shard(key)
+	sh.mutex.Lock()
+	defer sh.mutex.Unlock()
+	val, ok := sh.data[key]
 	if !ok {
 		return nil, ErrCacheKeyNotFound
 	}
-	b.delete(key)
+	b.delete(sh, key)
 	return val.val, nil
 }
 
 // delete 内部实现方法，删除缓存项
-// 注意: 此方法应在持有锁的情况下调用
+// 注意: 此方法应在持有分片锁的情况下调用
+// sh: 键所在的分片
 // key: 缓存键
 // 会触发onEvicted回调函数
-func (b *BuildInMapCache) delete(key string) {
-	itm, ok := b.data[key]
+func (b *BuildInMapCache) delete(sh *cacheShard, key string) {
+	itm, ok := sh.data[key]
 	if !ok {
 		return
 	}
-	delete(b.data, key)
+	delete(sh.data, key)
+	if b.policy != nil {
+		_ = b.policy.Remove(context.Background(), key)
+	}
 	b.onEvicted(key, itm.val)
 }
 
-// Close 关闭缓存，停止后台清理goroutine
-// 返回: 错误信息，nil表示成功
-// 注意: 重复关闭会返回错误
-// Close 关闭缓存，停止后台清理goroutine
+// Close 关闭缓存，停止所有分片的后台清理goroutine
 // 返回: 错误信息，nil表示成功
 // 注意: 重复关闭会返回错误
 func (b *BuildInMapCache) Close() error {
-	b.mutex.Lock()
-	defer b.mutex.Unlock()
+	b.lockAll()
+	defer b.unlockAll()
 
 	select {
 	case <-b.close:
@@ -237,7 +885,18 @@ func (b *BuildInMapCache) Close() error {
 // OnEvicted 设置缓存项被淘汰时的回调函数
 // 实现interfaces.Cache接口
 func (b *BuildInMapCache) OnEvicted(fn func(key string, val any)) {
-	b.mutex.Lock()
-	defer b.mutex.Unlock()
+	// 回调在持有分片锁时读取，需要锁住所有分片
+	b.lockAll()
+	defer b.unlockAll()
 	b.onEvicted = fn
 }
+
+// OnEvictedBatch 设置一批缓存项被淘汰后的回调函数
+// 触发时机见 BuildInMapCacheWithEvictedBatchCallback
+// fn: 回调函数，为nil时取消批量回调
+func (b *BuildInMapCache) OnEvictedBatch(fn func(items map[string]any)) {
+	// 回调在持有分片锁时读取，需要锁住所有分片
+	b.lockAll()
+	defer b.unlockAll()
+	b.onEvictedBatch = fn
+}
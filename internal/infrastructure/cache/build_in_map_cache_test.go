@@ -6,14 +6,39 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var implErrKeyNotFound = ErrCacheKeyNotFound
 
+// fakeClock 可手动推进的时钟，用于在测试中模拟时间流逝
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance 将时钟向前推进d
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
 func TestBuildInMapCache(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -104,6 +129,441 @@ func TestBuildInMapCache_Expiration(t *testing.T) {
 	}
 }
 
+// TestBuildInMapCache_Clock 测试使用注入的时钟判断过期，无需真实等待
+func TestBuildInMapCache_Clock(t *testing.T) {
+	tests := []struct {
+		name       string
+		expiration time.Duration
+		advance    time.Duration
+		wantErr    error
+	}{
+		{
+			name:       "未到过期时间",
+			expiration: time.Minute,
+			advance:    59 * time.Second,
+		},
+		{
+			name:       "超过过期时间",
+			expiration: time.Minute,
+			advance:    time.Minute + time.Nanosecond,
+			wantErr:    ErrCacheKeyNotFound,
+		},
+		{
+			name:       "永不过期",
+			expiration: 0,
+			advance:    24 * time.Hour,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := newFakeClock()
+			c := NewBuildInMapCache(0, BuildInMapCacheWithClock(clock))
+
+			assert.NoError(t, c.Set(context.Background(), "key", "value", tt.expiration))
+			clock.Advance(tt.advance)
+
+			val, err := c.Get(context.Background(), "key")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "value", val)
+		})
+	}
+}
+
+// TestBuildInMapCache_SetNX 测试仅在键不存在时设置缓存值
+func TestBuildInMapCache_SetNX(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(*BuildInMapCache, *fakeClock)
+		wantOK  bool
+		wantVal any
+	}{
+		{
+			name:    "键不存在时设置成功",
+			setup:   func(*BuildInMapCache, *fakeClock) {},
+			wantOK:  true,
+			wantVal: "new",
+		},
+		{
+			name: "键已存在时保留原值",
+			setup: func(c *BuildInMapCache, _ *fakeClock) {
+				ok, err := c.SetNX(context.Background(), "key", "old", time.Minute)
+				assert.NoError(t, err)
+				assert.True(t, ok)
+			},
+			wantOK:  false,
+			wantVal: "old",
+		},
+		{
+			name: "键过期后设置成功",
+			setup: func(c *BuildInMapCache, clock *fakeClock) {
+				assert.NoError(t, c.Set(context.Background(), "key", "old", time.Minute))
+				clock.Advance(2 * time.Minute)
+			},
+			wantOK:  true,
+			wantVal: "new",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := newFakeClock()
+			c := NewBuildInMapCache(0, BuildInMapCacheWithClock(clock))
+			tt.setup(c, clock)
+
+			ok, err := c.SetNX(context.Background(), "key", "new", time.Minute)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantOK, ok)
+
+			val, err := c.Get(context.Background(), "key")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantVal, val)
+		})
+	}
+}
+
+// TestBuildInMapCache_GetOrSet 测试原子地获取或设置缓存值
+func TestBuildInMapCache_GetOrSet(t *testing.T) {
+	tests := []struct {
+		name       string
+		setup      func(*BuildInMapCache, *fakeClock)
+		wantVal    any
+		wantLoaded bool
+	}{
+		{
+			name:       "键不存在时存入新值",
+			setup:      func(*BuildInMapCache, *fakeClock) {},
+			wantVal:    "new",
+			wantLoaded: false,
+		},
+		{
+			name: "键已存在时返回原值",
+			setup: func(c *BuildInMapCache, _ *fakeClock) {
+				assert.NoError(t, c.Set(context.Background(), "key", "old", time.Minute))
+			},
+			wantVal:    "old",
+			wantLoaded: true,
+		},
+		{
+			name: "键过期后存入新值",
+			setup: func(c *BuildInMapCache, clock *fakeClock) {
+				assert.NoError(t, c.Set(context.Background(), "key", "old", time.Minute))
+				clock.Advance(2 * time.Minute)
+			},
+			wantVal:    "new",
+			wantLoaded: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := newFakeClock()
+			c := NewBuildInMapCache(0, BuildInMapCacheWithClock(clock))
+			tt.setup(c, clock)
+
+			actual, loaded, err := c.GetOrSet(context.Background(), "key", "new", time.Minute)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantVal, actual)
+			assert.Equal(t, tt.wantLoaded, loaded)
+
+			val, err := c.Get(context.Background(), "key")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantVal, val)
+		})
+	}
+}
+
+// TestBuildInMapCache_GetOrSet_Concurrent 测试并发GetOrSet只有一个值胜出
+func TestBuildInMapCache_GetOrSet_Concurrent(t *testing.T) {
+	c := NewBuildInMapCache(0)
+	const n = 50
+
+	var wg sync.WaitGroup
+	var stored atomic.Int64
+	results := make([]any, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			actual, loaded, err := c.GetOrSet(context.Background(), "key", i, time.Minute)
+			assert.NoError(t, err)
+			if !loaded {
+				stored.Add(1)
+			}
+			results[i] = actual
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int64(1), stored.Load())
+	winner, err := c.Get(context.Background(), "key")
+	assert.NoError(t, err)
+	for _, res := range results {
+		assert.Equal(t, winner, res)
+	}
+}
+
+// TestBuildInMapCache_Increment 测试原子地增减整数缓存值
+func TestBuildInMapCache_Increment(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(*BuildInMapCache)
+		op      func(*BuildInMapCache) (int64, error)
+		want    int64
+		wantErr error
+	}{
+		{
+			name:  "键不存在时以增量初始化",
+			setup: func(*BuildInMapCache) {},
+			op: func(c *BuildInMapCache) (int64, error) {
+				return c.Increment(context.Background(), "counter", 5)
+			},
+			want: 5,
+		},
+		{
+			name: "在已有整数上增加",
+			setup: func(c *BuildInMapCache) {
+				assert.NoError(t, c.Set(context.Background(), "counter", 10, time.Minute))
+			},
+			op: func(c *BuildInMapCache) (int64, error) {
+				return c.Increment(context.Background(), "counter", 3)
+			},
+			want: 13,
+		},
+		{
+			name: "减少到零以下",
+			setup: func(c *BuildInMapCache) {
+				assert.NoError(t, c.Set(context.Background(), "counter", uint8(2), time.Minute))
+			},
+			op: func(c *BuildInMapCache) (int64, error) {
+				return c.Decrement(context.Background(), "counter", 5)
+			},
+			want: -3,
+		},
+		{
+			name: "原值不是整数",
+			setup: func(c *BuildInMapCache) {
+				assert.NoError(t, c.Set(context.Background(), "counter", "ten", time.Minute))
+			},
+			op: func(c *BuildInMapCache) (int64, error) {
+				return c.Increment(context.Background(), "counter", 1)
+			},
+			wantErr: ErrValueNotInteger,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewBuildInMapCache(0)
+			tt.setup(c)
+
+			got, err := tt.op(c)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+
+			val, err := c.Get(context.Background(), "counter")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, val)
+		})
+	}
+}
+
+// TestBuildInMapCache_Increment_Concurrent 测试并发增加不会丢失更新
+func TestBuildInMapCache_Increment_Concurrent(t *testing.T) {
+	c := NewBuildInMapCache(0)
+	const n = 100
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := c.Increment(context.Background(), "counter", 1)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	val, err := c.Get(context.Background(), "counter")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(n), val)
+}
+
+// TestBuildInMapCache_Increment_ConcurrentGet 测试增加与读取并发执行时读取到的值单调递增
+// 使用 go test -race 运行时可以发现对缓存项的无锁读写竞争
+func TestBuildInMapCache_Increment_ConcurrentGet(t *testing.T) {
+	ctx := context.Background()
+	c := NewBuildInMapCache(0)
+	const n = 1000
+	_, err := c.Increment(ctx, "counter", 0)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < n; i++ {
+			_, err := c.Increment(ctx, "counter", 1)
+			assert.NoError(t, err)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		var last int64
+		for i := 0; i < n; i++ {
+			val, err := c.Get(ctx, "counter")
+			if err != nil {
+				continue
+			}
+			cur := val.(int64)
+			assert.GreaterOrEqual(t, cur, last)
+			last = cur
+		}
+	}()
+	wg.Wait()
+
+	val, version, ok, err := c.GetWithVersion(ctx, "counter")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, int64(n), val)
+	assert.Equal(t, uint64(n+1), version)
+}
+
+// TestBuildInMapCache_Exists 测试检查键是否存在
+func TestBuildInMapCache_Exists(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(*BuildInMapCache, *fakeClock)
+		wantHit bool
+	}{
+		{
+			name: "键存在",
+			setup: func(c *BuildInMapCache, _ *fakeClock) {
+				assert.NoError(t, c.Set(context.Background(), "key", "value", time.Minute))
+			},
+			wantHit: true,
+		},
+		{
+			name:    "键不存在",
+			setup:   func(*BuildInMapCache, *fakeClock) {},
+			wantHit: false,
+		},
+		{
+			name: "键已过期",
+			setup: func(c *BuildInMapCache, clock *fakeClock) {
+				assert.NoError(t, c.Set(context.Background(), "key", "value", time.Minute))
+				clock.Advance(2 * time.Minute)
+			},
+			wantHit: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := newFakeClock()
+			c := NewBuildInMapCache(0, BuildInMapCacheWithClock(clock))
+			tt.setup(c, clock)
+
+			ok, err := c.Exists(context.Background(), "key")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantHit, ok)
+		})
+	}
+}
+
+// TestBuildInMapCache_SetWithDeadline 测试使用绝对过期时间点设置缓存值
+func TestBuildInMapCache_SetWithDeadline(t *testing.T) {
+	tests := []struct {
+		name     string
+		deadline func(now time.Time) time.Time
+		wantTTL  time.Duration
+		wantErr  error
+	}{
+		{
+			name:     "未来的过期时间点",
+			deadline: func(now time.Time) time.Time { return now.Add(time.Hour) },
+			wantTTL:  time.Hour,
+		},
+		{
+			name:     "零值表示永不过期",
+			deadline: func(time.Time) time.Time { return time.Time{} },
+			wantTTL:  0,
+		},
+		{
+			name:     "过去的过期时间点视为已过期",
+			deadline: func(now time.Time) time.Time { return now.Add(-time.Second) },
+			wantErr:  ErrCacheKeyNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := newFakeClock()
+			c := NewBuildInMapCache(0, BuildInMapCacheWithClock(clock))
+			// 已有的值会被覆盖或删除
+			assert.NoError(t, c.Set(context.Background(), "key", "old", 0))
+
+			err := c.SetWithDeadline(context.Background(), "key", "value", tt.deadline(clock.Now()))
+			assert.NoError(t, err)
+
+			val, err := c.Get(context.Background(), "key")
+			ttl, ttlErr := c.TTL(context.Background(), "key")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.ErrorIs(t, ttlErr, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "value", val)
+			assert.NoError(t, ttlErr)
+			assert.Equal(t, tt.wantTTL, ttl)
+		})
+	}
+
+	t.Run("到达过期时间点后过期", func(t *testing.T) {
+		clock := newFakeClock()
+		c := NewBuildInMapCache(0, BuildInMapCacheWithClock(clock))
+		deadline := clock.Now().Add(time.Minute)
+		assert.NoError(t, c.SetWithDeadline(context.Background(), "key", "value", deadline))
+
+		clock.Advance(30 * time.Second)
+		ttl, err := c.TTL(context.Background(), "key")
+		assert.NoError(t, err)
+		assert.Equal(t, 30*time.Second, ttl)
+
+		clock.Advance(31 * time.Second)
+		_, err = c.Get(context.Background(), "key")
+		assert.ErrorIs(t, err, ErrCacheKeyNotFound)
+	})
+}
+
+// TestBuildInMapCache_NilValue 测试缓存的nil值与键不存在相区分
+func TestBuildInMapCache_NilValue(t *testing.T) {
+	c := NewBuildInMapCache(0)
+	assert.NoError(t, c.Set(context.Background(), "nil_key", nil, time.Minute))
+
+	// 缓存的nil值是一次命中
+	val, err := c.Get(context.Background(), "nil_key")
+	assert.NoError(t, err)
+	assert.Nil(t, val)
+
+	ok, err := c.Exists(context.Background(), "nil_key")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// 键不存在是一次未命中
+	_, err = c.Get(context.Background(), "absent_key")
+	assert.ErrorIs(t, err, ErrCacheKeyNotFound)
+}
+
 // TestBuildInMapCache_Delete 测试缓存的删除功能
 func TestBuildInMapCache_Delete(t *testing.T) {
 	tests := []struct {
@@ -305,6 +765,56 @@ func TestBuildInMapCache_OnEvicted(t *testing.T) {
 	}
 }
 
+// TestBuildInMapCache_OnEvicted_Expiration 测试缓存项过期被清理时触发回调
+func TestBuildInMapCache_OnEvicted_Expiration(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval time.Duration
+		expire   func(*BuildInMapCache)
+	}{
+		{
+			name:     "后台清理触发回调",
+			interval: 10 * time.Millisecond,
+			expire:   func(*BuildInMapCache) {},
+		},
+		{
+			name:     "Get惰性删除触发回调",
+			interval: 0,
+			expire: func(c *BuildInMapCache) {
+				_, err := c.Get(context.Background(), "key")
+				assert.ErrorIs(t, err, ErrCacheKeyNotFound)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			evicted := make(map[string]any)
+
+			clock := newFakeClock()
+			c := NewBuildInMapCache(tt.interval,
+				BuildInMapCacheWithClock(clock),
+				BuildInMapCacheWithEvictedCallback(func(key string, val any) {
+					mu.Lock()
+					defer mu.Unlock()
+					evicted[key] = val
+				}))
+			defer c.Close()
+
+			assert.NoError(t, c.Set(context.Background(), "key", "value", time.Second))
+			clock.Advance(2 * time.Second)
+			tt.expire(c)
+
+			assert.Eventually(t, func() bool {
+				mu.Lock()
+				defer mu.Unlock()
+				return evicted["key"] == "value"
+			}, time.Second, 5*time.Millisecond)
+		})
+	}
+}
+
 // TestBuildInMapCache_BackgroundCleanup 测试后台清理过期缓存项功能
 func TestBuildInMapCache_BackgroundCleanup(t *testing.T) {
 	tests := []struct {
@@ -586,3 +1096,585 @@ func TestBuildInMapCache_Get_ConcurrentExpiration(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, val)
 }
+
+// TestBuildInMapCache_Shards 测试分片存储下所有操作都路由到正确的分片
+func TestBuildInMapCache_Shards(t *testing.T) {
+	testCases := []struct {
+		name       string
+		shards     int
+		wantShards int
+	}{
+		{name: "默认单分片", shards: 0, wantShards: 1},
+		{name: "负数使用单分片", shards: -1, wantShards: 1},
+		{name: "16个分片", shards: 16, wantShards: 16},
+		{name: "分片数多于键数", shards: 1024, wantShards: 1024},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			var evicted atomic.Int64
+			c := NewBuildInMapCache(0, BuildInMapCacheWithShards(tc.shards),
+				BuildInMapCacheWithEvictedCallback(func(string, any) {
+					evicted.Add(1)
+				}))
+			assert.Len(t, c.shards, tc.wantShards)
+
+			const n = 500
+			var wg sync.WaitGroup
+			for i := range n {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					assert.NoError(t, c.Set(ctx, fmt.Sprintf("key%d", i), i, 0))
+				}(i)
+			}
+			wg.Wait()
+
+			// 每个写入的键都能读回
+			for i := range n {
+				val, err := c.Get(ctx, fmt.Sprintf("key%d", i))
+				require.NoError(t, err)
+				assert.Equal(t, i, val)
+			}
+
+			total := 0
+			for _, sh := range c.shards {
+				total += len(sh.data)
+			}
+			assert.Equal(t, n, total)
+
+			for i := range n {
+				require.NoError(t, c.Delete(ctx, fmt.Sprintf("key%d", i)))
+			}
+			assert.Equal(t, int64(n), evicted.Load())
+			require.NoError(t, c.Close())
+		})
+	}
+}
+
+// TestBuildInMapCache_Shards_Expiration 测试每个分片的过期项都会被后台清理
+func TestBuildInMapCache_Shards_Expiration(t *testing.T) {
+	ctx := context.Background()
+	clock := newFakeClock()
+	c := NewBuildInMapCache(10*time.Millisecond, BuildInMapCacheWithShards(8), BuildInMapCacheWithClock(clock))
+	defer func() {
+		_ = c.Close()
+	}()
+
+	for i := range 100 {
+		require.NoError(t, c.Set(ctx, fmt.Sprintf("key%d", i), i, time.Minute))
+	}
+	clock.Advance(2 * time.Minute)
+
+	assert.Eventually(t, func() bool {
+		for _, sh := range c.shards {
+			sh.mutex.RLock()
+			size := len(sh.data)
+			sh.mutex.RUnlock()
+			if size > 0 {
+				return false
+			}
+		}
+		return true
+	}, time.Second, 10*time.Millisecond)
+}
+
+// TestBuildInMapCache_MaxEntries 测试超过数量上限时按淘汰策略删除缓存项
+func TestBuildInMapCache_MaxEntries(t *testing.T) {
+	const n = 3
+	testCases := []struct {
+		name        string
+		opt         BuildInMapCacheOption
+		shards      int
+		wantEvicted []string
+		wantKeys    []string
+	}{
+		{
+			// key0每次写入前都被访问，LRU淘汰最久未访问的key1、key2、key3
+			name:        "默认LRU策略",
+			opt:         BuildInMapCacheWithMaxEntries(n),
+			wantEvicted: []string{"key1", "key2", "key3"},
+			wantKeys:    []string{"key0", "key4", "key5"},
+		},
+		{
+			// 访问不影响FIFO顺序，按写入顺序淘汰
+			name:        "FIFO策略",
+			opt:         BuildInMapCacheWithMaxEntries(n, NewFIFOPolicy()),
+			wantEvicted: []string{"key0", "key1", "key2"},
+			wantKeys:    []string{"key3", "key4", "key5"},
+		},
+		{
+			name:        "分片时LRU策略",
+			opt:         BuildInMapCacheWithMaxEntries(n),
+			shards:      4,
+			wantEvicted: []string{"key1", "key2", "key3"},
+			wantKeys:    []string{"key0", "key4", "key5"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			var evicted []string
+			c := NewBuildInMapCache(0, tc.opt, BuildInMapCacheWithShards(tc.shards),
+				BuildInMapCacheWithEvictedCallback(func(key string, _ any) {
+					evicted = append(evicted, key)
+				}))
+			defer func() {
+				_ = c.Close()
+			}()
+
+			for j := range n {
+				require.NoError(t, c.Set(ctx, fmt.Sprintf("key%d", j), j, 0))
+			}
+			for j := n; j < n+3; j++ {
+				// 写入前访问key0，FIFO策略下key0已被淘汰时忽略错误
+				_, _ = c.Get(ctx, "key0")
+				require.NoError(t, c.Set(ctx, fmt.Sprintf("key%d", j), j, 0))
+			}
+
+			assert.Equal(t, tc.wantEvicted, evicted)
+			total := 0
+			for _, sh := range c.shards {
+				total += len(sh.data)
+			}
+			assert.Equal(t, n, total)
+			for _, key := range tc.wantKeys {
+				ok, err := c.Exists(ctx, key)
+				require.NoError(t, err)
+				assert.True(t, ok, key)
+			}
+			size, err := c.policy.Size(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, n, size)
+		})
+	}
+}
+
+// TestBuildInMapCache_MaxEntries_PolicySync 测试删除和过期后淘汰策略与存储保持一致
+func TestBuildInMapCache_MaxEntries_PolicySync(t *testing.T) {
+	ctx := context.Background()
+	clock := newFakeClock()
+	c := NewBuildInMapCache(0, BuildInMapCacheWithMaxEntries(2), BuildInMapCacheWithClock(clock))
+	defer func() {
+		_ = c.Close()
+	}()
+
+	require.NoError(t, c.Set(ctx, "key1", 1, 0))
+	require.NoError(t, c.Set(ctx, "key2", 2, time.Minute))
+	require.NoError(t, c.Delete(ctx, "key1"))
+	clock.Advance(2 * time.Minute)
+	assert.Equal(t, 1, c.DeleteExpired(ctx))
+
+	size, err := c.policy.Size(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, size)
+
+	// 删除后腾出的位置可以直接使用，不会淘汰新写入的键
+	require.NoError(t, c.Set(ctx, "key3", 3, 0))
+	require.NoError(t, c.Set(ctx, "key4", 4, 0))
+	for _, key := range []string{"key3", "key4"} {
+		ok, err := c.Exists(ctx, key)
+		require.NoError(t, err)
+		assert.True(t, ok, key)
+	}
+
+	// 覆盖已有的键不会导致淘汰
+	require.NoError(t, c.Set(ctx, "key3", 33, 0))
+	ok, err := c.Exists(ctx, "key4")
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+// TestBuildInMapCache_OnEvictedBatch 测试批量淘汰回调一次收到整批被删除的缓存项
+func TestBuildInMapCache_OnEvictedBatch(t *testing.T) {
+	t.Run("DeleteExpired跨分片只触发一次", func(t *testing.T) {
+		ctx := context.Background()
+		clock := newFakeClock()
+		var batches []map[string]any
+		var single int
+		c := NewBuildInMapCache(0, BuildInMapCacheWithShards(4), BuildInMapCacheWithClock(clock),
+			BuildInMapCacheWithEvictedCallback(func(string, any) { single++ }),
+			BuildInMapCacheWithEvictedBatchCallback(func(items map[string]any) {
+				batches = append(batches, items)
+			}))
+		defer func() {
+			_ = c.Close()
+		}()
+
+		want := make(map[string]any)
+		for i := range 10 {
+			key := fmt.Sprintf("key%d", i)
+			require.NoError(t, c.Set(ctx, key, i, time.Minute))
+			want[key] = i
+		}
+		require.NoError(t, c.Set(ctx, "forever", "value", 0))
+		clock.Advance(2 * time.Minute)
+
+		assert.Equal(t, 10, c.DeleteExpired(ctx))
+		require.Len(t, batches, 1)
+		assert.Equal(t, want, batches[0])
+		assert.Equal(t, 10, single)
+
+		// 没有缓存项被删除时不触发
+		assert.Equal(t, 0, c.DeleteExpired(ctx))
+		assert.Len(t, batches, 1)
+	})
+
+	t.Run("主动删除不触发", func(t *testing.T) {
+		ctx := context.Background()
+		called := false
+		c := NewBuildInMapCache(0)
+		c.OnEvictedBatch(func(map[string]any) { called = true })
+
+		require.NoError(t, c.Set(ctx, "key1", 1, 0))
+		require.NoError(t, c.Delete(ctx, "key1"))
+		assert.False(t, called)
+	})
+
+	t.Run("后台清理", func(t *testing.T) {
+		ctx := context.Background()
+		clock := newFakeClock()
+		batchCh := make(chan map[string]any, 1)
+		c := NewBuildInMapCache(10*time.Millisecond, BuildInMapCacheWithClock(clock),
+			BuildInMapCacheWithEvictedBatchCallback(func(items map[string]any) {
+				batchCh <- items
+			}))
+		defer func() {
+			_ = c.Close()
+		}()
+
+		require.NoError(t, c.Set(ctx, "key1", 1, time.Minute))
+		require.NoError(t, c.Set(ctx, "key2", 2, time.Minute))
+		clock.Advance(2 * time.Minute)
+
+		select {
+		case items := <-batchCh:
+			assert.Equal(t, map[string]any{"key1": 1, "key2": 2}, items)
+		case <-time.After(time.Second):
+			t.Fatal("批量回调未被调用")
+		}
+	})
+
+	t.Run("超出数量上限的淘汰", func(t *testing.T) {
+		ctx := context.Background()
+		var batches []map[string]any
+		c := NewBuildInMapCache(0, BuildInMapCacheWithMaxEntries(1),
+			BuildInMapCacheWithEvictedBatchCallback(func(items map[string]any) {
+				batches = append(batches, items)
+			}))
+
+		require.NoError(t, c.Set(ctx, "key1", 1, 0))
+		require.NoError(t, c.Set(ctx, "key2", 2, 0))
+		assert.Equal(t, []map[string]any{{"key1": 1}}, batches)
+	})
+}
+
+// TestBuildInMapCache_Len 测试Len只统计未过期的缓存项
+func TestBuildInMapCache_Len(t *testing.T) {
+	ctx := context.Background()
+	clock := newFakeClock()
+	c := NewBuildInMapCache(0, BuildInMapCacheWithShards(4), BuildInMapCacheWithClock(clock))
+	assert.Equal(t, 0, c.Len())
+
+	for i := range 5 {
+		require.NoError(t, c.Set(ctx, fmt.Sprintf("key%d", i), i, time.Minute))
+	}
+	require.NoError(t, c.Set(ctx, "forever", "value", 0))
+	require.NoError(t, c.Delete(ctx, "key0"))
+	assert.Equal(t, 5, c.Len())
+
+	clock.Advance(2 * time.Minute)
+	assert.Equal(t, 1, c.Len())
+}
+
+// TestBuildInMapCache_Metadata 测试读取命中时记录访问次数和最后访问时间
+func TestBuildInMapCache_Metadata(t *testing.T) {
+	ctx := context.Background()
+	clock := newFakeClock()
+	c := NewBuildInMapCache(0, BuildInMapCacheWithClock(clock))
+	start := clock.Now()
+
+	require.NoError(t, c.Set(ctx, "key1", "value1", time.Minute))
+	meta, ok := c.Metadata("key1")
+	require.True(t, ok)
+	assert.Equal(t, "key1", meta.Key)
+	assert.Equal(t, start.Add(time.Minute), meta.Deadline)
+	assert.Equal(t, int64(0), meta.AccessCount)
+	// 未被读取时最后访问时间为写入时间
+	assert.True(t, meta.LastAccessedAt.Equal(start))
+
+	for i := 1; i <= 3; i++ {
+		clock.Advance(time.Second)
+		_, err := c.Get(ctx, "key1")
+		require.NoError(t, err)
+		meta, ok = c.Metadata("key1")
+		require.True(t, ok)
+		assert.Equal(t, int64(i), meta.AccessCount)
+		assert.True(t, meta.LastAccessedAt.Equal(clock.Now()))
+	}
+
+	// GetOrSet命中也是一次访问，未命中、Exists和TTL不计入
+	_, loaded, err := c.GetOrSet(ctx, "key1", "other", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, loaded)
+	_, err = c.Get(ctx, "absent")
+	assert.ErrorIs(t, err, ErrCacheKeyNotFound)
+	_, err = c.Exists(ctx, "key1")
+	require.NoError(t, err)
+	_, err = c.TTL(ctx, "key1")
+	require.NoError(t, err)
+	meta, _ = c.Metadata("key1")
+	assert.Equal(t, int64(4), meta.AccessCount)
+
+	// 覆盖写入保留访问信息，删除后重新写入从0开始
+	require.NoError(t, c.Set(ctx, "key1", "value2", time.Minute))
+	meta, _ = c.Metadata("key1")
+	assert.Equal(t, int64(4), meta.AccessCount)
+	require.NoError(t, c.Delete(ctx, "key1"))
+	_, ok = c.Metadata("key1")
+	assert.False(t, ok)
+	require.NoError(t, c.Set(ctx, "key1", "value3", time.Minute))
+	meta, _ = c.Metadata("key1")
+	assert.Equal(t, int64(0), meta.AccessCount)
+
+	// 已过期的缓存项没有访问信息
+	clock.Advance(2 * time.Minute)
+	_, ok = c.Metadata("key1")
+	assert.False(t, ok)
+
+	// GetOrSet命中与Get一样刷新淘汰策略中的访问顺序，LRU不会淘汰刚命中的键
+	lru := NewBuildInMapCache(0, BuildInMapCacheWithMaxEntries(2), BuildInMapCacheWithClock(clock))
+	defer func() {
+		_ = lru.Close()
+	}()
+	require.NoError(t, lru.Set(ctx, "key1", 1, 0))
+	require.NoError(t, lru.Set(ctx, "key2", 2, 0))
+	_, loaded, err = lru.GetOrSet(ctx, "key1", 10, 0)
+	require.NoError(t, err)
+	assert.True(t, loaded)
+	require.NoError(t, lru.Set(ctx, "key3", 3, 0))
+	ok, err = lru.Exists(ctx, "key1")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = lru.Exists(ctx, "key2")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	meta, _ = lru.Metadata("key1")
+	assert.Equal(t, int64(1), meta.AccessCount)
+}
+
+// TestBuildInMapCache_Metadata_Policy 测试淘汰策略的操作不影响访问次数
+func TestBuildInMapCache_Metadata_Policy(t *testing.T) {
+	ctx := context.Background()
+	c := NewBuildInMapCache(0, BuildInMapCacheWithMaxEntries(3))
+
+	for _, key := range []string{"hot", "warm", "cold"} {
+		require.NoError(t, c.Set(ctx, key, key, 0))
+	}
+	for range 5 {
+		_, err := c.Get(ctx, "hot")
+		require.NoError(t, err)
+	}
+	for range 2 {
+		_, err := c.Get(ctx, "warm")
+		require.NoError(t, err)
+	}
+	_, err := c.Increment(ctx, "counter", 1)
+	require.NoError(t, err)
+
+	// cold最久未被访问，被LRU淘汰，其他键的访问次数不变
+	_, ok := c.Metadata("cold")
+	assert.False(t, ok)
+
+	hot := c.HotKeys(0)
+	require.Len(t, hot, 3)
+	assert.Equal(t, []string{"hot", "warm", "counter"}, []string{hot[0].Key, hot[1].Key, hot[2].Key})
+	assert.Equal(t, []int64{5, 2, 0}, []int64{hot[0].AccessCount, hot[1].AccessCount, hot[2].AccessCount})
+
+	// 修改快照不影响缓存
+	hot[0].AccessCount = 100
+	top := c.HotKeys(1)
+	require.Len(t, top, 1)
+	assert.Equal(t, "hot", top[0].Key)
+	assert.Equal(t, int64(5), top[0].AccessCount)
+}
+
+// TestBuildInMapCache_Expire 测试重新设置过期时间不改变缓存值和访问信息
+func TestBuildInMapCache_Expire(t *testing.T) {
+	ctx := context.Background()
+	clock := newFakeClock()
+	c := NewBuildInMapCache(0, BuildInMapCacheWithClock(clock))
+
+	require.NoError(t, c.Set(ctx, "key1", "value1", time.Minute))
+	_, err := c.Get(ctx, "key1")
+	require.NoError(t, err)
+
+	clock.Advance(30 * time.Second)
+	require.NoError(t, c.Expire(ctx, "key1", 5*time.Minute))
+	ttl, err := c.TTL(ctx, "key1")
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, ttl)
+	meta, ok := c.Metadata("key1")
+	require.True(t, ok)
+	assert.Equal(t, int64(1), meta.AccessCount)
+
+	// 超过原来的过期时间仍然有效
+	clock.Advance(time.Minute)
+	val, err := c.Get(ctx, "key1")
+	require.NoError(t, err)
+	assert.Equal(t, "value1", val)
+
+	// 0表示永不过期
+	require.NoError(t, c.Expire(ctx, "key1", 0))
+	clock.Advance(time.Hour)
+	ttl, err = c.TTL(ctx, "key1")
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(0), ttl)
+
+	// 键不存在或已过期
+	assert.ErrorIs(t, c.Expire(ctx, "absent", time.Minute), ErrCacheKeyNotFound)
+	require.NoError(t, c.Set(ctx, "key2", "value2", time.Second))
+	clock.Advance(2 * time.Second)
+	assert.ErrorIs(t, c.Expire(ctx, "key2", time.Minute), ErrCacheKeyNotFound)
+}
+
+// TestBuildInMapCache_Version 测试每次写入版本号递增，以及指定版本号写入
+func TestBuildInMapCache_Version(t *testing.T) {
+	ctx := context.Background()
+	clock := newFakeClock()
+	c := NewBuildInMapCache(0, BuildInMapCacheWithClock(clock))
+
+	_, version, found, err := c.GetWithVersion(ctx, "key1")
+	require.NoError(t, err)
+	assert.False(t, found)
+	assert.Equal(t, uint64(0), version)
+
+	// 新键从1开始，每次写入加1
+	require.NoError(t, c.Set(ctx, "key1", "v1", time.Minute))
+	val, version, found, err := c.GetWithVersion(ctx, "key1")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "v1", val)
+	assert.Equal(t, uint64(1), version)
+
+	require.NoError(t, c.Set(ctx, "key1", "v2", time.Minute))
+	_, version, _, _ = c.GetWithVersion(ctx, "key1")
+	assert.Equal(t, uint64(2), version)
+
+	// 只修改过期时间不改变版本号
+	require.NoError(t, c.Expire(ctx, "key1", time.Hour))
+	_, version, _, _ = c.GetWithVersion(ctx, "key1")
+	assert.Equal(t, uint64(2), version)
+
+	// 自增同样是一次写入
+	_, err = c.Increment(ctx, "counter", 1)
+	require.NoError(t, err)
+	_, err = c.Increment(ctx, "counter", 1)
+	require.NoError(t, err)
+	_, version, _, _ = c.GetWithVersion(ctx, "counter")
+	assert.Equal(t, uint64(2), version)
+
+	// 指定版本号写入，0表示自动递增
+	require.NoError(t, c.SetWithVersion(ctx, "key1", "v10", 10, time.Minute))
+	val, version, _, _ = c.GetWithVersion(ctx, "key1")
+	assert.Equal(t, "v10", val)
+	assert.Equal(t, uint64(10), version)
+	require.NoError(t, c.SetWithVersion(ctx, "key1", "v11", 0, time.Minute))
+	_, version, _, _ = c.GetWithVersion(ctx, "key1")
+	assert.Equal(t, uint64(11), version)
+
+	// 读取计入访问信息
+	meta, ok := c.Metadata("key1")
+	require.True(t, ok)
+	assert.Equal(t, int64(5), meta.AccessCount)
+
+	// 过期后视为不存在
+	clock.Advance(2 * time.Minute)
+	_, _, found, err = c.GetWithVersion(ctx, "key1")
+	require.NoError(t, err)
+	assert.False(t, found)
+}
+
+// TestBuildInMapCache_CompareAndSwap 测试按版本号比较并设置
+func TestBuildInMapCache_CompareAndSwap(t *testing.T) {
+	ctx := context.Background()
+	clock := newFakeClock()
+	c := NewBuildInMapCache(0, BuildInMapCacheWithClock(clock))
+
+	// 期望版本号0表示键不存在时创建
+	ok, err := c.CompareAndSwap(ctx, "key1", 0, "v1", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = c.CompareAndSwap(ctx, "key1", 0, "other", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// 版本号一致时设置成功并递增版本号
+	val, version, _, _ := c.GetWithVersion(ctx, "key1")
+	assert.Equal(t, "v1", val)
+	ok, err = c.CompareAndSwap(ctx, "key1", version, "v2", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	val, newVersion, _, _ := c.GetWithVersion(ctx, "key1")
+	assert.Equal(t, "v2", val)
+	assert.Equal(t, version+1, newVersion)
+
+	// 使用旧版本号设置失败，保留原值
+	ok, err = c.CompareAndSwap(ctx, "key1", version, "stale", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	val, _, _, _ = c.GetWithVersion(ctx, "key1")
+	assert.Equal(t, "v2", val)
+
+	// 其他写入后版本号变化，之前读到的版本号失效
+	require.NoError(t, c.Set(ctx, "key1", "v3", time.Minute))
+	ok, err = c.CompareAndSwap(ctx, "key1", newVersion, "stale", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// 已过期的键视为不存在
+	clock.Advance(2 * time.Minute)
+	ok, err = c.CompareAndSwap(ctx, "key1", newVersion+1, "stale", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = c.CompareAndSwap(ctx, "key1", 0, "fresh", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	val, version, _, _ = c.GetWithVersion(ctx, "key1")
+	assert.Equal(t, "fresh", val)
+	assert.Equal(t, uint64(1), version)
+}
+
+// TestBuildInMapCache_CompareAndSwap_Concurrent 测试并发比较并设置时同一个版本号只有一个成功
+func TestBuildInMapCache_CompareAndSwap_Concurrent(t *testing.T) {
+	ctx := context.Background()
+	c := NewBuildInMapCache(0)
+	require.NoError(t, c.Set(ctx, "counter", 0, 0))
+
+	const workers, perWorker = 8, 50
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < perWorker; j++ {
+				for {
+					val, version, _, err := c.GetWithVersion(ctx, "counter")
+					require.NoError(t, err)
+					ok, err := c.CompareAndSwap(ctx, "counter", version, val.(int)+1, 0)
+					require.NoError(t, err)
+					if ok {
+						break
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	val, version, _, _ := c.GetWithVersion(ctx, "counter")
+	assert.Equal(t, workers*perWorker, val)
+	assert.Equal(t, uint64(workers*perWorker+1), version)
+}
@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// Codec 缓存值的序列化器
+// CodecCache 和 WriteBackJournal 共用，解码时由调用方指定目标类型，
+// 目标为*any时得到序列化器默认的类型（JSON中的对象会变为map[string]any）
+type Codec interface {
+	// Marshal 将缓存值序列化为字节
+	Marshal(val any) ([]byte, error)
+	// Unmarshal 将字节反序列化到ptr指向的值
+	Unmarshal(data []byte, ptr any) error
+}
+
+// JSONCodec 使用JSON的序列化器
+type JSONCodec struct{}
+
+// Marshal 将缓存值序列化为JSON
+func (JSONCodec) Marshal(val any) ([]byte, error) {
+	return json.Marshal(val)
+}
+
+// Unmarshal 将JSON反序列化到ptr指向的值
+func (JSONCodec) Unmarshal(data []byte, ptr any) error {
+	return json.Unmarshal(data, ptr)
+}
+
+// GobCodec 使用gob的序列化器
+// 缓存值以接口类型编码，解码到*any时能够还原原始类型，自定义类型需要先调用 gob.Register 注册
+type GobCodec struct{}
+
+// Marshal 将缓存值序列化为gob字节
+func (GobCodec) Marshal(val any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&val); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal 将gob字节反序列化到ptr指向的值
+// 返回: 错误信息，解码得到的值不能赋给ptr指向的类型时返回错误
+func (GobCodec) Unmarshal(data []byte, ptr any) error {
+	var val any
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&val); err != nil {
+		return err
+	}
+
+	target := reflect.ValueOf(ptr)
+	if target.Kind() != reflect.Pointer || target.IsNil() {
+		return fmt.Errorf("gob: 解码目标必须是非nil指针，实际为 %T", ptr)
+	}
+	target = target.Elem()
+	if val == nil {
+		target.SetZero()
+		return nil
+	}
+	v := reflect.ValueOf(val)
+	if !v.Type().AssignableTo(target.Type()) {
+		return fmt.Errorf("gob: 类型为 %T 的值不能解码到 %s", val, target.Type())
+	}
+	target.Set(v)
+	return nil
+}
@@ -0,0 +1,166 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	domainCache "github.com/justinwongcn/hamster/internal/domain/cache"
+)
+
+// ErrCodecFailed 缓存值编码或解码失败
+var ErrCodecFailed = errors.New("cache：缓存值编解码失败")
+
+// CodecCache 序列化缓存值的装饰器
+// 写入时将缓存值序列化为字节再交给底层缓存，读取时反序列化，
+// 底层缓存只保存字节，可以与按字节计算内存的MaxMemoryCache、持久化的FileCache等组合使用
+// Get 将值反序列化为any（JSON中的对象会变为map[string]any），需要原始类型时使用 GetInto
+type CodecCache struct {
+	domainCache.Repository
+	codec Codec
+}
+
+// NewCodecCache 创建序列化缓存值的装饰器
+// repo: 底层缓存
+// codec: 序列化器，为nil时使用 JSONCodec
+// 返回: CodecCache实例
+func NewCodecCache(repo domainCache.Repository, codec Codec) *CodecCache {
+	if codec == nil {
+		codec = JSONCodec{}
+	}
+	return &CodecCache{
+		Repository: repo,
+		codec:      codec,
+	}
+}
+
+// Set 序列化缓存值后写入底层缓存
+// 返回: 错误信息，值无法序列化时返回 ErrCodecFailed
+func (c *CodecCache) Set(ctx context.Context, key string, val any, expiration time.Duration) error {
+	data, err := c.marshal(key, val)
+	if err != nil {
+		return err
+	}
+	return c.Repository.Set(ctx, key, data, expiration)
+}
+
+// SetWithDeadline 序列化缓存值后写入底层缓存，并指定绝对的过期时间点
+// 返回: 错误信息，底层缓存不支持SetWithDeadline时返回错误
+func (c *CodecCache) SetWithDeadline(ctx context.Context, key string, val any, deadline time.Time) error {
+	repo, ok := c.Repository.(interface {
+		SetWithDeadline(ctx context.Context, key string, val any, deadline time.Time) error
+	})
+	if !ok {
+		return fmt.Errorf("底层缓存不支持SetWithDeadline操作")
+	}
+	data, err := c.marshal(key, val)
+	if err != nil {
+		return err
+	}
+	return repo.SetWithDeadline(ctx, key, data, deadline)
+}
+
+// SetNX 仅当键不存在或已过期时序列化缓存值并写入底层缓存
+// 返回: 是否设置成功和错误信息，底层缓存不支持SetNX时返回错误
+func (c *CodecCache) SetNX(ctx context.Context, key string, val any, expiration time.Duration) (bool, error) {
+	repo, ok := c.Repository.(interface {
+		SetNX(ctx context.Context, key string, val any, expiration time.Duration) (bool, error)
+	})
+	if !ok {
+		return false, fmt.Errorf("底层缓存不支持SetNX操作")
+	}
+	data, err := c.marshal(key, val)
+	if err != nil {
+		return false, err
+	}
+	return repo.SetNX(ctx, key, data, expiration)
+}
+
+// Get 获取缓存值并反序列化为any
+// 返回: 缓存值和错误信息，值无法反序列化时返回 ErrCodecFailed
+func (c *CodecCache) Get(ctx context.Context, key string) (any, error) {
+	var val any
+	if err := c.GetInto(ctx, key, &val); err != nil {
+		return nil, err
+	}
+	return val, nil
+}
+
+// GetInto 获取缓存值并反序列化到ptr指向的值
+// ptr: 目标值的指针，如*User
+// 返回: 错误信息，键不存在时返回底层缓存的错误，值无法反序列化时返回 ErrCodecFailed
+func (c *CodecCache) GetInto(ctx context.Context, key string, ptr any) error {
+	val, err := c.Repository.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	return c.unmarshal(key, val, ptr)
+}
+
+// LoadAndDelete 获取并删除缓存值，返回反序列化后的值
+func (c *CodecCache) LoadAndDelete(ctx context.Context, key string) (any, error) {
+	val, err := c.Repository.LoadAndDelete(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	var res any
+	if err = c.unmarshal(key, val, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Exists 检查键是否存在且未过期
+// 返回: 是否存在和错误信息，底层缓存不支持Exists时返回错误
+func (c *CodecCache) Exists(ctx context.Context, key string) (bool, error) {
+	repo, ok := c.Repository.(interface {
+		Exists(ctx context.Context, key string) (bool, error)
+	})
+	if !ok {
+		return false, fmt.Errorf("底层缓存不支持Exists操作")
+	}
+	return repo.Exists(ctx, key)
+}
+
+// Len 返回底层缓存的缓存项数量，底层缓存不支持时返回0
+func (c *CodecCache) Len() int {
+	if repo, ok := c.Repository.(interface{ Len() int }); ok {
+		return repo.Len()
+	}
+	return 0
+}
+
+// OnEvicted 设置缓存项被淘汰时的回调函数
+// 回调收到反序列化后的值，无法反序列化时收到底层缓存保存的原始值
+func (c *CodecCache) OnEvicted(fn func(key string, val any)) {
+	c.Repository.OnEvicted(func(key string, val any) {
+		var res any
+		if err := c.unmarshal(key, val, &res); err != nil {
+			fn(key, val)
+			return
+		}
+		fn(key, res)
+	})
+}
+
+// marshal 序列化缓存值
+func (c *CodecCache) marshal(key string, val any) ([]byte, error) {
+	data, err := c.codec.Marshal(val)
+	if err != nil {
+		return nil, fmt.Errorf("%w: key: %s, %v", ErrCodecFailed, key, err)
+	}
+	return data, nil
+}
+
+// unmarshal 将底层缓存保存的字节反序列化到ptr指向的值
+func (c *CodecCache) unmarshal(key string, val any, ptr any) error {
+	data, ok := val.([]byte)
+	if !ok {
+		return fmt.Errorf("%w: key: %s, 缓存值类型为 %T，不是[]byte", ErrCodecFailed, key, val)
+	}
+	if err := c.codec.Unmarshal(data, ptr); err != nil {
+		return fmt.Errorf("%w: key: %s, %v", ErrCodecFailed, key, err)
+	}
+	return nil
+}
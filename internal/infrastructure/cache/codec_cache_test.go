@@ -0,0 +1,98 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// codecUser 用于测试序列化的结构体
+type codecUser struct {
+	Name string   `json:"name"`
+	Age  int      `json:"age"`
+	Tags []string `json:"tags"`
+}
+
+// TestCodecCache_GetInto 测试结构体经过序列化后按原始类型取回
+func TestCodecCache_GetInto(t *testing.T) {
+	ctx := context.Background()
+	inner := NewBuildInMapCache(0)
+	c := NewCodecCache(inner, nil)
+
+	want := codecUser{Name: "Tom", Age: 18, Tags: []string{"a", "b"}}
+	require.NoError(t, c.Set(ctx, "user", want, time.Minute))
+
+	// 底层缓存只保存字节
+	raw, err := inner.Get(ctx, "user")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"Tom","age":18,"tags":["a","b"]}`, string(raw.([]byte)))
+
+	var got codecUser
+	require.NoError(t, c.GetInto(ctx, "user", &got))
+	assert.Equal(t, want, got)
+
+	// Get 反序列化为any
+	val, err := c.Get(ctx, "user")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"name": "Tom", "age": float64(18), "tags": []any{"a", "b"}}, val)
+
+	// 键不存在时返回底层缓存的错误
+	err = c.GetInto(ctx, "absent", &got)
+	assert.ErrorIs(t, err, ErrCacheKeyNotFound)
+}
+
+// TestCodecCache_Errors 测试无法序列化和反序列化的值
+func TestCodecCache_Errors(t *testing.T) {
+	ctx := context.Background()
+	inner := NewBuildInMapCache(0)
+	c := NewCodecCache(inner, JSONCodec{})
+
+	err := c.Set(ctx, "chan", make(chan int), time.Minute)
+	assert.ErrorIs(t, err, ErrCodecFailed)
+	ok, err := c.Exists(ctx, "chan")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// 底层缓存中的值不是字节
+	require.NoError(t, inner.Set(ctx, "raw", 42, time.Minute))
+	_, err = c.Get(ctx, "raw")
+	assert.ErrorIs(t, err, ErrCodecFailed)
+
+	require.NoError(t, c.Set(ctx, "user", "not a user", time.Minute))
+	var user codecUser
+	assert.ErrorIs(t, c.GetInto(ctx, "user", &user), ErrCodecFailed)
+}
+
+// TestCodecCache_Delegation 测试其他操作转发给底层缓存
+func TestCodecCache_Delegation(t *testing.T) {
+	ctx := context.Background()
+	c := NewCodecCache(NewBuildInMapCache(0), nil)
+	var evicted []any
+	c.OnEvicted(func(_ string, val any) {
+		evicted = append(evicted, val)
+	})
+
+	set, err := c.SetNX(ctx, "key1", "value1", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, set)
+	set, err = c.SetNX(ctx, "key1", "value2", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, set)
+	require.NoError(t, c.SetWithDeadline(ctx, "key2", []int{1, 2}, time.Now().Add(time.Minute)))
+	assert.Equal(t, 2, c.Len())
+
+	val, err := c.LoadAndDelete(ctx, "key1")
+	require.NoError(t, err)
+	assert.Equal(t, "value1", val)
+	require.NoError(t, c.Delete(ctx, "key2"))
+	assert.Equal(t, []any{"value1", []any{float64(1), float64(2)}}, evicted)
+
+	// 底层缓存不支持的操作返回错误
+	c = NewCodecCache(&MockCache{store: make(map[string]any)}, nil)
+	_, err = c.SetNX(ctx, "key1", "value1", time.Minute)
+	assert.Error(t, err)
+	assert.Equal(t, 0, c.Len())
+}
@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"encoding/gob"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCodec_RoundTrip 测试序列化器解码到指定类型和*any
+func TestCodec_RoundTrip(t *testing.T) {
+	gob.Register(codecUser{})
+	want := codecUser{Name: "alice", Age: 30, Tags: []string{"a"}}
+	tests := []struct {
+		name    string
+		codec   Codec
+		wantAny any
+	}{
+		{
+			name:    "JSONCodec",
+			codec:   JSONCodec{},
+			wantAny: map[string]any{"name": "alice", "age": float64(30), "tags": []any{"a"}},
+		},
+		{
+			name:    "GobCodec",
+			codec:   GobCodec{},
+			wantAny: want,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.codec.Marshal(want)
+			require.NoError(t, err)
+
+			var user codecUser
+			require.NoError(t, tt.codec.Unmarshal(data, &user))
+			assert.Equal(t, want, user)
+
+			var val any
+			require.NoError(t, tt.codec.Unmarshal(data, &val))
+			assert.Equal(t, tt.wantAny, val)
+
+			var wrong string
+			assert.Error(t, tt.codec.Unmarshal(data, &wrong))
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/justinwongcn/hamster/internal/domain/cache"
+	domainHash "github.com/justinwongcn/hamster/internal/domain/consistent_hash"
+)
+
+// ErrNotOwner 当前节点不是键的所有者
+var ErrNotOwner = errors.New("cache：当前节点不是键的所有者")
+
+// PeerFetcher 从远程节点获取缓存值
+// 由使用方根据通信方式（HTTP、gRPC等）实现，远程节点应直接读取其本地仓储，不能再次路由
+type PeerFetcher interface {
+	// Fetch 从指定节点获取缓存值
+	// ctx: 上下文
+	// peer: 键的所有者节点
+	// key: 缓存键
+	// 返回: 缓存值和错误信息
+	Fetch(ctx context.Context, peer domainHash.Peer, key string) (any, error)
+}
+
+// DistributedCache 基于一致性哈希的分布式缓存
+// 类似groupcache，每个键由一致性哈希选出的节点负责：
+// 当前节点是所有者时读取本地仓储，否则通过 PeerFetcher 从所有者节点获取，
+// 同一个键的并发远程获取通过singleflight合并为一次
+type DistributedCache struct {
+	local   cache.Repository      // 本地仓储，保存当前节点负责的键
+	picker  domainHash.PeerPicker // 节点选择器
+	fetcher PeerFetcher           // 远程获取器
+	selfID  string                // 当前节点ID
+	g       singleflight.Group    // 合并远程获取
+}
+
+// NewDistributedCache 创建分布式缓存
+// selfID: 当前节点ID，需与节点选择器中的节点ID一致
+// local: 本地仓储
+// picker: 节点选择器
+// fetcher: 远程获取器
+// 返回: DistributedCache实例
+func NewDistributedCache(selfID string, local cache.Repository, picker domainHash.PeerPicker, fetcher PeerFetcher) *DistributedCache {
+	return &DistributedCache{
+		local:   local,
+		picker:  picker,
+		fetcher: fetcher,
+		selfID:  selfID,
+	}
+}
+
+// owner 选择键的所有者节点
+// 返回: 所有者节点、当前节点是否为所有者和错误信息
+func (d *DistributedCache) owner(key string) (domainHash.Peer, bool, error) {
+	peer, err := d.picker.PickPeer(key)
+	if err != nil {
+		return nil, false, fmt.Errorf("选择节点失败: %w", err)
+	}
+	return peer, peer.ID() == d.selfID, nil
+}
+
+// Get 获取缓存值
+// 当前节点是所有者时读取本地仓储，否则从所有者节点获取
+// ctx: 上下文
+// key: 缓存键
+// 返回: 缓存值和错误信息
+func (d *DistributedCache) Get(ctx context.Context, key string) (any, error) {
+	peer, isSelf, err := d.owner(key)
+	if err != nil {
+		return nil, err
+	}
+	if isSelf {
+		return d.local.Get(ctx, key)
+	}
+
+	val, err, _ := d.g.Do(key, func() (interface{}, error) {
+		return d.fetcher.Fetch(ctx, peer, key)
+	})
+	return val, err
+}
+
+// Set 设置缓存值
+// 只有所有者节点可以写入，否则返回 ErrNotOwner
+// ctx: 上下文
+// key: 缓存键
+// val: 缓存值
+// expiration: 过期时间
+// 返回: 错误信息
+func (d *DistributedCache) Set(ctx context.Context, key string, val any, expiration time.Duration) error {
+	if err := d.checkOwner(key); err != nil {
+		return err
+	}
+	return d.local.Set(ctx, key, val, expiration)
+}
+
+// Delete 删除缓存值
+// 只有所有者节点可以删除，否则返回 ErrNotOwner
+// ctx: 上下文
+// key: 缓存键
+// 返回: 错误信息
+func (d *DistributedCache) Delete(ctx context.Context, key string) error {
+	if err := d.checkOwner(key); err != nil {
+		return err
+	}
+	return d.local.Delete(ctx, key)
+}
+
+// LoadAndDelete 获取并删除缓存值
+// 只有所有者节点可以删除，否则返回 ErrNotOwner
+// ctx: 上下文
+// key: 缓存键
+// 返回: 被删除的缓存值和错误信息
+func (d *DistributedCache) LoadAndDelete(ctx context.Context, key string) (any, error) {
+	if err := d.checkOwner(key); err != nil {
+		return nil, err
+	}
+	return d.local.LoadAndDelete(ctx, key)
+}
+
+// OnEvicted 设置本地仓储的淘汰回调
+// fn: 回调函数
+func (d *DistributedCache) OnEvicted(fn func(key string, val any)) {
+	d.local.OnEvicted(fn)
+}
+
+// checkOwner 检查当前节点是否为键的所有者
+// 返回: 不是所有者时返回 ErrNotOwner
+func (d *DistributedCache) checkOwner(key string) error {
+	peer, isSelf, err := d.owner(key)
+	if err != nil {
+		return err
+	}
+	if !isSelf {
+		return fmt.Errorf("%w: key: %s, owner: %s", ErrNotOwner, key, peer.ID())
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domainHash "github.com/justinwongcn/hamster/internal/domain/consistent_hash"
+	"github.com/justinwongcn/hamster/internal/infrastructure/consistent_hash"
+)
+
+// fakePeerFetcher 进程内的远程获取器，直接读取目标节点的本地仓储
+type fakePeerFetcher struct {
+	locals  map[string]*BuildInMapCache
+	calls   atomic.Int64
+	release chan struct{} // 非nil时阻塞获取，直到通道关闭
+}
+
+func (f *fakePeerFetcher) Fetch(ctx context.Context, peer domainHash.Peer, key string) (any, error) {
+	f.calls.Add(1)
+	if f.release != nil {
+		<-f.release
+	}
+	return f.locals[peer.ID()].Get(ctx, key)
+}
+
+// newTestCluster 创建共享节点选择器的多节点集群
+func newTestCluster(t *testing.T, ids ...string) (map[string]*DistributedCache, *fakePeerFetcher, domainHash.PeerPicker) {
+	picker := consistent_hash.NewSingleflightPeerPicker(consistent_hash.NewConsistentHashMap(50, nil))
+	fetcher := &fakePeerFetcher{locals: make(map[string]*BuildInMapCache)}
+	nodes := make(map[string]*DistributedCache)
+
+	for _, id := range ids {
+		peer, err := domainHash.NewPeerInfo(id, id+":8080", 1)
+		require.NoError(t, err)
+		picker.AddPeers(peer)
+
+		local := NewBuildInMapCache(0)
+		fetcher.locals[id] = local
+		nodes[id] = NewDistributedCache(id, local, picker, fetcher)
+	}
+	return nodes, fetcher, picker
+}
+
+// TestDistributedCache_Routing 测试键路由到所有者节点
+func TestDistributedCache_Routing(t *testing.T) {
+	ctx := context.Background()
+	nodes, fetcher, picker := newTestCluster(t, "node-a", "node-b", "node-c")
+
+	for i := 0; i < 30; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		owner, err := picker.PickPeer(key)
+		require.NoError(t, err)
+
+		// 只有所有者可以写入
+		for id, node := range nodes {
+			err := node.Set(ctx, key, i, time.Minute)
+			if id == owner.ID() {
+				require.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrNotOwner)
+			}
+		}
+
+		// 数据只存在于所有者的本地仓储
+		for id, local := range fetcher.locals {
+			_, err := local.Get(ctx, key)
+			if id == owner.ID() {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrCacheKeyNotFound)
+			}
+		}
+
+		// 任意节点都能读到
+		for _, node := range nodes {
+			val, err := node.Get(ctx, key)
+			require.NoError(t, err)
+			assert.Equal(t, i, val)
+		}
+	}
+
+	// 每个键被两个非所有者节点各远程获取一次
+	assert.Equal(t, int64(60), fetcher.calls.Load())
+}
+
+// TestDistributedCache_DedupRemoteFetch 测试并发远程获取被合并
+func TestDistributedCache_DedupRemoteFetch(t *testing.T) {
+	ctx := context.Background()
+	nodes, fetcher, picker := newTestCluster(t, "node-a", "node-b")
+
+	// 找到一个由node-b负责的键，从node-a读取
+	var key string
+	for i := 0; ; i++ {
+		key = fmt.Sprintf("hot-%d", i)
+		owner, err := picker.PickPeer(key)
+		require.NoError(t, err)
+		if owner.ID() == "node-b" {
+			break
+		}
+	}
+	require.NoError(t, nodes["node-b"].Set(ctx, key, "hot", time.Minute))
+
+	fetcher.release = make(chan struct{})
+	const n = 10
+	var wg sync.WaitGroup
+	results := make([]any, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = nodes["node-a"].Get(ctx, key)
+		}(i)
+	}
+
+	// 等待第一个请求进入远程获取后放行
+	assert.Eventually(t, func() bool { return fetcher.calls.Load() == 1 }, time.Second, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	close(fetcher.release)
+	wg.Wait()
+
+	assert.Equal(t, int64(1), fetcher.calls.Load())
+	for _, res := range results {
+		assert.Equal(t, "hot", res)
+	}
+}
@@ -1,6 +1,12 @@
 package cache
 
-import "context"
+import (
+	"context"
+	"errors"
+)
+
+// ErrInvalidCapacity 淘汰策略的容量无效
+var ErrInvalidCapacity = errors.New("cache：容量不能为负数")
 
 // EvictionPolicy 定义缓存淘汰策略接口
 // 该接口遵循DDD设计原则，提供了缓存淘汰的核心领域行为
@@ -24,6 +30,15 @@ type EvictionPolicy interface {
 	//   - error: 操作错误，nil表示成功
 	Evict(ctx context.Context) (string, error)
 
+	// Peek 返回下一次Evict将要淘汰的key，但不移除
+	// 用于在淘汰前检查候选key
+	// 参数:
+	//   - ctx: 上下文，用于传递请求级别的信息
+	// 返回值:
+	//   - string: 下一个被淘汰的key，空字符串表示没有可淘汰的key
+	//   - error: 操作错误，nil表示成功
+	Peek(ctx context.Context) (string, error)
+
 	// Remove 移除指定key
 	// 从策略中移除指定的key，通常在key被删除时调用
 	// 参数:
@@ -97,7 +97,28 @@ func (f *FIFOPolicy) Evict(context.Context) (string, error) {
 		return "", nil
 	}
 
-	// 移除队列头部节点
+	return f.popHead(), nil
+}
+
+// EvictMultiple 一次淘汰最多n个key
+// 按FIFO顺序从最早添加的key开始淘汰，只加一次锁
+// 返回值:
+//   - []string: 被淘汰的key，策略中的key不足n个时全部淘汰
+//   - error: 操作错误，nil表示成功
+func (f *FIFOPolicy) EvictMultiple(_ context.Context, n int) ([]string, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	var evicted []string
+	for ; n > 0 && f.size > 0 && f.head != nil; n-- {
+		evicted = append(evicted, f.popHead())
+	}
+	return evicted, nil
+}
+
+// popHead 移除队列头部节点并返回其key（内部方法，不加锁）
+// 注意: 调用方需保证队列不为空
+func (f *FIFOPolicy) popHead() string {
 	oldHead := f.head
 	f.head = f.head.next
 	if f.head == nil {
@@ -105,8 +126,43 @@ func (f *FIFOPolicy) Evict(context.Context) (string, error) {
 	}
 	delete(f.cache, oldHead.key)
 	f.size--
+	return oldHead.key
+}
 
-	return oldHead.key, nil
+// SetCapacity 调整容量限制
+// 新容量小于当前大小时，按FIFO顺序淘汰最早添加的key直到满足新容量
+// 参数:
+//   - capacity: 新的容量限制，0表示无限制
+//
+// 返回值:
+//   - []string: 被淘汰的key，调用方需要从缓存中删除这些key
+//   - error: 容量为负数时返回ErrInvalidCapacity
+func (f *FIFOPolicy) SetCapacity(_ context.Context, capacity int) ([]string, error) {
+	if capacity < 0 {
+		return nil, ErrInvalidCapacity
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.capacity = capacity
+	var evicted []string
+	for f.capacity > 0 && f.size > f.capacity {
+		evicted = append(evicted, f.popHead())
+	}
+	return evicted, nil
+}
+
+// Peek 返回下一个将被淘汰的key
+// 即最早进入队列的key（队列头部），不移除
+func (f *FIFOPolicy) Peek(context.Context) (string, error) {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	if f.size == 0 || f.head == nil {
+		return "", nil
+	}
+	return f.head.key, nil
 }
 
 // Remove 移除指定key
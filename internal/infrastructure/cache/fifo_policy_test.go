@@ -564,3 +564,105 @@ func TestFIFOPolicy_CapacityEviction(t *testing.T) {
 	require.NoError(t, err)
 	assert.True(t, has)
 }
+
+// TestFIFOPolicy_Peek 测试Peek返回下一个被淘汰的key且不移除
+func TestFIFOPolicy_Peek(t *testing.T) {
+	ctx := context.Background()
+	policy := NewFIFOPolicy()
+
+	key, err := policy.Peek(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, key)
+
+	for _, k := range []string{"key1", "key2", "key3"} {
+		require.NoError(t, policy.KeyAccessed(ctx, k))
+	}
+
+	want := []string{"key1", "key2", "key3"}
+	for _, w := range want {
+		peeked, err := policy.Peek(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, w, peeked)
+
+		has, err := policy.Has(ctx, peeked)
+		require.NoError(t, err)
+		assert.True(t, has)
+
+		evicted, err := policy.Evict(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, peeked, evicted)
+	}
+}
+
+// TestFIFOPolicy_SetCapacity 测试调整容量时按FIFO顺序淘汰
+func TestFIFOPolicy_SetCapacity(t *testing.T) {
+	tests := []struct {
+		name        string
+		capacity    int
+		wantEvicted []string
+		wantSize    int
+		wantErr     error
+	}{
+		{
+			name:        "缩小容量淘汰最早添加的key",
+			capacity:    1,
+			wantEvicted: []string{"key1", "key2", "key3"},
+			wantSize:    1,
+		},
+		{
+			name:     "扩大容量不淘汰",
+			capacity: 10,
+			wantSize: 4,
+		},
+		{
+			name:     "负数容量",
+			capacity: -1,
+			wantSize: 4,
+			wantErr:  ErrInvalidCapacity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			policy := NewFIFOPolicy(4)
+			for _, k := range []string{"key1", "key2", "key3", "key4"} {
+				require.NoError(t, policy.KeyAccessed(ctx, k))
+			}
+
+			evicted, err := policy.SetCapacity(ctx, tt.capacity)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantEvicted, evicted)
+
+			size, err := policy.Size(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantSize, size)
+		})
+	}
+}
+
+// TestFIFOPolicy_EvictMultiple 测试一次淘汰多个key
+func TestFIFOPolicy_EvictMultiple(t *testing.T) {
+	ctx := context.Background()
+	policy := NewFIFOPolicy()
+	for _, k := range []string{"key1", "key2", "key3", "key1"} {
+		require.NoError(t, policy.KeyAccessed(ctx, k))
+	}
+
+	// 访问不改变FIFO顺序
+	evicted, err := policy.EvictMultiple(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"key1", "key2"}, evicted)
+
+	evicted, err = policy.EvictMultiple(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"key3"}, evicted)
+
+	size, err := policy.Size(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, size)
+}
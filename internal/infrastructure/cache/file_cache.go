@@ -0,0 +1,212 @@
+package cache
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ErrValueNotEncodable 缓存值无法被gob编码
+var ErrValueNotEncodable = errors.New("cache：缓存值无法持久化")
+
+// fileCacheEntry 持久化到文件中的缓存项
+type fileCacheEntry struct {
+	Key      string
+	Value    any
+	Deadline time.Time
+}
+
+// FileCache 可持久化到文件的缓存
+// 在 BuildInMapCache 的基础上定期（以及关闭时）将未过期的缓存项写入文件，
+// 创建时从文件加载缓存项并跳过已过期的项，用于进程重启后的缓存预热
+// 缓存值使用gob编码，只支持可以被gob编码的值，自定义类型需要先调用 gob.Register 注册
+type FileCache struct {
+	*BuildInMapCache
+	path      string
+	persistMu sync.Mutex // 保证同一时间只有一个持久化操作
+	close     chan struct{}
+}
+
+// NewFileCache 创建可持久化到文件的缓存
+// path: 持久化文件路径，文件不存在时创建空缓存
+// persistInterval: 定期持久化的间隔，不大于0时只在 Persist 和 Close 时持久化
+// opts: 底层 BuildInMapCache 的可选配置项
+// 返回: FileCache实例和错误信息，文件内容无法解码时返回错误
+func NewFileCache(path string, persistInterval time.Duration, opts ...BuildInMapCacheOption) (*FileCache, error) {
+	res := &FileCache{
+		BuildInMapCache: NewBuildInMapCache(0, opts...),
+		path:            path,
+		close:           make(chan struct{}),
+	}
+
+	if err := res.load(); err != nil {
+		return nil, err
+	}
+
+	if persistInterval > 0 {
+		go func() {
+			ticker := time.NewTicker(persistInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					_ = res.Persist()
+				case <-res.close:
+					return
+				}
+			}
+		}()
+	}
+
+	return res, nil
+}
+
+// Set 设置缓存值
+// 值无法被gob编码时返回 ErrValueNotEncodable，避免持久化时才发现问题
+// ctx: 上下文
+// key: 缓存键
+// val: 缓存值，必须可以被gob编码
+// expiration: 过期时间，0表示永不过期
+// 返回: 错误信息，nil表示成功
+func (f *FileCache) Set(ctx context.Context, key string, val any, expiration time.Duration) error {
+	if err := checkEncodable(key, val); err != nil {
+		return err
+	}
+	return f.BuildInMapCache.Set(ctx, key, val, expiration)
+}
+
+// SetWithDeadline 设置缓存值，并指定绝对的过期时间点
+// 值无法被gob编码时返回 ErrValueNotEncodable
+// 返回: 错误信息，nil表示成功
+func (f *FileCache) SetWithDeadline(ctx context.Context, key string, val any, deadline time.Time) error {
+	if err := checkEncodable(key, val); err != nil {
+		return err
+	}
+	return f.BuildInMapCache.SetWithDeadline(ctx, key, val, deadline)
+}
+
+// SetNX 仅当键不存在或已过期时设置缓存值
+// 值无法被gob编码时返回 ErrValueNotEncodable
+// 返回: 是否设置成功和错误信息
+func (f *FileCache) SetNX(ctx context.Context, key string, val any, expiration time.Duration) (bool, error) {
+	if err := checkEncodable(key, val); err != nil {
+		return false, err
+	}
+	return f.BuildInMapCache.SetNX(ctx, key, val, expiration)
+}
+
+// GetOrSet 获取缓存值，键不存在或已过期时存入给定的值
+// 值无法被gob编码时返回 ErrValueNotEncodable
+// 返回: 实际的缓存值、是否为已存在的值和错误信息
+func (f *FileCache) GetOrSet(ctx context.Context, key string, val any, expiration time.Duration) (any, bool, error) {
+	if err := checkEncodable(key, val); err != nil {
+		return nil, false, err
+	}
+	return f.BuildInMapCache.GetOrSet(ctx, key, val, expiration)
+}
+
+// checkEncodable 检查缓存值能否被gob编码
+func checkEncodable(key string, val any) error {
+	if err := gob.NewEncoder(io.Discard).Encode(&val); err != nil {
+		return fmt.Errorf("%w: key: %s, %v", ErrValueNotEncodable, key, err)
+	}
+	return nil
+}
+
+// Persist 将未过期的缓存项写入文件
+// 先写入同目录下的临时文件再重命名，避免写入中途失败破坏已有文件
+// 返回: 错误信息，nil表示成功
+func (f *FileCache) Persist() error {
+	f.persistMu.Lock()
+	defer f.persistMu.Unlock()
+
+	now := f.clock.Now()
+	var entries []fileCacheEntry
+	for _, sh := range f.shards {
+		sh.mutex.RLock()
+		for key, itm := range sh.data {
+			if itm.deadlineBefore(now) {
+				continue
+			}
+			entries = append(entries, fileCacheEntry{Key: key, Value: itm.val, Deadline: itm.deadline})
+		}
+		sh.mutex.RUnlock()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+
+	if err = gob.NewEncoder(tmp).Encode(entries); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("%w: %v", ErrValueNotEncodable, err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("写入临时文件失败: %w", err)
+	}
+
+	if err = os.Rename(tmp.Name(), f.path); err != nil {
+		return fmt.Errorf("替换持久化文件失败: %w", err)
+	}
+	return nil
+}
+
+// load 从文件加载缓存项，跳过已过期的项
+// 返回: 错误信息，文件不存在不算错误
+func (f *FileCache) load() error {
+	file, err := os.Open(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("打开持久化文件失败: %w", err)
+	}
+	defer file.Close()
+
+	var entries []fileCacheEntry
+	if err = gob.NewDecoder(file).Decode(&entries); err != nil {
+		return fmt.Errorf("解码持久化文件失败: %w", err)
+	}
+
+	now := f.clock.Now()
+	for _, entry := range entries {
+		itm := &item{val: entry.Value, deadline: entry.Deadline}
+		if itm.deadlineBefore(now) {
+			continue
+		}
+		sh := f.shard(entry.Key)
+		sh.mutex.Lock()
+		sh.data[entry.Key] = itm
+		f.keyAccessed(entry.Key)
+		sh.mutex.Unlock()
+	}
+	f.evictOverflow()
+	return nil
+}
+
+// Close 停止定期持久化，将缓存项写入文件并关闭缓存
+// 返回: 错误信息，nil表示成功
+// 注意: 重复关闭会返回错误
+func (f *FileCache) Close() error {
+	select {
+	case <-f.close:
+		return ErrDuplicateClose
+	default:
+		close(f.close)
+	}
+
+	if err := f.Persist(); err != nil {
+		return err
+	}
+	return f.BuildInMapCache.Close()
+}
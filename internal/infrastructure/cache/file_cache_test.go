@@ -0,0 +1,112 @@
+package cache
+
+import (
+	"context"
+	"encoding/gob"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fileCacheUser 测试持久化自定义类型
+type fileCacheUser struct {
+	Name string
+	Age  int
+}
+
+func init() {
+	gob.Register(fileCacheUser{})
+}
+
+// TestFileCache_PersistAndLoad 测试持久化后重建缓存
+func TestFileCache_PersistAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.gob")
+	ctx := context.Background()
+
+	c, err := NewFileCache(path, 0)
+	require.NoError(t, err)
+
+	require.NoError(t, c.Set(ctx, "string", "value", 0))
+	require.NoError(t, c.Set(ctx, "int", 42, time.Minute))
+	require.NoError(t, c.Set(ctx, "struct", fileCacheUser{Name: "Tom", Age: 18}, time.Minute))
+	require.NoError(t, c.Set(ctx, "short", "expiring", 20*time.Millisecond))
+	require.NoError(t, c.Close())
+
+	intItem, ok := c.lookup("int")
+	require.True(t, ok)
+	wantDeadline := intItem.deadline
+
+	// 等待短期缓存项过期后重建
+	time.Sleep(40 * time.Millisecond)
+	loaded, err := NewFileCache(path, 0)
+	require.NoError(t, err)
+	defer func() {
+		_ = loaded.Close()
+	}()
+
+	val, err := loaded.Get(ctx, "string")
+	require.NoError(t, err)
+	assert.Equal(t, "value", val)
+
+	val, err = loaded.Get(ctx, "int")
+	require.NoError(t, err)
+	assert.Equal(t, 42, val)
+
+	val, err = loaded.Get(ctx, "struct")
+	require.NoError(t, err)
+	assert.Equal(t, fileCacheUser{Name: "Tom", Age: 18}, val)
+
+	// 过期时间保持不变
+	intItem, ok = loaded.lookup("int")
+	require.True(t, ok)
+	assert.True(t, wantDeadline.Equal(intItem.deadline))
+	stringItem, ok := loaded.lookup("string")
+	require.True(t, ok)
+	assert.True(t, stringItem.deadline.IsZero())
+	// 已过期的缓存项不会被加载
+	_, ok = loaded.lookup("short")
+	assert.False(t, ok)
+}
+
+// TestFileCache_PeriodicPersist 测试定期持久化
+func TestFileCache_PeriodicPersist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.gob")
+	ctx := context.Background()
+
+	c, err := NewFileCache(path, 20*time.Millisecond)
+	require.NoError(t, err)
+	defer func() {
+		_ = c.Close()
+	}()
+	require.NoError(t, c.Set(ctx, "key", "value", time.Minute))
+
+	assert.Eventually(t, func() bool {
+		loaded, err := NewFileCache(path, 0)
+		if err != nil {
+			return false
+		}
+		val, err := loaded.Get(ctx, "key")
+		return err == nil && val == "value"
+	}, time.Second, 10*time.Millisecond)
+}
+
+// TestFileCache_NotEncodable 测试无法编码的值
+func TestFileCache_NotEncodable(t *testing.T) {
+	c, err := NewFileCache(filepath.Join(t.TempDir(), "cache.gob"), 0)
+	require.NoError(t, err)
+
+	err = c.Set(context.Background(), "func", func() {}, time.Minute)
+	assert.ErrorIs(t, err, ErrValueNotEncodable)
+	err = c.Set(context.Background(), "chan", make(chan int), time.Minute)
+	assert.ErrorIs(t, err, ErrValueNotEncodable)
+	_, err = c.SetNX(context.Background(), "func", func() {}, time.Minute)
+	assert.ErrorIs(t, err, ErrValueNotEncodable)
+
+	_, err = c.Get(context.Background(), "func")
+	assert.ErrorIs(t, err, ErrCacheKeyNotFound)
+	assert.NoError(t, c.Close())
+	assert.ErrorIs(t, c.Close(), ErrDuplicateClose)
+}
@@ -95,6 +95,64 @@ func (l *LRUPolicy) Evict(context.Context) (string, error) {
 	return tail.key, nil
 }
 
+// EvictMultiple 一次淘汰最多n个key
+// 按LRU顺序从最久未使用的key开始淘汰，只加一次锁
+// 返回值:
+//   - []string: 被淘汰的key，策略中的key不足n个时全部淘汰
+//   - error: 操作错误，nil表示成功
+func (l *LRUPolicy) EvictMultiple(_ context.Context, n int) ([]string, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	var evicted []string
+	for ; n > 0 && l.size > 0; n-- {
+		tail := l.removeTail()
+		delete(l.cache, tail.key)
+		l.size--
+		evicted = append(evicted, tail.key)
+	}
+	return evicted, nil
+}
+
+// SetCapacity 调整容量限制
+// 新容量小于当前大小时，按LRU顺序淘汰最久未使用的key直到满足新容量
+// 参数:
+//   - capacity: 新的容量限制，0表示无限制
+//
+// 返回值:
+//   - []string: 被淘汰的key，调用方需要从缓存中删除这些key
+//   - error: 容量为负数时返回ErrInvalidCapacity
+func (l *LRUPolicy) SetCapacity(_ context.Context, capacity int) ([]string, error) {
+	if capacity < 0 {
+		return nil, ErrInvalidCapacity
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.capacity = capacity
+	var evicted []string
+	for l.capacity > 0 && l.size > l.capacity {
+		tail := l.removeTail()
+		delete(l.cache, tail.key)
+		l.size--
+		evicted = append(evicted, tail.key)
+	}
+	return evicted, nil
+}
+
+// Peek 返回下一个将被淘汰的key
+// 即最久未使用的key（链表尾部），不移除
+func (l *LRUPolicy) Peek(context.Context) (string, error) {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	if l.size == 0 {
+		return "", nil
+	}
+	return l.tail.prev.key, nil
+}
+
 // Remove 移除指定key
 func (l *LRUPolicy) Remove(_ context.Context, key string) error {
 	l.mutex.Lock()
@@ -450,3 +450,137 @@ func TestLRUPolicy_Capacity(t *testing.T) {
 		assert.True(t, has)
 	})
 }
+
+// TestLRUPolicy_Peek 测试Peek返回下一个被淘汰的key且不移除
+func TestLRUPolicy_Peek(t *testing.T) {
+	ctx := context.Background()
+	policy := NewLRUPolicy()
+
+	key, err := policy.Peek(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, key)
+
+	for _, k := range []string{"key1", "key2", "key3"} {
+		require.NoError(t, policy.KeyAccessed(ctx, k))
+	}
+	// 访问key1后，key2成为最久未使用的key
+	require.NoError(t, policy.KeyAccessed(ctx, "key1"))
+
+	want := []string{"key2", "key3", "key1"}
+	for _, w := range want {
+		peeked, err := policy.Peek(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, w, peeked)
+
+		has, err := policy.Has(ctx, peeked)
+		require.NoError(t, err)
+		assert.True(t, has)
+
+		evicted, err := policy.Evict(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, peeked, evicted)
+	}
+}
+
+// TestLRUPolicy_SetCapacity 测试调整容量时按LRU顺序淘汰
+func TestLRUPolicy_SetCapacity(t *testing.T) {
+	tests := []struct {
+		name        string
+		capacity    int
+		wantEvicted []string
+		wantSize    int
+		wantErr     error
+	}{
+		{
+			name:        "缩小容量淘汰最久未使用的key",
+			capacity:    2,
+			wantEvicted: []string{"key2", "key3"},
+			wantSize:    2,
+		},
+		{
+			name:     "扩大容量不淘汰",
+			capacity: 10,
+			wantSize: 4,
+		},
+		{
+			name:     "容量为0表示无限制",
+			capacity: 0,
+			wantSize: 4,
+		},
+		{
+			name:     "负数容量",
+			capacity: -1,
+			wantSize: 4,
+			wantErr:  ErrInvalidCapacity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			policy := NewLRUPolicy(4)
+			for _, k := range []string{"key1", "key2", "key3", "key4"} {
+				require.NoError(t, policy.KeyAccessed(ctx, k))
+			}
+			// 访问key1后，最久未使用的顺序为key2、key3、key4、key1
+			require.NoError(t, policy.KeyAccessed(ctx, "key1"))
+
+			evicted, err := policy.SetCapacity(ctx, tt.capacity)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantEvicted, evicted)
+
+			size, err := policy.Size(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantSize, size)
+		})
+	}
+
+	t.Run("扩大容量后可以容纳更多key", func(t *testing.T) {
+		ctx := context.Background()
+		policy := NewLRUPolicy(1)
+		_, err := policy.SetCapacity(ctx, 3)
+		require.NoError(t, err)
+		for _, k := range []string{"key1", "key2", "key3"} {
+			require.NoError(t, policy.KeyAccessed(ctx, k))
+		}
+		size, err := policy.Size(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 3, size)
+	})
+}
+
+// TestLRUPolicy_EvictMultiple 测试一次淘汰多个key
+func TestLRUPolicy_EvictMultiple(t *testing.T) {
+	tests := []struct {
+		name        string
+		n           int
+		wantEvicted []string
+		wantSize    int
+	}{
+		{name: "淘汰最久未使用的两个key", n: 2, wantEvicted: []string{"key2", "key3"}, wantSize: 1},
+		{name: "数量超过key总数时全部淘汰", n: 5, wantEvicted: []string{"key2", "key3", "key1"}, wantSize: 0},
+		{name: "数量为0时不淘汰", n: 0, wantEvicted: nil, wantSize: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			policy := NewLRUPolicy()
+			for _, k := range []string{"key1", "key2", "key3", "key1"} {
+				require.NoError(t, policy.KeyAccessed(ctx, k))
+			}
+
+			evicted, err := policy.EvictMultiple(ctx, tt.n)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantEvicted, evicted)
+
+			size, err := policy.Size(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantSize, size)
+		})
+	}
+}
@@ -4,7 +4,10 @@ package cache
 
 import (
 	"context"
+	"errors"
+	"runtime/metrics"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	domainCache "github.com/justinwongcn/hamster/internal/domain/cache"
@@ -19,6 +22,32 @@ type MaxMemoryCache struct {
 	used   int64                  // 当前已使用内存(字节)，仅计算缓存值本身大小
 	mutex  *sync.Mutex            // 互斥锁保证并发安全
 	policy EvictionPolicy         // 淘汰策略
+	// preferExpired 内存不足时是否先回收已过期的缓存项，再按策略淘汰
+	preferExpired bool
+	// evictions 因内存不足被策略淘汰的缓存项数量
+	evictions atomic.Int64
+	// evictBatch 每轮淘汰向策略请求的key数量，默认为1
+	evictBatch int
+	// onEvictedBatch 一次Set因内存不足淘汰一批缓存项后的回调函数，nil表示未设置
+	onEvictedBatch func(items map[string]any)
+	// pressureThreshold 进程堆内存的阈值(字节)，超过时即使未达到max也主动淘汰，0表示不启用
+	pressureThreshold uint64
+	// heapBytes 获取进程当前的堆内存(字节)，用于判断内存压力
+	heapBytes func() uint64
+}
+
+// heapObjectsMetric 堆上存活和尚未回收的对象占用的内存
+const heapObjectsMetric = "/memory/classes/heap/objects:bytes"
+
+// readHeapBytes 读取进程当前的堆内存(字节)
+// 使用 runtime/metrics 读取，与 runtime.ReadMemStats 不同，不需要暂停所有goroutine
+func readHeapBytes() uint64 {
+	sample := []metrics.Sample{{Name: heapObjectsMetric}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return sample[0].Value.Uint64()
 }
 
 // NewMaxMemoryCache 创建新的MaxMemoryCache实例
@@ -37,10 +66,12 @@ type MaxMemoryCache struct {
 //	创建带内存限制的缓存实例，支持自定义淘汰策略
 func NewMaxMemoryCache(max int64, cache domainCache.Repository, policy ...EvictionPolicy) *MaxMemoryCache {
 	res := &MaxMemoryCache{
-		max:    max,
-		Cache:  cache,
-		mutex:  &sync.Mutex{},
-		policy: NewLRUPolicy(), // 默认使用LRU策略
+		max:        max,
+		Cache:      cache,
+		mutex:      &sync.Mutex{},
+		policy:     NewLRUPolicy(), // 默认使用LRU策略
+		evictBatch: 1,
+		heapBytes:  readHeapBytes,
 	}
 	// 如果提供了自定义策略，则使用自定义策略
 	if len(policy) > 0 && policy[0] != nil {
@@ -105,17 +136,33 @@ func NewMaxMemoryCacheWithRandom(max int64, cache domainCache.Repository) *MaxMe
 func (m *MaxMemoryCache) Set(ctx context.Context, key string, val []byte,
 	expiration time.Duration,
 ) error {
+	// 批量回调在释放锁之后调用，避免回调中的I/O阻塞其他操作
+	var fn func(items map[string]any)
+	var victims map[string]any
+	defer func() {
+		if len(victims) > 0 {
+			fn(victims)
+		}
+	}()
+
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
-	// 先删除可能存在的旧键，避免内存泄露
-	oldVal, err := m.Cache.LoadAndDelete(ctx, key)
-	if err == nil && oldVal != nil {
-		m.evicted(key, oldVal)
+	if m.onEvictedBatch != nil {
+		fn, victims = m.onEvictedBatch, make(map[string]any)
+	}
+
+	// 进程内存压力高时，在写入前按策略淘汰一轮已有的缓存项，使缓存不再增长
+	if m.underPressure() {
+		m.evictRound(ctx, victims)
 	}
 
+	// 先删除可能存在的旧键，避免内存泄露
+	// 内存统计和淘汰策略由底层缓存的淘汰回调更新，这里不能再次扣除，否则覆盖写入时会重复扣除旧值大小
+	_, _ = m.Cache.LoadAndDelete(ctx, key)
+
 	// 将新键值对存入底层缓存
-	err = m.Cache.Set(ctx, key, val, expiration)
+	err := m.Cache.Set(ctx, key, val, expiration)
 	if err == nil {
 		// 更新已使用内存大小
 		m.used = m.used + int64(len(val))
@@ -123,20 +170,148 @@ func (m *MaxMemoryCache) Set(ctx context.Context, key string, val []byte,
 		_ = m.policy.KeyAccessed(ctx, key)
 	}
 
+	// 优先回收已过期的缓存项，避免淘汰仍然有效的数据
+	if m.used > m.max && m.preferExpired {
+		m.reclaimExpired(ctx)
+	}
+
 	// 如果添加新值后超出最大内存限制，则执行淘汰策略
 	for m.used > m.max {
-		// 调用淘汰策略获取要删除的键
-		k, evictErr := m.policy.Evict(ctx)
-		if evictErr != nil || k == "" {
+		if !m.evictRound(ctx, victims) {
 			break // 没有可淘汰的键或出错，退出循环
 		}
-		// 从底层缓存中删除选中的键
-		_ = m.Cache.Delete(ctx, k)
 	}
 
 	return err
 }
 
+// evictRound 按淘汰策略淘汰一轮缓存项
+// victims 不为nil时记录被淘汰的键值
+// 返回值:
+//   - bool: 是否还可以继续淘汰，策略出错或没有可淘汰的键时返回false
+//
+// 注意: 此方法应在持有锁的情况下调用
+func (m *MaxMemoryCache) evictRound(ctx context.Context, victims map[string]any) bool {
+	// 调用淘汰策略获取要删除的键
+	keys, evictErr := m.evictKeys(ctx)
+	for _, k := range keys {
+		// 从底层缓存中删除选中的键，只有键确实存在时才算一次淘汰
+		if val, delErr := m.Cache.LoadAndDelete(ctx, k); delErr == nil {
+			m.evictions.Add(1)
+			if victims != nil {
+				victims[k] = val
+			}
+		}
+	}
+	return evictErr == nil && len(keys) > 0
+}
+
+// underPressure 判断进程堆内存是否超过阈值
+// 注意: 此方法应在持有锁的情况下调用
+func (m *MaxMemoryCache) underPressure() bool {
+	return m.pressureThreshold > 0 && m.used > 0 && m.heapBytes() > m.pressureThreshold
+}
+
+// SetMemoryPressure 设置按进程内存压力主动淘汰
+// 启用后，每次Set前检查进程的堆内存，超过阈值时即使未达到max也先按淘汰策略淘汰一轮已有的缓存项，
+// 使缓存在内存压力高时不再增长；配合 SetEvictBatch 设置大于1的数量可以让缓存逐步收缩。
+// 压力解除后恢复只按max淘汰
+// 参数:
+//   - threshold: 堆内存阈值(字节)，为0时关闭，默认关闭
+//   - heapBytes: 获取当前堆内存(字节)的函数，为nil时通过 runtime/metrics 读取进程的堆对象内存
+func (m *MaxMemoryCache) SetMemoryPressure(threshold uint64, heapBytes func() uint64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.pressureThreshold = threshold
+	if heapBytes == nil {
+		heapBytes = readHeapBytes
+	}
+	m.heapBytes = heapBytes
+}
+
+// evictKeys 从淘汰策略取出一轮要淘汰的键
+// 策略实现了 EvictMultiple 时一次取出evictBatch个键，否则逐个调用Evict
+// 注意: 此方法应在持有锁的情况下调用
+func (m *MaxMemoryCache) evictKeys(ctx context.Context) ([]string, error) {
+	if p, ok := m.policy.(interface {
+		EvictMultiple(ctx context.Context, n int) ([]string, error)
+	}); ok {
+		return p.EvictMultiple(ctx, m.evictBatch)
+	}
+
+	keys := make([]string, 0, m.evictBatch)
+	for len(keys) < m.evictBatch {
+		k, err := m.policy.Evict(ctx)
+		if err != nil {
+			return keys, err
+		}
+		if k == "" {
+			break
+		}
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+// EvictionCount 获取因内存不足被淘汰策略淘汰的缓存项数量
+// 只统计实际从底层缓存删除的键，回收已过期的缓存项和主动删除不计入
+// 返回值:
+//   - int64: 淘汰次数
+func (m *MaxMemoryCache) EvictionCount() int64 {
+	return m.evictions.Load()
+}
+
+// Used 获取当前已使用的内存(字节)
+// 返回值:
+//   - int64: 已使用内存
+func (m *MaxMemoryCache) Used() int64 {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.used
+}
+
+// Max 获取最大内存限制(字节)
+// 返回值:
+//   - int64: 最大内存限制
+func (m *MaxMemoryCache) Max() int64 {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.max
+}
+
+// SetPreferExpired 设置内存不足时是否优先回收已过期的缓存项
+// 启用后，Set在超出内存限制时先删除所有已过期的缓存项，仍然超出时才按淘汰策略淘汰
+// 需要底层缓存实现 DeleteExpired(ctx) int 方法（如BuildInMapCache），否则不生效
+// 参数:
+//   - prefer: 是否优先回收已过期的缓存项，默认关闭
+func (m *MaxMemoryCache) SetPreferExpired(prefer bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.preferExpired = prefer
+}
+
+// SetEvictBatch 设置内存不足时每轮淘汰的缓存项数量
+// 写入压力大时一次淘汰多个键可以减少对淘汰策略的调用次数，代价是可能多淘汰一些缓存项，
+// 每轮淘汰后仍会检查内存，直到不超过max限制
+// 参数:
+//   - n: 每轮淘汰的数量，小于1时视为1，默认为1即每次只淘汰一个键
+func (m *MaxMemoryCache) SetEvictBatch(n int) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.evictBatch = max(n, 1)
+}
+
+// reclaimExpired 删除底层缓存中已过期的缓存项
+// 内存统计和淘汰策略通过淘汰回调同步更新
+// 注意: 此方法应在持有锁的情况下调用
+func (m *MaxMemoryCache) reclaimExpired(ctx context.Context) {
+	if c, ok := m.Cache.(interface {
+		DeleteExpired(ctx context.Context) int
+	}); ok {
+		c.DeleteExpired(ctx)
+	}
+}
+
 // Get 获取缓存值
 // 会更新key的访问时间以维护LRU淘汰顺序
 // 参数:
@@ -158,9 +333,8 @@ func (m *MaxMemoryCache) Get(ctx context.Context, key string) (any, error) {
 	// 从底层缓存获取值
 	val, err := m.Cache.Get(ctx, key)
 	if err == nil {
-		// 从策略中移除键（用于更新访问顺序）
-		_ = m.policy.Remove(ctx, key)
-		// 通知策略该键已被访问
+		// 通知策略该键已被访问，由策略决定是否调整淘汰顺序
+		// LRU会将键移到最近使用的位置，FIFO和随机策略保持原有顺序
 		_ = m.policy.KeyAccessed(ctx, key)
 
 		return val, nil
@@ -168,6 +342,39 @@ func (m *MaxMemoryCache) Get(ctx context.Context, key string) (any, error) {
 	return nil, err
 }
 
+// Exists 检查键是否存在且未过期
+// 与Get不同，不会通知淘汰策略，因此不会改变LRU等策略的淘汰顺序
+// 参数:
+//   - ctx: 上下文
+//   - key: 缓存键
+//
+// 返回值:
+//   - bool: 键是否存在
+//   - error: 操作错误信息
+//
+// 功能:
+//   - 底层缓存实现了Exists时直接调用
+//   - 否则通过底层缓存的Get判断，键不存在不算错误
+func (m *MaxMemoryCache) Exists(ctx context.Context, key string) (bool, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if c, ok := m.Cache.(interface {
+		Exists(ctx context.Context, key string) (bool, error)
+	}); ok {
+		return c.Exists(ctx, key)
+	}
+
+	_, err := m.Cache.Get(ctx, key)
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, ErrCacheKeyNotFound) || errors.Is(err, domainCache.ErrKeyNotFound) {
+		return false, nil
+	}
+	return false, err
+}
+
 // Delete 删除指定缓存项
 // 参数:
 //   - ctx: 上下文
@@ -179,10 +386,15 @@ func (m *MaxMemoryCache) Get(ctx context.Context, key string) (any, error) {
 // 功能:
 //   - 从缓存中删除指定键
 //   - 更新内存使用统计
+//   - 从淘汰策略中移除键，即使底层缓存没有触发淘汰回调也保持同步
 func (m *MaxMemoryCache) Delete(ctx context.Context, key string) error {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
-	return m.Cache.Delete(ctx, key)
+	if err := m.Cache.Delete(ctx, key); err != nil {
+		return err
+	}
+	_ = m.policy.Remove(ctx, key)
+	return nil
 }
 
 // LoadAndDelete 获取并删除缓存项
@@ -198,6 +410,7 @@ func (m *MaxMemoryCache) Delete(ctx context.Context, key string) error {
 //   - 原子性地获取并删除指定键
 //   - 更新内存使用统计
 //   - 处理类型断言错误
+//   - 从淘汰策略中移除键
 func (m *MaxMemoryCache) LoadAndDelete(ctx context.Context, key string) (any, error) {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
@@ -205,6 +418,7 @@ func (m *MaxMemoryCache) LoadAndDelete(ctx context.Context, key string) (any, er
 	// 从底层缓存获取并删除值
 	val, err := m.Cache.LoadAndDelete(ctx, key)
 	if err == nil {
+		_ = m.policy.Remove(ctx, key)
 		return val, nil
 	}
 	return nil, err
@@ -227,6 +441,18 @@ func (m *MaxMemoryCache) OnEvicted(fn func(key string, val any)) {
 	})
 }
 
+// OnEvictedBatch 设置一批缓存项被淘汰后的回调函数
+// 一次Set因内存不足按淘汰策略删除的所有缓存项会一次性交给回调，
+// 适合需要批量持久化淘汰数据的场景；回收已过期的缓存项和主动删除不触发批量回调
+// 回调在Set释放锁之后调用，逐个的OnEvicted回调照常触发
+// 参数:
+//   - fn: 回调函数，参数为被淘汰的键值对，为nil时取消批量回调
+func (m *MaxMemoryCache) OnEvictedBatch(fn func(items map[string]any)) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.onEvictedBatch = fn
+}
+
 // evicted 处理缓存项淘汰逻辑
 // 当缓存项被淘汰时调用，更新内存统计并从策略中移除key
 func (m *MaxMemoryCache) evicted(key string, val any) {
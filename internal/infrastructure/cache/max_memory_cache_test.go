@@ -835,3 +835,435 @@ func TestMaxMemoryCache_LoadAndDelete_EvictedUpdate(t *testing.T) {
 	// 验证内存使用减少
 	assert.Less(t, maxCache.used, initialUsed)
 }
+
+// TestMaxMemoryCache_Exists 测试Exists不改变LRU淘汰顺序
+// 参数:
+//   - t: 测试上下文
+//
+// 功能:
+//   - 验证Exists对存在、不存在的键返回正确结果
+//   - 验证Exists不会把键标记为最近使用
+func TestMaxMemoryCache_Exists(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMaxMemoryCache(12, NewBuildInMapCache(0), NewLRUPolicy())
+
+	assert.NoError(t, cache.Set(ctx, "key1", []byte("value1"), time.Minute))
+	assert.NoError(t, cache.Set(ctx, "key2", []byte("value2"), time.Minute))
+
+	ok, err := cache.Exists(ctx, "key1")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = cache.Exists(ctx, "not_exist")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// key1仍是最久未使用的键，写入key3时被淘汰
+	assert.NoError(t, cache.Set(ctx, "key3", []byte("value3"), time.Minute))
+
+	ok, err = cache.Exists(ctx, "key1")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = cache.Exists(ctx, "key2")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+// TestMaxMemoryCache_Get_UpdatesEvictionOrder 测试Get按策略更新淘汰顺序
+// 参数:
+//   - t: 测试上下文
+//
+// 功能:
+//   - 写满缓存后Get最早写入的键，再写入新键触发淘汰
+//   - LRU策略下被访问的键保留，次早写入的键被淘汰
+//   - FIFO策略下访问不改变顺序，最早写入的键被淘汰
+func TestMaxMemoryCache_Get_UpdatesEvictionOrder(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      EvictionPolicy
+		wantEvicted string
+		wantKept    []string
+	}{
+		{
+			name:        "LRU策略",
+			policy:      NewLRUPolicy(),
+			wantEvicted: "key2",
+			wantKept:    []string{"key1", "key3", "key4"},
+		},
+		{
+			name:        "FIFO策略",
+			policy:      NewFIFOPolicy(),
+			wantEvicted: "key1",
+			wantKept:    []string{"key2", "key3", "key4"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			cache := NewMaxMemoryCache(18, NewBuildInMapCache(0), tt.policy)
+
+			for _, key := range []string{"key1", "key2", "key3"} {
+				assert.NoError(t, cache.Set(ctx, key, []byte("value1"), time.Minute))
+			}
+
+			_, err := cache.Get(ctx, "key1")
+			assert.NoError(t, err)
+
+			assert.NoError(t, cache.Set(ctx, "key4", []byte("value4"), time.Minute))
+
+			ok, err := cache.Exists(ctx, tt.wantEvicted)
+			assert.NoError(t, err)
+			assert.False(t, ok)
+			for _, key := range tt.wantKept {
+				ok, err = cache.Exists(ctx, key)
+				assert.NoError(t, err)
+				assert.True(t, ok, key)
+			}
+		})
+	}
+}
+
+// TestMaxMemoryCache_Delete_SyncsPolicy 测试删除键后淘汰策略保持同步
+// 参数:
+//   - t: 测试上下文
+//
+// 功能:
+//   - 模拟不触发淘汰回调的底层缓存
+//   - 验证Delete和LoadAndDelete后策略的Size和Has反映删除结果
+//   - 验证被删除的键不会再被Evict返回
+func TestMaxMemoryCache_Delete_SyncsPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		delete func(*MaxMemoryCache, string) error
+	}{
+		{
+			name: "Delete",
+			delete: func(c *MaxMemoryCache, key string) error {
+				return c.Delete(context.Background(), key)
+			},
+		},
+		{
+			name: "LoadAndDelete",
+			delete: func(c *MaxMemoryCache, key string) error {
+				_, err := c.LoadAndDelete(context.Background(), key)
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			mock := &mockCache{data: make(map[string]any)}
+			policy := NewFIFOPolicy()
+			cache := NewMaxMemoryCache(100, mock, policy)
+			// 底层缓存不再触发淘汰回调
+			mock.fn = nil
+
+			for _, key := range []string{"key1", "key2", "key3"} {
+				assert.NoError(t, cache.Set(ctx, key, []byte("value"), time.Minute))
+			}
+
+			assert.NoError(t, tt.delete(cache, "key1"))
+
+			size, err := policy.Size(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, 2, size)
+			has, err := policy.Has(ctx, "key1")
+			assert.NoError(t, err)
+			assert.False(t, has)
+
+			key, err := policy.Evict(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, "key2", key)
+		})
+	}
+}
+
+// TestMaxMemoryCache_PreferExpired 测试内存不足时优先回收已过期的缓存项
+// 参数:
+//   - t: 测试上下文
+//
+// 功能:
+//   - 混合写入已过期和未过期的缓存项后触发淘汰
+//   - 启用后已过期的缓存项先被回收，未过期的缓存项保留
+//   - 关闭时按LRU顺序淘汰，即使最久未使用的缓存项仍然有效
+func TestMaxMemoryCache_PreferExpired(t *testing.T) {
+	tests := []struct {
+		name          string
+		preferExpired bool
+		wantKept      []string
+		wantGone      []string
+	}{
+		{
+			name:          "优先回收已过期的缓存项",
+			preferExpired: true,
+			wantKept:      []string{"fresh1", "fresh2", "new"},
+			wantGone:      []string{"expired1", "expired2"},
+		},
+		{
+			name:          "按策略淘汰",
+			preferExpired: false,
+			wantKept:      []string{"fresh2", "new"},
+			wantGone:      []string{"fresh1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			clock := newFakeClock()
+			cache := NewMaxMemoryCache(24, NewBuildInMapCache(0, BuildInMapCacheWithClock(clock)), NewLRUPolicy())
+			cache.SetPreferExpired(tt.preferExpired)
+
+			// fresh1是最久未使用的缓存项
+			assert.NoError(t, cache.Set(ctx, "fresh1", []byte("value1"), time.Hour))
+			assert.NoError(t, cache.Set(ctx, "expired1", []byte("value2"), time.Second))
+			assert.NoError(t, cache.Set(ctx, "expired2", []byte("value3"), time.Second))
+			assert.NoError(t, cache.Set(ctx, "fresh2", []byte("value4"), time.Hour))
+			clock.Advance(time.Minute)
+
+			assert.NoError(t, cache.Set(ctx, "new", []byte("value5"), time.Hour))
+
+			for _, key := range tt.wantKept {
+				ok, err := cache.Exists(ctx, key)
+				assert.NoError(t, err)
+				assert.True(t, ok, key)
+			}
+			for _, key := range tt.wantGone {
+				ok, err := cache.Exists(ctx, key)
+				assert.NoError(t, err)
+				assert.False(t, ok, key)
+			}
+			assert.LessOrEqual(t, cache.used, cache.max)
+		})
+	}
+}
+
+// TestMaxMemoryCache_Set_Overwrite 测试覆盖写入时只扣除一次旧值大小
+func TestMaxMemoryCache_Set_Overwrite(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMaxMemoryCache(10, NewBuildInMapCache(0))
+
+	assert.NoError(t, cache.Set(ctx, "key1", []byte("12345"), time.Minute))
+	assert.NoError(t, cache.Set(ctx, "key2", []byte("123"), time.Minute))
+	assert.Equal(t, int64(8), cache.Used())
+
+	// 覆盖为更短和更长的值，已使用内存按新值大小计算
+	assert.NoError(t, cache.Set(ctx, "key1", []byte("12"), time.Minute))
+	assert.Equal(t, int64(5), cache.Used())
+	assert.NoError(t, cache.Set(ctx, "key1", []byte("1234567"), time.Minute))
+	assert.Equal(t, int64(10), cache.Used())
+	assert.Equal(t, int64(0), cache.EvictionCount())
+
+	val, err := cache.Get(ctx, "key2")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("123"), val)
+}
+
+// TestMaxMemoryCache_EvictionCount 测试淘汰次数和内存使用统计
+func TestMaxMemoryCache_EvictionCount(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMaxMemoryCache(10, NewBuildInMapCache(0))
+	assert.Equal(t, int64(10), cache.Max())
+
+	// 每个值5字节，最多容纳2个
+	for i := range 6 {
+		assert.NoError(t, cache.Set(ctx, fmt.Sprintf("key%d", i), []byte("12345"), time.Minute))
+	}
+	assert.Equal(t, int64(4), cache.EvictionCount())
+	assert.Equal(t, int64(10), cache.Used())
+
+	// 主动删除和覆盖不计入淘汰次数
+	assert.NoError(t, cache.Delete(ctx, "key5"))
+	assert.NoError(t, cache.Set(ctx, "key4", []byte("123"), time.Minute))
+	assert.Equal(t, int64(4), cache.EvictionCount())
+	assert.Equal(t, int64(3), cache.Used())
+
+	// 一次写入淘汰多个键
+	assert.NoError(t, cache.Set(ctx, "key6", []byte("1234567890"), time.Minute))
+	assert.Equal(t, int64(5), cache.EvictionCount())
+	assert.Equal(t, int64(10), cache.Used())
+}
+
+// TestMaxMemoryCache_EvictionCount_MissingCandidate 测试策略选出的键不在底层缓存中时不计入淘汰次数
+func TestMaxMemoryCache_EvictionCount_MissingCandidate(t *testing.T) {
+	ctx := context.Background()
+	mock := &mockCache{data: make(map[string]any)}
+	cache := NewMaxMemoryCache(10, mock)
+
+	assert.NoError(t, cache.Set(ctx, "key1", []byte("12345"), time.Minute))
+	// 策略中残留一个底层缓存中已不存在的键
+	assert.NoError(t, cache.policy.KeyAccessed(ctx, "ghost"))
+	assert.NoError(t, cache.policy.KeyAccessed(ctx, "key1"))
+
+	assert.NoError(t, cache.Set(ctx, "key2", []byte("123456"), time.Minute))
+	assert.Equal(t, int64(1), cache.EvictionCount())
+	assert.Equal(t, int64(6), cache.Used())
+}
+
+// singleEvictPolicy 只实现 EvictionPolicy 接口，没有 EvictMultiple 的策略
+type singleEvictPolicy struct {
+	EvictionPolicy
+}
+
+// TestMaxMemoryCache_EvictBatch 测试不同的淘汰批量下内存都不超过max限制
+func TestMaxMemoryCache_EvictBatch(t *testing.T) {
+	policies := []struct {
+		name      string
+		newPolicy func() EvictionPolicy
+	}{
+		{"LRU", func() EvictionPolicy { return NewLRUPolicy() }},
+		{"FIFO", func() EvictionPolicy { return NewFIFOPolicy() }},
+		{"Random", func() EvictionPolicy { return NewRandomPolicy() }},
+		{"不支持批量淘汰的策略", func() EvictionPolicy { return singleEvictPolicy{NewLRUPolicy()} }},
+	}
+
+	for _, p := range policies {
+		for _, batch := range []int{0, 1, 3, 8, 100} {
+			t.Run(fmt.Sprintf("%s_%d", p.name, batch), func(t *testing.T) {
+				ctx := context.Background()
+				cache := NewMaxMemoryCache(50, NewBuildInMapCache(0), p.newPolicy())
+				cache.SetEvictBatch(batch)
+
+				for i := range 200 {
+					val := make([]byte, 1+i%9)
+					assert.NoError(t, cache.Set(ctx, fmt.Sprintf("key%d", i%40), val, time.Minute))
+					assert.LessOrEqual(t, cache.Used(), cache.Max())
+				}
+				assert.Positive(t, cache.EvictionCount())
+			})
+		}
+	}
+
+	t.Run("一次超出时淘汰一批键", func(t *testing.T) {
+		ctx := context.Background()
+		cache := NewMaxMemoryCache(10, NewBuildInMapCache(0))
+		cache.SetEvictBatch(3)
+
+		for i := range 5 {
+			assert.NoError(t, cache.Set(ctx, fmt.Sprintf("key%d", i), []byte("12"), time.Minute))
+		}
+		// 写入key5后超出1字节，按LRU顺序一次淘汰key0、key1、key2
+		assert.NoError(t, cache.Set(ctx, "key5", []byte("12"), time.Minute))
+		assert.Equal(t, int64(3), cache.EvictionCount())
+		assert.Equal(t, int64(6), cache.Used())
+		for i, want := range []bool{false, false, false, true, true, true} {
+			ok, err := cache.Exists(ctx, fmt.Sprintf("key%d", i))
+			assert.NoError(t, err)
+			assert.Equal(t, want, ok)
+		}
+	})
+}
+
+// TestMaxMemoryCache_OnEvictedBatch 测试一次Set淘汰多个键时批量回调一次收到所有被淘汰的键
+func TestMaxMemoryCache_OnEvictedBatch(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMaxMemoryCache(10, NewBuildInMapCache(0))
+	var single []string
+	var batches []map[string]any
+	cache.OnEvicted(func(key string, _ any) {
+		single = append(single, key)
+	})
+	cache.OnEvictedBatch(func(items map[string]any) {
+		batches = append(batches, items)
+	})
+
+	for i := range 5 {
+		assert.NoError(t, cache.Set(ctx, fmt.Sprintf("key%d", i), []byte("12"), time.Minute))
+	}
+	assert.Empty(t, batches)
+
+	// 写入8字节需要淘汰4个键
+	assert.NoError(t, cache.Set(ctx, "big", []byte("12345678"), time.Minute))
+	assert.Equal(t, []map[string]any{{
+		"key0": []byte("12"),
+		"key1": []byte("12"),
+		"key2": []byte("12"),
+		"key3": []byte("12"),
+	}}, batches)
+	assert.Equal(t, []string{"key0", "key1", "key2", "key3"}, single)
+	assert.Equal(t, int64(10), cache.Used())
+
+	// 覆盖写入不超出内存时不触发
+	assert.NoError(t, cache.Set(ctx, "key4", []byte("1"), time.Minute))
+	assert.Len(t, batches, 1)
+
+	// 取消批量回调
+	cache.OnEvictedBatch(nil)
+	assert.NoError(t, cache.Set(ctx, "big2", []byte("123456789"), time.Minute))
+	assert.Len(t, batches, 1)
+}
+
+// TestMaxMemoryCache_MemoryPressure 测试进程内存压力高时未达到max也主动淘汰，压力解除后停止
+func TestMaxMemoryCache_MemoryPressure(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMaxMemoryCache(1024, NewBuildInMapCache(0))
+	var heap uint64
+	cache.SetMemoryPressure(1000, func() uint64 { return heap })
+
+	exists := func(keys ...string) []bool {
+		res := make([]bool, 0, len(keys))
+		for _, key := range keys {
+			ok, err := cache.Exists(ctx, key)
+			assert.NoError(t, err)
+			res = append(res, ok)
+		}
+		return res
+	}
+
+	// 没有压力时只按max淘汰
+	heap = 500
+	for i := range 5 {
+		assert.NoError(t, cache.Set(ctx, fmt.Sprintf("key%d", i), []byte("12"), time.Minute))
+	}
+	assert.Equal(t, int64(10), cache.Used())
+	assert.Equal(t, int64(0), cache.EvictionCount())
+
+	// 压力高时每次写入前按LRU淘汰一个已有的键，缓存不再增长
+	heap = 2000
+	for i := 5; i < 8; i++ {
+		assert.NoError(t, cache.Set(ctx, fmt.Sprintf("key%d", i), []byte("12"), time.Minute))
+	}
+	assert.Equal(t, int64(10), cache.Used())
+	assert.Equal(t, int64(3), cache.EvictionCount())
+	assert.Equal(t, []bool{false, false, false, true, true, true, true, true},
+		exists("key0", "key1", "key2", "key3", "key4", "key5", "key6", "key7"))
+
+	// 每轮淘汰多个键时缓存逐步收缩
+	cache.SetEvictBatch(2)
+	assert.NoError(t, cache.Set(ctx, "key8", []byte("12"), time.Minute))
+	assert.Equal(t, int64(8), cache.Used())
+	assert.Equal(t, []bool{false, false, true, true, true}, exists("key3", "key4", "key5", "key6", "key8"))
+
+	// 压力解除后不再主动淘汰
+	heap = 800
+	for i := 9; i < 12; i++ {
+		assert.NoError(t, cache.Set(ctx, fmt.Sprintf("key%d", i), []byte("12"), time.Minute))
+	}
+	assert.Equal(t, int64(14), cache.Used())
+	assert.Equal(t, int64(5), cache.EvictionCount())
+
+	// 阈值为0时关闭
+	heap = 2000
+	cache.SetMemoryPressure(0, func() uint64 { return heap })
+	assert.NoError(t, cache.Set(ctx, "key12", []byte("12"), time.Minute))
+	assert.Equal(t, int64(16), cache.Used())
+	assert.Equal(t, int64(5), cache.EvictionCount())
+}
+
+// TestMaxMemoryCache_MemoryPressure_Default 测试默认读取进程的堆内存
+func TestMaxMemoryCache_MemoryPressure_Default(t *testing.T) {
+	ctx := context.Background()
+	assert.Positive(t, readHeapBytes())
+
+	// 阈值为1字节时进程始终处于压力之下
+	cache := NewMaxMemoryCache(1024, NewBuildInMapCache(0))
+	cache.SetMemoryPressure(1, nil)
+	assert.NoError(t, cache.Set(ctx, "key1", []byte("12"), time.Minute))
+	assert.NoError(t, cache.Set(ctx, "key2", []byte("12"), time.Minute))
+	assert.Equal(t, int64(2), cache.Used())
+	assert.Equal(t, int64(1), cache.EvictionCount())
+}
@@ -0,0 +1,157 @@
+package cache
+
+import (
+	"context"
+	"math/bits"
+	"sync"
+
+	domainCache "github.com/justinwongcn/hamster/internal/domain/cache"
+)
+
+var _ domainCache.BloomFilter = &PartitionedBloomFilter{}
+
+// PartitionedBloomFilter 分区布隆过滤器
+// 将位数组平均分为k个分区，第i个哈希函数只设置第i个分区中的位，
+// 各分区的填充率相互独立且更可预测，查询时每个分区只访问一段连续的内存，局部性更好
+// 线程安全，支持并发访问
+type PartitionedBloomFilter struct {
+	config domainCache.BloomFilterConfig
+	// partitionBits 每个分区的位数，按字节对齐，使分区之间不共享字节
+	partitionBits uint64
+	bitArray      []byte
+	addedCount    uint64
+	mu            sync.RWMutex
+}
+
+// NewPartitionedBloomFilter 创建分区布隆过滤器
+// 总位数与 InMemoryBloomFilter 相同，分区按字节对齐时最多多占用k个字节
+// config: 布隆过滤器配置，分区数为哈希函数数量
+// 返回: PartitionedBloomFilter实例
+func NewPartitionedBloomFilter(config domainCache.BloomFilterConfig) *PartitionedBloomFilter {
+	k := config.HashFunctions()
+	partitionBytes := (config.BitArraySize() + k - 1) / k
+	partitionBytes = (partitionBytes + 7) / 8
+	return &PartitionedBloomFilter{
+		config:        config,
+		partitionBits: partitionBytes * 8,
+		bitArray:      make([]byte, partitionBytes*k),
+	}
+}
+
+// Add 添加元素到布隆过滤器
+// ctx: 上下文
+// key: 要添加的键
+// 返回: 操作错误
+func (bf *PartitionedBloomFilter) Add(ctx context.Context, key string) error {
+	bfKey, err := domainCache.NewBloomFilterKey(key)
+	if err != nil {
+		return err
+	}
+
+	bf.mu.Lock()
+	defer bf.mu.Unlock()
+	for i := uint64(0); i < bf.config.HashFunctions(); i++ {
+		bitIndex := bf.bitIndex(bfKey, i)
+		bf.bitArray[bitIndex/8] |= 1 << (bitIndex % 8)
+	}
+	bf.addedCount++
+	return nil
+}
+
+// HasKey 检查键是否可能存在
+// ctx: 上下文
+// key: 要检查的键
+// 返回: 是否可能存在（true表示可能存在，false表示一定不存在）
+func (bf *PartitionedBloomFilter) HasKey(ctx context.Context, key string) bool {
+	bfKey, err := domainCache.NewBloomFilterKey(key)
+	if err != nil {
+		return false
+	}
+
+	bf.mu.RLock()
+	defer bf.mu.RUnlock()
+	for i := uint64(0); i < bf.config.HashFunctions(); i++ {
+		bitIndex := bf.bitIndex(bfKey, i)
+		if bf.bitArray[bitIndex/8]&(1<<(bitIndex%8)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Clear 清空布隆过滤器
+// ctx: 上下文
+// 返回: 操作错误
+func (bf *PartitionedBloomFilter) Clear(ctx context.Context) error {
+	bf.mu.Lock()
+	defer bf.mu.Unlock()
+	clear(bf.bitArray)
+	bf.addedCount = 0
+	return nil
+}
+
+// Stats 获取布隆过滤器统计信息
+// 已设置的位数为所有分区之和，各分区的填充率通过 PartitionFill 获取
+// ctx: 上下文
+// 返回: 统计信息和错误
+func (bf *PartitionedBloomFilter) Stats(ctx context.Context) (domainCache.BloomFilterStats, error) {
+	bf.mu.RLock()
+	defer bf.mu.RUnlock()
+
+	var setBits uint64
+	for _, n := range bf.partitionSetBits() {
+		setBits += n
+	}
+	return domainCache.NewBloomFilterStats(bf.config, bf.addedCount, setBits), nil
+}
+
+// PartitionFill 获取每个分区的填充率
+// ctx: 上下文
+// 返回: 第i个元素为第i个分区中已设置的位数占分区位数的比例
+func (bf *PartitionedBloomFilter) PartitionFill(ctx context.Context) []float64 {
+	bf.mu.RLock()
+	defer bf.mu.RUnlock()
+
+	setBits := bf.partitionSetBits()
+	fill := make([]float64, len(setBits))
+	for i, n := range setBits {
+		fill[i] = float64(n) / float64(bf.partitionBits)
+	}
+	return fill
+}
+
+// EstimateFalsePositiveRate 估算当前假阳性率
+// 未添加的键在每个分区中命中已设置位的概率即该分区的填充率，假阳性率为各分区填充率之积
+// ctx: 上下文
+// 返回: 假阳性率和错误
+func (bf *PartitionedBloomFilter) EstimateFalsePositiveRate(ctx context.Context) (float64, error) {
+	rate := 1.0
+	for _, fill := range bf.PartitionFill(ctx) {
+		rate *= fill
+	}
+	return rate, nil
+}
+
+// GetConfig 获取配置信息（用于测试和调试）
+// 返回: 布隆过滤器配置
+func (bf *PartitionedBloomFilter) GetConfig() domainCache.BloomFilterConfig {
+	return bf.config
+}
+
+// bitIndex 计算第i个哈希函数在位数组中的位置，位于第i个分区内
+func (bf *PartitionedBloomFilter) bitIndex(key domainCache.BloomFilterKey, i uint64) uint64 {
+	return i*bf.partitionBits + key.Hash(i)%bf.partitionBits
+}
+
+// partitionSetBits 计算每个分区中已设置的位数
+// 注意: 此方法应在持有锁的情况下调用
+func (bf *PartitionedBloomFilter) partitionSetBits() []uint64 {
+	partitionBytes := bf.partitionBits / 8
+	res := make([]uint64, bf.config.HashFunctions())
+	for i := range res {
+		for _, b := range bf.bitArray[uint64(i)*partitionBytes : uint64(i+1)*partitionBytes] {
+			res[i] += uint64(bits.OnesCount8(b))
+		}
+	}
+	return res
+}
@@ -0,0 +1,123 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domainCache "github.com/justinwongcn/hamster/internal/domain/cache"
+)
+
+// observedFalsePositiveRate 添加n个键后用probes个未添加的键统计实际假阳性率
+func observedFalsePositiveRate(t *testing.T, bf domainCache.BloomFilter, n, probes int) float64 {
+	ctx := context.Background()
+	for i := 0; i < n; i++ {
+		require.NoError(t, bf.Add(ctx, fmt.Sprintf("added_key_%d", i)))
+	}
+	falsePositives := 0
+	for i := 0; i < probes; i++ {
+		if bf.HasKey(ctx, fmt.Sprintf("probe_key_%d", i)) {
+			falsePositives++
+		}
+	}
+	return float64(falsePositives) / float64(probes)
+}
+
+// TestPartitionedBloomFilter_FalsePositiveRate 测试相同内存下分区布隆过滤器与内存布隆过滤器的假阳性率相当
+func TestPartitionedBloomFilter_FalsePositiveRate(t *testing.T) {
+	for _, tc := range []struct {
+		n   uint64
+		fpr float64
+	}{
+		{1000, 0.01},
+		{10000, 0.01},
+		{10000, 0.001},
+	} {
+		t.Run(fmt.Sprintf("%d_%v", tc.n, tc.fpr), func(t *testing.T) {
+			config, err := domainCache.NewBloomFilterConfig(tc.n, tc.fpr)
+			require.NoError(t, err)
+			partitioned := NewPartitionedBloomFilter(config)
+			plain := NewInMemoryBloomFilter(config)
+			// 内存预算相同，分区按字节对齐最多多占用k个字节
+			assert.LessOrEqual(t, uint64(len(partitioned.bitArray)), config.MemoryUsage()+config.HashFunctions())
+
+			partitionedFPR := observedFalsePositiveRate(t, partitioned, int(tc.n), 100000)
+			plainFPR := observedFalsePositiveRate(t, plain, int(tc.n), 100000)
+			assert.Less(t, partitionedFPR, 2*tc.fpr)
+			assert.Less(t, partitionedFPR, 2.5*max(plainFPR, tc.fpr))
+
+			// 按分区填充率估算的假阳性率接近实际值
+			estimated, err := partitioned.EstimateFalsePositiveRate(context.Background())
+			require.NoError(t, err)
+			assert.InDelta(t, estimated, partitionedFPR, estimated/2)
+		})
+	}
+}
+
+// TestPartitionedBloomFilter_Membership 测试已添加的键一定存在，未添加的键大多不存在
+func TestPartitionedBloomFilter_Membership(t *testing.T) {
+	ctx := context.Background()
+	config, err := domainCache.NewBloomFilterConfig(1000, 0.01)
+	require.NoError(t, err)
+	bf := NewPartitionedBloomFilter(config)
+
+	assert.False(t, bf.HasKey(ctx, "key1"))
+	for i := 0; i < 1000; i++ {
+		require.NoError(t, bf.Add(ctx, fmt.Sprintf("key%d", i)))
+	}
+	for i := 0; i < 1000; i++ {
+		assert.True(t, bf.HasKey(ctx, fmt.Sprintf("key%d", i)))
+	}
+
+	// 无效的键
+	assert.ErrorIs(t, bf.Add(ctx, ""), domainCache.ErrInvalidCacheKey)
+	assert.False(t, bf.HasKey(ctx, ""))
+
+	stats, err := bf.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1000), stats.AddedElements())
+	assert.Greater(t, stats.SetBits(), uint64(0))
+
+	require.NoError(t, bf.Clear(ctx))
+	assert.False(t, bf.HasKey(ctx, "key1"))
+	stats, err = bf.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), stats.AddedElements())
+	assert.Equal(t, uint64(0), stats.SetBits())
+}
+
+// TestPartitionedBloomFilter_PartitionFill 测试每个哈希函数只设置自己分区中的位
+func TestPartitionedBloomFilter_PartitionFill(t *testing.T) {
+	ctx := context.Background()
+	config, err := domainCache.NewBloomFilterConfig(100, 0.01)
+	require.NoError(t, err)
+	bf := NewPartitionedBloomFilter(config)
+	k := int(config.HashFunctions())
+	partitionBits := float64(bf.partitionBits)
+
+	fill := bf.PartitionFill(ctx)
+	require.Len(t, fill, k)
+	for _, f := range fill {
+		assert.Equal(t, 0.0, f)
+	}
+
+	// 一个键在每个分区中恰好设置一位
+	require.NoError(t, bf.Add(ctx, "key1"))
+	for i, f := range bf.PartitionFill(ctx) {
+		assert.Equal(t, 1/partitionBits, f, "分区%d", i)
+	}
+	stats, err := bf.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(k), stats.SetBits())
+
+	// 各分区的填充率随添加的键数量一起增长
+	for i := 0; i < 100; i++ {
+		require.NoError(t, bf.Add(ctx, fmt.Sprintf("key%d", i)))
+	}
+	for _, f := range bf.PartitionFill(ctx) {
+		assert.InDelta(t, 0.5, f, 0.15)
+	}
+}
@@ -16,6 +16,7 @@ type RandomPolicy struct {
 	keySet   map[string]int // key到索引的映射，用于快速查找
 	mutex    sync.RWMutex   // 读写锁，保证并发安全
 	rand     *rand.Rand     // 随机数生成器
+	peeked   string         // Peek选出的候选key，下一次淘汰时优先使用
 }
 
 // NewRandomPolicy 创建新的随机策略实例
@@ -25,16 +26,34 @@ type RandomPolicy struct {
 // 返回值:
 //   - *RandomPolicy: 新的随机策略实例
 func NewRandomPolicy(capacity ...int) *RandomPolicy {
+	return NewRandomPolicyWithRand(nil, capacity...)
+}
+
+// NewRandomPolicyWithRand 使用指定的随机数生成器创建随机策略实例
+// 使用固定种子的生成器可以得到可复现的淘汰顺序，便于测试
+// 参数:
+//   - rng: 随机数生成器，为nil时使用以当前时间为种子的生成器
+//   - capacity: 容量限制，0表示无限制
+//
+// 返回值:
+//   - *RandomPolicy: 新的随机策略实例
+//
+// 注意: rand.Rand不是并发安全的，同一个生成器不能在多个策略之间共享
+func NewRandomPolicyWithRand(rng *rand.Rand, capacity ...int) *RandomPolicy {
 	capacityVal := 0
 	if len(capacity) > 0 && capacity[0] > 0 {
 		capacityVal = capacity[0]
 	}
 
+	if rng == nil {
+		rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+
 	return &RandomPolicy{
 		capacity: capacityVal,
 		keys:     make([]string, 0),
 		keySet:   make(map[string]int),
-		rand:     rand.New(rand.NewSource(time.Now().UnixNano())),
+		rand:     rng,
 	}
 }
 
@@ -72,22 +91,95 @@ func (r *RandomPolicy) Evict(context.Context) (string, error) {
 	return r.evictInternal()
 }
 
+// EvictMultiple 一次随机淘汰最多n个key，只加一次锁
+// 返回值:
+//   - []string: 被淘汰的key，策略中的key不足n个时全部淘汰
+//   - error: 操作错误，nil表示成功
+func (r *RandomPolicy) EvictMultiple(_ context.Context, n int) ([]string, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var evicted []string
+	for ; n > 0 && len(r.keys) > 0; n-- {
+		key, err := r.evictInternal()
+		if err != nil {
+			return evicted, err
+		}
+		evicted = append(evicted, key)
+	}
+	return evicted, nil
+}
+
 // evictInternal 内部淘汰方法（不加锁）
 func (r *RandomPolicy) evictInternal() (string, error) {
 	if len(r.keys) == 0 {
 		return "", nil
 	}
 
-	// 随机选择一个索引
-	randomIndex := r.rand.Intn(len(r.keys))
-	key := r.keys[randomIndex]
+	// 优先淘汰Peek选出的候选key，保证Peek与Evict的结果一致
+	index := r.candidateIndex()
+	key := r.keys[index]
 
 	// 移除key
-	r.removeByIndex(randomIndex)
+	r.removeByIndex(index)
+
+	return key, nil
+}
 
+// Peek 返回下一个将被淘汰的key，不移除
+// 随机选出候选key并记住，之后的Evict（包括超出容量时的淘汰）会淘汰同一个key，
+// 候选key被移除后重新随机选择
+func (r *RandomPolicy) Peek(context.Context) (string, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if len(r.keys) == 0 {
+		return "", nil
+	}
+
+	key := r.keys[r.candidateIndex()]
+	r.peeked = key
 	return key, nil
 }
 
+// candidateIndex 返回候选key的索引（内部方法，不加锁）
+// 有仍被跟踪的Peek候选时返回其索引，否则随机选择
+// 注意: 调用方需保证keys不为空
+func (r *RandomPolicy) candidateIndex() int {
+	if index, exists := r.keySet[r.peeked]; exists && r.peeked != "" {
+		return index
+	}
+	return r.rand.Intn(len(r.keys))
+}
+
+// SetCapacity 调整容量限制
+// 新容量小于当前大小时，随机淘汰key直到满足新容量
+// 参数:
+//   - capacity: 新的容量限制，0表示无限制
+//
+// 返回值:
+//   - []string: 被淘汰的key，调用方需要从缓存中删除这些key
+//   - error: 容量为负数时返回ErrInvalidCapacity
+func (r *RandomPolicy) SetCapacity(_ context.Context, capacity int) ([]string, error) {
+	if capacity < 0 {
+		return nil, ErrInvalidCapacity
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.capacity = capacity
+	var evicted []string
+	for r.capacity > 0 && len(r.keys) > r.capacity {
+		key, err := r.evictInternal()
+		if err != nil {
+			return evicted, err
+		}
+		evicted = append(evicted, key)
+	}
+	return evicted, nil
+}
+
 // Remove 移除指定key
 func (r *RandomPolicy) Remove(_ context.Context, key string) error {
 	r.mutex.Lock()
@@ -117,6 +209,9 @@ func (r *RandomPolicy) removeByIndex(index int) {
 	// 删除最后一个元素
 	r.keys = r.keys[:lastIndex]
 	delete(r.keySet, key)
+	if key == r.peeked {
+		r.peeked = ""
+	}
 }
 
 // Has 判断key是否存在
@@ -143,5 +238,6 @@ func (r *RandomPolicy) Clear(context.Context) error {
 
 	r.keys = r.keys[:0]
 	r.keySet = make(map[string]int)
+	r.peeked = ""
 	return nil
 }
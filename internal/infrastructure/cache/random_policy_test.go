@@ -2,6 +2,7 @@ package cache
 
 import (
 	"context"
+	"math/rand"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -370,3 +371,122 @@ func TestRandomPolicy_Capacity(t *testing.T) {
 		})
 	}
 }
+
+// TestRandomPolicy_WithRand 测试固定种子时淘汰顺序可复现
+func TestRandomPolicy_WithRand(t *testing.T) {
+	ctx := context.Background()
+	keys := []string{"key1", "key2", "key3", "key4", "key5"}
+
+	evictAll := func(seed int64) []string {
+		policy := NewRandomPolicyWithRand(rand.New(rand.NewSource(seed)))
+		for _, key := range keys {
+			require.NoError(t, policy.KeyAccessed(ctx, key))
+		}
+
+		var order []string
+		for {
+			key, err := policy.Evict(ctx)
+			require.NoError(t, err)
+			if key == "" {
+				return order
+			}
+			order = append(order, key)
+		}
+	}
+
+	order := evictAll(42)
+	assert.Equal(t, []string{"key1", "key4", "key3", "key5", "key2"}, order)
+	assert.Equal(t, order, evictAll(42))
+}
+
+// TestRandomPolicy_Peek 测试Peek选出的候选key会被下一次淘汰
+func TestRandomPolicy_Peek(t *testing.T) {
+	ctx := context.Background()
+	policy := NewRandomPolicyWithRand(rand.New(rand.NewSource(1)), 3)
+
+	key, err := policy.Peek(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, key)
+
+	for _, k := range []string{"key1", "key2", "key3"} {
+		require.NoError(t, policy.KeyAccessed(ctx, k))
+	}
+
+	for i := 0; i < 3; i++ {
+		peeked, err := policy.Peek(ctx)
+		require.NoError(t, err)
+
+		// 重复Peek返回同一个候选
+		again, err := policy.Peek(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, peeked, again)
+
+		evicted, err := policy.Evict(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, peeked, evicted)
+	}
+
+	// 超出容量时同样淘汰候选key
+	for _, k := range []string{"key1", "key2", "key3"} {
+		require.NoError(t, policy.KeyAccessed(ctx, k))
+	}
+	peeked, err := policy.Peek(ctx)
+	require.NoError(t, err)
+	require.NoError(t, policy.KeyAccessed(ctx, "key4"))
+	has, err := policy.Has(ctx, peeked)
+	require.NoError(t, err)
+	assert.False(t, has)
+}
+
+// TestRandomPolicy_SetCapacity 测试调整容量时随机淘汰到新容量
+func TestRandomPolicy_SetCapacity(t *testing.T) {
+	ctx := context.Background()
+	keys := []string{"key1", "key2", "key3", "key4", "key5"}
+	policy := NewRandomPolicyWithRand(rand.New(rand.NewSource(42)))
+	for _, k := range keys {
+		require.NoError(t, policy.KeyAccessed(ctx, k))
+	}
+
+	evicted, err := policy.SetCapacity(ctx, 2)
+	require.NoError(t, err)
+	assert.Len(t, evicted, 3)
+
+	// 被淘汰的key不再被跟踪，剩余的key都在策略中
+	remaining := 0
+	for _, k := range keys {
+		has, err := policy.Has(ctx, k)
+		require.NoError(t, err)
+		if has {
+			remaining++
+			assert.NotContains(t, evicted, k)
+		}
+	}
+	assert.Equal(t, 2, remaining)
+
+	_, err = policy.SetCapacity(ctx, -1)
+	assert.ErrorIs(t, err, ErrInvalidCapacity)
+}
+
+// TestRandomPolicy_EvictMultiple 测试一次随机淘汰多个key
+func TestRandomPolicy_EvictMultiple(t *testing.T) {
+	ctx := context.Background()
+	policy := NewRandomPolicy()
+	keys := []string{"key1", "key2", "key3", "key4"}
+	for _, k := range keys {
+		require.NoError(t, policy.KeyAccessed(ctx, k))
+	}
+
+	evicted, err := policy.EvictMultiple(ctx, 3)
+	require.NoError(t, err)
+	require.Len(t, evicted, 3)
+	assert.Subset(t, keys, evicted)
+	for _, k := range evicted {
+		has, err := policy.Has(ctx, k)
+		require.NoError(t, err)
+		assert.False(t, has)
+	}
+
+	evicted, err = policy.EvictMultiple(ctx, 3)
+	require.NoError(t, err)
+	assert.Len(t, evicted, 1)
+}
@@ -4,6 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	domainCache "github.com/justinwongcn/hamster/internal/domain/cache"
@@ -22,8 +26,69 @@ type ReadThroughCache struct {
 	domainCache.Repository
 	LoadFunc   func(ctx context.Context, key string) (any, error)
 	Expiration time.Duration
+	// IsNotFound 判断底层仓储Get返回的错误是否表示未命中，未命中时调用LoadFunc加载
+	// 为nil时识别 ErrKeyNotFound 和 ErrCacheKeyNotFound；
+	// 适配其他存储时可以设置为识别其哨兵错误，其余错误直接返回给调用方
+	IsNotFound func(err error) bool
 	logFunc    func(format string, args ...any)
 	g          singleflight.Group
+	batchG     singleflight.Group // 合并批量加载，与单键加载分开避免键冲突
+	forceG     singleflight.Group // 合并强制重新加载，不与已在进行的普通加载合并，避免拿到写入之前的旧值
+	deleteG    singleflight.Group // 合并同一个键的并发删除
+
+	// AdaptiveTTLFactor 自适应过期时间的增长因子，大于1且 MaxAdaptiveTTL 大于 Expiration 时启用：
+	// Get 每次命中时将该键的过期时间乘以该因子（不超过 MaxAdaptiveTTL）并从当前时间起重新计算，
+	// 过期后重新加载时除以该因子（不低于 Expiration），使经常读取的键存活更久，很少读取的键回到 Expiration。
+	// 需要底层仓储实现 Expire(ctx, key, expiration) error（如BuildInMapCache），否则不生效
+	AdaptiveTTLFactor float64
+	// MaxAdaptiveTTL 自适应过期时间的上限
+	MaxAdaptiveTTL time.Duration
+
+	// SlidingExpiration 是否启用滑动过期：Get 每次命中时将该键的过期时间从当前时间起重置为 Expiration，
+	// 只要键持续被读取就一直存活，停止读取 Expiration 后过期。与提前刷新不同，命中时不调用 LoadFunc。
+	// Expiration 为0或启用了自适应过期时间时不生效；需要底层仓储实现 Expire(ctx, key, expiration) error
+	SlidingExpiration bool
+
+	// StaleIfError 加载失败时继续使用旧值的时长，大于0时启用：
+	// 加载的值在缓存中多保留 StaleIfError，过期时间之后的 Get 同步重新加载，
+	// 加载失败时返回旧值并通过 OnStaleError 报告错误，而不是让读取失败；
+	// 再超过 StaleIfError 后缓存项才真正过期，此时加载失败返回错误。过期时间为0的缓存项不受影响
+	StaleIfError time.Duration
+	// OnStaleError 加载失败而返回旧值时调用，用于在读取路径之外记录日志和告警，为nil时不报告
+	OnStaleError func(key string, err error)
+	// Clock 判断缓存值是否过期需要重新加载的时钟，为nil时使用系统时间
+	Clock Clock
+
+	// stateMu 保护states和pruneAt
+	stateMu sync.Mutex
+	// states 自适应过期时间和 StaleIfError 在仓储之外为每个键记录的状态，仓储中只保存原始的缓存值
+	states map[string]keyState
+	// pruneAt states增长到该数量时清理已不在仓储中的键
+	pruneAt int
+
+	// 统计计数器，使用原子操作更新，不经过缓存和singleflight的锁
+	hits         atomic.Int64
+	loaderCalls  atomic.Int64
+	loaderErrors atomic.Int64
+	setFailures  atomic.Int64
+}
+
+// statePruneMinSize 键状态数量达到该值之前不清理
+const statePruneMinSize = 1024
+
+// keyState 读透缓存在仓储之外为一个键记录的状态
+type keyState struct {
+	ttl        time.Duration // 自适应过期时间，0表示 Expiration
+	freshUntil time.Time     // 启用 StaleIfError 时缓存值需要重新加载的时间点，零值表示没有旧值窗口
+	expiresAt  time.Time     // 缓存项在仓储中的过期时间点，零值表示永不过期，用于清理已不在仓储中的键
+}
+
+// ReadThroughCacheStats 读透缓存的统计信息快照
+type ReadThroughCacheStats struct {
+	Hits         int64 // 缓存命中次数
+	LoaderCalls  int64 // 实际调用LoadFunc的次数，singleflight合并的请求只计一次
+	LoaderErrors int64 // LoadFunc返回错误的次数
+	SetFailures  int64 // 加载成功但写入缓存失败的次数
 }
 
 // RateLimitReadThroughCache 带限流功能的读透缓存
@@ -36,6 +101,28 @@ type RateLimitReadThroughCache struct {
 	g          singleflight.Group
 }
 
+// forceReloadKey 强制重新加载标记在上下文中的键
+type forceReloadKey struct{}
+
+// WithForceReload 返回带有强制重新加载标记的上下文
+// ReadThroughCache 使用该上下文获取时跳过缓存读取，调用加载函数并覆盖缓存，
+// 适用于已知缓存值过期（例如刚写入数据源）的场景，并发的强制重新加载仍通过singleflight合并
+func WithForceReload(ctx context.Context) context.Context {
+	return context.WithValue(ctx, forceReloadKey{}, true)
+}
+
+// isForceReload 判断上下文是否带有强制重新加载标记
+func isForceReload(ctx context.Context) bool {
+	force, _ := ctx.Value(forceReloadKey{}).(bool)
+	return force
+}
+
+// isKeyNotFound 判断错误是否表示缓存未命中
+// 兼容本包的 ErrKeyNotFound 和 BuildInMapCache 返回的 ErrCacheKeyNotFound
+func isKeyNotFound(err error) bool {
+	return errors.Is(err, ErrKeyNotFound) || errors.Is(err, ErrCacheKeyNotFound)
+}
+
 // Get 实现读透缓存获取逻辑
 // 参数:
 //   - ctx: 上下文
@@ -46,24 +133,336 @@ type RateLimitReadThroughCache struct {
 //   - error: 错误信息
 //
 // 功能:
-//   - 优先从缓存获取数据
+//   - 优先从缓存获取数据，缓存的nil值视为命中
 //   - 缓存未命中时调用handleCacheMiss处理
+//   - ctx 由 WithForceReload 创建时跳过缓存读取，直接加载并覆盖缓存
+//   - 设置了 AdaptiveTTLFactor 时按读取频率调整过期时间
+//   - 设置了 SlidingExpiration 时命中将过期时间重置为 Expiration
+//   - 设置了 StaleIfError 时，过期后重新加载失败返回旧值，错误通过 OnStaleError 报告
 func (r *ReadThroughCache) Get(ctx context.Context, key string) (any, error) {
+	val, hit, err := r.get(ctx, key, func(ctx context.Context, key string) (any, time.Duration, error) {
+		val, err := r.LoadFunc(ctx, key)
+		if err != nil {
+			return nil, 0, err
+		}
+		return val, r.loadedTTL(key), nil
+	})
+	if hit {
+		r.extendTTL(ctx, key)
+	}
+	return val, err
+}
+
+// adaptiveTTLEnabled 判断是否启用自适应过期时间
+func (r *ReadThroughCache) adaptiveTTLEnabled() bool {
+	return r.AdaptiveTTLFactor > 1 && r.Expiration > 0 && r.MaxAdaptiveTTL > r.Expiration
+}
+
+// extendTTL 命中后延长键的过期时间
+// 启用自适应过期时间时按增长因子延长，启用滑动过期时重置为 Expiration
+func (r *ReadThroughCache) extendTTL(ctx context.Context, key string) {
+	adaptive := r.adaptiveTTLEnabled()
+	if !adaptive && !(r.SlidingExpiration && r.Expiration > 0) {
+		return
+	}
+	repo, ok := r.Repository.(interface {
+		Expire(ctx context.Context, key string, expiration time.Duration) error
+	})
+	if !ok {
+		return
+	}
+	ttl := r.Expiration
+	if adaptive {
+		r.stateMu.Lock()
+		st := r.states[key]
+		if st.ttl > 0 {
+			ttl = st.ttl
+		}
+		ttl = min(time.Duration(float64(ttl)*r.AdaptiveTTLFactor), r.MaxAdaptiveTTL)
+		st.ttl = ttl
+		r.setState(key, st)
+		r.stateMu.Unlock()
+	}
+
+	// 过期时间同样包含 StaleIfError 的旧值窗口；键可能刚好过期或被删除，此时等待下一次加载
+	if repo.Expire(ctx, key, r.withStaleWindow(ttl)) == nil {
+		r.recordStored(key, ttl)
+	}
+}
+
+// loadedTTL 获取重新加载的键的过期时间
+// 键在过期前没有被足够频繁地读取，按增长因子缩短其自适应过期时间，缩短到 Expiration 时不再记录
+func (r *ReadThroughCache) loadedTTL(key string) time.Duration {
+	if !r.adaptiveTTLEnabled() {
+		return r.Expiration
+	}
+
+	r.stateMu.Lock()
+	defer r.stateMu.Unlock()
+	st, ok := r.states[key]
+	if !ok || st.ttl == 0 {
+		return r.Expiration
+	}
+	st.ttl = time.Duration(float64(st.ttl) / r.AdaptiveTTLFactor)
+	if st.ttl <= r.Expiration {
+		st.ttl = 0
+	}
+	r.states[key] = st
+	return max(st.ttl, r.Expiration)
+}
+
+// GetWithLoaderTTL 使用加载器获取缓存值，由加载器决定每个缓存项的过期时间
+// 适用于新鲜度各不相同的数据，例如有的数据每小时变化，有的每天变化
+// 参数:
+//   - ctx: 上下文
+//   - key: 缓存键
+//   - loader: 数据加载函数，返回值和该值的过期时间，0表示永不过期
+//
+// 返回值:
+//   - any: 缓存值
+//   - error: 错误信息
+//
+// 功能:
+//   - 与 Get 相同，未命中时使用singleflight合并加载，并计入统计信息，同样支持 WithForceReload
+//   - 不使用 LoadFunc 和 Expiration 字段
+func (r *ReadThroughCache) GetWithLoaderTTL(ctx context.Context, key string,
+	loader func(ctx context.Context, key string) (any, time.Duration, error)) (any, error) {
+	val, _, err := r.get(ctx, key, loader)
+	return val, err
+}
+
+// GetManyWithLoader 批量获取缓存值，未命中的键通过一次批量加载获取
+// 参数:
+//   - ctx: 上下文
+//   - keys: 缓存键列表，重复的键只处理一次
+//   - batchLoader: 批量加载函数，参数为未命中的键，返回加载到的键值，数据源中不存在的键可以不返回
+//   - expiration: 加载结果的缓存过期时间
+//
+// 返回值:
+//   - map[string]any: 命中和加载到的键值，不包含数据源中不存在的键
+//   - error: 错误信息，写入缓存失败时仍返回已获取的键值
+//
+// 功能:
+//   - 命中的键直接返回，所有未命中的键只调用一次batchLoader
+//   - 未命中键集合相同的并发请求通过singleflight合并为一次加载
+//   - 设置了 StaleIfError 时与 Get 相同，过期时间之后的键随未命中的键一起重新加载，
+//     加载失败且需要加载的键都有旧值时返回旧值，错误通过 OnStaleError 报告
+func (r *ReadThroughCache) GetManyWithLoader(ctx context.Context, keys []string,
+	batchLoader func(ctx context.Context, missing []string) (map[string]any, error),
+	expiration time.Duration) (map[string]any, error) {
+	res := make(map[string]any, len(keys))
+	var missing []string
+	stale := make(map[string]any)
+	for _, key := range keys {
+		if _, ok := res[key]; ok || slices.Contains(missing, key) {
+			continue
+		}
+		val, err := r.Repository.Get(ctx, key)
+		if err != nil {
+			if r.isNotFound(err) {
+				missing = append(missing, key)
+				continue
+			}
+			return nil, err
+		}
+		if r.needsRefresh(key) {
+			stale[key] = val
+			missing = append(missing, key)
+			continue
+		}
+		r.hits.Add(1)
+		res[key] = val
+	}
+	if len(missing) == 0 {
+		return res, nil
+	}
+
+	// 排序后作为singleflight的键，使相同的未命中集合可以合并
+	slices.Sort(missing)
+	loaded, loadErr, _ := r.batchG.Do(strings.Join(missing, "\x00"), func() (any, error) {
+		if r.logFunc != nil {
+			r.logFunc("缓存未命中，从数据源批量加载数据 keys: %v", missing)
+		}
+
+		r.loaderCalls.Add(1)
+		vals, err := batchLoader(ctx, missing)
+		if err != nil {
+			r.loaderErrors.Add(1)
+			return nil, err
+		}
+
+		var setErr error
+		for key, val := range vals {
+			if err = r.Repository.Set(ctx, key, val, r.withStaleWindow(expiration)); err != nil {
+				r.setFailures.Add(1)
+				if r.logFunc != nil {
+					r.logFunc("刷新缓存失败，键：%s，错误：%v", key, err)
+				}
+				setErr = fmt.Errorf("%w, 原因：%s", ErrFailedToRefreshCache, err.Error())
+				continue
+			}
+			r.recordStored(key, expiration)
+		}
+		return vals, setErr
+	})
+	if loaded == nil {
+		// 需要加载的键都有旧值时返回旧值
+		if len(stale) == len(missing) {
+			for key, val := range stale {
+				if r.OnStaleError != nil {
+					r.OnStaleError(key, loadErr)
+				}
+				res[key] = val
+			}
+			return res, nil
+		}
+		return nil, loadErr
+	}
+
+	// 加载结果可能被多个请求共享，只复制需要的键
+	vals := loaded.(map[string]any)
+	for _, key := range missing {
+		if val, ok := vals[key]; ok {
+			res[key] = val
+		}
+	}
+	return res, loadErr
+}
+
+// get 从缓存获取数据，未命中时使用loader加载
+// 返回: 缓存值、是否命中缓存和错误信息
+func (r *ReadThroughCache) get(ctx context.Context, key string,
+	loader func(ctx context.Context, key string) (any, time.Duration, error)) (any, bool, error) {
+	if isForceReload(ctx) {
+		val, err := r.load(ctx, &r.forceG, key, "强制重新加载数据 key: %s", loader)
+		return val, false, err
+	}
 	cachedVal, err := r.Repository.Get(ctx, key)
 	if err != nil {
-		if errors.Is(err, ErrKeyNotFound) {
-			return r.handleCacheMiss(ctx, key)
+		if r.isNotFound(err) {
+			val, err := r.handleCacheMiss(ctx, key, loader)
+			return val, false, err
 		}
-		return nil, err
+		return nil, false, err
+	}
+	if r.needsRefresh(key) {
+		return r.refreshStale(ctx, key, cachedVal, loader)
+	}
+	r.hits.Add(1)
+	return cachedVal, true, nil
+}
+
+// refreshStale 重新加载已过期但仍在 StaleIfError 时长内的键
+// 加载失败时返回旧值并通过 OnStaleError 报告错误，写入缓存失败时与普通加载相同返回新值和错误
+func (r *ReadThroughCache) refreshStale(ctx context.Context, key string, stale any,
+	loader func(ctx context.Context, key string) (any, time.Duration, error)) (any, bool, error) {
+	val, err := r.load(ctx, &r.g, key, "缓存已过期，重新加载数据 key: %s", loader)
+	if err == nil || errors.Is(err, ErrFailedToRefreshCache) {
+		return val, false, err
+	}
+	if r.OnStaleError != nil {
+		r.OnStaleError(key, err)
+	}
+	return stale, false, nil
+}
+
+// withStaleWindow 获取缓存项在仓储中的过期时间，启用 StaleIfError 时延长 StaleIfError
+func (r *ReadThroughCache) withStaleWindow(expiration time.Duration) time.Duration {
+	if r.StaleIfError <= 0 || expiration <= 0 {
+		return expiration
 	}
-	return cachedVal, nil
+	return expiration + r.StaleIfError
+}
+
+// needsRefresh 判断命中的键是否已过了过期时间、处于 StaleIfError 的旧值窗口内需要重新加载
+func (r *ReadThroughCache) needsRefresh(key string) bool {
+	if r.StaleIfError <= 0 {
+		return false
+	}
+	r.stateMu.Lock()
+	st := r.states[key]
+	r.stateMu.Unlock()
+	return !st.freshUntil.IsZero() && !r.now().Before(st.freshUntil)
+}
+
+// recordStored 缓存值写入仓储或延长过期时间之后记录该键的旧值窗口和过期时间点
+// 没有自适应过期时间也没有旧值窗口的键不记录状态
+// expiration: 缓存值的过期时间，不包含 StaleIfError
+func (r *ReadThroughCache) recordStored(key string, expiration time.Duration) {
+	stale := r.StaleIfError > 0 && expiration > 0
+	r.stateMu.Lock()
+	defer r.stateMu.Unlock()
+	st := r.states[key]
+	if !stale && st.ttl == 0 {
+		delete(r.states, key)
+		return
+	}
+
+	now := r.now()
+	st.freshUntil, st.expiresAt = time.Time{}, time.Time{}
+	if expiration > 0 {
+		st.expiresAt = now.Add(r.withStaleWindow(expiration))
+	}
+	if stale {
+		st.freshUntil = now.Add(expiration)
+	}
+	r.setState(key, st)
+}
+
+// setState 保存键的状态
+// 状态数量增长到上次清理后的两倍时，清理已过期超过 MaxAdaptiveTTL 仍未重新加载的键，
+// 这些键视为冷数据，自适应过期时间回到 Expiration，使状态数量与仓储中的键数量保持同一量级
+// 注意: 此方法应在持有stateMu的情况下调用
+func (r *ReadThroughCache) setState(key string, st keyState) {
+	if r.states == nil {
+		r.states = make(map[string]keyState)
+	}
+	r.states[key] = st
+	if len(r.states) < r.pruneAt {
+		return
+	}
+
+	var retain time.Duration
+	if r.adaptiveTTLEnabled() {
+		retain = r.MaxAdaptiveTTL
+	}
+	now := r.now()
+	for k, s := range r.states {
+		if !s.expiresAt.IsZero() && now.After(s.expiresAt.Add(retain)) {
+			delete(r.states, k)
+		}
+	}
+	r.pruneAt = max(2*len(r.states), statePruneMinSize)
+}
+
+// clearState 删除键的状态，键被删除或被直接写入时调用
+func (r *ReadThroughCache) clearState(key string) {
+	r.stateMu.Lock()
+	delete(r.states, key)
+	r.stateMu.Unlock()
+}
+
+// now 获取当前时间
+func (r *ReadThroughCache) now() time.Time {
+	if r.Clock != nil {
+		return r.Clock.Now()
+	}
+	return time.Now()
+}
+
+// isNotFound 使用配置的 IsNotFound 判断错误是否表示未命中
+func (r *ReadThroughCache) isNotFound(err error) bool {
+	if r.IsNotFound != nil {
+		return r.IsNotFound(err)
+	}
+	return isKeyNotFound(err)
 }
 
 // Get 实现带限流功能的缓存获取逻辑
 // 当缓存未命中且未被限流时，调用LoadFunc加载数据并更新缓存
 func (r *RateLimitReadThroughCache) Get(ctx context.Context, key string) (any, error) {
 	val, err := r.Repository.Get(ctx, key)
-	if errors.Is(err, ErrKeyNotFound) && ctx.Value("limited") == nil {
+	if isKeyNotFound(err) && ctx.Value("limited") == nil {
 		// 使用single flight防止缓存击穿
 		loadedVal, loadErr, _ := r.g.Do(key, func() (any, error) {
 			newVal, loadErr := r.LoadFunc(ctx, key)
@@ -87,6 +486,7 @@ func (r *RateLimitReadThroughCache) Get(ctx context.Context, key string) (any, e
 // 参数:
 //   - ctx: 上下文
 //   - key: 缓存键
+//   - loader: 数据加载函数，返回值和过期时间
 //
 // 返回值:
 //   - any: 加载的值
@@ -94,30 +494,42 @@ func (r *RateLimitReadThroughCache) Get(ctx context.Context, key string) (any, e
 //
 // 功能:
 //   - 使用single flight防止缓存击穿
-//   - 调用LoadFunc从数据源加载数据
+//   - 调用loader从数据源加载数据
 //   - 更新缓存并处理可能的错误
-func (r *ReadThroughCache) handleCacheMiss(ctx context.Context, key string) (any, error) {
+func (r *ReadThroughCache) handleCacheMiss(ctx context.Context, key string,
+	loader func(ctx context.Context, key string) (any, time.Duration, error)) (any, error) {
+	return r.load(ctx, &r.g, key, "缓存未命中，从数据源加载数据 key: %s", loader)
+}
+
+// load 在g中合并对key的加载，调用loader并写入缓存
+// logFormat 为加载前记录的日志格式，参数为key
+func (r *ReadThroughCache) load(ctx context.Context, g *singleflight.Group, key string, logFormat string,
+	loader func(ctx context.Context, key string) (any, time.Duration, error)) (any, error) {
 	// 使用single flight防止缓存击穿
-	loadedVal, loadErr, _ := r.g.Do(key, func() (any, error) {
+	loadedVal, loadErr, _ := g.Do(key, func() (any, error) {
 		// 记录日志
 		if r.logFunc != nil {
-			r.logFunc("缓存未命中，从数据源加载数据 key: %s", key)
+			r.logFunc(logFormat, key)
 		}
 
 		// 从数据源加载数据
-		newVal, loadErr := r.LoadFunc(ctx, key)
+		r.loaderCalls.Add(1)
+		newVal, expiration, loadErr := loader(ctx, key)
 		if loadErr != nil {
+			r.loaderErrors.Add(1)
 			return nil, loadErr
 		}
 
 		// 尝试更新缓存（即使失败也返回加载的值）
-		if setErr := r.Repository.Set(ctx, key, newVal, r.Expiration); setErr != nil {
+		if setErr := r.Repository.Set(ctx, key, newVal, r.withStaleWindow(expiration)); setErr != nil {
+			r.setFailures.Add(1)
 			if r.logFunc != nil {
 				r.logFunc("刷新缓存失败，键：%s，错误：%v", key, setErr)
 			}
 			// 返回加载的值和错误
 			return newVal, fmt.Errorf("%w, 原因：%s", ErrFailedToRefreshCache, setErr.Error())
 		}
+		r.recordStored(key, expiration)
 		return newVal, nil
 	})
 
@@ -128,6 +540,52 @@ func (r *ReadThroughCache) handleCacheMiss(ctx context.Context, key string) (any
 	return loadedVal, nil
 }
 
+// Delete 删除缓存值
+// 参数:
+//   - ctx: 上下文
+//   - key: 缓存键
+//
+// 返回值:
+//   - error: 底层缓存删除的错误，合并的调用返回同一个错误
+//
+// 功能:
+//   - 同一个键的并发删除（如失效通知扇出）通过singleflight合并为一次底层删除，
+//     底层缓存的淘汰回调也只触发一次
+//   - 合并到进行中删除的调用在该次删除完成后返回，不会再单独删除一次
+func (r *ReadThroughCache) Delete(ctx context.Context, key string) error {
+	_, err, _ := r.deleteG.Do(key, func() (any, error) {
+		r.clearState(key)
+		return nil, r.Repository.Delete(ctx, key)
+	})
+	return err
+}
+
+// Set 直接写入缓存值，并删除该键的自适应过期时间和旧值窗口
+// 写入的值按新值对待，不继承之前加载的值的状态
+func (r *ReadThroughCache) Set(ctx context.Context, key string, val any, expiration time.Duration) error {
+	r.clearState(key)
+	return r.Repository.Set(ctx, key, val, expiration)
+}
+
+// LoadAndDelete 获取并删除缓存值，同时删除该键的状态
+func (r *ReadThroughCache) LoadAndDelete(ctx context.Context, key string) (any, error) {
+	r.clearState(key)
+	return r.Repository.LoadAndDelete(ctx, key)
+}
+
+// Stats 获取统计信息快照
+// 用于观察数据源的实际加载次数与缓存命中次数，据此调整过期时间
+// 返回值:
+//   - ReadThroughCacheStats: 统计信息，各计数器分别读取，并发更新时不保证彼此一致
+func (r *ReadThroughCache) Stats() ReadThroughCacheStats {
+	return ReadThroughCacheStats{
+		Hits:         r.hits.Load(),
+		LoaderCalls:  r.loaderCalls.Load(),
+		LoaderErrors: r.loaderErrors.Load(),
+		SetFailures:  r.setFailures.Load(),
+	}
+}
+
 // SetLogFunc 设置日志记录函数
 // SetLogFunc 设置日志记录函数
 // 参数:
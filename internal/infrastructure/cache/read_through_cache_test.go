@@ -3,11 +3,14 @@ package cache
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/sync/singleflight"
 )
 
@@ -549,3 +552,881 @@ func TestReadThroughCache_Get_CacheError(t *testing.T) {
 	assert.Nil(t, val)
 	assert.Equal(t, "mock get error", err.Error())
 }
+
+// TestReadThroughCache_CacheNilValue 测试读透缓存可以缓存空结果
+func TestReadThroughCache_CacheNilValue(t *testing.T) {
+	loadCount := 0
+	cache := &ReadThroughCache{
+		Repository: NewBuildInMapCache(0),
+		LoadFunc: func(ctx context.Context, key string) (any, error) {
+			loadCount++
+			// 数据源中不存在该数据，返回空结果
+			return nil, nil
+		},
+		Expiration: time.Minute,
+	}
+
+	for i := 0; i < 3; i++ {
+		val, err := cache.Get(context.Background(), "empty_key")
+		assert.NoError(t, err)
+		assert.Nil(t, val)
+	}
+
+	// 空结果被缓存，只加载一次
+	assert.Equal(t, 1, loadCount)
+}
+
+// TestReadThroughCache_Stats 测试统计数据源加载次数与缓存命中次数
+func TestReadThroughCache_Stats(t *testing.T) {
+	ctx := context.Background()
+	mockCache := &MockCache{store: make(map[string]any)}
+	release := make(chan struct{})
+	var loadCount atomic.Int64
+	cache := &ReadThroughCache{
+		Repository: mockCache,
+		LoadFunc: func(ctx context.Context, key string) (any, error) {
+			loadCount.Add(1)
+			switch key {
+			case "burst":
+				// 阻塞直到所有并发请求都进入singleflight
+				<-release
+			case "error":
+				return nil, errors.New("load error")
+			}
+			return "value_" + key, nil
+		},
+		Expiration: time.Minute,
+	}
+
+	// 未命中后加载，之后两次命中
+	for i := 0; i < 3; i++ {
+		val, err := cache.Get(ctx, "key1")
+		assert.NoError(t, err)
+		assert.Equal(t, "value_key1", val)
+	}
+	assert.Equal(t, ReadThroughCacheStats{Hits: 2, LoaderCalls: 1}, cache.Stats())
+
+	// 并发未命中被singleflight合并为一次加载
+	const burst = 10
+	var wg sync.WaitGroup
+	for i := 0; i < burst; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			val, err := cache.Get(ctx, "burst")
+			assert.NoError(t, err)
+			assert.Equal(t, "value_burst", val)
+		}()
+	}
+	assert.Eventually(t, func() bool {
+		return loadCount.Load() == 2
+	}, time.Second, time.Millisecond)
+	// 等待其余请求进入singleflight等待加载结果
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	// 调度较慢、在加载完成后才到达的请求会直接命中缓存，不会再次加载
+	assert.Equal(t, int64(2), cache.Stats().LoaderCalls)
+	hits := cache.Stats().Hits
+
+	// 加载失败
+	_, err := cache.Get(ctx, "error")
+	assert.Error(t, err)
+
+	// 加载成功但写入缓存失败
+	mockCache.setShouldFail = true
+	_, err = cache.Get(ctx, "key2")
+	assert.ErrorIs(t, err, ErrFailedToRefreshCache)
+
+	assert.Equal(t, ReadThroughCacheStats{
+		Hits:         hits,
+		LoaderCalls:  4,
+		LoaderErrors: 1,
+		SetFailures:  1,
+	}, cache.Stats())
+	assert.Equal(t, loadCount.Load(), cache.Stats().LoaderCalls)
+}
+
+// notFoundRepository 未命中时返回自定义哨兵错误的仓储
+type notFoundRepository struct {
+	*MockCache
+	errNotFound error
+}
+
+func (n *notFoundRepository) Get(ctx context.Context, key string) (any, error) {
+	val, err := n.MockCache.Get(ctx, key)
+	if errors.Is(err, ErrKeyNotFound) {
+		return nil, n.errNotFound
+	}
+	return val, err
+}
+
+// TestReadThroughCache_IsNotFound 测试自定义未命中判断
+func TestReadThroughCache_IsNotFound(t *testing.T) {
+	errNil := errors.New("redis: nil")
+
+	tests := []struct {
+		name          string
+		isNotFound    func(error) bool
+		wantVal       any
+		wantErr       error
+		wantLoadCount int
+	}{
+		{
+			name: "自定义哨兵错误视为未命中",
+			isNotFound: func(err error) bool {
+				return errors.Is(err, errNil)
+			},
+			wantVal:       "loaded_value",
+			wantLoadCount: 1,
+		},
+		{
+			name:          "默认判断不识别自定义哨兵错误",
+			wantErr:       errNil,
+			wantLoadCount: 0,
+		},
+		{
+			name: "不识别的错误直接返回",
+			isNotFound: func(err error) bool {
+				return false
+			},
+			wantErr:       errNil,
+			wantLoadCount: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &notFoundRepository{
+				MockCache:   &MockCache{store: make(map[string]any)},
+				errNotFound: errNil,
+			}
+			loadCount := 0
+			cache := &ReadThroughCache{
+				Repository: repo,
+				LoadFunc: func(ctx context.Context, key string) (any, error) {
+					loadCount++
+					return "loaded_value", nil
+				},
+				Expiration: time.Minute,
+				IsNotFound: tt.isNotFound,
+			}
+
+			val, err := cache.Get(context.Background(), "key1")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantVal, val)
+			assert.Equal(t, tt.wantLoadCount, loadCount)
+		})
+	}
+}
+
+// TestReadThroughCache_GetWithLoaderTTL 测试加载器返回的过期时间用于各自的缓存项
+func TestReadThroughCache_GetWithLoaderTTL(t *testing.T) {
+	ctx := context.Background()
+	clock := newFakeClock()
+	ttls := map[string]time.Duration{
+		"hourly": time.Hour,
+		"daily":  24 * time.Hour,
+	}
+	loadCount := map[string]int{}
+	loader := func(ctx context.Context, key string) (any, time.Duration, error) {
+		loadCount[key]++
+		return "value_" + key, ttls[key], nil
+	}
+	cache := &ReadThroughCache{
+		Repository: NewBuildInMapCache(0, BuildInMapCacheWithClock(clock)),
+		// 固定过期时间不会被使用
+		Expiration: time.Minute,
+	}
+
+	for key := range ttls {
+		val, err := cache.GetWithLoaderTTL(ctx, key, loader)
+		require.NoError(t, err)
+		assert.Equal(t, "value_"+key, val)
+	}
+
+	// 超过固定过期时间，两个缓存项仍然有效
+	clock.Advance(30 * time.Minute)
+	for key := range ttls {
+		_, err := cache.GetWithLoaderTTL(ctx, key, loader)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, map[string]int{"hourly": 1, "daily": 1}, loadCount)
+
+	// hourly过期重新加载，daily仍然命中
+	clock.Advance(time.Hour)
+	for key := range ttls {
+		_, err := cache.GetWithLoaderTTL(ctx, key, loader)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, map[string]int{"hourly": 2, "daily": 1}, loadCount)
+
+	// daily过期后重新加载
+	clock.Advance(24 * time.Hour)
+	_, err := cache.GetWithLoaderTTL(ctx, "daily", loader)
+	require.NoError(t, err)
+	assert.Equal(t, 2, loadCount["daily"])
+	assert.Equal(t, int64(4), cache.Stats().LoaderCalls)
+
+	// 加载失败时返回错误且不缓存
+	_, err = cache.GetWithLoaderTTL(ctx, "error", func(ctx context.Context, key string) (any, time.Duration, error) {
+		return nil, 0, errors.New("load error")
+	})
+	assert.Error(t, err)
+	_, err = cache.Repository.Get(ctx, "error")
+	assert.ErrorIs(t, err, ErrCacheKeyNotFound)
+}
+
+// TestReadThroughCache_GetManyWithLoader 测试批量获取时只为未命中的键调用一次批量加载
+func TestReadThroughCache_GetManyWithLoader(t *testing.T) {
+	ctx := context.Background()
+	mockCache := &MockCache{store: map[string]any{
+		"key1": "cached1",
+		"key3": "cached3",
+	}}
+	cache := &ReadThroughCache{Repository: mockCache}
+
+	var calls [][]string
+	batchLoader := func(ctx context.Context, missing []string) (map[string]any, error) {
+		calls = append(calls, missing)
+		res := make(map[string]any, len(missing))
+		for _, key := range missing {
+			// 数据源中不存在key5
+			if key != "key5" {
+				res[key] = "loaded_" + key
+			}
+		}
+		return res, nil
+	}
+
+	vals, err := cache.GetManyWithLoader(ctx, []string{"key4", "key1", "key2", "key3", "key5", "key2"}, batchLoader, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"key1": "cached1",
+		"key2": "loaded_key2",
+		"key3": "cached3",
+		"key4": "loaded_key4",
+	}, vals)
+	assert.Equal(t, [][]string{{"key2", "key4", "key5"}}, calls)
+	// 加载结果已写入缓存
+	assert.Equal(t, "loaded_key2", mockCache.store["key2"])
+	assert.Equal(t, "loaded_key4", mockCache.store["key4"])
+
+	// 全部命中时不调用批量加载
+	vals, err = cache.GetManyWithLoader(ctx, []string{"key1", "key2"}, batchLoader, time.Minute)
+	require.NoError(t, err)
+	assert.Len(t, vals, 2)
+	assert.Len(t, calls, 1)
+	assert.Equal(t, ReadThroughCacheStats{Hits: 4, LoaderCalls: 1}, cache.Stats())
+
+	// 批量加载失败
+	_, err = cache.GetManyWithLoader(ctx, []string{"key6"}, func(ctx context.Context, missing []string) (map[string]any, error) {
+		return nil, errors.New("load error")
+	}, time.Minute)
+	assert.Error(t, err)
+
+	// 写入缓存失败时仍返回加载的值
+	mockCache.setShouldFail = true
+	vals, err = cache.GetManyWithLoader(ctx, []string{"key7"}, batchLoader, time.Minute)
+	assert.ErrorIs(t, err, ErrFailedToRefreshCache)
+	assert.Equal(t, map[string]any{"key7": "loaded_key7"}, vals)
+}
+
+// TestReadThroughCache_GetManyWithLoader_SingleFlight 测试相同未命中集合的并发批量请求合并为一次加载
+func TestReadThroughCache_GetManyWithLoader_SingleFlight(t *testing.T) {
+	ctx := context.Background()
+	cache := &ReadThroughCache{Repository: &MockCache{store: make(map[string]any)}}
+
+	release := make(chan struct{})
+	var loadCount atomic.Int64
+	batchLoader := func(ctx context.Context, missing []string) (map[string]any, error) {
+		loadCount.Add(1)
+		<-release
+		return map[string]any{"key1": 1, "key2": 2}, nil
+	}
+
+	const concurrency = 5
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			vals, err := cache.GetManyWithLoader(ctx, []string{"key2", "key1"}, batchLoader, time.Minute)
+			assert.NoError(t, err)
+			assert.Equal(t, map[string]any{"key1": 1, "key2": 2}, vals)
+		}()
+	}
+	assert.Eventually(t, func() bool {
+		return loadCount.Load() == 1
+	}, time.Second, time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int64(1), loadCount.Load())
+}
+
+// TestReadThroughCache_ForceReload 测试强制重新加载在命中时也调用加载函数并覆盖缓存
+func TestReadThroughCache_ForceReload(t *testing.T) {
+	ctx := context.Background()
+	var version atomic.Int64
+	cache := &ReadThroughCache{
+		Repository: NewBuildInMapCache(0),
+		LoadFunc: func(ctx context.Context, key string) (any, error) {
+			return fmt.Sprintf("%s_v%d", key, version.Add(1)), nil
+		},
+		Expiration: time.Minute,
+	}
+
+	val, err := cache.Get(ctx, "key1")
+	require.NoError(t, err)
+	assert.Equal(t, "key1_v1", val)
+	val, err = cache.Get(ctx, "key1")
+	require.NoError(t, err)
+	assert.Equal(t, "key1_v1", val)
+
+	// 命中时仍然重新加载，并覆盖缓存中的值
+	val, err = cache.Get(WithForceReload(ctx), "key1")
+	require.NoError(t, err)
+	assert.Equal(t, "key1_v2", val)
+	stored, err := cache.Repository.Get(ctx, "key1")
+	require.NoError(t, err)
+	assert.Equal(t, "key1_v2", stored)
+
+	// 普通读取返回新值
+	val, err = cache.Get(ctx, "key1")
+	require.NoError(t, err)
+	assert.Equal(t, "key1_v2", val)
+	assert.Equal(t, ReadThroughCacheStats{Hits: 2, LoaderCalls: 2}, cache.Stats())
+
+	// GetWithLoaderTTL 同样支持强制重新加载
+	val, err = cache.GetWithLoaderTTL(WithForceReload(ctx), "key1", func(ctx context.Context, key string) (any, time.Duration, error) {
+		return "key1_ttl", time.Minute, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "key1_ttl", val)
+
+	// 加载失败时保留缓存中的值
+	cache.LoadFunc = func(ctx context.Context, key string) (any, error) {
+		return nil, errors.New("load error")
+	}
+	_, err = cache.Get(WithForceReload(ctx), "key1")
+	assert.Error(t, err)
+	stored, err = cache.Repository.Get(ctx, "key1")
+	require.NoError(t, err)
+	assert.Equal(t, "key1_ttl", stored)
+}
+
+// TestReadThroughCache_ForceReload_SingleFlight 测试并发的强制重新加载合并为一次加载
+func TestReadThroughCache_ForceReload_SingleFlight(t *testing.T) {
+	ctx := context.Background()
+	release := make(chan struct{})
+	var loadCount atomic.Int64
+	cache := &ReadThroughCache{
+		Repository: &MockCache{store: map[string]any{"key1": "stale"}},
+		LoadFunc: func(ctx context.Context, key string) (any, error) {
+			loadCount.Add(1)
+			<-release
+			return "fresh", nil
+		},
+		Expiration: time.Minute,
+	}
+
+	const concurrency = 5
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			val, err := cache.Get(WithForceReload(ctx), "key1")
+			assert.NoError(t, err)
+			assert.Equal(t, "fresh", val)
+		}()
+	}
+	assert.Eventually(t, func() bool {
+		return loadCount.Load() == 1
+	}, time.Second, time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int64(1), loadCount.Load())
+}
+
+// TestReadThroughCache_AdaptiveTTL 测试经常读取的键存活时间逐步延长，未读取的键按时过期
+func TestReadThroughCache_AdaptiveTTL(t *testing.T) {
+	ctx := context.Background()
+	clock := newFakeClock()
+	repo := NewBuildInMapCache(0, BuildInMapCacheWithClock(clock))
+	loadCount := map[string]int{}
+	cache := &ReadThroughCache{
+		Repository: repo,
+		LoadFunc: func(ctx context.Context, key string) (any, error) {
+			loadCount[key]++
+			return "value_" + key, nil
+		},
+		Expiration:        time.Minute,
+		AdaptiveTTLFactor: 2,
+		MaxAdaptiveTTL:    8 * time.Minute,
+	}
+	ttl := func(key string) time.Duration {
+		d, err := repo.TTL(ctx, key)
+		require.NoError(t, err)
+		return d
+	}
+
+	for _, key := range []string{"hot", "cold"} {
+		_, err := cache.Get(ctx, key)
+		require.NoError(t, err)
+		assert.Equal(t, time.Minute, ttl(key))
+	}
+
+	// 每次命中时过期时间翻倍，不超过上限
+	for _, want := range []time.Duration{2 * time.Minute, 4 * time.Minute, 8 * time.Minute, 8 * time.Minute} {
+		clock.Advance(50 * time.Second)
+		_, err := cache.Get(ctx, "hot")
+		require.NoError(t, err)
+		assert.Equal(t, want, ttl("hot"))
+	}
+
+	// 未读取的键按基础过期时间过期，经常读取的键已超过基础过期时间仍然有效
+	_, err := repo.TTL(ctx, "cold")
+	assert.ErrorIs(t, err, ErrCacheKeyNotFound)
+	_, err = cache.Get(ctx, "cold")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"hot": 1, "cold": 2}, loadCount)
+	assert.Equal(t, time.Minute, ttl("cold"))
+
+	// 停止读取后过期，重新加载时过期时间向基础值收缩
+	clock.Advance(8*time.Minute + time.Second)
+	_, err = cache.Get(ctx, "hot")
+	require.NoError(t, err)
+	assert.Equal(t, 2, loadCount["hot"])
+	assert.Equal(t, 4*time.Minute, ttl("hot"))
+
+	clock.Advance(4*time.Minute + time.Second)
+	_, err = cache.Get(ctx, "hot")
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Minute, ttl("hot"))
+
+	clock.Advance(2*time.Minute + time.Second)
+	_, err = cache.Get(ctx, "hot")
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, ttl("hot"))
+	assert.Equal(t, 4, loadCount["hot"])
+}
+
+// TestReadThroughCache_AdaptiveTTL_StateBounded 测试删除键时删除其状态，不断更替的键不会使状态无限增长
+func TestReadThroughCache_AdaptiveTTL_StateBounded(t *testing.T) {
+	ctx := context.Background()
+	clock := newFakeClock()
+	repo := NewBuildInMapCache(0, BuildInMapCacheWithClock(clock))
+	cache := &ReadThroughCache{
+		Repository: repo,
+		LoadFunc: func(ctx context.Context, key string) (any, error) {
+			return "value_" + key, nil
+		},
+		Expiration:        time.Minute,
+		AdaptiveTTLFactor: 2,
+		MaxAdaptiveTTL:    8 * time.Minute,
+		Clock:             clock,
+	}
+	stateCount := func() int {
+		cache.stateMu.Lock()
+		defer cache.stateMu.Unlock()
+		return len(cache.states)
+	}
+
+	// 只加载过一次的键没有状态，命中之后才记录自适应过期时间
+	_, err := cache.Get(ctx, "key1")
+	require.NoError(t, err)
+	assert.Equal(t, 0, stateCount())
+	_, err = cache.Get(ctx, "key1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, stateCount())
+	require.NoError(t, cache.Delete(ctx, "key1"))
+	assert.Equal(t, 0, stateCount())
+
+	// 每一轮读取一批新的键，之前的键过期后不再访问
+	const perRound = 500
+	for round := 0; round < 20; round++ {
+		for i := 0; i < perRound; i++ {
+			key := fmt.Sprintf("key_%d_%d", round, i)
+			for j := 0; j < 2; j++ {
+				_, err := cache.Get(ctx, key)
+				require.NoError(t, err)
+			}
+		}
+		clock.Advance(10 * time.Minute)
+	}
+	assert.LessOrEqual(t, stateCount(), 2*statePruneMinSize)
+}
+
+// TestReadThroughCache_AdaptiveTTL_Disabled 测试未启用自适应过期时间时命中不改变过期时间
+func TestReadThroughCache_AdaptiveTTL_Disabled(t *testing.T) {
+	ctx := context.Background()
+	clock := newFakeClock()
+	repo := NewBuildInMapCache(0, BuildInMapCacheWithClock(clock))
+	cache := &ReadThroughCache{
+		Repository: repo,
+		LoadFunc: func(ctx context.Context, key string) (any, error) {
+			return "value", nil
+		},
+		Expiration: time.Minute,
+		// 上限不大于基础过期时间时不启用
+		AdaptiveTTLFactor: 2,
+		MaxAdaptiveTTL:    time.Minute,
+	}
+
+	_, err := cache.Get(ctx, "key1")
+	require.NoError(t, err)
+	clock.Advance(30 * time.Second)
+	_, err = cache.Get(ctx, "key1")
+	require.NoError(t, err)
+	remaining, err := repo.TTL(ctx, "key1")
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, remaining)
+}
+
+// blockingDeleteCache 包装缓存，统计底层删除次数，删除在release关闭前阻塞
+type blockingDeleteCache struct {
+	*BuildInMapCache
+	deletes atomic.Int64
+	release chan struct{}
+}
+
+func (c *blockingDeleteCache) Delete(ctx context.Context, key string) error {
+	c.deletes.Add(1)
+	<-c.release
+	return c.BuildInMapCache.Delete(ctx, key)
+}
+
+// TestReadThroughCache_Delete_SingleFlight 测试同一个键的并发删除合并为一次底层删除和一次淘汰回调
+func TestReadThroughCache_Delete_SingleFlight(t *testing.T) {
+	ctx := context.Background()
+	var evicted atomic.Int64
+	repo := &blockingDeleteCache{
+		BuildInMapCache: NewBuildInMapCache(0, BuildInMapCacheWithEvictedCallback(func(key string, val any) {
+			evicted.Add(1)
+		})),
+		release: make(chan struct{}),
+	}
+	cache := &ReadThroughCache{
+		Repository: repo,
+		LoadFunc: func(ctx context.Context, key string) (any, error) {
+			return "value", nil
+		},
+		Expiration: time.Minute,
+	}
+	require.NoError(t, repo.Set(ctx, "key1", "value", time.Minute))
+
+	const concurrency = 20
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, cache.Delete(ctx, "key1"))
+		}()
+	}
+	assert.Eventually(t, func() bool {
+		return repo.deletes.Load() == 1
+	}, time.Second, time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	close(repo.release)
+	wg.Wait()
+
+	assert.Equal(t, int64(1), repo.deletes.Load())
+	assert.Equal(t, int64(1), evicted.Load())
+	_, err := repo.Get(ctx, "key1")
+	assert.ErrorIs(t, err, ErrCacheKeyNotFound)
+
+	// 删除完成后再次删除会重新执行
+	require.NoError(t, cache.Delete(ctx, "key1"))
+	assert.Equal(t, int64(2), repo.deletes.Load())
+}
+
+// TestReadThroughCache_SlidingExpiration 测试持续读取的键超过原过期时间仍然存活，停止读取后过期
+func TestReadThroughCache_SlidingExpiration(t *testing.T) {
+	ctx := context.Background()
+	clock := newFakeClock()
+	repo := NewBuildInMapCache(0, BuildInMapCacheWithClock(clock))
+	var loadCount int
+	cache := &ReadThroughCache{
+		Repository: repo,
+		LoadFunc: func(ctx context.Context, key string) (any, error) {
+			loadCount++
+			return "session", nil
+		},
+		Expiration:        time.Minute,
+		SlidingExpiration: true,
+	}
+
+	_, err := cache.Get(ctx, "session1")
+	require.NoError(t, err)
+
+	// 每40秒读取一次，累计超过原过期时间仍然命中
+	for i := 0; i < 5; i++ {
+		clock.Advance(40 * time.Second)
+		val, err := cache.Get(ctx, "session1")
+		require.NoError(t, err)
+		assert.Equal(t, "session", val)
+	}
+	assert.Equal(t, 1, loadCount)
+	remaining, err := repo.TTL(ctx, "session1")
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, remaining)
+
+	// 停止读取后按 Expiration 过期
+	clock.Advance(59 * time.Second)
+	_, err = repo.Get(ctx, "session1")
+	require.NoError(t, err)
+	clock.Advance(2 * time.Second)
+	_, err = repo.Get(ctx, "session1")
+	assert.ErrorIs(t, err, ErrCacheKeyNotFound)
+
+	// 过期后重新加载
+	_, err = cache.Get(ctx, "session1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, loadCount)
+}
+
+// TestReadThroughCache_SlidingExpiration_Disabled 测试未启用滑动过期时命中不重置过期时间
+func TestReadThroughCache_SlidingExpiration_Disabled(t *testing.T) {
+	ctx := context.Background()
+	clock := newFakeClock()
+	repo := NewBuildInMapCache(0, BuildInMapCacheWithClock(clock))
+	cache := &ReadThroughCache{
+		Repository: repo,
+		LoadFunc: func(ctx context.Context, key string) (any, error) {
+			return "value", nil
+		},
+		Expiration: time.Minute,
+	}
+
+	_, err := cache.Get(ctx, "key1")
+	require.NoError(t, err)
+	clock.Advance(40 * time.Second)
+	_, err = cache.Get(ctx, "key1")
+	require.NoError(t, err)
+	clock.Advance(30 * time.Second)
+	_, err = repo.Get(ctx, "key1")
+	assert.ErrorIs(t, err, ErrCacheKeyNotFound)
+}
+
+// TestReadThroughCache_StaleIfError 测试加载失败时返回旧值，错误通过回调报告
+func TestReadThroughCache_StaleIfError(t *testing.T) {
+	ctx := context.Background()
+	clock := newFakeClock()
+	loadErr := errors.New("数据源暂时不可用")
+	var version int
+	var failing bool
+	var reported []error
+	cache := &ReadThroughCache{
+		Repository: NewBuildInMapCache(0, BuildInMapCacheWithClock(clock)),
+		LoadFunc: func(ctx context.Context, key string) (any, error) {
+			if failing {
+				return nil, loadErr
+			}
+			version++
+			return fmt.Sprintf("v%d", version), nil
+		},
+		Expiration:   time.Minute,
+		StaleIfError: 10 * time.Minute,
+		OnStaleError: func(key string, err error) {
+			assert.Equal(t, "key1", key)
+			reported = append(reported, err)
+		},
+		Clock: clock,
+	}
+
+	val, err := cache.Get(ctx, "key1")
+	require.NoError(t, err)
+	assert.Equal(t, "v1", val)
+
+	// 过期前命中缓存，返回的是原始值
+	clock.Advance(30 * time.Second)
+	val, err = cache.Get(ctx, "key1")
+	require.NoError(t, err)
+	assert.Equal(t, "v1", val)
+
+	// 过期后加载成功时返回新值
+	clock.Advance(time.Minute)
+	val, err = cache.Get(ctx, "key1")
+	require.NoError(t, err)
+	assert.Equal(t, "v2", val)
+
+	// 过期后加载失败时返回旧值，错误通过回调报告
+	failing = true
+	clock.Advance(2 * time.Minute)
+	val, err = cache.Get(ctx, "key1")
+	require.NoError(t, err)
+	assert.Equal(t, "v2", val)
+	require.Len(t, reported, 1)
+	assert.ErrorIs(t, reported[0], loadErr)
+
+	// 数据源恢复后重新加载
+	failing = false
+	val, err = cache.Get(ctx, "key1")
+	require.NoError(t, err)
+	assert.Equal(t, "v3", val)
+
+	// 超过允许使用旧值的时长后缓存项真正过期，加载失败返回错误
+	failing = true
+	clock.Advance(12 * time.Minute)
+	_, err = cache.Get(ctx, "key1")
+	assert.ErrorIs(t, err, loadErr)
+	assert.Len(t, reported, 1)
+
+	assert.Equal(t, ReadThroughCacheStats{Hits: 1, LoaderCalls: 5, LoaderErrors: 2}, cache.Stats())
+}
+
+// TestReadThroughCache_StaleIfError_GetMany 测试批量获取同样按过期时间重新加载，加载失败时返回旧值
+func TestReadThroughCache_StaleIfError_GetMany(t *testing.T) {
+	ctx := context.Background()
+	clock := newFakeClock()
+	repo := NewBuildInMapCache(0, BuildInMapCacheWithClock(clock))
+	loadErr := errors.New("数据源暂时不可用")
+	var reported []string
+	cache := &ReadThroughCache{
+		Repository: repo,
+		LoadFunc: func(ctx context.Context, key string) (any, error) {
+			return "single_" + key, nil
+		},
+		Expiration:   time.Minute,
+		StaleIfError: 10 * time.Minute,
+		OnStaleError: func(key string, err error) {
+			assert.ErrorIs(t, err, loadErr)
+			reported = append(reported, key)
+		},
+		Clock: clock,
+	}
+	var loaded [][]string
+	version := "v1"
+	failing := false
+	batchLoader := func(ctx context.Context, missing []string) (map[string]any, error) {
+		loaded = append(loaded, missing)
+		if failing {
+			return nil, loadErr
+		}
+		vals := make(map[string]any, len(missing))
+		for _, key := range missing {
+			vals[key] = version + "_" + key
+		}
+		return vals, nil
+	}
+
+	// Get 和批量加载写入的都是原始值，批量加载的键同样带有旧值窗口
+	_, err := cache.Get(ctx, "key1")
+	require.NoError(t, err)
+	vals, err := cache.GetManyWithLoader(ctx, []string{"key1", "key2"}, batchLoader, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"key1": "single_key1", "key2": "v1_key2"}, vals)
+	raw, err := repo.Get(ctx, "key2")
+	require.NoError(t, err)
+	assert.Equal(t, "v1_key2", raw)
+	ttl, err := repo.TTL(ctx, "key2")
+	require.NoError(t, err)
+	assert.Equal(t, 11*time.Minute, ttl)
+
+	// 过期时间之后不会把旧值当作新值返回，而是重新加载
+	clock.Advance(2 * time.Minute)
+	version = "v2"
+	vals, err = cache.GetManyWithLoader(ctx, []string{"key1", "key2"}, batchLoader, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"key1": "v2_key1", "key2": "v2_key2"}, vals)
+	assert.Equal(t, [][]string{{"key2"}, {"key1", "key2"}}, loaded)
+
+	// 加载失败且需要加载的键都有旧值时返回旧值
+	clock.Advance(2 * time.Minute)
+	failing = true
+	vals, err = cache.GetManyWithLoader(ctx, []string{"key1", "key2"}, batchLoader, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"key1": "v2_key1", "key2": "v2_key2"}, vals)
+	assert.ElementsMatch(t, []string{"key1", "key2"}, reported)
+
+	// 有键没有旧值时加载失败返回错误
+	_, err = cache.GetManyWithLoader(ctx, []string{"key1", "key3"}, batchLoader, time.Minute)
+	assert.ErrorIs(t, err, loadErr)
+}
+
+// TestReadThroughCache_StaleIfError_Promoted 测试底层仓储和嵌入的方法只看到原始值
+func TestReadThroughCache_StaleIfError_Promoted(t *testing.T) {
+	ctx := context.Background()
+	clock := newFakeClock()
+	repo := NewBuildInMapCache(0, BuildInMapCacheWithClock(clock))
+	var loads int
+	cache := &ReadThroughCache{
+		Repository: repo,
+		LoadFunc: func(ctx context.Context, key string) (any, error) {
+			loads++
+			return fmt.Sprintf("loaded%d", loads), nil
+		},
+		Expiration:   time.Minute,
+		StaleIfError: 10 * time.Minute,
+		Clock:        clock,
+	}
+
+	_, err := cache.Get(ctx, "key1")
+	require.NoError(t, err)
+	raw, err := repo.Get(ctx, "key1")
+	require.NoError(t, err)
+	assert.Equal(t, "loaded1", raw)
+
+	// LoadAndDelete 返回原始值，并删除旧值窗口
+	val, err := cache.LoadAndDelete(ctx, "key1")
+	require.NoError(t, err)
+	assert.Equal(t, "loaded1", val)
+	assert.Empty(t, cache.states)
+
+	// 直接写入的值按新值对待，不会因为之前加载的值过期而被重新加载
+	_, err = cache.Get(ctx, "key2")
+	require.NoError(t, err)
+	require.NoError(t, cache.Set(ctx, "key2", "manual", time.Hour))
+	clock.Advance(2 * time.Minute)
+	val, err = cache.Get(ctx, "key2")
+	require.NoError(t, err)
+	assert.Equal(t, "manual", val)
+	assert.Equal(t, 2, loads)
+}
+
+// TestReadThroughCache_StaleIfError_Sliding 测试滑动过期延长过期时间时保留旧值窗口
+func TestReadThroughCache_StaleIfError_Sliding(t *testing.T) {
+	ctx := context.Background()
+	clock := newFakeClock()
+	repo := NewBuildInMapCache(0, BuildInMapCacheWithClock(clock))
+	failing := false
+	cache := &ReadThroughCache{
+		Repository: repo,
+		LoadFunc: func(ctx context.Context, key string) (any, error) {
+			if failing {
+				return nil, errors.New("数据源暂时不可用")
+			}
+			return "value", nil
+		},
+		Expiration:        time.Minute,
+		SlidingExpiration: true,
+		StaleIfError:      10 * time.Minute,
+		Clock:             clock,
+	}
+
+	_, err := cache.Get(ctx, "key1")
+	require.NoError(t, err)
+	clock.Advance(30 * time.Second)
+	_, err = cache.Get(ctx, "key1")
+	require.NoError(t, err)
+	ttl, err := repo.TTL(ctx, "key1")
+	require.NoError(t, err)
+	assert.Equal(t, 11*time.Minute, ttl)
+
+	// 滑动后的过期时间之后加载失败仍然返回旧值
+	failing = true
+	clock.Advance(5 * time.Minute)
+	val, err := cache.Get(ctx, "key1")
+	require.NoError(t, err)
+	assert.Equal(t, "value", val)
+}
@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	domainCache "github.com/justinwongcn/hamster/internal/domain/cache"
+)
+
+// ReadWriteThroughCache 同时实现读透和写透的缓存
+// Get 未命中时通过 LoadFunc 从数据源加载并写入缓存，Set 先通过 StoreFunc 写入数据源再写入缓存，
+// 读写共用同一个底层缓存和singleflight，同一个键的加载和写入依次执行，
+// 避免写入之前开始的加载在写入之后用旧值覆盖缓存
+type ReadWriteThroughCache struct {
+	ReadThroughCache
+	StoreFunc func(ctx context.Context, key string, val any) error
+}
+
+// NewReadWriteThroughCache 创建读写穿透缓存
+// 参数:
+//   - repo: 底层缓存
+//   - loadFunc: 未命中时从数据源加载数据的函数
+//   - storeFunc: 写入数据源的函数
+//   - expiration: 缓存过期时间，0表示永不过期
+//
+// 返回值:
+//   - *ReadWriteThroughCache: 读写穿透缓存实例
+func NewReadWriteThroughCache(repo domainCache.Repository,
+	loadFunc func(ctx context.Context, key string) (any, error),
+	storeFunc func(ctx context.Context, key string, val any) error,
+	expiration time.Duration) *ReadWriteThroughCache {
+	return &ReadWriteThroughCache{
+		ReadThroughCache: ReadThroughCache{
+			Repository: repo,
+			LoadFunc:   loadFunc,
+			Expiration: expiration,
+		},
+		StoreFunc: storeFunc,
+	}
+}
+
+// Set 先写入数据源再写入缓存
+// 参数:
+//   - ctx: 上下文
+//   - key: 缓存键
+//   - val: 缓存值
+//   - expiration: 过期时间
+//
+// 返回值:
+//   - error: 错误信息，写入数据源失败时不写入缓存
+//
+// 功能:
+//   - 与该键正在进行的加载或写入通过singleflight依次执行，不会合并到其他调用中
+//   - 写入期间同一个键的 Get 等待写入完成并返回写入的值
+func (c *ReadWriteThroughCache) Set(ctx context.Context, key string, val any, expiration time.Duration) error {
+	for {
+		var ran bool
+		_, err, _ := c.g.Do(key, func() (any, error) {
+			ran = true
+			if err := c.StoreFunc(ctx, key, val); err != nil {
+				return nil, err
+			}
+			c.clearState(key)
+			return val, c.Repository.Set(ctx, key, val, expiration)
+		})
+		if ran {
+			return err
+		}
+		// 等待的是同一个键的其他加载或写入，完成后再执行本次写入
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
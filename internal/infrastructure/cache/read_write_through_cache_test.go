@@ -0,0 +1,139 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestReadWriteThroughCache_Get 测试未命中时从数据源加载并写入缓存
+func TestReadWriteThroughCache_Get(t *testing.T) {
+	ctx := context.Background()
+	mockCache := &MockCache{store: make(map[string]any)}
+	source := map[string]any{"key1": "value1"}
+	var loaded []string
+	cache := NewReadWriteThroughCache(mockCache,
+		func(ctx context.Context, key string) (any, error) {
+			loaded = append(loaded, key)
+			val, ok := source[key]
+			if !ok {
+				return nil, errors.New("not found in source")
+			}
+			return val, nil
+		},
+		func(ctx context.Context, key string, val any) error {
+			source[key] = val
+			return nil
+		},
+		time.Minute)
+
+	val, err := cache.Get(ctx, "key1")
+	require.NoError(t, err)
+	assert.Equal(t, "value1", val)
+	assert.Equal(t, "value1", mockCache.store["key1"])
+
+	// 命中时不再加载
+	val, err = cache.Get(ctx, "key1")
+	require.NoError(t, err)
+	assert.Equal(t, "value1", val)
+	assert.Equal(t, []string{"key1"}, loaded)
+
+	// 加载失败时不写入缓存
+	_, err = cache.Get(ctx, "absent")
+	assert.Error(t, err)
+	assert.NotContains(t, mockCache.store, "absent")
+	assert.Equal(t, ReadThroughCacheStats{Hits: 1, LoaderCalls: 2, LoaderErrors: 1}, cache.Stats())
+}
+
+// TestReadWriteThroughCache_Set 测试先写入数据源再写入缓存
+func TestReadWriteThroughCache_Set(t *testing.T) {
+	ctx := context.Background()
+	mockCache := &MockCache{store: make(map[string]any)}
+	var steps []string
+	storeErr := errors.New("store failed")
+	failKey := "fail"
+	cache := NewReadWriteThroughCache(mockCache,
+		func(ctx context.Context, key string) (any, error) {
+			steps = append(steps, "load:"+key)
+			return "loaded_" + key, nil
+		},
+		func(ctx context.Context, key string, val any) error {
+			if key == failKey {
+				return storeErr
+			}
+			// 写入数据源时缓存中还没有新值
+			_, cached := mockCache.store[key]
+			assert.False(t, cached)
+			steps = append(steps, "store:"+key)
+			return nil
+		},
+		time.Minute)
+
+	require.NoError(t, cache.Set(ctx, "key1", "value1", time.Minute))
+	assert.Equal(t, "value1", mockCache.store["key1"])
+
+	// 写入后读取命中缓存，不调用加载函数
+	val, err := cache.Get(ctx, "key1")
+	require.NoError(t, err)
+	assert.Equal(t, "value1", val)
+	assert.Equal(t, []string{"store:key1"}, steps)
+
+	// 写入数据源失败时不写入缓存
+	err = cache.Set(ctx, failKey, "value", time.Minute)
+	assert.ErrorIs(t, err, storeErr)
+	assert.NotContains(t, mockCache.store, failKey)
+
+	// 写入缓存失败时返回错误，数据源已写入
+	mockCache.setShouldFail = true
+	err = cache.Set(ctx, "key2", "value2", time.Minute)
+	assert.Error(t, err)
+	assert.Equal(t, []string{"store:key1", "store:key2"}, steps)
+}
+
+// TestReadWriteThroughCache_SetWaitsForLoad 测试写入等待同一个键正在进行的加载，加载的旧值不会覆盖写入的值
+func TestReadWriteThroughCache_SetWaitsForLoad(t *testing.T) {
+	ctx := context.Background()
+	mockCache := &MockCache{store: make(map[string]any)}
+	loading := make(chan struct{})
+	release := make(chan struct{})
+	cache := NewReadWriteThroughCache(mockCache,
+		func(ctx context.Context, key string) (any, error) {
+			close(loading)
+			<-release
+			return "old", nil
+		},
+		func(ctx context.Context, key string, val any) error {
+			return nil
+		},
+		time.Minute)
+
+	getDone := make(chan any)
+	go func() {
+		val, _ := cache.Get(ctx, "key1")
+		getDone <- val
+	}()
+	<-loading
+
+	setDone := make(chan error)
+	go func() {
+		setDone <- cache.Set(ctx, "key1", "new", time.Minute)
+	}()
+
+	// 加载完成前写入一直等待
+	select {
+	case <-setDone:
+		t.Fatal("写入没有等待正在进行的加载")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+
+	assert.Equal(t, "old", <-getDone)
+	require.NoError(t, <-setDone)
+	val, err := cache.Get(ctx, "key1")
+	require.NoError(t, err)
+	assert.Equal(t, "new", val)
+}
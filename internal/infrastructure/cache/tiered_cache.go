@@ -0,0 +1,175 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	domainCache "github.com/justinwongcn/hamster/internal/domain/cache"
+)
+
+// TieredCacheOption 多级缓存的配置选项
+type TieredCacheOption func(c *TieredCache)
+
+// TieredCache 两级缓存
+// L1通常是容量较小的本地缓存，L2是容量较大或远程的缓存，L2中的数据是权威数据
+// 读取时先查L1，L1未命中时查L2，L2命中后将缓存项提升到L1，之后的读取由L1直接返回
+type TieredCache struct {
+	l1 domainCache.Repository
+	l2 domainCache.Repository
+	// l1TTL 写入L1的缓存项的过期时间
+	l1TTL time.Duration
+	// promote 是否在L2命中时提升到L1
+	promote bool
+
+	l1Hits     atomic.Int64
+	l2Hits     atomic.Int64
+	misses     atomic.Int64
+	promotions atomic.Int64
+}
+
+// TieredCacheStats 多级缓存的统计信息
+type TieredCacheStats struct {
+	// L1Hits L1命中次数
+	L1Hits int64
+	// L2Hits L1未命中、L2命中的次数
+	L2Hits int64
+	// Misses 两级缓存都未命中的次数
+	Misses int64
+	// Promotions 从L2提升到L1的次数
+	Promotions int64
+}
+
+// NewTieredCache 创建两级缓存
+// l1: 一级缓存
+// l2: 二级缓存
+// opts: 可选配置项
+// 返回: TieredCache实例
+func NewTieredCache(l1, l2 domainCache.Repository, opts ...TieredCacheOption) *TieredCache {
+	res := &TieredCache{
+		l1:      l1,
+		l2:      l2,
+		l1TTL:   time.Minute,
+		promote: true,
+	}
+	for _, opt := range opts {
+		opt(res)
+	}
+	return res
+}
+
+// TieredCacheWithL1TTL 设置写入L1的缓存项的过期时间
+// 提升和写入L1时都不超过该时间，避免L1长期保留L2中已更新的数据
+// ttl: 过期时间，不大于0时忽略，默认1分钟
+func TieredCacheWithL1TTL(ttl time.Duration) TieredCacheOption {
+	return func(c *TieredCache) {
+		if ttl > 0 {
+			c.l1TTL = ttl
+		}
+	}
+}
+
+// TieredCacheWithPromotion 设置L2命中时是否提升到L1
+// 扫描大量冷数据的场景可以关闭提升，避免冷数据挤占L1
+// enabled: 是否提升，默认开启
+func TieredCacheWithPromotion(enabled bool) TieredCacheOption {
+	return func(c *TieredCache) {
+		c.promote = enabled
+	}
+}
+
+// Get 获取缓存值，L2命中时按配置提升到L1
+// 与 GetWithPromotion 相同
+func (t *TieredCache) Get(ctx context.Context, key string) (any, error) {
+	return t.GetWithPromotion(ctx, key)
+}
+
+// GetWithPromotion 获取缓存值，L2命中时按配置提升到L1
+// 提升使用L1的过期时间，L2能返回剩余存活时间时取两者中较小的，提升失败不影响读取结果
+// 返回: 缓存值和错误信息，两级缓存都未命中时返回L2的错误
+func (t *TieredCache) GetWithPromotion(ctx context.Context, key string) (any, error) {
+	if val, err := t.l1.Get(ctx, key); err == nil {
+		t.l1Hits.Add(1)
+		return val, nil
+	}
+
+	val, err := t.l2.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, ErrCacheKeyNotFound) {
+			t.misses.Add(1)
+		}
+		return nil, err
+	}
+	t.l2Hits.Add(1)
+
+	if t.promote {
+		if err = t.l1.Set(ctx, key, val, t.promotionTTL(ctx, key)); err == nil {
+			t.promotions.Add(1)
+		}
+	}
+	return val, nil
+}
+
+// promotionTTL 计算提升到L1的缓存项的过期时间
+func (t *TieredCache) promotionTTL(ctx context.Context, key string) time.Duration {
+	repo, ok := t.l2.(interface {
+		TTL(ctx context.Context, key string) (time.Duration, error)
+	})
+	if !ok {
+		return t.l1TTL
+	}
+	remaining, err := repo.TTL(ctx, key)
+	if err != nil || remaining <= 0 {
+		return t.l1TTL
+	}
+	return min(remaining, t.l1TTL)
+}
+
+// Set 先写入L2再写入L1
+// 写入L1的过期时间不超过L1的过期时间
+// 返回: 错误信息，写入L2失败时不写入L1
+func (t *TieredCache) Set(ctx context.Context, key string, val any, expiration time.Duration) error {
+	if err := t.l2.Set(ctx, key, val, expiration); err != nil {
+		return err
+	}
+	l1Expiration := t.l1TTL
+	if expiration > 0 {
+		l1Expiration = min(expiration, t.l1TTL)
+	}
+	return t.l1.Set(ctx, key, val, l1Expiration)
+}
+
+// Delete 从两级缓存中删除缓存项
+// 先删除L1，避免删除L2之后的读取再次提升旧数据
+func (t *TieredCache) Delete(ctx context.Context, key string) error {
+	if err := t.l1.Delete(ctx, key); err != nil {
+		return err
+	}
+	return t.l2.Delete(ctx, key)
+}
+
+// LoadAndDelete 从两级缓存中删除缓存项，返回L2中的值
+// 返回: 被删除的值和错误信息，键不在L2中时返回L2的错误
+func (t *TieredCache) LoadAndDelete(ctx context.Context, key string) (any, error) {
+	if err := t.l1.Delete(ctx, key); err != nil {
+		return nil, err
+	}
+	return t.l2.LoadAndDelete(ctx, key)
+}
+
+// OnEvicted 设置L2中的缓存项被淘汰时的回调函数
+// L1中的缓存项只是L2的副本，其淘汰不触发回调
+func (t *TieredCache) OnEvicted(fn func(key string, val any)) {
+	t.l2.OnEvicted(fn)
+}
+
+// Stats 获取统计信息的快照
+func (t *TieredCache) Stats() TieredCacheStats {
+	return TieredCacheStats{
+		L1Hits:     t.l1Hits.Load(),
+		L2Hits:     t.l2Hits.Load(),
+		Misses:     t.misses.Load(),
+		Promotions: t.promotions.Load(),
+	}
+}
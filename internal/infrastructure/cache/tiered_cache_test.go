@@ -0,0 +1,112 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTieredCache_Promotion 测试L2命中后提升到L1，第二次读取由L1返回
+func TestTieredCache_Promotion(t *testing.T) {
+	ctx := context.Background()
+	clock := newFakeClock()
+	l1 := NewBuildInMapCache(0, BuildInMapCacheWithClock(clock))
+	l2 := NewBuildInMapCache(0, BuildInMapCacheWithClock(clock))
+	c := NewTieredCache(l1, l2, TieredCacheWithL1TTL(time.Minute))
+
+	require.NoError(t, l2.Set(ctx, "key1", "value1", time.Hour))
+	require.NoError(t, l2.Set(ctx, "key2", "value2", 30*time.Second))
+
+	val, err := c.GetWithPromotion(ctx, "key1")
+	require.NoError(t, err)
+	assert.Equal(t, "value1", val)
+	assert.Equal(t, TieredCacheStats{L2Hits: 1, Promotions: 1}, c.Stats())
+
+	// 第二次读取由L1返回，使用L1的过期时间
+	val, err = c.Get(ctx, "key1")
+	require.NoError(t, err)
+	assert.Equal(t, "value1", val)
+	assert.Equal(t, TieredCacheStats{L1Hits: 1, L2Hits: 1, Promotions: 1}, c.Stats())
+	ttl, err := l1.TTL(ctx, "key1")
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, ttl)
+
+	// L2中剩余存活时间更短时，L1不会比L2活得更久
+	_, err = c.Get(ctx, "key2")
+	require.NoError(t, err)
+	ttl, err = l1.TTL(ctx, "key2")
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, ttl)
+
+	// L1过期后再次从L2读取并提升
+	clock.Advance(2 * time.Minute)
+	val, err = c.Get(ctx, "key1")
+	require.NoError(t, err)
+	assert.Equal(t, "value1", val)
+	assert.Equal(t, int64(3), c.Stats().Promotions)
+
+	_, err = c.Get(ctx, "absent")
+	assert.ErrorIs(t, err, ErrCacheKeyNotFound)
+	assert.Equal(t, int64(1), c.Stats().Misses)
+}
+
+// TestTieredCache_NoPromotion 测试关闭提升后L1保持不变
+func TestTieredCache_NoPromotion(t *testing.T) {
+	ctx := context.Background()
+	l1 := NewBuildInMapCache(0)
+	l2 := NewBuildInMapCache(0)
+	c := NewTieredCache(l1, l2, TieredCacheWithPromotion(false))
+
+	require.NoError(t, l2.Set(ctx, "key1", "value1", time.Minute))
+	for range 3 {
+		val, err := c.GetWithPromotion(ctx, "key1")
+		require.NoError(t, err)
+		assert.Equal(t, "value1", val)
+	}
+
+	assert.Equal(t, 0, l1.Len())
+	assert.Equal(t, TieredCacheStats{L2Hits: 3}, c.Stats())
+}
+
+// TestTieredCache_Write 测试写入和删除同时作用于两级缓存
+func TestTieredCache_Write(t *testing.T) {
+	ctx := context.Background()
+	clock := newFakeClock()
+	l1 := NewBuildInMapCache(0, BuildInMapCacheWithClock(clock))
+	l2 := NewBuildInMapCache(0, BuildInMapCacheWithClock(clock))
+	c := NewTieredCache(l1, l2, TieredCacheWithL1TTL(time.Minute))
+	var evicted []string
+	c.OnEvicted(func(key string, _ any) {
+		evicted = append(evicted, key)
+	})
+
+	// 永不过期的缓存项在L1中使用L1的过期时间
+	require.NoError(t, c.Set(ctx, "key1", "value1", 0))
+	ttl, err := l1.TTL(ctx, "key1")
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, ttl)
+	ttl, err = l2.TTL(ctx, "key1")
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(0), ttl)
+
+	require.NoError(t, c.Set(ctx, "key2", "value2", time.Second))
+	ttl, err = l1.TTL(ctx, "key2")
+	require.NoError(t, err)
+	assert.Equal(t, time.Second, ttl)
+
+	require.NoError(t, c.Delete(ctx, "key1"))
+	_, err = c.Get(ctx, "key1")
+	assert.ErrorIs(t, err, ErrCacheKeyNotFound)
+
+	val, err := c.LoadAndDelete(ctx, "key2")
+	require.NoError(t, err)
+	assert.Equal(t, "value2", val)
+	assert.Equal(t, 0, l1.Len())
+	assert.Equal(t, 0, l2.Len())
+
+	// 只有L2的淘汰触发回调
+	assert.Equal(t, []string{"key1", "key2"}, evicted)
+}
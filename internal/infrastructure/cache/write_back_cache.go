@@ -3,7 +3,9 @@ package cache
 import (
 	"context"
 	"fmt"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/justinwongcn/hamster/internal/domain/cache"
@@ -13,28 +15,142 @@ import (
 // 写入时只更新缓存，不立即写入持久化存储
 // 通过异步批量写入或定时刷新的方式将脏数据写入持久化存储
 type WriteBackCache struct {
-	cache.Repository                 // 嵌入领域仓储接口
-	dirtyKeys        map[string]bool // 脏数据键集合
-	dirtyMutex       sync.RWMutex    // 脏数据锁
-	flushInterval    time.Duration   // 刷新间隔
-	batchSize        int             // 批量大小
-	lastFlushTime    time.Time       // 上次刷新时间
-	flushMutex       sync.Mutex      // 刷新锁
+	cache.Repository                   // 嵌入领域仓储接口
+	dirtyKeys        map[string]bool   // 脏数据键集合
+	dirtyMutex       sync.RWMutex      // 脏数据锁
+	flushInterval    time.Duration     // 刷新间隔
+	batchSize        int               // 批量大小
+	lastFlushTime    time.Time         // 上次刷新时间
+	flushMutex       sync.Mutex        // 刷新锁
+	onFlushError     func(error)       // 自动刷新失败时的回调
+	sizeOf           SizeEstimator     // 缓存值大小估算函数
+	dirtySizes       map[string]int64  // 每个脏数据键的估算字节数
+	dirtyBytes       int64             // 脏数据的估算总字节数
+	dirtySeqs        map[string]uint64 // 每个脏数据键最后一次写入的序号
+	writeSeq         atomic.Uint64     // 写入序号，刷新时用于判断刷新期间是否有新的写入
+	flushConcurrency int               // 刷新时并行写入的worker数量
+	journal          *WriteBackJournal // 预写日志，为nil时不记录
+}
+
+// WriteBackCacheOption 写回缓存的配置选项
+type WriteBackCacheOption func(*WriteBackCache)
+
+// WriteBackCacheWithFlushConcurrency 设置刷新时并行写入持久化存储的worker数量
+// 存储器较慢且脏数据较多时，可以并行写入以缩短刷新时间，存储器必须是并发安全的
+// 并行写入时不同键之间的写入顺序没有任何保证
+// n: worker数量，不大于1时顺序写入
+func WriteBackCacheWithFlushConcurrency(n int) WriteBackCacheOption {
+	return func(w *WriteBackCache) {
+		if n > 1 {
+			w.flushConcurrency = n
+		}
+	}
+}
+
+// SizeEstimator 估算缓存值占用的字节数
+type SizeEstimator func(val any) int64
+
+// defaultSizeEstimator 默认的大小估算函数
+// 只能确定[]byte和string的大小，其他类型计为0，需要时可以通过 SetSizeEstimator 替换
+func defaultSizeEstimator(val any) int64 {
+	switch v := val.(type) {
+	case []byte:
+		return int64(len(v))
+	case string:
+		return int64(len(v))
+	default:
+		return 0
+	}
+}
+
+// WriteBackCacheWithJournal 为写回缓存启用预写日志
+// 脏数据先追加到日志再写入缓存，创建缓存时将日志中未刷新的脏数据恢复到缓存并标记为脏数据，
+// 避免进程崩溃丢失尚未刷新的写入；日志的关闭由调用方负责
+// journal: 通过 NewWriteBackJournal 打开的日志
+func WriteBackCacheWithJournal(journal *WriteBackJournal) WriteBackCacheOption {
+	return func(w *WriteBackCache) {
+		w.journal = journal
+	}
 }
 
 // NewWriteBackCache 创建写回缓存实例
 // repository: 底层缓存仓储
 // flushInterval: 刷新间隔
 // batchSize: 批量大小
+// opts: 可选配置项
 // 返回: WriteBackCache实例
-func NewWriteBackCache(repository cache.Repository, flushInterval time.Duration, batchSize int) *WriteBackCache {
-	return &WriteBackCache{
-		Repository:    repository,
-		dirtyKeys:     make(map[string]bool),
-		flushInterval: flushInterval,
-		batchSize:     batchSize,
-		lastFlushTime: time.Now(),
+func NewWriteBackCache(repository cache.Repository, flushInterval time.Duration, batchSize int, opts ...WriteBackCacheOption) *WriteBackCache {
+	w := &WriteBackCache{
+		Repository:       repository,
+		dirtyKeys:        make(map[string]bool),
+		dirtySizes:       make(map[string]int64),
+		dirtySeqs:        make(map[string]uint64),
+		flushInterval:    flushInterval,
+		batchSize:        batchSize,
+		lastFlushTime:    time.Now(),
+		onFlushError:     func(error) {},
+		sizeOf:           defaultSizeEstimator,
+		flushConcurrency: 1,
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	if w.journal != nil {
+		w.recoverJournal()
 	}
+	return w
+}
+
+// recoverJournal 将日志中恢复的脏数据写入缓存并标记为脏数据
+// 写入缓存失败的数据仍保留在日志中，下次启动时再次恢复
+func (w *WriteBackCache) recoverJournal() {
+	ctx := context.Background()
+	for _, entry := range w.journal.takeRecovered() {
+		var expiration time.Duration
+		if !entry.deadline.IsZero() {
+			expiration = time.Until(entry.deadline)
+			if expiration <= 0 {
+				continue
+			}
+		}
+		if err := w.Repository.Set(ctx, entry.key, entry.val, expiration); err != nil {
+			continue
+		}
+		// 之后的写入序号从恢复的最大序号之后开始，与日志中的记录保持一致
+		if entry.seq > w.writeSeq.Load() {
+			w.writeSeq.Store(entry.seq)
+		}
+		w.markDirty(entry.key, entry.seq, entry.val)
+	}
+}
+
+// journalDelete 脏数据被单独清理后在日志中记录删除
+// 记录失败时忽略，重启后最多重复刷新一次该数据
+func (w *WriteBackCache) journalDelete(key string) {
+	if w.journal != nil {
+		_ = w.journal.appendDelete(key)
+	}
+}
+
+// SetSizeEstimator 设置估算缓存值大小的函数，用于统计 DirtyBytes
+// 应在写入数据之前调用，已有脏数据的大小不会重新估算
+// fn: 大小估算函数，为nil时恢复默认实现
+func (w *WriteBackCache) SetSizeEstimator(fn SizeEstimator) {
+	if fn == nil {
+		fn = defaultSizeEstimator
+	}
+	w.sizeOf = fn
+}
+
+// SetFlushErrorHandler 设置自动刷新失败时的回调函数
+// StartAutoFlush 每次刷新失败都会调用该回调，可用于在持续失败时告警
+// 应在 StartAutoFlush 之前调用
+// fn: 回调函数，参数为刷新返回的错误；为nil时恢复为忽略错误
+func (w *WriteBackCache) SetFlushErrorHandler(fn func(err error)) {
+	if fn == nil {
+		fn = func(error) {}
+	}
+	w.onFlushError = fn
 }
 
 // SetDirty 设置缓存值并标记为脏数据
@@ -45,18 +161,39 @@ func NewWriteBackCache(repository cache.Repository, flushInterval time.Duration,
 // expiration: 过期时间
 // 返回: 操作错误
 func (w *WriteBackCache) SetDirty(ctx context.Context, key string, val any, expiration time.Duration) error {
-	// 先写入缓存
+	seq := w.writeSeq.Add(1)
+
+	// 启用预写日志时先写日志
+	if w.journal != nil {
+		var deadline time.Time
+		if expiration > 0 {
+			deadline = time.Now().Add(expiration)
+		}
+		if err := w.journal.appendSet(key, seq, val, deadline); err != nil {
+			return err
+		}
+	}
+
+	// 再写入缓存
 	err := w.Repository.Set(ctx, key, val, expiration)
 	if err != nil {
 		return fmt.Errorf("写入缓存失败: %w", err)
 	}
 
-	// 标记为脏数据
+	w.markDirty(key, seq, val)
+	return nil
+}
+
+// markDirty 标记为脏数据，覆盖已有脏数据时替换其大小和写入序号
+// 写入缓存之后才标记，刷新时读取到某个序号时，缓存中的值不会比该序号的写入更旧
+func (w *WriteBackCache) markDirty(key string, seq uint64, val any) {
+	size := w.sizeOf(val)
 	w.dirtyMutex.Lock()
 	w.dirtyKeys[key] = true
+	w.dirtyBytes += size - w.dirtySizes[key]
+	w.dirtySizes[key] = size
+	w.dirtySeqs[key] = max(w.dirtySeqs[key], seq)
 	w.dirtyMutex.Unlock()
-
-	return nil
 }
 
 // FlushKey 强制将指定键的脏数据写入持久化存储
@@ -65,12 +202,9 @@ func (w *WriteBackCache) SetDirty(ctx context.Context, key string, val any, expi
 // storer: 数据存储函数
 // 返回: 操作错误
 func (w *WriteBackCache) FlushKey(ctx context.Context, key string, storer func(ctx context.Context, key string, val any) error) error {
-	// 检查键是否为脏数据
-	w.dirtyMutex.RLock()
-	isDirty := w.dirtyKeys[key]
-	w.dirtyMutex.RUnlock()
-
-	if !isDirty {
+	// 检查键是否为脏数据，并记录读取缓存前的写入序号
+	seqs := w.snapshotDirty([]string{key})
+	if len(seqs) == 0 {
 		return fmt.Errorf("键 %s 不存在或不是脏数据", key)
 	}
 
@@ -86,15 +220,18 @@ func (w *WriteBackCache) FlushKey(ctx context.Context, key string, storer func(c
 		return fmt.Errorf("写入持久化存储失败: %w", err)
 	}
 
-	// 标记为干净数据
-	w.dirtyMutex.Lock()
-	delete(w.dirtyKeys, key)
-	w.dirtyMutex.Unlock()
+	// 标记为干净数据，刷新期间有新的写入时保持为脏数据
+	w.clearFlushed(seqs)
+	if w.journal != nil {
+		_ = w.journal.compact(seqs)
+	}
 
 	return nil
 }
 
 // Flush 强制将所有脏数据写入持久化存储
+// 配置了 WriteBackCacheWithFlushConcurrency 时由多个worker并行写入
+// 上下文被取消后不再写入剩余的键，这些键保持为脏数据
 // ctx: 上下文
 // storer: 数据存储函数
 // 返回: 操作错误
@@ -103,48 +240,206 @@ func (w *WriteBackCache) Flush(ctx context.Context, storer func(ctx context.Cont
 	defer w.flushMutex.Unlock()
 
 	// 获取所有脏数据键
-	w.dirtyMutex.RLock()
-	dirtyKeys := make([]string, 0, len(w.dirtyKeys))
-	for key := range w.dirtyKeys {
+	seqs := w.snapshotDirty(nil)
+	dirtyKeys := make([]string, 0, len(seqs))
+	for key := range seqs {
 		dirtyKeys = append(dirtyKeys, key)
 	}
-	w.dirtyMutex.RUnlock()
 
+	return w.flush(ctx, dirtyKeys, seqs, storer)
+}
+
+// FlushKeys 强制将指定的一组脏数据写入持久化存储，其他脏数据保持待刷新
+// 用于持久化某个事务涉及的键，不存在或不是脏数据的键会被跳过
+// 与 Flush 相同，上下文被取消后停止写入
+// ctx: 上下文
+// keys: 要刷新的缓存键
+// storer: 数据存储函数
+// 返回: 操作错误，包含所有刷新失败的键
+func (w *WriteBackCache) FlushKeys(ctx context.Context, keys []string, storer func(ctx context.Context, key string, val any) error) error {
+	w.flushMutex.Lock()
+	defer w.flushMutex.Unlock()
+
+	// 筛选出脏数据键，保持调用方给出的顺序
+	seqs := w.snapshotDirty(keys)
+	dirtyKeys := make([]string, 0, len(seqs))
+	for _, key := range keys {
+		if _, ok := seqs[key]; ok && !slices.Contains(dirtyKeys, key) {
+			dirtyKeys = append(dirtyKeys, key)
+		}
+	}
+
+	return w.flush(ctx, dirtyKeys, seqs, storer)
+}
+
+// FlushTx 将所有脏数据通过一次调用交给支持事务的存储函数，要么全部写入，要么全部保持为脏数据
+// 适用于下游存储支持事务的场景，避免 Flush 部分键写入成功、部分键失败导致的不一致
+// 任一脏数据键无法从缓存读取时不调用txStorer，所有键保持为脏数据
+// ctx: 上下文
+// txStorer: 事务存储函数，items为所有脏数据键到缓存值的映射，返回nil表示事务已提交
+// 返回: 操作错误，txStorer返回错误时所有键保持为脏数据；事务提交后压缩日志失败时键已标记为干净数据
+func (w *WriteBackCache) FlushTx(ctx context.Context, txStorer func(ctx context.Context, items map[string]any) error) error {
+	w.flushMutex.Lock()
+	defer w.flushMutex.Unlock()
+
+	seqs := w.snapshotDirty(nil)
+	if len(seqs) == 0 {
+		return nil // 没有脏数据需要刷新
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("刷新被取消，%d 个键未刷新: %w", len(seqs), err)
+	}
+
+	items := make(map[string]any, len(seqs))
+	for key := range seqs {
+		val, err := w.Repository.Get(ctx, key)
+		if err != nil {
+			return fmt.Errorf("获取键 %s 失败: %w", key, err)
+		}
+		items[key] = val
+	}
+
+	if err := txStorer(ctx, items); err != nil {
+		return fmt.Errorf("事务写入 %d 个键失败: %w", len(items), err)
+	}
+
+	// 事务已提交，清理所有脏数据标记，事务期间有新的写入的键保持为脏数据
+	w.clearFlushed(seqs)
+	w.lastFlushTime = time.Now()
+
+	// 压缩失败时已刷新的数据在重启后会被重复刷新
+	if w.journal != nil {
+		if err := w.journal.compact(seqs); err != nil {
+			return fmt.Errorf("压缩写回日志失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// snapshotDirty 获取脏数据键及其当前的写入序号
+// 应在读取缓存值之前调用，刷新成功后只清理序号未变化的键
+// keys: 要筛选的键，为nil时返回所有脏数据键
+// 返回: 脏数据键到写入序号的映射
+func (w *WriteBackCache) snapshotDirty(keys []string) map[string]uint64 {
+	w.dirtyMutex.RLock()
+	defer w.dirtyMutex.RUnlock()
+
+	if keys == nil {
+		seqs := make(map[string]uint64, len(w.dirtySeqs))
+		for key, seq := range w.dirtySeqs {
+			seqs[key] = seq
+		}
+		return seqs
+	}
+	seqs := make(map[string]uint64, len(keys))
+	for _, key := range keys {
+		if seq, ok := w.dirtySeqs[key]; ok {
+			seqs[key] = seq
+		}
+	}
+	return seqs
+}
+
+// clearFlushed 清理已刷新的脏数据标记
+// 刷新期间再次写入的键写入序号已经变大，保持为脏数据等待下次刷新
+// flushed: 已刷新的键到 snapshotDirty 返回的写入序号的映射
+func (w *WriteBackCache) clearFlushed(flushed map[string]uint64) {
+	w.dirtyMutex.Lock()
+	defer w.dirtyMutex.Unlock()
+	for key, seq := range flushed {
+		if cur, ok := w.dirtySeqs[key]; ok && cur <= seq {
+			w.clearDirty(key)
+		}
+	}
+}
+
+// flush 将给定的脏数据键写入持久化存储并清理标记
+// 注意: 此方法应在持有flushMutex的情况下调用
+// seqs: snapshotDirty 返回的写入序号
+// 返回: 操作错误，包含所有刷新失败的键；因上下文取消而未刷新时返回包装了ctx.Err()的错误
+func (w *WriteBackCache) flush(ctx context.Context, dirtyKeys []string, seqs map[string]uint64, storer func(ctx context.Context, key string, val any) error) error {
 	if len(dirtyKeys) == 0 {
 		return nil // 没有脏数据需要刷新
 	}
 
-	var errors []error
-	successKeys := make([]string, 0, len(dirtyKeys))
+	var (
+		errors      []error
+		skipped     int
+		resultMutex sync.Mutex // 并行写入时保护errors、skipped和successKeys
+	)
+	successKeys := make(map[string]uint64, len(dirtyKeys))
+
+	storeKey := func(key string) {
+		// 上下文已取消时不再写入，剩余的键保持为脏数据
+		if ctx.Err() != nil {
+			resultMutex.Lock()
+			skipped++
+			resultMutex.Unlock()
+			return
+		}
 
-	// 批量写入持久化存储
-	for _, key := range dirtyKeys {
 		val, err := w.Repository.Get(ctx, key)
-		if err != nil {
-			errors = append(errors, fmt.Errorf("获取键 %s 失败: %w", key, err))
-			continue
+		if err == nil {
+			err = storer(ctx, key, val)
+			if err != nil {
+				err = fmt.Errorf("存储键 %s 失败: %w", key, err)
+			}
+		} else {
+			err = fmt.Errorf("获取键 %s 失败: %w", key, err)
 		}
 
-		err = storer(ctx, key, val)
+		resultMutex.Lock()
+		defer resultMutex.Unlock()
 		if err != nil {
-			errors = append(errors, fmt.Errorf("存储键 %s 失败: %w", key, err))
-			continue
+			errors = append(errors, err)
+			return
 		}
+		successKeys[key] = seqs[key]
+	}
 
-		successKeys = append(successKeys, key)
+	// 批量写入持久化存储
+	workers := min(w.flushConcurrency, len(dirtyKeys))
+	if workers <= 1 {
+		for _, key := range dirtyKeys {
+			storeKey(key)
+		}
+	} else {
+		keyCh := make(chan string)
+		var wg sync.WaitGroup
+		for range workers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for key := range keyCh {
+					storeKey(key)
+				}
+			}()
+		}
+		for _, key := range dirtyKeys {
+			keyCh <- key
+		}
+		close(keyCh)
+		wg.Wait()
 	}
 
 	// 清理成功写入的脏数据标记
 	if len(successKeys) > 0 {
-		w.dirtyMutex.Lock()
-		for _, key := range successKeys {
-			delete(w.dirtyKeys, key)
+		w.clearFlushed(successKeys)
+
+		// 压缩日志，只保留仍未刷新的脏数据；压缩失败时已刷新的数据在重启后会被重复刷新
+		if w.journal != nil {
+			if err := w.journal.compact(successKeys); err != nil {
+				errors = append(errors, fmt.Errorf("压缩写回日志失败: %w", err))
+			}
 		}
-		w.dirtyMutex.Unlock()
 
 		w.lastFlushTime = time.Now()
 	}
 
+	if skipped > 0 {
+		return fmt.Errorf("刷新被取消，%d 个键未刷新: %w", skipped, ctx.Err())
+	}
+
 	// 如果有错误，返回组合错误
 	if len(errors) > 0 {
 		return fmt.Errorf("刷新过程中发生 %d 个错误: %v", len(errors), errors)
@@ -166,6 +461,24 @@ func (w *WriteBackCache) GetDirtyKeys() []string {
 	return keys
 }
 
+// clearDirty 清除键的脏数据标记，并扣除其大小
+// 注意: 此方法应在持有dirtyMutex写锁的情况下调用
+func (w *WriteBackCache) clearDirty(key string) {
+	delete(w.dirtyKeys, key)
+	w.dirtyBytes -= w.dirtySizes[key]
+	delete(w.dirtySizes, key)
+	delete(w.dirtySeqs, key)
+}
+
+// DirtyBytes 获取等待刷新的脏数据估算字节数
+// 大小由 SetSizeEstimator 设置的函数估算，可用于内存压力判断
+// 返回: 估算字节数
+func (w *WriteBackCache) DirtyBytes() int64 {
+	w.dirtyMutex.RLock()
+	defer w.dirtyMutex.RUnlock()
+	return w.dirtyBytes
+}
+
 // GetDirtyCount 获取脏数据数量
 // 返回: 脏数据数量
 func (w *WriteBackCache) GetDirtyCount() int {
@@ -196,7 +509,7 @@ func (w *WriteBackCache) ShouldFlush() bool {
 }
 
 // StartAutoFlush 启动自动刷新
-// 在后台定期检查并刷新脏数据
+// 在后台定期检查并刷新脏数据，刷新失败时调用 SetFlushErrorHandler 设置的回调
 // ctx: 上下文，用于控制停止
 // storer: 数据存储函数
 func (w *WriteBackCache) StartAutoFlush(ctx context.Context, storer func(ctx context.Context, key string, val any) error) {
@@ -212,13 +525,17 @@ func (w *WriteBackCache) StartAutoFlush(ctx context.Context, storer func(ctx con
 	for {
 		select {
 		case <-ctx.Done():
-			// 上下文取消，执行最后一次刷新
-			_ = w.Flush(ctx, storer)
+			// 上下文取消，执行最后一次刷新，刷新本身不能再被已取消的上下文中断
+			if err := w.Flush(context.WithoutCancel(ctx), storer); err != nil {
+				w.onFlushError(err)
+			}
 			return
 		case <-ticker.C:
 			// 定期检查是否需要刷新
 			if w.ShouldFlush() {
-				_ = w.Flush(ctx, storer)
+				if err := w.Flush(ctx, storer); err != nil {
+					w.onFlushError(err)
+				}
 			}
 		}
 	}
@@ -237,8 +554,9 @@ func (w *WriteBackCache) Delete(ctx context.Context, key string) error {
 
 	// 清理脏数据标记（无论删除是否成功）
 	w.dirtyMutex.Lock()
-	delete(w.dirtyKeys, key)
+	w.clearDirty(key)
 	w.dirtyMutex.Unlock()
+	w.journalDelete(key)
 
 	return err
 }
@@ -250,8 +568,9 @@ func (w *WriteBackCache) LoadAndDelete(ctx context.Context, key string) (any, er
 
 	// 清理脏数据标记（无论操作是否成功）
 	w.dirtyMutex.Lock()
-	delete(w.dirtyKeys, key)
+	w.clearDirty(key)
 	w.dirtyMutex.Unlock()
+	w.journalDelete(key)
 
 	return val, err
 }
@@ -270,8 +589,9 @@ func (w *WriteBackCache) OnEvicted(fn func(key string, val any)) {
 			// 脏数据被淘汰，清理标记
 			// 注意：这里应该记录日志或触发告警，因为脏数据丢失了
 			w.dirtyMutex.Lock()
-			delete(w.dirtyKeys, key)
+			w.clearDirty(key)
 			w.dirtyMutex.Unlock()
+			w.journalDelete(key)
 		}
 
 		// 调用原始回调函数
@@ -39,6 +39,14 @@ func NewMockStorer() *MockStorer {
 
 // Store 模拟存储操作
 func (m *MockStorer) Store(ctx context.Context, key string, val any) error {
+	// 模拟延迟，延迟期间不持有锁，允许并发存储
+	m.mu.RLock()
+	delay := m.storeDelay
+	m.mu.RUnlock()
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -49,11 +57,6 @@ func (m *MockStorer) Store(ctx context.Context, key string, val any) error {
 		Time:  time.Now(),
 	})
 
-	// 模拟延迟
-	if m.storeDelay > 0 {
-		time.Sleep(m.storeDelay)
-	}
-
 	// 模拟失败
 	if m.failKeys[key] {
 		return errors.New("模拟存储失败")
@@ -336,6 +339,283 @@ func TestWriteBackCache_Flush(t *testing.T) {
 	}
 }
 
+// TestWriteBackCache_FlushKeys 测试只刷新指定的脏数据键
+func TestWriteBackCache_FlushKeys(t *testing.T) {
+	tests := []struct {
+		name          string
+		failKey       string
+		keys          []string
+		wantErr       bool
+		wantStored    []string
+		wantDirtyKeys []string
+	}{
+		{
+			name:          "刷新部分键",
+			keys:          []string{"key1", "key3"},
+			wantStored:    []string{"key1", "key3"},
+			wantDirtyKeys: []string{"key2", "key4"},
+		},
+		{
+			name:          "跳过不存在和重复的键",
+			keys:          []string{"key2", "missing", "key2"},
+			wantStored:    []string{"key2"},
+			wantDirtyKeys: []string{"key1", "key3", "key4"},
+		},
+		{
+			name:          "空列表",
+			keys:          nil,
+			wantStored:    []string{},
+			wantDirtyKeys: []string{"key1", "key2", "key3", "key4"},
+		},
+		{
+			name:          "部分失败",
+			failKey:       "key1",
+			keys:          []string{"key1", "key2"},
+			wantErr:       true,
+			wantStored:    []string{"key2"},
+			wantDirtyKeys: []string{"key1", "key3", "key4"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			cache := NewWriteBackCache(&MockCache{store: make(map[string]any)}, time.Minute, 10)
+			storer := NewMockStorer()
+			if tt.failKey != "" {
+				storer.SetFailKey(tt.failKey, true)
+			}
+			for _, key := range []string{"key1", "key2", "key3", "key4"} {
+				require.NoError(t, cache.SetDirty(ctx, key, "value_"+key, time.Minute))
+			}
+
+			err := cache.FlushKeys(ctx, tt.keys, storer.Store)
+			if tt.wantErr {
+				assert.ErrorContains(t, err, tt.failKey)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			stored := []string{}
+			for key := range storer.data {
+				stored = append(stored, key)
+			}
+			assert.ElementsMatch(t, tt.wantStored, stored)
+			assert.ElementsMatch(t, tt.wantDirtyKeys, cache.GetDirtyKeys())
+		})
+	}
+}
+
+// TestWriteBackCache_DirtyBytes 测试统计等待刷新的脏数据字节数
+func TestWriteBackCache_DirtyBytes(t *testing.T) {
+	ctx := context.Background()
+	mockCache := &MockCache{store: make(map[string]any)}
+	cache := NewWriteBackCache(mockCache, time.Minute, 100)
+	storer := NewMockStorer()
+
+	require.NoError(t, cache.SetDirty(ctx, "key1", make([]byte, 10), time.Minute))
+	require.NoError(t, cache.SetDirty(ctx, "key2", make([]byte, 20), time.Minute))
+	require.NoError(t, cache.SetDirty(ctx, "key3", make([]byte, 30), time.Minute))
+	assert.Equal(t, int64(60), cache.DirtyBytes())
+
+	// 覆盖脏数据时替换原有大小
+	require.NoError(t, cache.SetDirty(ctx, "key3", make([]byte, 5), time.Minute))
+	assert.Equal(t, int64(35), cache.DirtyBytes())
+
+	// 单键刷新和删除都会扣除大小
+	require.NoError(t, cache.FlushKey(ctx, "key1", storer.Store))
+	assert.Equal(t, int64(25), cache.DirtyBytes())
+	require.NoError(t, cache.Delete(ctx, "key3"))
+	assert.Equal(t, int64(20), cache.DirtyBytes())
+
+	require.NoError(t, cache.SetDirty(ctx, "key4", make([]byte, 40), time.Minute))
+	require.NoError(t, cache.Flush(ctx, storer.Store))
+	assert.Equal(t, int64(0), cache.DirtyBytes())
+
+	// 刷新失败的键保留其大小
+	storer.SetFailKey("key5", true)
+	require.NoError(t, cache.SetDirty(ctx, "key5", make([]byte, 50), time.Minute))
+	require.NoError(t, cache.SetDirty(ctx, "key6", make([]byte, 60), time.Minute))
+	assert.Error(t, cache.Flush(ctx, storer.Store))
+	assert.Equal(t, int64(50), cache.DirtyBytes())
+}
+
+// TestWriteBackCache_SetSizeEstimator 测试自定义大小估算函数
+func TestWriteBackCache_SetSizeEstimator(t *testing.T) {
+	ctx := context.Background()
+	cache := NewWriteBackCache(&MockCache{store: make(map[string]any)}, time.Minute, 100)
+	cache.SetSizeEstimator(func(val any) int64 {
+		return int64(len(val.([]int)) * 8)
+	})
+
+	require.NoError(t, cache.SetDirty(ctx, "key1", []int{1, 2, 3}, time.Minute))
+	assert.Equal(t, int64(24), cache.DirtyBytes())
+
+	// 设置为nil时恢复默认实现
+	cache.SetSizeEstimator(nil)
+	require.NoError(t, cache.SetDirty(ctx, "key2", "value", time.Minute))
+	assert.Equal(t, int64(29), cache.DirtyBytes())
+}
+
+// TestWriteBackCache_FlushConcurrency 测试并行刷新
+func TestWriteBackCache_FlushConcurrency(t *testing.T) {
+	const (
+		keyCount = 20
+		delay    = 20 * time.Millisecond
+	)
+
+	tests := []struct {
+		name        string
+		concurrency int
+		maxDuration time.Duration
+	}{
+		{
+			name:        "单个worker顺序写入",
+			concurrency: 1,
+			maxDuration: time.Hour,
+		},
+		{
+			name:        "10个worker并行写入",
+			concurrency: 10,
+			// 顺序写入至少需要400ms，10个worker约需要40ms
+			maxDuration: keyCount * delay / 2,
+		},
+	}
+
+	durations := make([]time.Duration, len(tests))
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			cache := NewWriteBackCache(&MockCache{store: make(map[string]any)}, time.Minute, 100,
+				WriteBackCacheWithFlushConcurrency(tt.concurrency))
+			storer := NewMockStorer()
+			storer.SetStoreDelay(delay)
+			storer.SetFailKey("key0", true)
+			for j := range keyCount {
+				require.NoError(t, cache.SetDirty(ctx, fmt.Sprintf("key%d", j), j, time.Minute))
+			}
+
+			start := time.Now()
+			err := cache.Flush(ctx, storer.Store)
+			durations[i] = time.Since(start)
+
+			// 失败的键保持为脏数据，其余键全部写入
+			assert.ErrorContains(t, err, "key0")
+			assert.Equal(t, []string{"key0"}, cache.GetDirtyKeys())
+			assert.Equal(t, keyCount, storer.GetStoreCallCount())
+			assert.Len(t, storer.data, keyCount-1)
+			assert.Less(t, durations[i], tt.maxDuration)
+		})
+	}
+	assert.Less(t, durations[1], durations[0])
+}
+
+// TestWriteBackCache_Flush_ContextCanceled 测试刷新过程中取消上下文
+func TestWriteBackCache_Flush_ContextCanceled(t *testing.T) {
+	tests := []struct {
+		name  string
+		flush func(ctx context.Context, cache *WriteBackCache, storer func(ctx context.Context, key string, val any) error) error
+	}{
+		{
+			name: "Flush",
+			flush: func(ctx context.Context, cache *WriteBackCache, storer func(ctx context.Context, key string, val any) error) error {
+				return cache.Flush(ctx, storer)
+			},
+		},
+		{
+			name: "FlushKeys",
+			flush: func(ctx context.Context, cache *WriteBackCache, storer func(ctx context.Context, key string, val any) error) error {
+				return cache.FlushKeys(ctx, []string{"key1", "key2", "key3"}, storer)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := NewWriteBackCache(&MockCache{store: make(map[string]any)}, time.Minute, 100)
+			for _, key := range []string{"key1", "key2", "key3"} {
+				require.NoError(t, cache.SetDirty(context.Background(), key, "value", time.Minute))
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			var stored []string
+			// 第一次写入后取消上下文
+			storer := func(ctx context.Context, key string, val any) error {
+				stored = append(stored, key)
+				cancel()
+				return nil
+			}
+
+			err := tt.flush(ctx, cache, storer)
+			assert.ErrorIs(t, err, context.Canceled)
+			require.Len(t, stored, 1)
+			assert.NotContains(t, cache.GetDirtyKeys(), stored[0])
+			assert.Equal(t, 2, cache.GetDirtyCount())
+		})
+	}
+}
+
+// TestWriteBackCache_FlushTx 测试事务刷新全部成功或全部保持为脏数据
+func TestWriteBackCache_FlushTx(t *testing.T) {
+	tests := []struct {
+		name          string
+		txErr         error
+		wantDirtyKeys []string
+		wantBytes     int64
+	}{
+		{
+			name:          "事务提交_清理所有脏数据",
+			wantDirtyKeys: []string{},
+			wantBytes:     0,
+		},
+		{
+			name:          "事务失败_所有键保持为脏数据",
+			txErr:         errors.New("模拟事务回滚"),
+			wantDirtyKeys: []string{"key1", "key2", "key3"},
+			wantBytes:     18,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			cache := NewWriteBackCache(&MockCache{store: make(map[string]any)}, time.Minute, 100)
+			for _, key := range []string{"key1", "key2", "key3"} {
+				require.NoError(t, cache.SetDirty(ctx, key, key+"-v", time.Minute))
+			}
+
+			var calls int
+			var got map[string]any
+			err := cache.FlushTx(ctx, func(ctx context.Context, items map[string]any) error {
+				calls++
+				got = items
+				return tt.txErr
+			})
+
+			// 整批脏数据在一次调用中交给存储函数
+			assert.Equal(t, 1, calls)
+			assert.Equal(t, map[string]any{"key1": "key1-v", "key2": "key2-v", "key3": "key3-v"}, got)
+			if tt.txErr != nil {
+				assert.ErrorIs(t, err, tt.txErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.ElementsMatch(t, tt.wantDirtyKeys, cache.GetDirtyKeys())
+			assert.Equal(t, tt.wantBytes, cache.DirtyBytes())
+		})
+	}
+
+	t.Run("没有脏数据_不调用存储函数", func(t *testing.T) {
+		cache := NewWriteBackCache(&MockCache{store: make(map[string]any)}, time.Minute, 100)
+		err := cache.FlushTx(context.Background(), func(ctx context.Context, items map[string]any) error {
+			t.Fatal("没有脏数据时不应调用存储函数")
+			return nil
+		})
+		assert.NoError(t, err)
+	})
+}
+
 // TestWriteBackCache_AutoFlush 测试自动刷新
 func TestWriteBackCache_AutoFlush(t *testing.T) {
 	t.Run("定时自动刷新", func(t *testing.T) {
@@ -385,6 +665,55 @@ func TestWriteBackCache_AutoFlush(t *testing.T) {
 		// 检查是否已刷新
 		assert.Equal(t, 2, mockStorer.GetStoreCallCount())
 	})
+
+	t.Run("取消上下文后执行最后一次刷新", func(t *testing.T) {
+		mockCache := &MockCache{store: make(map[string]any)}
+		mockStorer := NewMockStorer()
+		cache := NewWriteBackCache(mockCache, time.Hour, 10)
+		_ = cache.SetDirty(context.Background(), "key1", "value1", time.Minute)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			cache.StartAutoFlush(ctx, mockStorer.Store)
+			close(done)
+		}()
+		cancel()
+		<-done
+
+		// 最后一次刷新不受已取消的上下文影响
+		assert.Equal(t, 1, mockStorer.GetStoreCallCount())
+		assert.Equal(t, 0, cache.GetDirtyCount())
+	})
+
+	t.Run("刷新失败时调用错误回调", func(t *testing.T) {
+		mockCache := &MockCache{store: make(map[string]any)}
+		mockStorer := NewMockStorer()
+		mockStorer.SetFailKey("key1", true)
+
+		cache := NewWriteBackCache(mockCache, 50*time.Millisecond, 10)
+		errCh := make(chan error, 10)
+		cache.SetFlushErrorHandler(func(err error) {
+			errCh <- err
+		})
+
+		_ = cache.SetDirty(context.Background(), "key1", "value1", time.Minute)
+		_ = cache.SetDirty(context.Background(), "key2", "value2", time.Minute)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		go cache.StartAutoFlush(ctx, mockStorer.Store)
+
+		select {
+		case err := <-errCh:
+			assert.Contains(t, err.Error(), "key1")
+		case <-time.After(time.Second):
+			t.Fatal("未收到刷新错误")
+		}
+		// 失败的键保持为脏数据，成功的键被清理
+		assert.Equal(t, []string{"key1"}, cache.GetDirtyKeys())
+	})
 }
 
 // TestWriteBackCache_ConcurrentOperations 测试并发操作
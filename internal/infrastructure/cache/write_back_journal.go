@@ -0,0 +1,302 @@
+package cache
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	journalOpSet    byte = 1 // 写入脏数据
+	journalOpDelete byte = 2 // 脏数据被删除或淘汰
+
+	// journalCompactMinRecords 日志文件中的记录数达到该值且超过未刷新脏数据数量的两倍时才重写日志
+	journalCompactMinRecords = 1024
+)
+
+// ErrCorruptJournal 日志记录无法解析
+var ErrCorruptJournal = errors.New("cache：写回日志已损坏")
+
+// journalRecord 日志中的一条脏数据
+type journalRecord struct {
+	data     []byte    // 编码后的缓存值
+	deadline time.Time // 过期时间点，零值表示永不过期
+	seq      uint64    // 写入序号，只保存在内存中，用于判断刷新的是否为最新的写入
+}
+
+// journalEntry 从日志恢复的脏数据
+type journalEntry struct {
+	key      string
+	val      any
+	deadline time.Time
+	seq      uint64
+}
+
+// WriteBackJournal 写回缓存的预写日志
+// SetDirty 先将脏数据追加到日志文件，刷新成功后为已刷新的脏数据追加删除记录，
+// 已失效的记录累积到一定数量后重写日志，只保留仍未刷新的脏数据，
+// 进程崩溃重启后通过 WriteBackCacheWithJournal 从日志恢复未刷新的脏数据
+// 日志只在写入时交给操作系统，不调用fsync，能够应对进程崩溃，不能应对机器掉电
+type WriteBackJournal struct {
+	path      string
+	codec     Codec
+	file      *os.File
+	records   map[string]journalRecord // 仍未刷新的脏数据，用于压缩日志
+	written   int                      // 日志文件中的记录数，包括已失效的记录
+	seq       uint64                   // 恢复时分配给脏数据的最大写入序号
+	recovered []journalEntry           // 打开时从日志恢复的脏数据
+	mutex     sync.Mutex
+}
+
+// NewWriteBackJournal 打开写回日志，并读取其中未刷新的脏数据
+// 文件末尾写入到一半的记录（进程在写入时崩溃）会被丢弃，已过期的脏数据不会恢复
+// path: 日志文件路径，文件不存在时创建
+// codec: 缓存值编解码器，为nil时使用 GobCodec
+// 返回: WriteBackJournal实例和错误信息，日志无法解析时返回 ErrCorruptJournal
+func NewWriteBackJournal(path string, codec Codec) (*WriteBackJournal, error) {
+	if codec == nil {
+		codec = GobCodec{}
+	}
+	j := &WriteBackJournal{
+		path:    path,
+		codec:   codec,
+		records: make(map[string]journalRecord),
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("打开写回日志失败: %w", err)
+	}
+
+	valid, err := j.replay(file)
+	if err == nil {
+		// 丢弃末尾不完整的记录，之后的追加从完整记录之后开始
+		err = file.Truncate(valid)
+	}
+	if err == nil {
+		_, err = file.Seek(valid, io.SeekStart)
+	}
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	j.file = file
+
+	now := time.Now()
+	for key, rec := range j.records {
+		if !rec.deadline.IsZero() && rec.deadline.Before(now) {
+			delete(j.records, key)
+			continue
+		}
+		var val any
+		if err := codec.Unmarshal(rec.data, &val); err != nil {
+			_ = file.Close()
+			return nil, fmt.Errorf("%w: key: %s, %v", ErrCorruptJournal, key, err)
+		}
+		j.recovered = append(j.recovered, journalEntry{key: key, val: val, deadline: rec.deadline, seq: rec.seq})
+	}
+	return j, nil
+}
+
+// replay 读取日志中的所有完整记录
+// 返回: 完整记录的结束位置和错误信息
+func (j *WriteBackJournal) replay(r io.Reader) (int64, error) {
+	reader := bufio.NewReader(r)
+	var valid int64
+	header := make([]byte, 4)
+	for {
+		if _, err := io.ReadFull(reader, header); err != nil {
+			return valid, nil
+		}
+		payload := make([]byte, binary.BigEndian.Uint32(header))
+		if _, err := io.ReadFull(reader, payload); err != nil {
+			return valid, nil
+		}
+
+		op, key, rec, err := decodeJournalRecord(payload)
+		if err != nil {
+			return 0, err
+		}
+		switch op {
+		case journalOpSet:
+			j.seq++
+			rec.seq = j.seq
+			j.records[key] = rec
+		case journalOpDelete:
+			delete(j.records, key)
+		default:
+			return 0, fmt.Errorf("%w: 未知的操作类型 %d", ErrCorruptJournal, op)
+		}
+		valid += int64(len(header) + len(payload))
+		j.written++
+	}
+}
+
+// appendSet 追加一条脏数据记录
+// seq: 写入序号，刷新时只移除序号不大于已刷新序号的记录
+// 返回: 错误信息，值无法编码时返回 ErrValueNotEncodable
+func (j *WriteBackJournal) appendSet(key string, seq uint64, val any, deadline time.Time) error {
+	data, err := j.codec.Marshal(val)
+	if err != nil {
+		return fmt.Errorf("%w: key: %s, %v", ErrValueNotEncodable, key, err)
+	}
+	rec := journalRecord{data: data, deadline: deadline, seq: seq}
+
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	if _, err = j.file.Write(encodeJournalRecord(journalOpSet, key, rec)); err != nil {
+		return fmt.Errorf("写入写回日志失败: %w", err)
+	}
+	j.records[key] = rec
+	j.written++
+	return nil
+}
+
+// appendDelete 追加一条删除记录，键不是日志中的脏数据时忽略
+func (j *WriteBackJournal) appendDelete(key string) error {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	if _, ok := j.records[key]; !ok {
+		return nil
+	}
+	if _, err := j.file.Write(encodeJournalRecord(journalOpDelete, key, journalRecord{})); err != nil {
+		return fmt.Errorf("写入写回日志失败: %w", err)
+	}
+	delete(j.records, key)
+	j.written++
+	return nil
+}
+
+// compact 移除已刷新的脏数据
+// 只移除写入序号不大于已刷新序号的记录，刷新期间写入的更新的脏数据保留在日志中；
+// 移除的记录以删除记录的形式一次追加到日志，日志文件中已失效的记录累积到一定数量后才重写日志
+// flushed: 已刷新的键到刷新时写入序号的映射
+func (j *WriteBackJournal) compact(flushed map[string]uint64) error {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	var buf []byte
+	for key, seq := range flushed {
+		if rec, ok := j.records[key]; ok && rec.seq <= seq {
+			buf = append(buf, encodeJournalRecord(journalOpDelete, key, journalRecord{})...)
+			delete(j.records, key)
+			j.written++
+		}
+	}
+	if len(buf) > 0 {
+		if _, err := j.file.Write(buf); err != nil {
+			return fmt.Errorf("写入写回日志失败: %w", err)
+		}
+	}
+	if j.written >= journalCompactMinRecords && j.written > 2*len(j.records) {
+		return j.rewrite()
+	}
+	return nil
+}
+
+// rewrite 将仍未刷新的脏数据重写为新的日志
+// 先写入临时文件再重命名，避免重写过程中崩溃丢失日志；重写的耗时与未刷新的脏数据数量成正比
+// 注意: 此方法应在持有mutex的情况下调用
+func (j *WriteBackJournal) rewrite() error {
+	tmp, err := os.CreateTemp(filepath.Dir(j.path), filepath.Base(j.path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+
+	w := bufio.NewWriter(tmp)
+	for key, rec := range j.records {
+		if _, err = w.Write(encodeJournalRecord(journalOpSet, key, rec)); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("写入临时文件失败: %w", err)
+	}
+
+	if err = os.Rename(tmp.Name(), j.path); err != nil {
+		return fmt.Errorf("替换写回日志失败: %w", err)
+	}
+	file, err := os.OpenFile(j.path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("打开写回日志失败: %w", err)
+	}
+	_ = j.file.Close()
+	j.file = file
+	j.written = len(j.records)
+	return nil
+}
+
+// takeRecovered 取出打开时恢复的脏数据，只能取出一次
+func (j *WriteBackJournal) takeRecovered() []journalEntry {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	res := j.recovered
+	j.recovered = nil
+	return res
+}
+
+// Close 关闭日志文件
+// 未刷新的脏数据保留在日志中，下次打开时恢复
+func (j *WriteBackJournal) Close() error {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	return j.file.Close()
+}
+
+// encodeJournalRecord 编码一条日志记录
+// 格式: 4字节负载长度 | 操作类型 | uvarint键长度 | 键 | varint过期时间（UnixNano，0表示永不过期）| 编码后的值
+func encodeJournalRecord(op byte, key string, rec journalRecord) []byte {
+	var deadline int64
+	if !rec.deadline.IsZero() {
+		deadline = rec.deadline.UnixNano()
+	}
+
+	buf := make([]byte, 4, 4+1+binary.MaxVarintLen64*2+len(key)+len(rec.data))
+	buf = append(buf, op)
+	buf = binary.AppendUvarint(buf, uint64(len(key)))
+	buf = append(buf, key...)
+	buf = binary.AppendVarint(buf, deadline)
+	buf = append(buf, rec.data...)
+	binary.BigEndian.PutUint32(buf, uint32(len(buf)-4))
+	return buf
+}
+
+// decodeJournalRecord 解码一条日志记录的负载
+func decodeJournalRecord(payload []byte) (byte, string, journalRecord, error) {
+	if len(payload) == 0 {
+		return 0, "", journalRecord{}, ErrCorruptJournal
+	}
+	op, payload := payload[0], payload[1:]
+
+	keyLen, n := binary.Uvarint(payload)
+	if n <= 0 || uint64(len(payload)-n) < keyLen {
+		return 0, "", journalRecord{}, ErrCorruptJournal
+	}
+	payload = payload[n:]
+	key, payload := string(payload[:keyLen]), payload[keyLen:]
+
+	deadline, n := binary.Varint(payload)
+	if n <= 0 {
+		return 0, "", journalRecord{}, ErrCorruptJournal
+	}
+	rec := journalRecord{data: payload[n:]}
+	if deadline != 0 {
+		rec.deadline = time.Unix(0, deadline)
+	}
+	return op, key, rec, nil
+}
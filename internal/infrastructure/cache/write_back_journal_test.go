@@ -0,0 +1,277 @@
+package cache
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWriteBackJournal_Recover 测试重启后从日志恢复未刷新的脏数据
+func TestWriteBackJournal_Recover(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "write_back.journal")
+
+	journal, err := NewWriteBackJournal(path, nil)
+	require.NoError(t, err)
+	cache := NewWriteBackCache(&MockCache{store: make(map[string]any)}, time.Minute, 100,
+		WriteBackCacheWithJournal(journal))
+	require.NoError(t, cache.SetDirty(ctx, "key1", "value1", 0))
+	require.NoError(t, cache.SetDirty(ctx, "key2", "value2", time.Hour))
+	require.NoError(t, cache.SetDirty(ctx, "key2", "value2_new", time.Hour))
+	require.NoError(t, cache.SetDirty(ctx, "deleted", "value", 0))
+	require.NoError(t, cache.Delete(ctx, "deleted"))
+	require.NoError(t, cache.SetDirty(ctx, "expired", "value", 10*time.Millisecond))
+	// 模拟进程崩溃，不刷新直接关闭日志
+	require.NoError(t, journal.Close())
+	time.Sleep(20 * time.Millisecond)
+
+	journal, err = NewWriteBackJournal(path, nil)
+	require.NoError(t, err)
+	mockCache := &MockCache{store: make(map[string]any)}
+	cache = NewWriteBackCache(mockCache, time.Minute, 100, WriteBackCacheWithJournal(journal))
+
+	assert.ElementsMatch(t, []string{"key1", "key2"}, cache.GetDirtyKeys())
+	assert.Equal(t, map[string]any{"key1": "value1", "key2": "value2_new"}, mockCache.store)
+
+	// 恢复的脏数据可以正常刷新，刷新后日志被压缩
+	storer := NewMockStorer()
+	require.NoError(t, cache.Flush(ctx, storer.Store))
+	assert.Equal(t, map[string]any{"key1": "value1", "key2": "value2_new"}, storer.data)
+	require.NoError(t, journal.Close())
+
+	journal, err = NewWriteBackJournal(path, nil)
+	require.NoError(t, err)
+	defer func() {
+		_ = journal.Close()
+	}()
+	cache = NewWriteBackCache(&MockCache{store: make(map[string]any)}, time.Minute, 100,
+		WriteBackCacheWithJournal(journal))
+	assert.Empty(t, cache.GetDirtyKeys())
+}
+
+// TestWriteBackJournal_PartialFlush 测试部分刷新后日志只保留未刷新的脏数据
+func TestWriteBackJournal_PartialFlush(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "write_back.journal")
+
+	journal, err := NewWriteBackJournal(path, nil)
+	require.NoError(t, err)
+	cache := NewWriteBackCache(&MockCache{store: make(map[string]any)}, time.Minute, 100,
+		WriteBackCacheWithJournal(journal))
+	for _, key := range []string{"key1", "key2", "key3"} {
+		require.NoError(t, cache.SetDirty(ctx, key, "value_"+key, 0))
+	}
+	storer := NewMockStorer()
+	require.NoError(t, cache.FlushKey(ctx, "key3", storer.Store))
+	storer.SetFailKey("key2", true)
+	assert.Error(t, cache.Flush(ctx, storer.Store))
+	require.NoError(t, cache.SetDirty(ctx, "key4", "value_key4", 0))
+	require.NoError(t, journal.Close())
+
+	journal, err = NewWriteBackJournal(path, nil)
+	require.NoError(t, err)
+	defer func() {
+		_ = journal.Close()
+	}()
+	cache = NewWriteBackCache(&MockCache{store: make(map[string]any)}, time.Minute, 100,
+		WriteBackCacheWithJournal(journal))
+	assert.ElementsMatch(t, []string{"key2", "key4"}, cache.GetDirtyKeys())
+}
+
+// TestWriteBackJournal_TruncatedRecord 测试丢弃写入到一半的记录
+func TestWriteBackJournal_TruncatedRecord(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "write_back.journal")
+
+	journal, err := NewWriteBackJournal(path, nil)
+	require.NoError(t, err)
+	cache := NewWriteBackCache(&MockCache{store: make(map[string]any)}, time.Minute, 100,
+		WriteBackCacheWithJournal(journal))
+	require.NoError(t, cache.SetDirty(ctx, "key1", "value1", 0))
+	require.NoError(t, journal.Close())
+
+	// 模拟写入记录时崩溃，只写入了一部分
+	record := encodeJournalRecord(journalOpSet, "key2", journalRecord{data: []byte("partial")})
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	require.NoError(t, err)
+	_, err = file.Write(record[:len(record)-3])
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	journal, err = NewWriteBackJournal(path, nil)
+	require.NoError(t, err)
+	cache = NewWriteBackCache(&MockCache{store: make(map[string]any)}, time.Minute, 100,
+		WriteBackCacheWithJournal(journal))
+	assert.Equal(t, []string{"key1"}, cache.GetDirtyKeys())
+
+	// 之后追加的记录不受不完整记录影响
+	require.NoError(t, cache.SetDirty(ctx, "key3", "value3", 0))
+	require.NoError(t, journal.Close())
+	journal, err = NewWriteBackJournal(path, nil)
+	require.NoError(t, err)
+	defer func() {
+		_ = journal.Close()
+	}()
+	cache = NewWriteBackCache(&MockCache{store: make(map[string]any)}, time.Minute, 100,
+		WriteBackCacheWithJournal(journal))
+	assert.ElementsMatch(t, []string{"key1", "key3"}, cache.GetDirtyKeys())
+}
+
+// stringCodec 只支持字符串的编解码器
+type stringCodec struct{}
+
+func (stringCodec) Marshal(val any) ([]byte, error) {
+	s, ok := val.(string)
+	if !ok {
+		return nil, assert.AnError
+	}
+	return []byte(s), nil
+}
+
+func (stringCodec) Unmarshal(data []byte, ptr any) error {
+	*ptr.(*any) = string(data)
+	return nil
+}
+
+// TestWriteBackJournal_Codec 测试自定义编解码器和无法编码的值
+func TestWriteBackJournal_Codec(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "write_back.journal")
+
+	journal, err := NewWriteBackJournal(path, stringCodec{})
+	require.NoError(t, err)
+	mockCache := &MockCache{store: make(map[string]any)}
+	cache := NewWriteBackCache(mockCache, time.Minute, 100, WriteBackCacheWithJournal(journal))
+
+	require.NoError(t, cache.SetDirty(ctx, "key1", "value1", 0))
+	// 无法编码的值不会写入日志和缓存
+	err = cache.SetDirty(ctx, "key2", 42, 0)
+	assert.ErrorIs(t, err, ErrValueNotEncodable)
+	assert.NotContains(t, mockCache.store, "key2")
+	require.NoError(t, journal.Close())
+
+	journal, err = NewWriteBackJournal(path, stringCodec{})
+	require.NoError(t, err)
+	defer func() {
+		_ = journal.Close()
+	}()
+	mockCache = &MockCache{store: make(map[string]any)}
+	NewWriteBackCache(mockCache, time.Minute, 100, WriteBackCacheWithJournal(journal))
+	assert.Equal(t, map[string]any{"key1": "value1"}, mockCache.store)
+}
+
+// TestWriteBackJournal_WriteDuringFlush 测试刷新期间写入的新值不会被当作已刷新的数据移除
+func TestWriteBackJournal_WriteDuringFlush(t *testing.T) {
+	tests := []struct {
+		name  string
+		flush func(ctx context.Context, cache *WriteBackCache, storer func(ctx context.Context, key string, val any) error) error
+	}{
+		{
+			name: "Flush",
+			flush: func(ctx context.Context, cache *WriteBackCache, storer func(ctx context.Context, key string, val any) error) error {
+				return cache.Flush(ctx, storer)
+			},
+		},
+		{
+			name: "FlushKey",
+			flush: func(ctx context.Context, cache *WriteBackCache, storer func(ctx context.Context, key string, val any) error) error {
+				return cache.FlushKey(ctx, "key1", storer)
+			},
+		},
+		{
+			name: "FlushTx",
+			flush: func(ctx context.Context, cache *WriteBackCache, storer func(ctx context.Context, key string, val any) error) error {
+				return cache.FlushTx(ctx, func(ctx context.Context, items map[string]any) error {
+					return storer(ctx, "key1", items["key1"])
+				})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			path := filepath.Join(t.TempDir(), "write_back.journal")
+
+			journal, err := NewWriteBackJournal(path, nil)
+			require.NoError(t, err)
+			cache := NewWriteBackCache(&MockCache{store: make(map[string]any)}, time.Minute, 100,
+				WriteBackCacheWithJournal(journal))
+			require.NoError(t, cache.SetDirty(ctx, "key1", "old", 0))
+
+			// 写入持久化存储期间同一个键被再次写入
+			var stored []any
+			storer := func(ctx context.Context, key string, val any) error {
+				stored = append(stored, val)
+				return cache.SetDirty(ctx, key, "new", 0)
+			}
+			require.NoError(t, tt.flush(ctx, cache, storer))
+			assert.Equal(t, []any{"old"}, stored)
+
+			// 新值仍是脏数据，并且保留在日志中
+			assert.Equal(t, []string{"key1"}, cache.GetDirtyKeys())
+			require.NoError(t, journal.Close())
+
+			journal, err = NewWriteBackJournal(path, nil)
+			require.NoError(t, err)
+			defer func() {
+				_ = journal.Close()
+			}()
+			mockCache := &MockCache{store: make(map[string]any)}
+			cache = NewWriteBackCache(mockCache, time.Minute, 100, WriteBackCacheWithJournal(journal))
+			assert.Equal(t, []string{"key1"}, cache.GetDirtyKeys())
+			assert.Equal(t, map[string]any{"key1": "new"}, mockCache.store)
+
+			// 恢复后的写入序号接在日志之后，再次刷新可以正常清理
+			require.NoError(t, cache.SetDirty(ctx, "key2", "value2", 0))
+			require.NoError(t, cache.Flush(ctx, NewMockStorer().Store))
+			assert.Empty(t, cache.GetDirtyKeys())
+		})
+	}
+}
+
+// TestWriteBackJournal_Rewrite 测试已失效的记录累积到一定数量后才重写日志
+func TestWriteBackJournal_Rewrite(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "write_back.journal")
+
+	journal, err := NewWriteBackJournal(path, nil)
+	require.NoError(t, err)
+	defer func() {
+		_ = journal.Close()
+	}()
+	cache := NewWriteBackCache(&MockCache{store: make(map[string]any)}, time.Minute, 100,
+		WriteBackCacheWithJournal(journal))
+	storer := NewMockStorer()
+	require.NoError(t, cache.SetDirty(ctx, "pending", "value", 0))
+
+	// 每次刷新只追加删除记录，已失效的记录累积到阈值后重写日志，文件大小回落
+	var sizes []int64
+	rewritten := false
+	for i := 0; i < journalCompactMinRecords; i++ {
+		require.NoError(t, cache.SetDirty(ctx, "key", i, 0))
+		require.NoError(t, cache.FlushKey(ctx, "key", storer.Store))
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		if len(sizes) > 0 && info.Size() < sizes[len(sizes)-1] {
+			rewritten = true
+		}
+		sizes = append(sizes, info.Size())
+	}
+	assert.Greater(t, sizes[1], sizes[0])
+	assert.True(t, rewritten)
+	assert.Less(t, journal.written, journalCompactMinRecords)
+
+	// 重写后的日志只保留未刷新的脏数据
+	require.NoError(t, journal.Close())
+	journal, err = NewWriteBackJournal(path, nil)
+	require.NoError(t, err)
+	cache = NewWriteBackCache(&MockCache{store: make(map[string]any)}, time.Minute, 100,
+		WriteBackCacheWithJournal(journal))
+	assert.Equal(t, []string{"pending"}, cache.GetDirtyKeys())
+}
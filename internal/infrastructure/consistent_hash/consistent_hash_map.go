@@ -1,6 +1,7 @@
 package consistent_hash
 
 import (
+	"errors"
 	"fmt"
 	"hash/crc32"
 	"sort"
@@ -16,6 +17,7 @@ type ConsistentHashMap struct {
 	replicas int               // 虚拟节点倍数
 	keys     []uint32          // 哈希环（排序的哈希值列表）
 	hashMap  map[uint32]string // 虚拟节点与真实节点的映射表，键是虚拟节点的哈希值，值是真实节点的名称
+	vnodes   map[string]int    // 每个真实节点的虚拟节点数量，按权重添加的节点为replicas*weight
 	mu       sync.RWMutex      // 读写锁保护
 }
 
@@ -33,27 +35,23 @@ func NewConsistentHashMap(replicas int, hashFunc domainHash.Hash) *ConsistentHas
 		replicas: replicas,
 		keys:     make([]uint32, 0),
 		hashMap:  make(map[uint32]string),
+		vnodes:   make(map[string]int),
 	}
 }
 
 // Add 添加节点到哈希环
+// 操作是幂等的：已存在的节点会被跳过，不会重复创建虚拟节点，也不会改变按权重添加的节点的虚拟节点数量
 // peers: 要添加的节点列表
 func (m *ConsistentHashMap) Add(peers ...string) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	for _, peer := range peers {
-		// 为每个真实节点创建replicas个虚拟节点
-		for i := 0; i < m.replicas; i++ {
-			// 生成虚拟节点的键
-			virtualKey := m.generateVirtualNodeKey(peer, i)
-			// 计算虚拟节点的哈希值
-			hash := m.hash([]byte(virtualKey))
-			// 添加到哈希环
-			m.keys = append(m.keys, hash)
-			// 建立虚拟节点到真实节点的映射
-			m.hashMap[hash] = peer
+		if _, ok := m.vnodes[peer]; ok {
+			continue
 		}
+		// 为每个真实节点创建replicas个虚拟节点
+		m.addPeer(peer, m.replicas)
 	}
 
 	// 保持哈希环有序
@@ -62,6 +60,62 @@ func (m *ConsistentHashMap) Add(peers ...string) {
 	})
 }
 
+// AddValidated 校验节点名称后添加到哈希环
+// 节点名称按哈希键的规则校验，不能为空且不超过500个字符；任一节点无效时不添加任何节点
+// peers: 要添加的节点列表
+// 返回: 错误信息，包含所有无效节点的原因，均可通过errors.Is判断为 ErrInvalidPeer
+func (m *ConsistentHashMap) AddValidated(peers ...string) error {
+	var errs []error
+	for i, peer := range peers {
+		if _, err := domainHash.NewHashKey(peer); err != nil {
+			errs = append(errs, fmt.Errorf("%w: 第%d个节点 %q: %v", domainHash.ErrInvalidPeer, i+1, peer, err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	m.Add(peers...)
+	return nil
+}
+
+// AddPeerWithWeight 按权重添加节点到哈希环
+// 节点的虚拟节点数量为replicas*weight，权重越大分配到的键越多
+// 节点已存在时按新的权重重新添加
+// peer: 要添加的节点
+// weight: 节点权重，不大于0时视为1
+func (m *ConsistentHashMap) AddPeerWithWeight(peer string, weight int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if weight <= 0 {
+		weight = 1
+	}
+	if _, ok := m.vnodes[peer]; ok {
+		m.removePeer(peer)
+	}
+	m.addPeer(peer, m.replicas*weight)
+
+	sort.Slice(m.keys, func(i, j int) bool {
+		return m.keys[i] < m.keys[j]
+	})
+}
+
+// addPeer 为真实节点创建n个虚拟节点，调用方负责加锁和排序哈希环
+func (m *ConsistentHashMap) addPeer(peer string, n int) {
+	for i := 0; i < n; i++ {
+		// 生成虚拟节点的键
+		virtualKey := m.generateVirtualNodeKey(peer, i)
+		// 计算虚拟节点的哈希值
+		hash := m.hash([]byte(virtualKey))
+		// 添加到哈希环
+		m.keys = append(m.keys, hash)
+		// 建立虚拟节点到真实节点的映射
+		m.hashMap[hash] = peer
+	}
+	m.vnodes[peer] = n
+}
+
 // Remove 从哈希环中移除节点
 // peers: 要移除的节点列表
 func (m *ConsistentHashMap) Remove(peers ...string) {
@@ -70,28 +124,47 @@ func (m *ConsistentHashMap) Remove(peers ...string) {
 
 	for _, peer := range peers {
 		// 移除该节点的所有虚拟节点
-		for i := 0; i < m.replicas; i++ {
-			virtualKey := m.generateVirtualNodeKey(peer, i)
-			hash := m.hash([]byte(virtualKey))
-
-			// 从映射表中删除
-			delete(m.hashMap, hash)
-
-			// 从哈希环中删除
-			for j, key := range m.keys {
-				if key == hash {
-					m.keys = append(m.keys[:j], m.keys[j+1:]...)
-					break
-				}
+		m.removePeer(peer)
+	}
+}
+
+// removePeer 移除真实节点的所有虚拟节点，调用方负责加锁
+func (m *ConsistentHashMap) removePeer(peer string) {
+	n, ok := m.vnodes[peer]
+	if !ok {
+		n = m.replicas
+	}
+	for i := 0; i < n; i++ {
+		virtualKey := m.generateVirtualNodeKey(peer, i)
+		hash := m.hash([]byte(virtualKey))
+
+		// 从映射表中删除
+		delete(m.hashMap, hash)
+
+		// 从哈希环中删除
+		for j, key := range m.keys {
+			if key == hash {
+				m.keys = append(m.keys[:j], m.keys[j+1:]...)
+				break
 			}
 		}
 	}
+	delete(m.vnodes, peer)
 }
 
 // Get 根据键获取对应的节点
 // key: 要查找的键
 // 返回: 对应的节点名称和错误信息
 func (m *ConsistentHashMap) Get(key string) (string, error) {
+	return m.GetBytes([]byte(key))
+}
+
+// GetBytes 根据字节切片形式的键获取对应的节点
+// 直接对字节切片计算哈希，已持有[]byte键的调用方（如二进制协议）无需先转换为string
+// 与 Get 对相同内容的键返回相同的节点
+// key: 要查找的键
+// 返回: 对应的节点名称和错误信息
+func (m *ConsistentHashMap) GetBytes(key []byte) (string, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -100,7 +173,7 @@ func (m *ConsistentHashMap) Get(key string) (string, error) {
 	}
 
 	// 计算键的哈希值
-	hash := m.hash([]byte(key))
+	hash := m.hash(key)
 
 	// 在哈希环上顺时针查找第一个大于等于hash的虚拟节点
 	idx := sort.Search(len(m.keys), func(i int) bool {
@@ -274,23 +347,29 @@ func (m *ConsistentHashMap) Clone() *ConsistentHashMap {
 		replicas: m.replicas,
 		keys:     make([]uint32, len(m.keys)),
 		hashMap:  make(map[uint32]string),
+		vnodes:   make(map[string]int, len(m.vnodes)),
 	}
 
 	copy(newMap.keys, m.keys)
 	for k, v := range m.hashMap {
 		newMap.hashMap[k] = v
 	}
+	for k, v := range m.vnodes {
+		newMap.vnodes[k] = v
+	}
 
 	return newMap
 }
 
 // Reset 重置一致性哈希映射
+// 在一次加锁中移除所有节点和虚拟节点，用于重新配置整个集群
 func (m *ConsistentHashMap) Reset() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	m.keys = make([]uint32, 0)
 	m.hashMap = make(map[uint32]string)
+	m.vnodes = make(map[string]int)
 }
 
 // GetVirtualNodeCount 获取指定节点的虚拟节点数量
@@ -324,3 +403,55 @@ func (m *ConsistentHashMap) GetLoadDistribution(testKeys []string) map[string]in
 
 	return distribution
 }
+
+// SimulateAdd 模拟添加节点，估算样本键的归属变化，不修改当前的哈希环
+// 用于在生产环境扩容前评估需要迁移的键数量
+// peer: 要添加的节点，已存在时不产生变化
+// sampleKeys: 样本键
+// 返回: 归属发生变化的样本键数量，以及每个节点分到的样本键数量的变化（只包含有变化的节点）
+func (m *ConsistentHashMap) SimulateAdd(peer string, sampleKeys []string) (int, map[string]int) {
+	return m.simulate(sampleKeys, func(ring *ConsistentHashMap) {
+		ring.Add(peer)
+	})
+}
+
+// SimulateRemove 模拟移除节点，估算样本键的归属变化，不修改当前的哈希环
+// 用于在生产环境缩容前评估需要迁移的键数量
+// peer: 要移除的节点，不存在时不产生变化
+// sampleKeys: 样本键
+// 返回: 归属发生变化的样本键数量，以及每个节点分到的样本键数量的变化（只包含有变化的节点）
+func (m *ConsistentHashMap) SimulateRemove(peer string, sampleKeys []string) (int, map[string]int) {
+	return m.simulate(sampleKeys, func(ring *ConsistentHashMap) {
+		ring.Remove(peer)
+	})
+}
+
+// simulate 在哈希环的副本上应用变更，比较变更前后样本键的归属
+// 变更前后的归属都基于同一个副本计算，不受并发修改当前哈希环的影响
+func (m *ConsistentHashMap) simulate(sampleKeys []string, change func(ring *ConsistentHashMap)) (int, map[string]int) {
+	ring := m.Clone()
+	before := make([]string, len(sampleKeys))
+	for i, key := range sampleKeys {
+		// 哈希环为空时没有归属节点，记为空字符串
+		before[i], _ = ring.Get(key)
+	}
+
+	change(ring)
+
+	moved := 0
+	delta := make(map[string]int)
+	for i, key := range sampleKeys {
+		after, _ := ring.Get(key)
+		if after == before[i] {
+			continue
+		}
+		moved++
+		if before[i] != "" {
+			delta[before[i]]--
+		}
+		if after != "" {
+			delta[after]++
+		}
+	}
+	return moved, delta
+}
@@ -3,6 +3,7 @@ package consistent_hash
 import (
 	"fmt"
 	"hash/crc32"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -588,3 +589,284 @@ func TestSingleflightPeerPicker(t *testing.T) {
 		assert.True(t, exists)
 	})
 }
+
+// TestConsistentHashMap_AddPeerWithWeight 测试按权重添加节点
+func TestConsistentHashMap_AddPeerWithWeight(t *testing.T) {
+	hashMap := NewConsistentHashMap(10, nil)
+	hashMap.AddPeerWithWeight("peer1", 1)
+	hashMap.AddPeerWithWeight("peer2", 3)
+	hashMap.AddPeerWithWeight("peer3", 0) // 权重不大于0时视为1
+	hashMap.Add("peer4")
+
+	assert.Equal(t, 10, hashMap.GetVirtualNodeCount("peer1"))
+	assert.Equal(t, 30, hashMap.GetVirtualNodeCount("peer2"))
+	assert.Equal(t, 10, hashMap.GetVirtualNodeCount("peer3"))
+	assert.Equal(t, 10, hashMap.GetVirtualNodeCount("peer4"))
+	assert.Len(t, hashMap.GetKeys(), 60)
+
+	// 按新的权重重新添加
+	hashMap.AddPeerWithWeight("peer2", 2)
+	assert.Equal(t, 20, hashMap.GetVirtualNodeCount("peer2"))
+	assert.Len(t, hashMap.GetKeys(), 50)
+
+	// 移除时删除节点的所有虚拟节点
+	hashMap.Remove("peer2")
+	assert.Equal(t, 0, hashMap.GetVirtualNodeCount("peer2"))
+	assert.Len(t, hashMap.GetKeys(), 30)
+
+	clone := hashMap.Clone()
+	clone.Remove("peer1", "peer3", "peer4")
+	assert.True(t, clone.IsEmpty())
+}
+
+// TestConsistentHashMap_AddPeerWithWeight_Remove 测试混合加权和未加权节点时移除的正确性
+func TestConsistentHashMap_AddPeerWithWeight_Remove(t *testing.T) {
+	hashMap := NewConsistentHashMap(5, nil)
+	hashMap.AddPeerWithWeight("weighted", 4)
+	hashMap.Add("plain")
+
+	assert.Equal(t, 20, hashMap.GetVirtualNodeCount("weighted"))
+	assert.Equal(t, 5, hashMap.GetVirtualNodeCount("plain"))
+	assert.Len(t, hashMap.GetKeys(), 25)
+	assert.Len(t, hashMap.GetHashMap(), 25)
+
+	// 移除加权节点后不残留任何虚拟节点，所有键都路由到剩余节点
+	hashMap.Remove("weighted")
+	assert.Len(t, hashMap.GetKeys(), 5)
+	assert.Len(t, hashMap.GetHashMap(), 5)
+	for i := range 100 {
+		peer, err := hashMap.Get(fmt.Sprintf("key%d", i))
+		require.NoError(t, err)
+		assert.Equal(t, "plain", peer)
+	}
+
+	hashMap.Remove("plain")
+	assert.True(t, hashMap.IsEmpty())
+	assert.Empty(t, hashMap.GetHashMap())
+}
+
+// TestSingleflightPeerPicker_RemoveAllPeers 测试一次性清空哈希环和节点集合
+func TestSingleflightPeerPicker_RemoveAllPeers(t *testing.T) {
+	hashMap := NewConsistentHashMap(10, nil)
+	picker := NewSingleflightPeerPicker(hashMap)
+	for _, id := range []string{"peer1", "peer2", "peer3"} {
+		peer, err := domainHash.NewPeerInfo(id, id+":8080", 1)
+		require.NoError(t, err)
+		picker.AddPeers(peer)
+	}
+	hashMap.AddPeerWithWeight("peer2", 3)
+	require.False(t, hashMap.IsEmpty())
+
+	picker.RemoveAllPeers()
+
+	assert.True(t, hashMap.IsEmpty())
+	assert.Empty(t, picker.GetAllPeers())
+	stats := picker.GetStats()
+	assert.Equal(t, 0, stats.TotalPeers())
+	assert.Equal(t, 0, stats.VirtualNodes())
+	assert.Empty(t, stats.KeyDistribution())
+	_, err := hashMap.Get("key")
+	assert.ErrorIs(t, err, domainHash.ErrNoPeers)
+	_, err = picker.PickPeer("key")
+	assert.ErrorIs(t, err, domainHash.ErrNoPeers)
+
+	// 重置后可以重新添加节点，之前的权重不会残留
+	hashMap.Add("peer2")
+	assert.Equal(t, 10, hashMap.GetVirtualNodeCount("peer2"))
+	hashMap.Reset()
+	assert.True(t, hashMap.IsEmpty())
+	assert.Equal(t, 0, hashMap.Stats().TotalPeers())
+}
+
+// TestConsistentHashMap_GetBytes 测试GetBytes与Get对相同内容的键返回相同的节点
+func TestConsistentHashMap_GetBytes(t *testing.T) {
+	hashMap := NewConsistentHashMap(50, nil)
+	_, err := hashMap.GetBytes([]byte("key"))
+	assert.ErrorIs(t, err, domainHash.ErrNoPeers)
+
+	hashMap.Add("peer1", "peer2", "peer3")
+	for i := range 1000 {
+		key := fmt.Sprintf("key%d", i)
+		want, err := hashMap.Get(key)
+		require.NoError(t, err)
+		got, err := hashMap.GetBytes([]byte(key))
+		require.NoError(t, err)
+		assert.Equal(t, want, got, key)
+	}
+
+	// 空键和nil切片等价
+	want, err := hashMap.Get("")
+	require.NoError(t, err)
+	got, err := hashMap.GetBytes(nil)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+// TestConsistentHashMap_AddIdempotent 测试重复添加同一节点不会重复创建虚拟节点
+func TestConsistentHashMap_AddIdempotent(t *testing.T) {
+	hashMap := NewConsistentHashMap(10, nil)
+	hashMap.Add("peer1")
+	hashMap.Add("peer1")
+	hashMap.Add("peer1", "peer2", "peer2")
+
+	assert.Equal(t, 10, hashMap.GetVirtualNodeCount("peer1"))
+	assert.Equal(t, 10, hashMap.GetVirtualNodeCount("peer2"))
+	assert.Len(t, hashMap.GetKeys(), 20)
+	stats := hashMap.Stats()
+	assert.Equal(t, 2, stats.TotalPeers())
+	assert.Equal(t, 20, stats.VirtualNodes())
+
+	// 已按权重添加的节点保持原有的虚拟节点数量
+	hashMap.AddPeerWithWeight("peer3", 3)
+	hashMap.Add("peer3")
+	assert.Equal(t, 30, hashMap.GetVirtualNodeCount("peer3"))
+
+	// 移除一次即可完全移除节点
+	hashMap.Remove("peer1")
+	assert.Equal(t, 0, hashMap.GetVirtualNodeCount("peer1"))
+	assert.Len(t, hashMap.GetKeys(), 40)
+}
+
+// TestConsistentHashMap_AddValidated 测试添加前校验节点名称
+func TestConsistentHashMap_AddValidated(t *testing.T) {
+	testCases := []struct {
+		name    string
+		peers   []string
+		wantErr bool
+	}{
+		{name: "有效节点", peers: []string{"peer1", "peer2"}},
+		{name: "空节点", peers: []string{""}, wantErr: true},
+		{name: "混合有效和空节点", peers: []string{"peer1", "", "peer2"}, wantErr: true},
+		{name: "节点名称过长", peers: []string{strings.Repeat("p", 501)}, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			hashMap := NewConsistentHashMap(10, nil)
+			err := hashMap.AddValidated(tc.peers...)
+			if tc.wantErr {
+				assert.ErrorIs(t, err, domainHash.ErrInvalidPeer)
+				// 任一节点无效时不添加任何节点
+				assert.True(t, hashMap.IsEmpty())
+				return
+			}
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tc.peers, hashMap.Peers())
+		})
+	}
+}
+
+// TestSingleflightPeerPicker_Weighted 测试启用权重后按节点权重分配虚拟节点
+func TestSingleflightPeerPicker_Weighted(t *testing.T) {
+	newPeer := func(id string, weight int) domainHash.Peer {
+		peer, err := domainHash.NewPeerInfo(id, id+":8080", weight)
+		require.NoError(t, err)
+		return peer
+	}
+
+	picker := NewSingleflightPeerPicker(NewConsistentHashMap(10, nil))
+	picker.AddPeers(newPeer("peer1", 1), newPeer("peer2", 2))
+	assert.Equal(t, map[string]int{"peer1": 10, "peer2": 10}, picker.GetStats().KeyDistribution())
+
+	picker = NewSingleflightPeerPicker(NewConsistentHashMap(10, nil))
+	picker.SetWeighted(true)
+	picker.AddPeers(newPeer("peer1", 1), newPeer("peer2", 2))
+	assert.Equal(t, map[string]int{"peer1": 10, "peer2": 20}, picker.GetStats().KeyDistribution())
+}
+
+// BenchmarkConsistentHashMap_Get 对比持有[]byte键时转换为string再查找与直接查找的开销
+func BenchmarkConsistentHashMap_Get(b *testing.B) {
+	hashMap := NewConsistentHashMap(150, nil)
+	hashMap.Add("peer1", "peer2", "peer3", "peer4", "peer5")
+	key := []byte("user:1234567890")
+
+	b.Run("Get", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = hashMap.Get(string(key))
+		}
+	})
+
+	b.Run("GetBytes", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = hashMap.GetBytes(key)
+		}
+	})
+}
+
+// rebalanceBruteForce 分别计算变更前后样本键的归属，统计迁移的键数量和各节点键数量的变化
+func rebalanceBruteForce(t *testing.T, before, after *ConsistentHashMap, keys []string) (int, map[string]int) {
+	moved := 0
+	delta := make(map[string]int)
+	for _, key := range keys {
+		from, err := before.Get(key)
+		require.NoError(t, err)
+		to, err := after.Get(key)
+		require.NoError(t, err)
+		if from != to {
+			moved++
+			delta[from]--
+			delta[to]++
+		}
+	}
+	return moved, delta
+}
+
+// TestConsistentHashMap_Simulate 测试模拟添加和移除节点的结果与实际应用变更后重新计算一致
+func TestConsistentHashMap_Simulate(t *testing.T) {
+	hashMap := NewConsistentHashMap(50, nil)
+	hashMap.Add("node1", "node2", "node3")
+	keys := make([]string, 2000)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key%d", i)
+	}
+	original := hashMap.Clone()
+
+	t.Run("添加节点", func(t *testing.T) {
+		moved, delta := hashMap.SimulateAdd("node4", keys)
+
+		// 模拟不修改当前的哈希环
+		assert.Equal(t, []string{"node1", "node2", "node3"}, hashMap.Peers())
+
+		applied := original.Clone()
+		applied.Add("node4")
+		wantMoved, wantDelta := rebalanceBruteForce(t, original, applied, keys)
+		assert.Equal(t, wantMoved, moved)
+		assert.Equal(t, wantDelta, delta)
+		assert.Positive(t, moved)
+		// 只有迁移到新节点的键
+		assert.Equal(t, moved, delta["node4"])
+	})
+
+	t.Run("移除节点", func(t *testing.T) {
+		moved, delta := hashMap.SimulateRemove("node2", keys)
+		assert.Equal(t, []string{"node1", "node2", "node3"}, hashMap.Peers())
+
+		applied := original.Clone()
+		applied.Remove("node2")
+		wantMoved, wantDelta := rebalanceBruteForce(t, original, applied, keys)
+		assert.Equal(t, wantMoved, moved)
+		assert.Equal(t, wantDelta, delta)
+		// 只有原来属于被移除节点的键迁移
+		assert.Equal(t, original.GetLoadDistribution(keys)["node2"], moved)
+		assert.Equal(t, -moved, delta["node2"])
+	})
+
+	t.Run("无变化", func(t *testing.T) {
+		moved, delta := hashMap.SimulateAdd("node1", keys)
+		assert.Equal(t, 0, moved)
+		assert.Empty(t, delta)
+		moved, delta = hashMap.SimulateRemove("absent", keys)
+		assert.Equal(t, 0, moved)
+		assert.Empty(t, delta)
+	})
+
+	t.Run("空哈希环", func(t *testing.T) {
+		empty := NewConsistentHashMap(10, nil)
+		moved, delta := empty.SimulateAdd("node1", keys[:10])
+		assert.Equal(t, 10, moved)
+		assert.Equal(t, map[string]int{"node1": 10}, delta)
+		assert.True(t, empty.IsEmpty())
+	})
+}
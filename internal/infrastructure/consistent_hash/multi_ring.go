@@ -0,0 +1,129 @@
+package consistent_hash
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	domainHash "github.com/justinwongcn/hamster/internal/domain/consistent_hash"
+)
+
+// MultiRing 管理多个命名的哈希环
+// 每个环是独立的 ConsistentHashMap，拥有各自的节点集合和统计信息，
+// 用于一个服务同时协调多个集群，例如缓存键和锁键分别路由到不同的节点集合
+type MultiRing struct {
+	replicas int
+	hashFunc domainHash.Hash
+	rings    map[string]*ConsistentHashMap
+	mu       sync.RWMutex
+}
+
+// NewMultiRing 创建多哈希环管理器
+// replicas: 每个环的虚拟节点倍数
+// hashFunc: 每个环的Hash函数，如果为nil则使用默认的crc32.ChecksumIEEE
+// 返回: MultiRing实例
+func NewMultiRing(replicas int, hashFunc domainHash.Hash) *MultiRing {
+	return &MultiRing{
+		replicas: replicas,
+		hashFunc: hashFunc,
+		rings:    make(map[string]*ConsistentHashMap),
+	}
+}
+
+// AddPeers 添加节点到指定的哈希环，环不存在时自动创建
+// ring: 哈希环名称
+// peers: 要添加的节点列表
+func (r *MultiRing) AddPeers(ring string, peers ...string) {
+	r.mu.Lock()
+	m, ok := r.rings[ring]
+	if !ok {
+		m = NewConsistentHashMap(r.replicas, r.hashFunc)
+		r.rings[ring] = m
+	}
+	r.mu.Unlock()
+
+	m.Add(peers...)
+}
+
+// RemovePeers 从指定的哈希环移除节点，环不存在时不做任何操作
+// 节点全部移除后环仍然保留，需要删除时调用 RemoveRing
+// ring: 哈希环名称
+// peers: 要移除的节点列表
+func (r *MultiRing) RemovePeers(ring string, peers ...string) {
+	if m, ok := r.Ring(ring); ok {
+		m.Remove(peers...)
+	}
+}
+
+// RemoveRing 删除指定的哈希环
+// ring: 哈希环名称
+// 返回: 环是否存在
+func (r *MultiRing) RemoveRing(ring string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := r.rings[ring]
+	delete(r.rings, ring)
+	return ok
+}
+
+// Get 在指定的哈希环中根据键获取对应的节点
+// ring: 哈希环名称
+// key: 要查找的键
+// 返回: 对应的节点名称和错误信息，环不存在或没有节点时返回 ErrNoPeers
+func (r *MultiRing) Get(ring string, key string) (string, error) {
+	m, ok := r.Ring(ring)
+	if !ok {
+		return "", fmt.Errorf("%w: 哈希环 %s 不存在", domainHash.ErrNoPeers, ring)
+	}
+	return m.Get(key)
+}
+
+// Ring 获取指定名称的哈希环
+// ring: 哈希环名称
+// 返回: 哈希环和是否存在
+func (r *MultiRing) Ring(ring string) (*ConsistentHashMap, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	m, ok := r.rings[ring]
+	return m, ok
+}
+
+// Rings 获取所有哈希环的名称
+// 返回: 按名称排序的哈希环名称列表
+func (r *MultiRing) Rings() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.rings))
+	for name := range r.rings {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Stats 获取指定哈希环的统计信息
+// ring: 哈希环名称
+// 返回: 统计信息和错误信息，环不存在时返回 ErrNoPeers
+func (r *MultiRing) Stats(ring string) (domainHash.HashStats, error) {
+	m, ok := r.Ring(ring)
+	if !ok {
+		return domainHash.HashStats{}, fmt.Errorf("%w: 哈希环 %s 不存在", domainHash.ErrNoPeers, ring)
+	}
+	return m.Stats(), nil
+}
+
+// AllStats 获取所有哈希环的统计信息
+// 返回: 哈希环名称到统计信息的映射
+func (r *MultiRing) AllStats() map[string]domainHash.HashStats {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stats := make(map[string]domainHash.HashStats, len(r.rings))
+	for name, m := range r.rings {
+		stats[name] = m.Stats()
+	}
+	return stats
+}
@@ -0,0 +1,118 @@
+package consistent_hash
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domainHash "github.com/justinwongcn/hamster/internal/domain/consistent_hash"
+)
+
+// TestMultiRing 测试按名称管理多个哈希环
+func TestMultiRing(t *testing.T) {
+	r := NewMultiRing(10, nil)
+	assert.Empty(t, r.Rings())
+
+	_, err := r.Get("cache", "key1")
+	assert.ErrorIs(t, err, domainHash.ErrNoPeers)
+	_, err = r.Stats("cache")
+	assert.ErrorIs(t, err, domainHash.ErrNoPeers)
+
+	r.AddPeers("lock", "lock-a", "lock-b")
+	r.AddPeers("cache", "cache-a", "cache-b", "cache-c")
+	assert.Equal(t, []string{"cache", "lock"}, r.Rings())
+
+	peer, err := r.Get("cache", "key1")
+	require.NoError(t, err)
+	assert.Contains(t, []string{"cache-a", "cache-b", "cache-c"}, peer)
+	peer, err = r.Get("lock", "key1")
+	require.NoError(t, err)
+	assert.Contains(t, []string{"lock-a", "lock-b"}, peer)
+
+	// 与直接使用单个哈希环的路由结果一致
+	single := NewConsistentHashMap(10, nil)
+	single.Add("cache-a", "cache-b", "cache-c")
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("key%d", i)
+		want, _ := single.Get(key)
+		got, err := r.Get("cache", key)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+
+	stats := r.AllStats()
+	assert.Len(t, stats, 2)
+	assert.Equal(t, 3, stats["cache"].TotalPeers())
+	assert.Equal(t, 2, stats["lock"].TotalPeers())
+
+	// 移除所有节点后环仍然存在但没有可用节点
+	r.RemovePeers("lock", "lock-a", "lock-b")
+	_, err = r.Get("lock", "key1")
+	assert.ErrorIs(t, err, domainHash.ErrNoPeers)
+	assert.Equal(t, []string{"cache", "lock"}, r.Rings())
+
+	assert.True(t, r.RemoveRing("lock"))
+	assert.False(t, r.RemoveRing("lock"))
+	_, ok := r.Ring("lock")
+	assert.False(t, ok)
+	assert.Equal(t, []string{"cache"}, r.Rings())
+
+	// 不存在的环上移除节点不做任何操作
+	r.RemovePeers("absent", "peer")
+	assert.Equal(t, []string{"cache"}, r.Rings())
+}
+
+// TestMultiRing_Isolation 测试各个哈希环相互隔离，修改一个环的节点不影响其他环的路由和统计
+func TestMultiRing_Isolation(t *testing.T) {
+	r := NewMultiRing(50, nil)
+	r.AddPeers("a", "node1", "node2")
+	r.AddPeers("b", "node1", "node2")
+
+	keys := make([]string, 1000)
+	before := make(map[string]string, len(keys))
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key%d", i)
+		peer, err := r.Get("b", keys[i])
+		require.NoError(t, err)
+		before[keys[i]] = peer
+	}
+	statsBefore, err := r.Stats("b")
+	require.NoError(t, err)
+
+	// 向环a添加节点，部分键在环a中迁移到新节点
+	r.AddPeers("a", "node3")
+	var moved int
+	for _, key := range keys {
+		peer, err := r.Get("a", key)
+		require.NoError(t, err)
+		if peer == "node3" {
+			moved++
+		}
+	}
+	assert.Positive(t, moved)
+
+	// 环b的路由和统计保持不变
+	for _, key := range keys {
+		peer, err := r.Get("b", key)
+		require.NoError(t, err)
+		assert.Equal(t, before[key], peer)
+	}
+	statsAfter, err := r.Stats("b")
+	require.NoError(t, err)
+	assert.Equal(t, statsBefore, statsAfter)
+	assert.Equal(t, 2, statsAfter.TotalPeers())
+
+	statsA, err := r.Stats("a")
+	require.NoError(t, err)
+	assert.Equal(t, 3, statsA.TotalPeers())
+
+	// 从环a移除节点同样不影响环b
+	r.RemovePeers("a", "node1")
+	for _, key := range keys {
+		peer, err := r.Get("b", key)
+		require.NoError(t, err)
+		assert.Equal(t, before[key], peer)
+	}
+}
@@ -16,6 +16,7 @@ type SingleflightPeerPicker struct {
 	peers          map[string]domainHash.Peer // 节点ID到节点实例的映射
 	mu             sync.RWMutex               // 保护peers映射
 	g              singleflight.Group         // singleflight组
+	weighted       bool                       // 是否按节点权重分配虚拟节点
 }
 
 // NewSingleflightPeerPicker 创建带singleflight优化的节点选择器
@@ -70,6 +71,17 @@ func (p *SingleflightPeerPicker) AddPeers(peers ...domainHash.Peer) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 	
+	// 按权重添加到一致性哈希
+	if wh, ok := p.consistentHash.(interface {
+		AddPeerWithWeight(peer string, weight int)
+	}); ok && p.weighted {
+		for _, peer := range peers {
+			p.peers[peer.ID()] = peer
+			wh.AddPeerWithWeight(peer.ID(), peer.Weight())
+		}
+		return
+	}
+
 	peerIDs := make([]string, len(peers))
 	for i, peer := range peers {
 		peerIDs[i] = peer.ID()
@@ -80,6 +92,16 @@ func (p *SingleflightPeerPicker) AddPeers(peers ...domainHash.Peer) {
 	p.consistentHash.Add(peerIDs...)
 }
 
+// SetWeighted 设置是否按节点权重分配虚拟节点
+// 启用后，一致性哈希实现了 AddPeerWithWeight(peer string, weight int) 时，
+// 之后添加的节点按权重创建虚拟节点，权重越大分配到的键越多；已添加的节点不受影响
+// enabled: 是否启用，默认关闭，所有节点的虚拟节点数量相同
+func (p *SingleflightPeerPicker) SetWeighted(enabled bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.weighted = enabled
+}
+
 // RemovePeers 移除节点
 // peers: 要移除的节点列表
 func (p *SingleflightPeerPicker) RemovePeers(peers ...domainHash.Peer) {
@@ -96,6 +118,21 @@ func (p *SingleflightPeerPicker) RemovePeers(peers ...domainHash.Peer) {
 	p.consistentHash.Remove(peerIDs...)
 }
 
+// RemoveAllPeers 移除所有节点
+// 在一次加锁中清空节点集合和哈希环，用于重新配置整个集群
+// 一致性哈希实现了 Reset() 时直接重置哈希环，否则逐个移除节点
+func (p *SingleflightPeerPicker) RemoveAllPeers() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if r, ok := p.consistentHash.(interface{ Reset() }); ok {
+		r.Reset()
+	} else {
+		p.consistentHash.Remove(p.consistentHash.Peers()...)
+	}
+	p.peers = make(map[string]domainHash.Peer)
+}
+
 // GetAllPeers 获取所有节点
 // 返回: 所有节点的列表
 func (p *SingleflightPeerPicker) GetAllPeers() []domainHash.Peer {
@@ -2,24 +2,100 @@ package lock
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"iter"
+	"math/rand/v2"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
-	"golang.org/x/sync/singleflight"
 
 	domainLock "github.com/justinwongcn/hamster/internal/domain/lock"
 )
 
+// ErrDuplicateClose 重复关闭锁管理器
+var ErrDuplicateClose = errors.New("重复关闭")
+
 // MemoryDistributedLock 基于内存的分布式锁实现
 // 使用内存存储锁信息，支持锁的获取、释放、续约等功能
 // 线程安全，支持并发访问
 type MemoryDistributedLock struct {
-	locks map[string]*memoryLock // 锁存储
-	mu    sync.RWMutex           // 读写锁保护
-	g     singleflight.Group     // singleflight优化
-	stats domainLock.LockStats   // 统计信息
+	locks   map[string]*memoryLock   // 锁存储
+	mu      sync.RWMutex             // 读写锁保护
+	stats   domainLock.LockStats     // 统计信息
+	fair    bool                     // 是否启用公平排队
+	waiters map[string][]*lockWaiter // 公平模式下每个键的FIFO等待队列
+	token   uint64                   // 最近一次发放的栅栏令牌
+	hooks   LockHooks                // 生命周期回调
+
+	// watchers 每个键上等待锁释放或过期通知的通道
+	watchers map[string][]chan struct{}
+
+	// callMu 保护calls以及其中每次调用的等待者计数
+	callMu sync.Mutex
+	// calls SingleflightLock 中每个键正在进行的共享获取锁操作
+	calls map[string]*singleflightCall
+
+	// valueGenerator 锁值生成函数，默认生成UUID
+	valueGenerator ValueGenerator
+
+	// clock 时钟，用于判断锁是否过期以及续约，默认使用系统时间
+	clock Clock
+
+	// cleanupInterval 后台清理过期锁的间隔，不大于0时不启动后台清理
+	cleanupInterval time.Duration
+	// maxExpiration 允许的最大锁过期时间，默认为 domainLock.DefaultMaxLockExpiration
+	maxExpiration time.Duration
+	// close 用于通知后台清理goroutine退出，重复关闭会返回ErrDuplicateClose
+	close chan struct{}
+}
+
+// Clock 时钟接口
+// 测试中可以替换为可手动推进的时钟，避免依赖真实的等待
+type Clock interface {
+	// Now 返回当前时间
+	Now() time.Time
+}
+
+// realClock 基于系统时间的默认时钟
+type realClock struct{}
+
+// Now 返回系统当前时间
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// ValueGenerator 锁值生成函数
+// 锁的所有权依赖锁值的唯一性，生成的值在锁的生命周期内必须全局唯一
+type ValueGenerator func() string
+
+// LockHooks 锁生命周期回调
+// 所有回调都是可选的，在持有内部锁时同步调用，回调中不应再调用锁管理器或锁实例的方法
+type LockHooks struct {
+	OnAcquire func(key, value string) // 获取锁成功时调用，包括公平模式下的锁移交
+	OnRelease func(key, value string) // 持有者主动释放锁时调用
+	OnExpire  func(key, value string) // 过期锁被清理时调用
+	OnRefresh func(key, value string) // 续约成功时调用
+}
+
+// MemoryDistributedLockOption 定义内存分布式锁配置选项函数类型
+type MemoryDistributedLockOption func(lock *MemoryDistributedLock)
+
+// lockWaiter 公平模式下排队等待锁的调用者
+type lockWaiter struct {
+	expiration time.Duration    // 获得锁后的过期时间
+	granted    chan *memoryLock // 锁移交通道，容量为1，保证移交时不会阻塞
+}
+
+// singleflightCall SingleflightLock 中多个调用方共享的一次获取锁操作
+type singleflightCall struct {
+	done     chan struct{}   // 获取锁结束后关闭
+	lock     domainLock.Lock // 获取到的锁，done关闭后可读
+	err      error           // 获取锁的错误，done关闭后可读
+	waiters  int             // 没有放弃等待的调用方数量
+	finished bool            // 获取锁是否已结束
 }
 
 // memoryLock 内存锁实例
@@ -28,25 +104,145 @@ type memoryLock struct {
 	value      string
 	expiration time.Duration
 	createdAt  time.Time
+	token      uint64
 	unlockChan chan struct{}
 	client     *MemoryDistributedLock
 }
 
 // NewMemoryDistributedLock 创建新的内存分布式锁
+// opts: 可选配置项
 // 返回: MemoryDistributedLock实例
-func NewMemoryDistributedLock() *MemoryDistributedLock {
-	return &MemoryDistributedLock{
-		locks: make(map[string]*memoryLock),
-		stats: domainLock.NewLockStats(),
+func NewMemoryDistributedLock(opts ...MemoryDistributedLockOption) *MemoryDistributedLock {
+	res := &MemoryDistributedLock{
+		locks:   make(map[string]*memoryLock),
+		stats:   domainLock.NewLockStats(),
+		waiters: make(map[string][]*lockWaiter),
+		close:   make(chan struct{}),
+
+		watchers: make(map[string][]chan struct{}),
+		calls:    make(map[string]*singleflightCall),
+		valueGenerator: func() string {
+			return uuid.New().String()
+		},
+		clock:         realClock{},
+		maxExpiration: domainLock.DefaultMaxLockExpiration,
+	}
+
+	for _, opt := range opts {
+		opt(res)
+	}
+
+	// 启动 goroutine 定期清理过期锁（仅当cleanupInterval > 0时）
+	if res.cleanupInterval > 0 {
+		go res.cleanupLoop(res.cleanupInterval)
+	}
+
+	return res
+}
+
+// cleanupLoop 按指定间隔清理过期锁，直到锁管理器被关闭
+func (mdl *MemoryDistributedLock) cleanupLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			mdl.CleanExpiredLocks()
+		case <-mdl.close:
+			return
+		}
+	}
+}
+
+// Close 关闭锁管理器，停止后台清理goroutine
+// 返回: 错误信息，nil表示成功
+// 注意: 重复关闭会返回错误；关闭后已持有的锁和获取锁的操作不受影响
+func (mdl *MemoryDistributedLock) Close() error {
+	mdl.mu.Lock()
+	defer mdl.mu.Unlock()
+
+	select {
+	case <-mdl.close:
+		return ErrDuplicateClose
+	default:
+		close(mdl.close)
+	}
+
+	return nil
+}
+
+// MemoryDistributedLockWithFairness 设置是否启用公平锁
+// 启用后，Lock 在锁被占用时按到达顺序排队，锁释放时直接移交给等待最久的调用者，避免饥饿
+// 有调用者排队时，TryLock 不会插队，直接返回抢锁失败
+// fair: 是否启用公平模式，默认关闭
+func MemoryDistributedLockWithFairness(fair bool) MemoryDistributedLockOption {
+	return func(lock *MemoryDistributedLock) {
+		lock.fair = fair
+	}
+}
+
+// MemoryDistributedLockWithCleanupInterval 设置后台清理过期锁的间隔
+// 设置后会启动后台goroutine定期调用 CleanExpiredLocks，避免只使用一次的锁键长期占用内存
+// 使用完毕后应调用 Close 停止后台清理
+// interval: 清理间隔，不大于0时不启动后台清理，默认关闭
+func MemoryDistributedLockWithCleanupInterval(interval time.Duration) MemoryDistributedLockOption {
+	return func(lock *MemoryDistributedLock) {
+		lock.cleanupInterval = interval
+	}
+}
+
+// MemoryDistributedLockWithValueGenerator 设置锁值生成函数
+// 可以在锁值中嵌入请求ID、主机名等信息，便于调试和跨系统关联
+// 锁的续约和释放通过锁值判断所有权，生成的值必须唯一，例如在前缀后拼接UUID
+// gen: 锁值生成函数，在持有内部锁时调用，为nil时使用默认的UUID
+func MemoryDistributedLockWithValueGenerator(gen ValueGenerator) MemoryDistributedLockOption {
+	return func(lock *MemoryDistributedLock) {
+		if gen != nil {
+			lock.valueGenerator = gen
+		}
+	}
+}
+
+// MemoryDistributedLockWithMaxExpiration 设置允许的最大锁过期时间
+// 需要长期租约时可以调大，TryLock 和 Lock 按该上限校验过期时间
+// maxExpiration: 最大过期时间，不大于0时忽略，默认24小时
+func MemoryDistributedLockWithMaxExpiration(maxExpiration time.Duration) MemoryDistributedLockOption {
+	return func(lock *MemoryDistributedLock) {
+		if maxExpiration > 0 {
+			lock.maxExpiration = maxExpiration
+		}
+	}
+}
+
+// MemoryDistributedLockWithClock 设置锁管理器使用的时钟
+// clock: 时钟实现，为nil时忽略，继续使用系统时间
+func MemoryDistributedLockWithClock(clock Clock) MemoryDistributedLockOption {
+	return func(lock *MemoryDistributedLock) {
+		if clock != nil {
+			lock.clock = clock
+		}
+	}
+}
+
+// MemoryDistributedLockWithHooks 设置锁生命周期回调
+// hooks: 生命周期回调，未设置的回调会被忽略
+func MemoryDistributedLockWithHooks(hooks LockHooks) MemoryDistributedLockOption {
+	return func(lock *MemoryDistributedLock) {
+		lock.hooks = hooks
 	}
 }
 
 // TryLock 尝试获取锁（不重试）
+// 上下文已取消或超时时直接返回上下文错误，否则不会阻塞，锁被占用时立即返回抢锁失败
 // ctx: 上下文
 // key: 锁的键
 // expiration: 锁的过期时间
 // 返回: 锁实例和错误信息
 func (mdl *MemoryDistributedLock) TryLock(ctx context.Context, key string, expiration time.Duration) (domainLock.Lock, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	// 验证输入
 	lockKey, err := domainLock.NewLockKey(key)
 	if err != nil {
@@ -56,7 +252,7 @@ func (mdl *MemoryDistributedLock) TryLock(ctx context.Context, key string, expir
 		return nil, err
 	}
 
-	lockExpiration, err := domainLock.NewLockExpiration(expiration)
+	lockExpiration, err := domainLock.NewLockExpirationWithMax(expiration, mdl.maxExpiration)
 	if err != nil {
 		mdl.mu.Lock()
 		mdl.stats = mdl.stats.IncrementFailedLocks()
@@ -67,34 +263,62 @@ func (mdl *MemoryDistributedLock) TryLock(ctx context.Context, key string, expir
 	mdl.mu.Lock()
 	defer mdl.mu.Unlock()
 
+	lock, err := mdl.tryLockLocked(lockKey.String(), lockExpiration.Duration())
+	if err != nil {
+		return nil, err
+	}
+	return lock, nil
+}
+
+// tryLockLocked 尝试获取锁的内部实现
+// 注意: 此方法应在持有写锁的情况下调用
+// key: 已验证的锁键
+// expiration: 已验证的过期时间
+// 返回: 锁实例和错误信息
+func (mdl *MemoryDistributedLock) tryLockLocked(key string, expiration time.Duration) (*memoryLock, error) {
+	// 公平模式下有调用者排队时不允许插队
+	if mdl.fair && len(mdl.waiters[key]) > 0 {
+		mdl.stats = mdl.stats.IncrementFailedLocks()
+		return nil, domainLock.ErrFailedToPreemptLock
+	}
+
 	// 检查是否已存在锁
 	if existingLock, exists := mdl.locks[key]; exists {
 		// 检查锁是否已过期
-		existingExpiration, _ := domainLock.NewLockExpiration(existingLock.expiration)
-		if !existingExpiration.IsExpired(existingLock.createdAt, time.Now()) {
+		existingExpiration, _ := domainLock.NewLockExpirationWithMax(existingLock.expiration, 0)
+		if !existingExpiration.IsExpired(existingLock.createdAt, mdl.clock.Now()) {
 			mdl.stats = mdl.stats.IncrementFailedLocks()
 			return nil, domainLock.ErrFailedToPreemptLock
 		}
 		// 锁已过期，清理旧锁
-		delete(mdl.locks, key)
-		mdl.stats = mdl.stats.IncrementExpiredLocks().DecrementActiveLocks()
+		mdl.expireLocked(existingLock)
 	}
 
-	// 创建新锁
-	value := uuid.New().String()
+	return mdl.newLockLocked(key, expiration), nil
+}
+
+// newLockLocked 创建新锁并登记到锁存储中
+// 注意: 此方法应在持有写锁的情况下调用
+func (mdl *MemoryDistributedLock) newLockLocked(key string, expiration time.Duration) *memoryLock {
+	// 栅栏令牌在所有键之间全局递增
+	mdl.token++
 	lock := &memoryLock{
-		key:        lockKey.String(),
-		value:      value,
-		expiration: lockExpiration.Duration(),
-		createdAt:  time.Now(),
+		key:        key,
+		value:      mdl.valueGenerator(),
+		expiration: expiration,
+		createdAt:  mdl.clock.Now(),
+		token:      mdl.token,
 		unlockChan: make(chan struct{}, 1),
 		client:     mdl,
 	}
 
 	mdl.locks[key] = lock
 	mdl.stats = mdl.stats.IncrementTotalLocks().IncrementActiveLocks()
+	if mdl.hooks.OnAcquire != nil {
+		mdl.hooks.OnAcquire(key, lock.value)
+	}
 
-	return lock, nil
+	return lock
 }
 
 // Lock 获取锁（支持重试）
@@ -109,6 +333,11 @@ func (mdl *MemoryDistributedLock) Lock(ctx context.Context, key string, expirati
 	lockCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
+	// 公平模式下按FIFO顺序排队等待
+	if mdl.fair {
+		return mdl.fairLock(lockCtx, key, expiration, retryStrategy)
+	}
+
 	// 首次尝试
 	lock, err := mdl.TryLock(lockCtx, key, expiration)
 	if err == nil {
@@ -139,6 +368,199 @@ func (mdl *MemoryDistributedLock) Lock(ctx context.Context, key string, expirati
 	return nil, domainLock.ErrFailedToPreemptLock
 }
 
+// LockMulti 同时获取多个键的锁
+// 按键排序后依次获取，所有调用方以相同的全局顺序加锁，避免按不同顺序获取重叠的键时互相等待造成死锁
+// 任一键获取失败时按获取的相反顺序释放已获取的锁，不会持有部分键
+// ctx: 上下文，用于控制超时和取消
+// keys: 锁的键列表，重复的键只获取一次
+// expiration: 每个锁的过期时间
+// timeout: 获取全部锁的总超时时间
+// retryStrategy: 获取每个键时使用的重试策略
+// 返回: 按键排序的锁实例列表和错误信息
+func (mdl *MemoryDistributedLock) LockMulti(ctx context.Context, keys []string, expiration time.Duration, timeout time.Duration, retryStrategy domainLock.RetryStrategy) ([]domainLock.Lock, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%w: 键列表不能为空", domainLock.ErrInvalidLockKey)
+	}
+	sorted := slices.Compact(slices.Sorted(slices.Values(keys)))
+
+	lockCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	locks := make([]domainLock.Lock, 0, len(sorted))
+	for _, key := range sorted {
+		lock, err := mdl.Lock(lockCtx, key, expiration, timeout, retryStrategy)
+		if err != nil {
+			mdl.releaseAll(locks)
+			return nil, fmt.Errorf("获取锁 %s 失败: %w", key, err)
+		}
+		locks = append(locks, lock)
+	}
+	return locks, nil
+}
+
+// TryLockMulti 尝试同时获取多个键的锁，不等待也不重试
+// 与 LockMulti 相同按键排序后依次获取，任一键已被持有时按相反顺序释放已获取的锁
+// ctx: 上下文
+// keys: 锁的键列表，重复的键只获取一次
+// expiration: 每个锁的过期时间
+// 返回: 按键排序的锁实例列表和错误信息，键被占用时返回包含该键的 ErrFailedToPreemptLock
+func (mdl *MemoryDistributedLock) TryLockMulti(ctx context.Context, keys []string, expiration time.Duration) ([]domainLock.Lock, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%w: 键列表不能为空", domainLock.ErrInvalidLockKey)
+	}
+	sorted := slices.Compact(slices.Sorted(slices.Values(keys)))
+
+	locks := make([]domainLock.Lock, 0, len(sorted))
+	for _, key := range sorted {
+		lock, err := mdl.TryLock(ctx, key, expiration)
+		if err != nil {
+			mdl.releaseAll(locks)
+			if errors.Is(err, domainLock.ErrFailedToPreemptLock) {
+				return nil, fmt.Errorf("%w: key: %s", domainLock.ErrFailedToPreemptLock, key)
+			}
+			return nil, err
+		}
+		locks = append(locks, lock)
+	}
+	return locks, nil
+}
+
+// releaseAll 按获取的相反顺序释放锁
+// 回滚时调用方的ctx可能已经结束，因此使用独立的上下文释放
+func (mdl *MemoryDistributedLock) releaseAll(locks []domainLock.Lock) {
+	for i := len(locks) - 1; i >= 0; i-- {
+		_ = locks[i].Unlock(context.Background())
+	}
+}
+
+// fairLock 公平模式下获取锁
+// 锁被占用时进入该键的FIFO等待队列，锁释放时由Unlock直接移交给队首等待者
+// 重试策略的每个间隔用于检查持有者的锁是否已过期，重试次数耗尽后放弃排队
+// ctx: 带超时的上下文
+// key: 锁的键
+// expiration: 锁的过期时间
+// retryStrategy: 重试策略
+// 返回: 锁实例和错误信息
+func (mdl *MemoryDistributedLock) fairLock(ctx context.Context, key string, expiration time.Duration, retryStrategy domainLock.RetryStrategy) (domainLock.Lock, error) {
+	lockKey, err := domainLock.NewLockKey(key)
+	if err != nil {
+		mdl.mu.Lock()
+		mdl.stats = mdl.stats.IncrementFailedLocks()
+		mdl.mu.Unlock()
+		return nil, err
+	}
+
+	lockExpiration, err := domainLock.NewLockExpirationWithMax(expiration, mdl.maxExpiration)
+	if err != nil {
+		mdl.mu.Lock()
+		mdl.stats = mdl.stats.IncrementFailedLocks()
+		mdl.mu.Unlock()
+		return nil, err
+	}
+
+	// 尝试获取锁与进入队列必须是原子的，否则可能错过锁释放时的移交
+	mdl.mu.Lock()
+	lock, err := mdl.tryLockLocked(lockKey.String(), lockExpiration.Duration())
+	if err == nil {
+		mdl.mu.Unlock()
+		return lock, nil
+	}
+	waiter := &lockWaiter{
+		expiration: lockExpiration.Duration(),
+		granted:    make(chan *memoryLock, 1),
+	}
+	mdl.waiters[key] = append(mdl.waiters[key], waiter)
+	mdl.mu.Unlock()
+
+	for interval := range retryStrategy.Iterator() {
+		select {
+		case lock := <-waiter.granted:
+			return lock, nil
+		case <-ctx.Done():
+			return mdl.abandonWaiter(key, waiter, ctx.Err())
+		case <-time.After(interval):
+			if lock, ok := mdl.acquireForHeadWaiter(key, waiter); ok {
+				return lock, nil
+			}
+		}
+	}
+
+	return mdl.abandonWaiter(key, waiter, domainLock.ErrFailedToPreemptLock)
+}
+
+// acquireForHeadWaiter 当等待者位于队首且锁已过期或不存在时直接获取锁
+// 用于处理持有者未释放而锁自然过期的情况
+// 返回: 锁实例和是否获取成功
+func (mdl *MemoryDistributedLock) acquireForHeadWaiter(key string, waiter *lockWaiter) (*memoryLock, bool) {
+	mdl.mu.Lock()
+	defer mdl.mu.Unlock()
+
+	queue := mdl.waiters[key]
+	if len(queue) == 0 || queue[0] != waiter {
+		return nil, false
+	}
+
+	if existingLock, exists := mdl.locks[key]; exists {
+		if !existingLock.IsExpired(mdl.clock.Now()) {
+			return nil, false
+		}
+		mdl.expireLocked(existingLock)
+	}
+
+	mdl.popWaiterLocked(key)
+	return mdl.newLockLocked(key, waiter.expiration), true
+}
+
+// abandonWaiter 放弃排队
+// 如果锁已经在放弃前移交给该等待者，则直接返回该锁，避免锁泄漏
+// 返回: 锁实例和错误信息
+func (mdl *MemoryDistributedLock) abandonWaiter(key string, waiter *lockWaiter, cause error) (domainLock.Lock, error) {
+	mdl.mu.Lock()
+	defer mdl.mu.Unlock()
+
+	queue := mdl.waiters[key]
+	for i, w := range queue {
+		if w == waiter {
+			mdl.waiters[key] = append(queue[:i:i], queue[i+1:]...)
+			if len(mdl.waiters[key]) == 0 {
+				delete(mdl.waiters, key)
+			}
+			mdl.stats = mdl.stats.IncrementFailedLocks()
+			return nil, cause
+		}
+	}
+
+	// 不在队列中说明锁已经移交
+	return <-waiter.granted, nil
+}
+
+// popWaiterLocked 弹出指定键的队首等待者
+// 注意: 此方法应在持有写锁的情况下调用
+func (mdl *MemoryDistributedLock) popWaiterLocked(key string) *lockWaiter {
+	queue := mdl.waiters[key]
+	if len(queue) == 0 {
+		return nil
+	}
+
+	waiter := queue[0]
+	if len(queue) == 1 {
+		delete(mdl.waiters, key)
+	} else {
+		mdl.waiters[key] = queue[1:]
+	}
+	return waiter
+}
+
+// grantNextLocked 将锁移交给等待最久的调用者
+// 注意: 此方法应在持有写锁且锁已从存储中移除的情况下调用
+func (mdl *MemoryDistributedLock) grantNextLocked(key string) {
+	waiter := mdl.popWaiterLocked(key)
+	if waiter == nil {
+		return
+	}
+	waiter.granted <- mdl.newLockLocked(key, waiter.expiration)
+}
+
 // SingleflightLock 使用singleflight优化的获取锁
 // 本地goroutine先竞争，胜利者再去抢全局锁
 // ctx: 上下文
@@ -147,17 +569,64 @@ func (mdl *MemoryDistributedLock) Lock(ctx context.Context, key string, expirati
 // timeout: 获取锁的超时时间
 // retryStrategy: 重试策略
 // 返回: 锁实例和错误信息
+// 每个调用方只等待到自己的ctx结束，ctx结束时立即返回ctx.Err()，不影响仍在获取锁的其他调用方；
+// 共享的获取锁操作不随任何一个调用方的ctx取消，只受timeout限制。
+// 所有调用方都放弃等待后获取到的锁没有人接收，会被立即释放，不会一直占用到过期
 func (mdl *MemoryDistributedLock) SingleflightLock(ctx context.Context, key string, expiration time.Duration, timeout time.Duration, retryStrategy domainLock.RetryStrategy) (domainLock.Lock, error) {
-	// 使用singleflight确保同一时间只有一个goroutine去获取锁
-	result, err, _ := mdl.g.Do(key, func() (interface{}, error) {
-		return mdl.Lock(ctx, key, expiration, timeout, retryStrategy)
-	})
+	// 同一时间每个键只有一个goroutine去获取锁，其他调用方等待并共享结果
+	mdl.callMu.Lock()
+	call, ok := mdl.calls[key]
+	if !ok {
+		call = &singleflightCall{done: make(chan struct{})}
+		mdl.calls[key] = call
+		go mdl.doSingleflightLock(context.WithoutCancel(ctx), call, key, expiration, timeout, retryStrategy)
+	}
+	call.waiters++
+	mdl.callMu.Unlock()
 
-	if err != nil {
-		return nil, err
+	select {
+	case <-call.done:
+		return call.lock, call.err
+	case <-ctx.Done():
+		mdl.leaveSingleflightCall(call)
+		return nil, ctx.Err()
+	}
+}
+
+// doSingleflightLock 执行共享的获取锁操作，结束时没有调用方在等待则释放获取到的锁
+func (mdl *MemoryDistributedLock) doSingleflightLock(ctx context.Context, call *singleflightCall, key string, expiration time.Duration, timeout time.Duration, retryStrategy domainLock.RetryStrategy) {
+	call.lock, call.err = mdl.Lock(ctx, key, expiration, timeout, retryStrategy)
+
+	mdl.callMu.Lock()
+	delete(mdl.calls, key)
+	call.finished = true
+	abandoned := call.waiters == 0
+	mdl.callMu.Unlock()
+	close(call.done)
+
+	if abandoned {
+		releaseAbandoned(call)
 	}
+}
 
-	return result.(domainLock.Lock), nil
+// leaveSingleflightCall 调用方放弃等待
+// 获取锁已结束且放弃的是最后一个调用方时，结果没有人接收，释放获取到的锁
+func (mdl *MemoryDistributedLock) leaveSingleflightCall(call *singleflightCall) {
+	mdl.callMu.Lock()
+	call.waiters--
+	abandoned := call.finished && call.waiters == 0
+	mdl.callMu.Unlock()
+
+	if abandoned {
+		releaseAbandoned(call)
+	}
+}
+
+// releaseAbandoned 释放没有调用方接收的锁
+func releaseAbandoned(call *singleflightCall) {
+	if call.err == nil {
+		_ = call.lock.Unlock(context.Background())
+	}
 }
 
 // GetStats 获取锁统计信息
@@ -174,22 +643,144 @@ func (mdl *MemoryDistributedLock) CleanExpiredLocks() int {
 	mdl.mu.Lock()
 	defer mdl.mu.Unlock()
 
-	now := time.Now()
-	expiredKeys := make([]string, 0)
+	now := mdl.clock.Now()
+	expiredLocks := make([]*memoryLock, 0)
 
-	for key, lock := range mdl.locks {
-		lockExpiration, _ := domainLock.NewLockExpiration(lock.expiration)
+	for _, lock := range mdl.locks {
+		lockExpiration, _ := domainLock.NewLockExpirationWithMax(lock.expiration, 0)
 		if lockExpiration.IsExpired(lock.createdAt, now) {
-			expiredKeys = append(expiredKeys, key)
+			expiredLocks = append(expiredLocks, lock)
+		}
+	}
+
+	for _, lock := range expiredLocks {
+		mdl.expireLocked(lock)
+		mdl.grantNextLocked(lock.key)
+	}
+
+	return len(expiredLocks)
+}
+
+// expireLocked 移除已过期的锁并触发过期回调
+// 注意: 此方法应在持有写锁的情况下调用
+func (mdl *MemoryDistributedLock) expireLocked(lock *memoryLock) {
+	delete(mdl.locks, lock.key)
+	mdl.stats = mdl.stats.IncrementExpiredLocks().DecrementActiveLocks()
+	if mdl.hooks.OnExpire != nil {
+		mdl.hooks.OnExpire(lock.key, lock.value)
+	}
+	mdl.notifyWatchersLocked(lock.key)
+}
+
+// Watch 监听锁的释放
+// 返回的通道在锁被释放或过期时关闭，锁当前未被持有时返回已关闭的通道，
+// 调用者可以据此阻塞等待锁空闲而无需轮询
+// 上下文取消后停止监听，通道不会被关闭
+// 使用系统时钟时，锁到期后由后台定时器清理并通知；注入了时钟时不使用真实的定时器，
+// 过期通知在按注入的时钟清理过期锁时发出（CleanExpiredLocks、后台清理或对该键的获取锁操作）
+// ctx: 控制监听生命周期的上下文
+// key: 锁的键
+// 返回: 通知通道和错误信息
+func (mdl *MemoryDistributedLock) Watch(ctx context.Context, key string) (<-chan struct{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	lockKey, err := domainLock.NewLockKey(key)
+	if err != nil {
+		return nil, err
+	}
+	key = lockKey.String()
+
+	ch := make(chan struct{})
+
+	mdl.mu.Lock()
+	lock, exists := mdl.locks[key]
+	if !exists || lock.IsExpired(mdl.clock.Now()) {
+		mdl.mu.Unlock()
+		close(ch)
+		return ch, nil
+	}
+	deadline := lock.createdAt.Add(lock.expiration)
+	mdl.watchers[key] = append(mdl.watchers[key], ch)
+	mdl.mu.Unlock()
+
+	go mdl.watchLoop(ctx, key, ch, deadline)
+
+	return ch, nil
+}
+
+// watchLoop 在锁到期时清理过期锁并通知监听者，直到通道被关闭或上下文被取消
+// 锁被续约后按新的到期时间继续等待
+// 真实的定时器只在使用系统时钟时启动，注入的时钟无法驱动它，此时只等待通道关闭或上下文取消
+func (mdl *MemoryDistributedLock) watchLoop(ctx context.Context, key string, ch chan struct{}, deadline time.Time) {
+	var timer *time.Timer
+	var expired <-chan time.Time
+	if _, ok := mdl.clock.(realClock); ok {
+		timer = time.NewTimer(time.Until(deadline))
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	for {
+		select {
+		case <-ch:
+			return
+		case <-ctx.Done():
+			mdl.removeWatcher(key, ch)
+			return
+		case <-expired:
+			next, held := mdl.expireIfDue(key)
+			if !held {
+				return
+			}
+			timer.Reset(time.Until(next))
 		}
 	}
+}
+
+// expireIfDue 锁已到期时清理并移交给等待者
+// 返回: 锁仍被持有时的到期时间和锁是否仍被持有
+func (mdl *MemoryDistributedLock) expireIfDue(key string) (time.Time, bool) {
+	mdl.mu.Lock()
+	defer mdl.mu.Unlock()
+
+	lock, exists := mdl.locks[key]
+	if !exists {
+		return time.Time{}, false
+	}
+	if lock.IsExpired(mdl.clock.Now()) {
+		mdl.expireLocked(lock)
+		mdl.grantNextLocked(key)
+		return time.Time{}, false
+	}
+	return lock.createdAt.Add(lock.expiration), true
+}
 
-	for _, key := range expiredKeys {
-		delete(mdl.locks, key)
-		mdl.stats = mdl.stats.IncrementExpiredLocks().DecrementActiveLocks()
+// removeWatcher 注销监听者，不关闭通道
+func (mdl *MemoryDistributedLock) removeWatcher(key string, ch chan struct{}) {
+	mdl.mu.Lock()
+	defer mdl.mu.Unlock()
+
+	watchers := mdl.watchers[key]
+	for i, w := range watchers {
+		if w == ch {
+			mdl.watchers[key] = append(watchers[:i:i], watchers[i+1:]...)
+			if len(mdl.watchers[key]) == 0 {
+				delete(mdl.watchers, key)
+			}
+			return
+		}
 	}
+}
 
-	return len(expiredKeys)
+// notifyWatchersLocked 关闭指定键上所有监听者的通道
+// 注意: 此方法应在持有写锁的情况下调用
+func (mdl *MemoryDistributedLock) notifyWatchersLocked(key string) {
+	for _, ch := range mdl.watchers[key] {
+		close(ch)
+	}
+	delete(mdl.watchers, key)
 }
 
 // memoryLock 实现 domainLock.Lock 接口
@@ -214,11 +805,16 @@ func (ml *memoryLock) CreatedAt() time.Time {
 	return ml.createdAt
 }
 
+// Token 获取锁的栅栏令牌
+func (ml *memoryLock) Token() uint64 {
+	return ml.token
+}
+
 // IsExpired 检查锁是否已过期
 // now: 当前时间
 // 返回: 是否已过期
 func (ml *memoryLock) IsExpired(now time.Time) bool {
-	lockExpiration, _ := domainLock.NewLockExpiration(ml.expiration)
+	lockExpiration, _ := domainLock.NewLockExpirationWithMax(ml.expiration, 0)
 	return lockExpiration.IsExpired(ml.createdAt, now)
 }
 
@@ -236,9 +832,12 @@ func (ml *memoryLock) Refresh(ctx context.Context) error {
 	}
 
 	// 更新创建时间以续约
-	existingLock.createdAt = time.Now()
+	existingLock.createdAt = ml.client.clock.Now()
 	ml.createdAt = existingLock.createdAt
 	ml.client.stats = ml.client.stats.IncrementRefreshCount()
+	if ml.client.hooks.OnRefresh != nil {
+		ml.client.hooks.OnRefresh(ml.key, ml.value)
+	}
 
 	return nil
 }
@@ -248,21 +847,39 @@ func (ml *memoryLock) Refresh(ctx context.Context) error {
 // timeout: 每次续约的超时时间
 // 返回: 操作错误
 func (ml *memoryLock) AutoRefresh(interval time.Duration, timeout time.Duration) error {
+	return ml.AutoRefreshContext(context.Background(), interval, timeout)
+}
+
+// AutoRefreshContext 自动续约锁，直到锁被释放或上下文被取消
+// 取消上下文只停止续约，不会释放锁
+// ctx: 控制续约生命周期的上下文
+// interval: 续约间隔
+// timeout: 每次续约的超时时间
+// 返回: 操作错误，上下文取消时返回ctx.Err()
+func (ml *memoryLock) AutoRefreshContext(ctx context.Context, interval time.Duration, timeout time.Duration) error {
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ticker.C:
-			ctx, cancel := context.WithTimeout(context.Background(), timeout)
-			err := ml.Refresh(ctx)
+			refreshCtx, cancel := context.WithTimeout(ctx, timeout)
+			err := ml.Refresh(refreshCtx)
 			cancel()
 
 			if err != nil {
+				// 续约与解锁同时发生时，锁是被主动释放的，正常结束
+				select {
+				case <-ml.unlockChan:
+					return nil
+				default:
+				}
 				return err
 			}
 		case <-ml.unlockChan:
 			return nil
+		case <-ctx.Done():
+			return ctx.Err()
 		}
 	}
 }
@@ -283,6 +900,13 @@ func (ml *memoryLock) Unlock(ctx context.Context) error {
 	// 删除锁
 	delete(ml.client.locks, ml.key)
 	ml.client.stats = ml.client.stats.IncrementUnlockCount().DecrementActiveLocks()
+	if ml.client.hooks.OnRelease != nil {
+		ml.client.hooks.OnRelease(ml.key, ml.value)
+	}
+	ml.client.notifyWatchersLocked(ml.key)
+
+	// 公平模式下将锁移交给等待最久的调用者
+	ml.client.grantNextLocked(ml.key)
 
 	// 通知自动续约停止
 	select {
@@ -308,7 +932,7 @@ func (ml *memoryLock) IsValid(ctx context.Context) (bool, error) {
 	}
 
 	// 检查锁是否已过期
-	if ml.IsExpired(time.Now()) {
+	if ml.IsExpired(ml.client.clock.Now()) {
 		return false, nil
 	}
 
@@ -417,3 +1041,98 @@ func (s *LinearBackoffRetryStrategy) Iterator() iter.Seq[time.Duration] {
 		}
 	}
 }
+
+// DecorrelatedJitterRetryStrategy 去相关抖动重试策略
+// 每次的重试间隔为 min(cap, random_between(base, prev*3))，
+// 间隔随机增长，避免大量客户端同时重试造成的惊群效应
+type DecorrelatedJitterRetryStrategy struct {
+	base     time.Duration
+	cap      time.Duration
+	maxRetry int
+	rand     *rand.Rand
+}
+
+// NewDecorrelatedJitterRetryStrategy 创建去相关抖动重试策略
+// base: 最小重试间隔
+// cap: 最大重试间隔
+// maxRetry: 最大重试次数
+// rng: 随机数生成器，为nil时使用全局随机源；注入固定种子的生成器可以得到确定的间隔序列
+// 返回: DecorrelatedJitterRetryStrategy实例
+func NewDecorrelatedJitterRetryStrategy(base time.Duration, cap time.Duration, maxRetry int, rng *rand.Rand) *DecorrelatedJitterRetryStrategy {
+	return &DecorrelatedJitterRetryStrategy{
+		base:     base,
+		cap:      cap,
+		maxRetry: maxRetry,
+		rand:     rng,
+	}
+}
+
+// Iterator 返回重试间隔的迭代器
+// 使用Go 1.23+的迭代器特性
+// 返回: 重试间隔的迭代器
+func (s *DecorrelatedJitterRetryStrategy) Iterator() iter.Seq[time.Duration] {
+	return func(yield func(time.Duration) bool) {
+		prev := s.base
+		for i := 0; i < s.maxRetry; i++ {
+			interval := min(s.cap, s.between(s.base, prev*3))
+			if !yield(interval) {
+				return
+			}
+			prev = interval
+		}
+	}
+}
+
+// between 返回 [low, high] 区间内的随机时长，high不大于low时返回low
+func (s *DecorrelatedJitterRetryStrategy) between(low, high time.Duration) time.Duration {
+	if high <= low {
+		return low
+	}
+	n := int64(high-low) + 1
+	if s.rand != nil {
+		return low + time.Duration(s.rand.Int64N(n))
+	}
+	return low + time.Duration(rand.Int64N(n))
+}
+
+// MaxElapsedRetryStrategy 按总耗时限制的重试策略
+// 包装另一个重试策略，当已耗时加上下一次间隔将超过预算时停止重试，
+// 适用于"最多重试5秒"这类不关心具体次数的场景
+type MaxElapsedRetryStrategy struct {
+	strategy   domainLock.RetryStrategy
+	maxElapsed time.Duration
+	now        func() time.Time
+}
+
+// NewMaxElapsedRetryStrategy 创建按总耗时限制的重试策略
+// strategy: 被包装的重试策略，决定每次的重试间隔
+// maxElapsed: 总耗时预算，从开始迭代时计算
+// now: 时钟函数，为nil时使用time.Now，可在测试中注入模拟时钟
+// 返回: MaxElapsedRetryStrategy实例
+func NewMaxElapsedRetryStrategy(strategy domainLock.RetryStrategy, maxElapsed time.Duration, now func() time.Time) *MaxElapsedRetryStrategy {
+	if now == nil {
+		now = time.Now
+	}
+	return &MaxElapsedRetryStrategy{
+		strategy:   strategy,
+		maxElapsed: maxElapsed,
+		now:        now,
+	}
+}
+
+// Iterator 返回重试间隔的迭代器
+// 使用Go 1.23+的迭代器特性
+// 返回: 重试间隔的迭代器
+func (s *MaxElapsedRetryStrategy) Iterator() iter.Seq[time.Duration] {
+	return func(yield func(time.Duration) bool) {
+		start := s.now()
+		for interval := range s.strategy.Iterator() {
+			if s.now().Sub(start)+interval > s.maxElapsed {
+				return
+			}
+			if !yield(interval) {
+				return
+			}
+		}
+	}
+}
@@ -2,6 +2,8 @@ package lock
 
 import (
 	"context"
+	"fmt"
+	"math/rand/v2"
 	"sync"
 	"testing"
 	"time"
@@ -186,6 +188,76 @@ func TestMemoryDistributedLock_LockExpiration(t *testing.T) {
 	assert.NotNil(t, newLock)
 }
 
+// fakeClock 可手动推进的时钟，用于在测试中模拟时间流逝
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance 将时钟向前推进d
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// TestMemoryDistributedLock_Clock 测试使用注入的时钟判断过期和续约，无需真实等待
+func TestMemoryDistributedLock_Clock(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("过期后可以重新获取", func(t *testing.T) {
+		clock := newFakeClock()
+		mdl := NewMemoryDistributedLock(MemoryDistributedLockWithClock(clock))
+
+		lock, err := mdl.TryLock(ctx, "clock_key", time.Second)
+		require.NoError(t, err)
+
+		_, err = mdl.TryLock(ctx, "clock_key", time.Minute)
+		assert.ErrorIs(t, err, domainLock.ErrFailedToPreemptLock)
+
+		clock.Advance(time.Second + time.Nanosecond)
+		valid, err := lock.IsValid(ctx)
+		require.NoError(t, err)
+		assert.False(t, valid)
+
+		newLock, err := mdl.TryLock(ctx, "clock_key", time.Minute)
+		require.NoError(t, err)
+		assert.NotEqual(t, lock.Value(), newLock.Value())
+	})
+
+	t.Run("续约按注入的时钟重置过期时间", func(t *testing.T) {
+		clock := newFakeClock()
+		mdl := NewMemoryDistributedLock(MemoryDistributedLockWithClock(clock))
+
+		lock, err := mdl.TryLock(ctx, "clock_key", time.Second)
+		require.NoError(t, err)
+
+		clock.Advance(800 * time.Millisecond)
+		require.NoError(t, lock.Refresh(ctx))
+		assert.Equal(t, clock.Now(), lock.CreatedAt())
+
+		// 超过最初的过期时间，但仍在续约后的有效期内
+		clock.Advance(800 * time.Millisecond)
+		assert.Equal(t, 0, mdl.CleanExpiredLocks())
+		valid, err := lock.IsValid(ctx)
+		require.NoError(t, err)
+		assert.True(t, valid)
+
+		clock.Advance(time.Second)
+		assert.Equal(t, 1, mdl.CleanExpiredLocks())
+	})
+}
+
 // TestMemoryDistributedLock_Refresh 测试锁续约
 func TestMemoryDistributedLock_Refresh(t *testing.T) {
 	mdl := NewMemoryDistributedLock()
@@ -334,6 +406,68 @@ func TestRetryStrategy(t *testing.T) {
 		assert.Equal(t, 200*time.Millisecond, intervals[1])
 		assert.Equal(t, 400*time.Millisecond, intervals[2])
 	})
+
+	t.Run("DecorrelatedJitterRetryStrategy", func(t *testing.T) {
+		base, cap := 10*time.Millisecond, 200*time.Millisecond
+		strategy := NewDecorrelatedJitterRetryStrategy(base, cap, 20, rand.New(rand.NewPCG(1, 2)))
+
+		intervals := make([]time.Duration, 0)
+		for interval := range strategy.Iterator() {
+			intervals = append(intervals, interval)
+		}
+
+		assert.Len(t, intervals, 20)
+		prev := base
+		distinct := make(map[time.Duration]bool)
+		for _, interval := range intervals {
+			assert.GreaterOrEqual(t, interval, base)
+			assert.LessOrEqual(t, interval, min(cap, prev*3))
+			distinct[interval] = true
+			prev = interval
+		}
+		assert.Greater(t, len(distinct), 1, "重试间隔应该随机变化")
+
+		// 相同种子得到相同的间隔序列
+		replay := NewDecorrelatedJitterRetryStrategy(base, cap, 20, rand.New(rand.NewPCG(1, 2)))
+		replayed := make([]time.Duration, 0)
+		for interval := range replay.Iterator() {
+			replayed = append(replayed, interval)
+		}
+		assert.Equal(t, intervals, replayed)
+	})
+
+	t.Run("MaxElapsedRetryStrategy", func(t *testing.T) {
+		// 模拟时钟，每次重试后前进一个间隔
+		now := time.Unix(0, 0)
+		clock := func() time.Time { return now }
+
+		inner := NewFixedIntervalRetryStrategy(time.Millisecond, 1_000_000)
+		strategy := NewMaxElapsedRetryStrategy(inner, 50*time.Millisecond, clock)
+
+		count := 0
+		for interval := range strategy.Iterator() {
+			now = now.Add(interval)
+			count++
+		}
+
+		// 在预算附近停止，而不是重试一百万次
+		assert.Equal(t, 50, count)
+		assert.Equal(t, 50*time.Millisecond, now.Sub(time.Unix(0, 0)))
+	})
+
+	t.Run("MaxElapsedRetryStrategy使用真实时钟", func(t *testing.T) {
+		inner := NewFixedIntervalRetryStrategy(time.Millisecond, 1_000_000)
+		strategy := NewMaxElapsedRetryStrategy(inner, 50*time.Millisecond, nil)
+
+		start := time.Now()
+		for interval := range strategy.Iterator() {
+			time.Sleep(interval)
+		}
+
+		elapsed := time.Since(start)
+		assert.GreaterOrEqual(t, elapsed, 40*time.Millisecond)
+		assert.Less(t, elapsed, 500*time.Millisecond)
+	})
 }
 
 // TestMemoryDistributedLock_SingleflightLock 测试singleflight优化
@@ -397,6 +531,79 @@ func TestMemoryDistributedLock_SingleflightLock(t *testing.T) {
 	}
 }
 
+// TestMemoryDistributedLock_SingleflightLock_WaiterTimeout 测试等待者按自己的ctx超时返回，不等待领头的调用方
+func TestMemoryDistributedLock_SingleflightLock_WaiterTimeout(t *testing.T) {
+	mdl := NewMemoryDistributedLock()
+	const lockKey = "singleflight_timeout_key"
+
+	// 锁被占用，领头的调用方需要不断重试
+	holder, err := mdl.TryLock(context.Background(), lockKey, time.Minute)
+	require.NoError(t, err)
+
+	retryStrategy := NewFixedIntervalRetryStrategy(10*time.Millisecond, 200)
+	leaderDone := make(chan error, 1)
+	go func() {
+		lock, err := mdl.SingleflightLock(context.Background(), lockKey, time.Second, 5*time.Second, retryStrategy)
+		if err == nil {
+			err = lock.Unlock(context.Background())
+		}
+		leaderDone <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = mdl.SingleflightLock(ctx, lockKey, time.Second, 5*time.Second, retryStrategy)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+
+	// 领头的调用方继续重试，锁释放后成功获取
+	select {
+	case err := <-leaderDone:
+		t.Fatalf("领头的调用方不应提前结束: %v", err)
+	default:
+	}
+	require.NoError(t, holder.Unlock(context.Background()))
+	select {
+	case err := <-leaderDone:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("领头的调用方没有获取到锁")
+	}
+}
+
+// TestMemoryDistributedLock_SingleflightLock_Abandoned 测试唯一的调用方超时后，之后获取到的锁会被释放
+func TestMemoryDistributedLock_SingleflightLock_Abandoned(t *testing.T) {
+	mdl := NewMemoryDistributedLock()
+	const lockKey = "singleflight_abandoned_key"
+
+	holder, err := mdl.TryLock(context.Background(), lockKey, time.Minute)
+	require.NoError(t, err)
+
+	retryStrategy := NewFixedIntervalRetryStrategy(10*time.Millisecond, 200)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = mdl.SingleflightLock(ctx, lockKey, time.Minute, 5*time.Second, retryStrategy)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// 调用方超时后获取仍在进行，持有者释放后获取成功，没有人接收的锁随即被释放
+	require.NoError(t, holder.Unlock(context.Background()))
+	assert.Eventually(t, func() bool {
+		stats := mdl.GetStats()
+		return stats.TotalLocks() == 2 && stats.UnlockCount() == 2
+	}, time.Second, 5*time.Millisecond)
+
+	// 锁没有被占用到过期，可以立即获取
+	lock, err := mdl.TryLock(context.Background(), lockKey, time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, lock.Unlock(context.Background()))
+
+	mdl.callMu.Lock()
+	defer mdl.callMu.Unlock()
+	assert.Empty(t, mdl.calls)
+}
+
 // TestMemoryDistributedLock_LockWithRetry 测试带重试的锁获取
 func TestMemoryDistributedLock_LockWithRetry(t *testing.T) {
 	mdl := NewMemoryDistributedLock()
@@ -422,3 +629,614 @@ func TestMemoryDistributedLock_LockWithRetry(t *testing.T) {
 	// 清理
 	_ = secondLock.Unlock(context.Background())
 }
+
+// TestMemoryDistributedLock_FairLock 测试公平模式下按到达顺序获取锁
+func TestMemoryDistributedLock_FairLock(t *testing.T) {
+	mdl := NewMemoryDistributedLock(MemoryDistributedLockWithFairness(true))
+
+	const numGoroutines = 5
+	const lockKey = "fair_test_key"
+
+	holder, err := mdl.TryLock(context.Background(), lockKey, time.Minute)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	order := make([]int, 0, numGoroutines)
+
+	retryStrategy := NewFixedIntervalRetryStrategy(50*time.Millisecond, 100)
+
+	for i := 0; i < numGoroutines; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+
+			lock, err := mdl.Lock(context.Background(), lockKey, time.Minute, 5*time.Second, retryStrategy)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			mu.Lock()
+			order = append(order, id)
+			mu.Unlock()
+
+			time.Sleep(5 * time.Millisecond)
+			assert.NoError(t, lock.Unlock(context.Background()))
+		}(i)
+
+		// 确保goroutine按顺序进入等待队列
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	// 有等待者排队时TryLock不能插队
+	_, err = mdl.TryLock(context.Background(), lockKey, time.Minute)
+	assert.ErrorIs(t, err, domainLock.ErrFailedToPreemptLock)
+
+	require.NoError(t, holder.Unlock(context.Background()))
+	wg.Wait()
+
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, order)
+
+	mdl.mu.RLock()
+	assert.Empty(t, mdl.waiters)
+	mdl.mu.RUnlock()
+}
+
+// TestMemoryDistributedLock_FairLockTimeout 测试公平模式下等待超时后退出队列
+func TestMemoryDistributedLock_FairLockTimeout(t *testing.T) {
+	mdl := NewMemoryDistributedLock(MemoryDistributedLockWithFairness(true))
+
+	_, err := mdl.TryLock(context.Background(), "fair_timeout_key", time.Minute)
+	require.NoError(t, err)
+
+	retryStrategy := NewFixedIntervalRetryStrategy(10*time.Millisecond, 100)
+	lock, err := mdl.Lock(context.Background(), "fair_timeout_key", time.Minute, 50*time.Millisecond, retryStrategy)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Nil(t, lock)
+
+	mdl.mu.RLock()
+	assert.Empty(t, mdl.waiters)
+	mdl.mu.RUnlock()
+}
+
+// TestMemoryDistributedLock_FencingToken 测试栅栏令牌严格递增
+func TestMemoryDistributedLock_FencingToken(t *testing.T) {
+	mdl := NewMemoryDistributedLock()
+
+	seen := make(map[uint64]bool)
+	var lastToken uint64
+
+	keys := []string{"token_key_a", "token_key_b", "token_key_a", "token_key_c", "token_key_b"}
+	for _, key := range keys {
+		lock, err := mdl.TryLock(context.Background(), key, time.Minute)
+		require.NoError(t, err)
+
+		token := lock.Token()
+		assert.Greater(t, token, lastToken, "令牌应该严格递增")
+		assert.False(t, seen[token], "令牌不应该重复")
+		seen[token] = true
+		lastToken = token
+
+		require.NoError(t, lock.Unlock(context.Background()))
+	}
+
+	// 过期后重新获取的锁也应得到更大的令牌
+	expiredLock, err := mdl.TryLock(context.Background(), "token_key_expired", 10*time.Millisecond)
+	require.NoError(t, err)
+	time.Sleep(20 * time.Millisecond)
+
+	newLock, err := mdl.TryLock(context.Background(), "token_key_expired", time.Minute)
+	require.NoError(t, err)
+	assert.Greater(t, newLock.Token(), expiredLock.Token())
+}
+
+// hookEvent 记录生命周期回调的调用
+type hookEvent struct {
+	name  string
+	key   string
+	value string
+}
+
+// TestMemoryDistributedLock_Hooks 测试锁生命周期回调
+func TestMemoryDistributedLock_Hooks(t *testing.T) {
+	var events []hookEvent
+	record := func(name string) func(key, value string) {
+		return func(key, value string) {
+			events = append(events, hookEvent{name: name, key: key, value: value})
+		}
+	}
+
+	mdl := NewMemoryDistributedLock(MemoryDistributedLockWithHooks(LockHooks{
+		OnAcquire: record("acquire"),
+		OnRelease: record("release"),
+		OnExpire:  record("expire"),
+		OnRefresh: record("refresh"),
+	}))
+
+	t.Run("获取、续约和释放", func(t *testing.T) {
+		events = nil
+		lock, err := mdl.TryLock(context.Background(), "hook_key", time.Minute)
+		require.NoError(t, err)
+		require.NoError(t, lock.Refresh(context.Background()))
+		require.NoError(t, lock.Unlock(context.Background()))
+
+		assert.Equal(t, []hookEvent{
+			{name: "acquire", key: "hook_key", value: lock.Value()},
+			{name: "refresh", key: "hook_key", value: lock.Value()},
+			{name: "release", key: "hook_key", value: lock.Value()},
+		}, events)
+	})
+
+	t.Run("TryLock惰性清理过期锁", func(t *testing.T) {
+		events = nil
+		expired, err := mdl.TryLock(context.Background(), "hook_lazy_key", 10*time.Millisecond)
+		require.NoError(t, err)
+		time.Sleep(20 * time.Millisecond)
+
+		lock, err := mdl.TryLock(context.Background(), "hook_lazy_key", time.Minute)
+		require.NoError(t, err)
+
+		assert.Equal(t, []hookEvent{
+			{name: "acquire", key: "hook_lazy_key", value: expired.Value()},
+			{name: "expire", key: "hook_lazy_key", value: expired.Value()},
+			{name: "acquire", key: "hook_lazy_key", value: lock.Value()},
+		}, events)
+	})
+
+	t.Run("CleanExpiredLocks清理过期锁", func(t *testing.T) {
+		events = nil
+		expired, err := mdl.TryLock(context.Background(), "hook_clean_key", 10*time.Millisecond)
+		require.NoError(t, err)
+		time.Sleep(20 * time.Millisecond)
+
+		// 只清理过期的锁，其余锁不受影响
+		events = nil
+		assert.Equal(t, 1, mdl.CleanExpiredLocks())
+		assert.Equal(t, []hookEvent{
+			{name: "expire", key: "hook_clean_key", value: expired.Value()},
+		}, events)
+	})
+
+	t.Run("未设置的回调被忽略", func(t *testing.T) {
+		partial := NewMemoryDistributedLock(MemoryDistributedLockWithHooks(LockHooks{}))
+		lock, err := partial.TryLock(context.Background(), "hook_nil_key", time.Minute)
+		require.NoError(t, err)
+		assert.NoError(t, lock.Refresh(context.Background()))
+		assert.NoError(t, lock.Unlock(context.Background()))
+	})
+}
+
+// TestMemoryDistributedLock_BackgroundCleanup 测试后台定期清理过期锁
+func TestMemoryDistributedLock_BackgroundCleanup(t *testing.T) {
+	mdl := NewMemoryDistributedLock(MemoryDistributedLockWithCleanupInterval(20 * time.Millisecond))
+
+	for i := 0; i < 100; i++ {
+		_, err := mdl.TryLock(context.Background(), fmt.Sprintf("cleanup_key_%d", i), 10*time.Millisecond)
+		require.NoError(t, err)
+	}
+	held, err := mdl.TryLock(context.Background(), "cleanup_held_key", time.Minute)
+	require.NoError(t, err)
+
+	mdl.mu.RLock()
+	assert.Len(t, mdl.locks, 101)
+	mdl.mu.RUnlock()
+
+	// 等待锁过期并被后台清理，未过期的锁保留
+	assert.Eventually(t, func() bool {
+		mdl.mu.RLock()
+		defer mdl.mu.RUnlock()
+		return len(mdl.locks) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	valid, err := held.IsValid(context.Background())
+	require.NoError(t, err)
+	assert.True(t, valid)
+	assert.Equal(t, int64(100), mdl.GetStats().ExpiredLocks())
+
+	// 重复关闭返回错误
+	assert.NoError(t, mdl.Close())
+	assert.ErrorIs(t, mdl.Close(), ErrDuplicateClose)
+}
+
+// TestMemoryDistributedLock_AutoRefreshContext 测试取消上下文停止自动续约但不释放锁
+func TestMemoryDistributedLock_AutoRefreshContext(t *testing.T) {
+	mdl := NewMemoryDistributedLock()
+
+	lock, err := mdl.TryLock(context.Background(), "auto_refresh_ctx_key", 200*time.Millisecond)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	refreshDone := make(chan error, 1)
+	go func() {
+		refreshDone <- lock.AutoRefreshContext(ctx, 20*time.Millisecond, 50*time.Millisecond)
+	}()
+
+	time.Sleep(70 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-refreshDone:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("取消上下文后自动续约没有及时结束")
+	}
+
+	// 停止续约不会释放锁
+	assert.Greater(t, mdl.GetStats().RefreshCount(), int64(0))
+	valid, err := lock.IsValid(context.Background())
+	require.NoError(t, err)
+	assert.True(t, valid)
+	assert.NoError(t, lock.Unlock(context.Background()))
+}
+
+// TestMemoryDistributedLock_TryLockContext 测试TryLock遵守上下文的取消和超时
+func TestMemoryDistributedLock_TryLockContext(t *testing.T) {
+	mdl := NewMemoryDistributedLock()
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	lock, err := mdl.TryLock(cancelled, "ctx_key", time.Minute)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, lock)
+
+	expired, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-expired.Done()
+	lock, err = mdl.TryLock(expired, "ctx_key", time.Minute)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Nil(t, lock)
+
+	// 上下文错误不会获取锁
+	assert.Equal(t, int64(0), mdl.GetStats().TotalLocks())
+	lock, err = mdl.TryLock(context.Background(), "ctx_key", time.Minute)
+	require.NoError(t, err)
+	assert.NotNil(t, lock)
+}
+
+// TestMemoryDistributedLock_ValueGenerator 测试自定义锁值生成函数
+func TestMemoryDistributedLock_ValueGenerator(t *testing.T) {
+	var seq int
+	mdl := NewMemoryDistributedLock(MemoryDistributedLockWithValueGenerator(func() string {
+		seq++
+		return fmt.Sprintf("request-%d", seq)
+	}))
+
+	lock, err := mdl.TryLock(context.Background(), "generator_key", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "request-1", lock.Value())
+
+	// 其他锁值无法释放锁，持有者按锁值释放
+	other, err := mdl.TryLock(context.Background(), "generator_other_key", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "request-2", other.Value())
+	require.NoError(t, other.Unlock(context.Background()))
+	assert.ErrorIs(t, other.Unlock(context.Background()), domainLock.ErrLockNotHold)
+
+	require.NoError(t, lock.Unlock(context.Background()))
+	relocked, err := mdl.TryLock(context.Background(), "generator_key", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "request-3", relocked.Value())
+
+	// nil生成函数保留默认的UUID
+	defaultLock, err := NewMemoryDistributedLock(MemoryDistributedLockWithValueGenerator(nil)).
+		TryLock(context.Background(), "generator_key", time.Minute)
+	require.NoError(t, err)
+	assert.Len(t, defaultLock.Value(), 36)
+}
+
+// TestMemoryDistributedLock_MaxExpiration 测试自定义最大过期时间
+func TestMemoryDistributedLock_MaxExpiration(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("调大上限接受长期租约", func(t *testing.T) {
+		clock := newFakeClock()
+		mdl := NewMemoryDistributedLock(
+			MemoryDistributedLockWithMaxExpiration(7*24*time.Hour),
+			MemoryDistributedLockWithClock(clock),
+		)
+
+		lock, err := mdl.TryLock(ctx, "lease_key", 48*time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, 48*time.Hour, lock.Expiration())
+
+		// 超过默认的24小时后锁仍然有效
+		clock.Advance(25 * time.Hour)
+		_, err = mdl.TryLock(ctx, "lease_key", time.Minute)
+		assert.ErrorIs(t, err, domainLock.ErrFailedToPreemptLock)
+		require.NoError(t, lock.Refresh(ctx))
+		require.NoError(t, lock.Unlock(ctx))
+
+		lock, err = mdl.Lock(ctx, "lease_key_2", 72*time.Hour, time.Second, NewFixedIntervalRetryStrategy(time.Millisecond, 3))
+		require.NoError(t, err)
+		assert.Equal(t, 72*time.Hour, lock.Expiration())
+	})
+
+	t.Run("调小上限拒绝超过上限的租约", func(t *testing.T) {
+		mdl := NewMemoryDistributedLock(MemoryDistributedLockWithMaxExpiration(time.Minute))
+
+		_, err := mdl.TryLock(ctx, "short_key", 2*time.Minute)
+		assert.ErrorIs(t, err, domainLock.ErrInvalidExpiration)
+		_, err = mdl.Lock(ctx, "short_key", 2*time.Minute, time.Second, NewFixedIntervalRetryStrategy(time.Millisecond, 3))
+		assert.ErrorIs(t, err, domainLock.ErrInvalidExpiration)
+
+		lock, err := mdl.TryLock(ctx, "short_key", time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, time.Minute, lock.Expiration())
+	})
+
+	t.Run("默认上限为24小时", func(t *testing.T) {
+		mdl := NewMemoryDistributedLock(MemoryDistributedLockWithMaxExpiration(0))
+		_, err := mdl.TryLock(ctx, "default_key", 25*time.Hour)
+		assert.ErrorIs(t, err, domainLock.ErrInvalidExpiration)
+	})
+}
+
+// TestMemoryDistributedLock_LockMulti 测试按全局顺序获取多个键的锁
+func TestMemoryDistributedLock_LockMulti(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("不同顺序获取重叠的键不会死锁", func(t *testing.T) {
+		mdl := NewMemoryDistributedLock()
+		keySets := [][]string{
+			{"multi_a", "multi_b", "multi_c"},
+			{"multi_c", "multi_b", "multi_a"},
+		}
+
+		var wg sync.WaitGroup
+		for _, keys := range keySets {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 50; i++ {
+					locks, err := mdl.LockMulti(ctx, keys, time.Minute, time.Second, NewFixedIntervalRetryStrategy(time.Millisecond, 1000))
+					if !assert.NoError(t, err) {
+						return
+					}
+					assert.Equal(t, []string{"multi_a", "multi_b", "multi_c"}, []string{locks[0].Key(), locks[1].Key(), locks[2].Key()})
+					for _, lock := range locks {
+						assert.NoError(t, lock.Unlock(ctx))
+					}
+				}
+			}()
+		}
+
+		done := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("LockMulti 发生死锁")
+		}
+	})
+
+	t.Run("获取失败时释放已获取的锁", func(t *testing.T) {
+		mdl := NewMemoryDistributedLock()
+		holder, err := mdl.TryLock(ctx, "rollback_c", time.Minute)
+		require.NoError(t, err)
+
+		_, err = mdl.LockMulti(ctx, []string{"rollback_c", "rollback_a", "rollback_b"}, time.Minute,
+			50*time.Millisecond, NewFixedIntervalRetryStrategy(10*time.Millisecond, 100))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "rollback_c")
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+		// 排在前面的键已被释放
+		for _, key := range []string{"rollback_a", "rollback_b"} {
+			lock, err := mdl.TryLock(ctx, key, time.Minute)
+			require.NoError(t, err, key)
+			require.NoError(t, lock.Unlock(ctx))
+		}
+
+		// 持有者释放后可以获取全部键
+		require.NoError(t, holder.Unlock(ctx))
+		locks, err := mdl.LockMulti(ctx, []string{"rollback_c", "rollback_a", "rollback_b"}, time.Minute,
+			time.Second, NewFixedIntervalRetryStrategy(10*time.Millisecond, 10))
+		require.NoError(t, err)
+		assert.Len(t, locks, 3)
+	})
+
+	t.Run("重复的键只获取一次", func(t *testing.T) {
+		mdl := NewMemoryDistributedLock()
+		locks, err := mdl.LockMulti(ctx, []string{"dup_key", "dup_key"}, time.Minute, time.Second, NewFixedIntervalRetryStrategy(time.Millisecond, 3))
+		require.NoError(t, err)
+		require.Len(t, locks, 1)
+		assert.Equal(t, "dup_key", locks[0].Key())
+	})
+
+	t.Run("无效的键", func(t *testing.T) {
+		mdl := NewMemoryDistributedLock()
+		_, err := mdl.LockMulti(ctx, nil, time.Minute, time.Second, NewFixedIntervalRetryStrategy(time.Millisecond, 3))
+		assert.ErrorIs(t, err, domainLock.ErrInvalidLockKey)
+
+		_, err = mdl.LockMulti(ctx, []string{"valid_key", ""}, time.Minute, time.Second, NewFixedIntervalRetryStrategy(time.Millisecond, 3))
+		assert.ErrorIs(t, err, domainLock.ErrInvalidLockKey)
+		lock, err := mdl.TryLock(ctx, "valid_key", time.Minute)
+		require.NoError(t, err)
+		assert.NotNil(t, lock)
+	})
+}
+
+// TestMemoryDistributedLock_TryLockMulti 测试不等待地获取多个键的锁
+func TestMemoryDistributedLock_TryLockMulti(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("全部获取成功", func(t *testing.T) {
+		mdl := NewMemoryDistributedLock()
+		locks, err := mdl.TryLockMulti(ctx, []string{"try_c", "try_a", "try_b", "try_a"}, time.Minute)
+		require.NoError(t, err)
+		require.Len(t, locks, 3)
+		for i, key := range []string{"try_a", "try_b", "try_c"} {
+			assert.Equal(t, key, locks[i].Key())
+		}
+		assert.Equal(t, int64(3), mdl.GetStats().ActiveLocks())
+	})
+
+	t.Run("部分键被占用时释放已获取的锁", func(t *testing.T) {
+		mdl := NewMemoryDistributedLock()
+		holder, err := mdl.TryLock(ctx, "try_b", time.Minute)
+		require.NoError(t, err)
+
+		locks, err := mdl.TryLockMulti(ctx, []string{"try_c", "try_b", "try_a"}, time.Minute)
+		assert.ErrorIs(t, err, domainLock.ErrFailedToPreemptLock)
+		assert.Nil(t, locks)
+
+		// 排在被占用键之前的try_a已释放，之后的try_c未被获取
+		for _, key := range []string{"try_a", "try_c"} {
+			lock, err := mdl.TryLock(ctx, key, time.Minute)
+			require.NoError(t, err, key)
+			require.NoError(t, lock.Unlock(ctx))
+		}
+		valid, err := holder.IsValid(ctx)
+		require.NoError(t, err)
+		assert.True(t, valid)
+	})
+
+	t.Run("错误包含被占用的键", func(t *testing.T) {
+		mdl := NewMemoryDistributedLock()
+		_, err := mdl.TryLock(ctx, "contended_key", time.Minute)
+		require.NoError(t, err)
+
+		_, err = mdl.TryLockMulti(ctx, []string{"free_key", "contended_key"}, time.Minute)
+		require.ErrorIs(t, err, domainLock.ErrFailedToPreemptLock)
+		assert.Contains(t, err.Error(), "contended_key")
+		assert.NotContains(t, err.Error(), "free_key")
+	})
+
+	t.Run("无效的参数", func(t *testing.T) {
+		mdl := NewMemoryDistributedLock()
+		_, err := mdl.TryLockMulti(ctx, nil, time.Minute)
+		assert.ErrorIs(t, err, domainLock.ErrInvalidLockKey)
+		_, err = mdl.TryLockMulti(ctx, []string{"try_a"}, 0)
+		assert.ErrorIs(t, err, domainLock.ErrInvalidExpiration)
+	})
+}
+
+// TestMemoryDistributedLock_Watch 测试监听锁的释放和过期
+func TestMemoryDistributedLock_Watch(t *testing.T) {
+	t.Run("锁释放时收到通知", func(t *testing.T) {
+		mdl := NewMemoryDistributedLock()
+		lock, err := mdl.TryLock(context.Background(), "watch_key", time.Minute)
+		require.NoError(t, err)
+
+		ch, err := mdl.Watch(context.Background(), "watch_key")
+		require.NoError(t, err)
+
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			_ = lock.Unlock(context.Background())
+		}()
+
+		select {
+		case <-ch:
+		case <-time.After(time.Second):
+			t.Fatal("锁释放后没有收到通知")
+		}
+
+		// 收到通知后可以获取锁
+		_, err = mdl.TryLock(context.Background(), "watch_key", time.Minute)
+		assert.NoError(t, err)
+	})
+
+	t.Run("锁过期时收到通知", func(t *testing.T) {
+		mdl := NewMemoryDistributedLock()
+		_, err := mdl.TryLock(context.Background(), "watch_expire_key", 50*time.Millisecond)
+		require.NoError(t, err)
+
+		ch, err := mdl.Watch(context.Background(), "watch_expire_key")
+		require.NoError(t, err)
+
+		select {
+		case <-ch:
+		case <-time.After(time.Second):
+			t.Fatal("锁过期后没有收到通知")
+		}
+		assert.Equal(t, int64(1), mdl.GetStats().ExpiredLocks())
+	})
+
+	t.Run("锁未被持有时立即通知", func(t *testing.T) {
+		mdl := NewMemoryDistributedLock()
+		ch, err := mdl.Watch(context.Background(), "watch_free_key")
+		require.NoError(t, err)
+
+		select {
+		case <-ch:
+		default:
+			t.Fatal("锁未被持有时通道应该已关闭")
+		}
+	})
+
+	t.Run("取消上下文停止监听", func(t *testing.T) {
+		mdl := NewMemoryDistributedLock()
+		lock, err := mdl.TryLock(context.Background(), "watch_cancel_key", time.Minute)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		ch, err := mdl.Watch(ctx, "watch_cancel_key")
+		require.NoError(t, err)
+		cancel()
+
+		assert.Eventually(t, func() bool {
+			mdl.mu.RLock()
+			defer mdl.mu.RUnlock()
+			return len(mdl.watchers) == 0
+		}, time.Second, 10*time.Millisecond)
+
+		// 注销后释放锁不会通知已取消的监听者
+		require.NoError(t, lock.Unlock(context.Background()))
+		select {
+		case <-ch:
+			t.Fatal("取消监听后不应该收到通知")
+		default:
+		}
+
+		// 已取消的上下文直接返回错误
+		_, err = mdl.Watch(ctx, "watch_cancel_key")
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+// TestMemoryDistributedLock_Watch_Clock 测试注入时钟时按该时钟清理过期锁会通知监听者
+func TestMemoryDistributedLock_Watch_Clock(t *testing.T) {
+	clock := newFakeClock()
+	mdl := NewMemoryDistributedLock(MemoryDistributedLockWithClock(clock))
+	_, err := mdl.TryLock(context.Background(), "watch_key", time.Minute)
+	require.NoError(t, err)
+
+	ch, err := mdl.Watch(context.Background(), "watch_key")
+	require.NoError(t, err)
+
+	// 时钟未到期时清理不会通知
+	clock.Advance(30 * time.Second)
+	assert.Equal(t, 0, mdl.CleanExpiredLocks())
+	select {
+	case <-ch:
+		t.Fatal("锁未过期时收到了通知")
+	default:
+	}
+
+	// 推进时钟后清理过期锁，立即收到通知
+	clock.Advance(31 * time.Second)
+	assert.Equal(t, 1, mdl.CleanExpiredLocks())
+	select {
+	case <-ch:
+	default:
+		t.Fatal("锁过期后没有收到通知")
+	}
+
+	// 对该键获取锁时发现锁已过期同样会通知
+	_, err = mdl.TryLock(context.Background(), "watch_key", time.Minute)
+	require.NoError(t, err)
+	ch, err = mdl.Watch(context.Background(), "watch_key")
+	require.NoError(t, err)
+	clock.Advance(2 * time.Minute)
+	_, err = mdl.TryLock(context.Background(), "watch_key", time.Minute)
+	require.NoError(t, err)
+	select {
+	case <-ch:
+	default:
+		t.Fatal("锁过期后没有收到通知")
+	}
+}
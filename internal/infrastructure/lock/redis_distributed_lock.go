@@ -0,0 +1,367 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/sync/singleflight"
+
+	domainLock "github.com/justinwongcn/hamster/internal/domain/lock"
+)
+
+const (
+	// acquireScript 仅当锁不存在时设置锁值和过期时间，成功后在同一个脚本内递增该锁的栅栏令牌计数器
+	// 获取锁和生成令牌是原子的，同一个锁后获取的持有者总是得到更大的令牌
+	// 返回: 获取成功时返回令牌，锁已被占用时返回0
+	acquireScript = `if redis.call("set", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+	return redis.call("incr", KEYS[2])
+else
+	return 0
+end`
+
+	// unlockScript 比较锁值后删除，保证只有持有者才能释放锁
+	unlockScript = `if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("del", KEYS[1])
+else
+	return 0
+end`
+
+	// refreshScript 比较锁值后重置过期时间，保证只有持有者才能续约
+	refreshScript = `if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("pexpire", KEYS[1], ARGV[2])
+else
+	return 0
+end`
+
+	// checkScript 检查锁是否仍由指定的值持有
+	checkScript = `if redis.call("get", KEYS[1]) == ARGV[1] then
+	return 1
+else
+	return 0
+end`
+
+	// fencingKeySuffix 栅栏令牌计数器键的后缀
+	fencingKeySuffix = ":fencing"
+)
+
+// ErrUnexpectedScriptResult Lua脚本返回了无法识别的结果
+var ErrUnexpectedScriptResult = errors.New("lua脚本返回了无法识别的结果")
+
+// RedisClient Redis客户端的最小抽象
+// 分布式锁的所有操作都通过Lua脚本完成，便于适配 go-redis 等客户端，也便于在测试中替换为模拟实现
+type RedisClient interface {
+	// Eval 执行Lua脚本
+	// 整数回复应以int64返回
+	Eval(ctx context.Context, script string, keys []string, args ...any) (any, error)
+}
+
+// RedisDistributedLock 基于Redis的分布式锁实现
+// 使用Lua脚本原子地执行 SET NX PX 并生成栅栏令牌，按锁值比较后释放和续约
+// 与 MemoryDistributedLock 相同，锁的所有权由UUID锁值保证
+// 每个锁有独立的栅栏令牌计数器，与锁键位于同一个哈希槽，可以在Redis Cluster上使用
+type RedisDistributedLock struct {
+	client RedisClient        // Redis客户端
+	g      singleflight.Group // singleflight优化
+}
+
+// redisLock Redis锁实例
+type redisLock struct {
+	key        string
+	value      string
+	expiration time.Duration
+	token      uint64
+	client     RedisClient
+	unlockChan chan struct{}
+
+	mu        sync.RWMutex // 保护createdAt，续约时会被更新
+	createdAt time.Time
+}
+
+// NewRedisDistributedLock 创建新的Redis分布式锁
+// client: Redis客户端
+// 返回: RedisDistributedLock实例
+func NewRedisDistributedLock(client RedisClient) *RedisDistributedLock {
+	return &RedisDistributedLock{
+		client: client,
+	}
+}
+
+// fencingKey 获取锁的栅栏令牌计数器键
+// Redis Cluster 只对键中第一个 {...} 内的部分计算哈希槽：锁键已有哈希标签时直接追加后缀，
+// 否则把整个锁键作为哈希标签，使计数器键与锁键落在同一个槽
+// 锁键含有 } 但没有有效的哈希标签时无法构造同槽的键，Redis Cluster 上应为这类锁键加上哈希标签
+func fencingKey(key string) string {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			return key + fencingKeySuffix
+		}
+	}
+	if strings.IndexByte(key, '}') >= 0 {
+		return key + fencingKeySuffix
+	}
+	return "{" + key + "}" + fencingKeySuffix
+}
+
+// TryLock 尝试获取锁（不重试）
+// ctx: 上下文
+// key: 锁的键
+// expiration: 锁的过期时间
+// 返回: 锁实例和错误信息
+func (rdl *RedisDistributedLock) TryLock(ctx context.Context, key string, expiration time.Duration) (domainLock.Lock, error) {
+	// 验证输入
+	lockKey, err := domainLock.NewLockKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	lockExpiration, err := domainLock.NewLockExpiration(expiration)
+	if err != nil {
+		return nil, err
+	}
+
+	value := uuid.New().String()
+	res, err := rdl.client.Eval(ctx, acquireScript, []string{lockKey.String(), fencingKey(lockKey.String())}, value, lockExpiration.Duration().Milliseconds())
+	if err != nil {
+		return nil, err
+	}
+
+	// 栅栏令牌与锁在同一个脚本内生成，Redis的INCR保证同一个锁的令牌跨进程严格递增
+	token, ok := res.(int64)
+	if !ok {
+		return nil, ErrUnexpectedScriptResult
+	}
+	if token == 0 {
+		return nil, domainLock.ErrFailedToPreemptLock
+	}
+
+	return &redisLock{
+		key:        lockKey.String(),
+		value:      value,
+		expiration: lockExpiration.Duration(),
+		token:      uint64(token),
+		client:     rdl.client,
+		unlockChan: make(chan struct{}, 1),
+		createdAt:  time.Now(),
+	}, nil
+}
+
+// Lock 获取锁（支持重试）
+// ctx: 上下文，用于控制超时和取消
+// key: 锁的键
+// expiration: 锁的过期时间
+// timeout: 获取锁的超时时间
+// retryStrategy: 重试策略
+// 返回: 锁实例和错误信息
+func (rdl *RedisDistributedLock) Lock(ctx context.Context, key string, expiration time.Duration, timeout time.Duration, retryStrategy domainLock.RetryStrategy) (domainLock.Lock, error) {
+	// 创建带超时的上下文
+	lockCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// 首次尝试
+	lock, err := rdl.TryLock(lockCtx, key, expiration)
+	if err == nil {
+		return lock, nil
+	}
+
+	// 如果不是抢锁失败，直接返回错误
+	if err != domainLock.ErrFailedToPreemptLock {
+		return nil, err
+	}
+
+	// 使用重试策略重试
+	for interval := range retryStrategy.Iterator() {
+		select {
+		case <-lockCtx.Done():
+			return nil, lockCtx.Err()
+		case <-time.After(interval):
+			lock, err := rdl.TryLock(lockCtx, key, expiration)
+			if err == nil {
+				return lock, nil
+			}
+			if err != domainLock.ErrFailedToPreemptLock {
+				return nil, err
+			}
+		}
+	}
+
+	return nil, domainLock.ErrFailedToPreemptLock
+}
+
+// SingleflightLock 使用singleflight优化的获取锁
+// 本进程内的goroutine先竞争，胜利者再去Redis抢全局锁
+// ctx: 上下文
+// key: 锁的键
+// expiration: 锁的过期时间
+// timeout: 获取锁的超时时间
+// retryStrategy: 重试策略
+// 返回: 锁实例和错误信息
+// 每个调用方只等待到自己的ctx结束，ctx结束时立即返回ctx.Err()，不影响仍在获取锁的其他调用方
+func (rdl *RedisDistributedLock) SingleflightLock(ctx context.Context, key string, expiration time.Duration, timeout time.Duration, retryStrategy domainLock.RetryStrategy) (domainLock.Lock, error) {
+	ch := rdl.g.DoChan(key, func() (interface{}, error) {
+		return rdl.Lock(ctx, key, expiration, timeout, retryStrategy)
+	})
+
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.(domainLock.Lock), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// redisLock 实现 domainLock.Lock 接口
+
+// Key 获取锁的键
+func (rl *redisLock) Key() string {
+	return rl.key
+}
+
+// Value 获取锁的值（UUID）
+func (rl *redisLock) Value() string {
+	return rl.value
+}
+
+// Expiration 获取锁的过期时间
+func (rl *redisLock) Expiration() time.Duration {
+	return rl.expiration
+}
+
+// CreatedAt 获取锁的创建时间
+// 续约成功后会更新为续约时间
+func (rl *redisLock) CreatedAt() time.Time {
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
+	return rl.createdAt
+}
+
+// Token 获取锁的栅栏令牌
+func (rl *redisLock) Token() uint64 {
+	return rl.token
+}
+
+// IsExpired 根据本地记录的时间检查锁是否已过期
+// now: 当前时间
+// 返回: 是否已过期
+func (rl *redisLock) IsExpired(now time.Time) bool {
+	lockExpiration, _ := domainLock.NewLockExpiration(rl.expiration)
+	return lockExpiration.IsExpired(rl.CreatedAt(), now)
+}
+
+// Refresh 手动续约锁
+// 只有锁值匹配时才会重置过期时间
+// ctx: 上下文
+// 返回: 操作错误
+func (rl *redisLock) Refresh(ctx context.Context) error {
+	res, err := rl.client.Eval(ctx, refreshScript, []string{rl.key}, rl.value, rl.expiration.Milliseconds())
+	if err != nil {
+		return err
+	}
+
+	ok, err := scriptSucceeded(res)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return domainLock.ErrLockNotHold
+	}
+
+	rl.mu.Lock()
+	rl.createdAt = time.Now()
+	rl.mu.Unlock()
+	return nil
+}
+
+// AutoRefresh 自动续约锁
+// interval: 续约间隔
+// timeout: 每次续约的超时时间
+// 返回: 操作错误
+func (rl *redisLock) AutoRefresh(interval time.Duration, timeout time.Duration) error {
+	return rl.AutoRefreshContext(context.Background(), interval, timeout)
+}
+
+// AutoRefreshContext 自动续约锁，直到锁被释放或上下文被取消
+// 取消上下文只停止续约，不会释放锁
+// ctx: 控制续约生命周期的上下文
+// interval: 续约间隔
+// timeout: 每次续约的超时时间
+// 返回: 操作错误，上下文取消时返回ctx.Err()
+func (rl *redisLock) AutoRefreshContext(ctx context.Context, interval time.Duration, timeout time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			refreshCtx, cancel := context.WithTimeout(ctx, timeout)
+			err := rl.Refresh(refreshCtx)
+			cancel()
+
+			if err != nil {
+				return err
+			}
+		case <-rl.unlockChan:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Unlock 释放锁
+// 只有锁值匹配时才会删除，避免误删其他持有者的锁
+// ctx: 上下文
+// 返回: 操作错误
+func (rl *redisLock) Unlock(ctx context.Context) error {
+	res, err := rl.client.Eval(ctx, unlockScript, []string{rl.key}, rl.value)
+	if err != nil {
+		return err
+	}
+
+	ok, err := scriptSucceeded(res)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return domainLock.ErrLockNotHold
+	}
+
+	// 通知自动续约停止
+	select {
+	case rl.unlockChan <- struct{}{}:
+	default:
+		// 没有人在等待，忽略
+	}
+
+	return nil
+}
+
+// IsValid 检查锁是否仍然由当前实例持有
+// ctx: 上下文
+// 返回: 是否有效和错误信息
+func (rl *redisLock) IsValid(ctx context.Context) (bool, error) {
+	res, err := rl.client.Eval(ctx, checkScript, []string{rl.key}, rl.value)
+	if err != nil {
+		return false, err
+	}
+	return scriptSucceeded(res)
+}
+
+// scriptSucceeded 解析Lua脚本的整数回复
+// 返回: 回复是否为正数和错误信息
+func scriptSucceeded(res any) (bool, error) {
+	switch v := res.(type) {
+	case int64:
+		return v > 0, nil
+	case int:
+		return v > 0, nil
+	default:
+		return false, ErrUnexpectedScriptResult
+	}
+}
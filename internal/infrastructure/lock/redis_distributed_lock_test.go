@@ -0,0 +1,313 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domainLock "github.com/justinwongcn/hamster/internal/domain/lock"
+)
+
+// mockRedisEntry 模拟Redis中的键值
+type mockRedisEntry struct {
+	value     string
+	expiresAt time.Time
+}
+
+// mockRedisClient 模拟Redis客户端
+// 按脚本内容模拟Lua脚本的获取锁、比较删除、比较续约和检查语义
+type mockRedisClient struct {
+	mu      sync.Mutex
+	data    map[string]mockRedisEntry
+	counter map[string]int64
+	err     error // 非nil时所有命令都返回该错误
+}
+
+func newMockRedisClient() *mockRedisClient {
+	return &mockRedisClient{
+		data:    make(map[string]mockRedisEntry),
+		counter: make(map[string]int64),
+	}
+}
+
+func (m *mockRedisClient) get(key string) (mockRedisEntry, bool) {
+	entry, ok := m.data[key]
+	if !ok {
+		return mockRedisEntry{}, false
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(m.data, key)
+		return mockRedisEntry{}, false
+	}
+	return entry, true
+}
+
+func (m *mockRedisClient) Eval(_ context.Context, script string, keys []string, args ...any) (any, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	if script == acquireScript {
+		if _, ok := m.get(keys[0]); ok {
+			return int64(0), nil
+		}
+		ms := args[1].(int64)
+		m.data[keys[0]] = mockRedisEntry{value: args[0].(string), expiresAt: time.Now().Add(time.Duration(ms) * time.Millisecond)}
+		m.counter[keys[1]]++
+		return m.counter[keys[1]], nil
+	}
+
+	entry, ok := m.get(keys[0])
+	if !ok || entry.value != args[0].(string) {
+		return int64(0), nil
+	}
+
+	switch script {
+	case unlockScript:
+		delete(m.data, keys[0])
+		return int64(1), nil
+	case refreshScript:
+		ms := args[1].(int64)
+		entry.expiresAt = time.Now().Add(time.Duration(ms) * time.Millisecond)
+		m.data[keys[0]] = entry
+		return int64(1), nil
+	case checkScript:
+		return int64(1), nil
+	default:
+		return nil, errors.New("未知脚本")
+	}
+}
+
+// TestRedisDistributedLock_TryLock 测试Redis锁的获取
+func TestRedisDistributedLock_TryLock(t *testing.T) {
+	tests := []struct {
+		name       string
+		setup      func(*mockRedisClient, *RedisDistributedLock)
+		key        string
+		expiration time.Duration
+		wantErr    error
+	}{
+		{
+			name:       "成功获取锁",
+			setup:      func(*mockRedisClient, *RedisDistributedLock) {},
+			key:        "redis_key",
+			expiration: time.Minute,
+		},
+		{
+			name: "锁已被占用",
+			setup: func(_ *mockRedisClient, rdl *RedisDistributedLock) {
+				_, _ = rdl.TryLock(context.Background(), "redis_key", time.Minute)
+			},
+			key:        "redis_key",
+			expiration: time.Minute,
+			wantErr:    domainLock.ErrFailedToPreemptLock,
+		},
+		{
+			name:       "无效的键",
+			setup:      func(*mockRedisClient, *RedisDistributedLock) {},
+			key:        "",
+			expiration: time.Minute,
+			wantErr:    domainLock.ErrInvalidLockKey,
+		},
+		{
+			name: "Redis错误",
+			setup: func(client *mockRedisClient, _ *RedisDistributedLock) {
+				client.err = errors.New("连接断开")
+			},
+			key:        "redis_key",
+			expiration: time.Minute,
+			wantErr:    errors.New("连接断开"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newMockRedisClient()
+			rdl := NewRedisDistributedLock(client)
+			tt.setup(client, rdl)
+
+			lock, err := rdl.TryLock(context.Background(), tt.key, tt.expiration)
+			if tt.wantErr != nil {
+				assert.Error(t, err)
+				if errors.Is(tt.wantErr, domainLock.ErrFailedToPreemptLock) || errors.Is(tt.wantErr, domainLock.ErrInvalidLockKey) {
+					assert.ErrorIs(t, err, tt.wantErr)
+				} else {
+					assert.EqualError(t, err, tt.wantErr.Error())
+				}
+				assert.Nil(t, lock)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.key, lock.Key())
+			assert.Equal(t, tt.expiration, lock.Expiration())
+			assert.NotEmpty(t, lock.Value())
+			assert.Equal(t, uint64(1), lock.Token())
+
+			valid, err := lock.IsValid(context.Background())
+			require.NoError(t, err)
+			assert.True(t, valid)
+		})
+	}
+}
+
+// TestRedisDistributedLock_ContendedLock 测试锁被占用时的重试获取
+func TestRedisDistributedLock_ContendedLock(t *testing.T) {
+	client := newMockRedisClient()
+	rdl := NewRedisDistributedLock(client)
+
+	first, err := rdl.TryLock(context.Background(), "contended_key", 100*time.Millisecond)
+	require.NoError(t, err)
+
+	// 重试次数不足时获取失败
+	_, err = rdl.Lock(context.Background(), "contended_key", time.Minute, time.Second, NewFixedIntervalRetryStrategy(10*time.Millisecond, 2))
+	assert.ErrorIs(t, err, domainLock.ErrFailedToPreemptLock)
+
+	// 等待第一个锁过期后获取成功，并得到更大的令牌
+	second, err := rdl.Lock(context.Background(), "contended_key", time.Minute, time.Second, NewFixedIntervalRetryStrategy(50*time.Millisecond, 5))
+	require.NoError(t, err)
+	assert.NotEqual(t, first.Value(), second.Value())
+	assert.Greater(t, second.Token(), first.Token())
+}
+
+// TestRedisDistributedLock_FencingToken 测试栅栏令牌与锁在同一次脚本调用中生成
+func TestRedisDistributedLock_FencingToken(t *testing.T) {
+	client := newMockRedisClient()
+	rdl := NewRedisDistributedLock(client)
+
+	stale, err := rdl.TryLock(context.Background(), "fencing_key", 50*time.Millisecond)
+	require.NoError(t, err)
+
+	// 抢锁失败不消耗令牌
+	_, err = rdl.TryLock(context.Background(), "fencing_key", time.Minute)
+	assert.ErrorIs(t, err, domainLock.ErrFailedToPreemptLock)
+	assert.Equal(t, int64(1), client.counter["{fencing_key}:fencing"])
+
+	// 旧锁过期后新持有者得到更大的令牌
+	time.Sleep(80 * time.Millisecond)
+	current, err := rdl.TryLock(context.Background(), "fencing_key", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), current.Token())
+	assert.Greater(t, current.Token(), stale.Token())
+
+	// 不同的锁使用各自的计数器
+	other, err := rdl.TryLock(context.Background(), "other_key", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), other.Token())
+
+	// 无法识别的脚本回复不会当作获取成功
+	_, err = NewRedisDistributedLock(replyRedisClient{reply: "OK"}).TryLock(context.Background(), "fencing_key", time.Minute)
+	assert.ErrorIs(t, err, ErrUnexpectedScriptResult)
+}
+
+// TestFencingKey 测试栅栏令牌计数器键与锁键位于同一个哈希槽
+func TestFencingKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{key: "order:1", want: "{order:1}:fencing"},
+		{key: "{user:1}:order", want: "{user:1}:order:fencing"},
+		{key: "a{b", want: "{a{b}:fencing"},
+		{key: "a{}b", want: "a{}b:fencing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			assert.Equal(t, tt.want, fencingKey(tt.key))
+		})
+	}
+}
+
+// replyRedisClient 对所有脚本返回固定回复的Redis客户端
+type replyRedisClient struct {
+	reply any
+}
+
+func (c replyRedisClient) Eval(context.Context, string, []string, ...any) (any, error) {
+	return c.reply, nil
+}
+
+// TestRedisDistributedLock_Refresh 测试Redis锁续约
+func TestRedisDistributedLock_Refresh(t *testing.T) {
+	client := newMockRedisClient()
+	rdl := NewRedisDistributedLock(client)
+
+	lock, err := rdl.TryLock(context.Background(), "refresh_key", 100*time.Millisecond)
+	require.NoError(t, err)
+
+	// 在过期前续约，锁应继续有效
+	time.Sleep(60 * time.Millisecond)
+	require.NoError(t, lock.Refresh(context.Background()))
+	time.Sleep(60 * time.Millisecond)
+
+	valid, err := lock.IsValid(context.Background())
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	// 释放后续约失败
+	require.NoError(t, lock.Unlock(context.Background()))
+	assert.ErrorIs(t, lock.Refresh(context.Background()), domainLock.ErrLockNotHold)
+}
+
+// TestRedisDistributedLock_SafeUnlock 测试只有锁值匹配时才能释放锁
+func TestRedisDistributedLock_SafeUnlock(t *testing.T) {
+	client := newMockRedisClient()
+	rdl := NewRedisDistributedLock(client)
+
+	stale, err := rdl.TryLock(context.Background(), "unlock_key", 50*time.Millisecond)
+	require.NoError(t, err)
+
+	// 旧锁过期后被其他持有者获取
+	time.Sleep(80 * time.Millisecond)
+	current, err := rdl.TryLock(context.Background(), "unlock_key", time.Minute)
+	require.NoError(t, err)
+
+	// 旧持有者不能释放新持有者的锁
+	assert.ErrorIs(t, stale.Unlock(context.Background()), domainLock.ErrLockNotHold)
+
+	valid, err := current.IsValid(context.Background())
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	// 新持有者可以正常释放
+	require.NoError(t, current.Unlock(context.Background()))
+	assert.ErrorIs(t, current.Unlock(context.Background()), domainLock.ErrLockNotHold)
+
+	_, err = rdl.TryLock(context.Background(), "unlock_key", time.Minute)
+	assert.NoError(t, err)
+}
+
+// TestRedisDistributedLock_AutoRefresh 测试Redis锁自动续约在解锁后停止
+func TestRedisDistributedLock_AutoRefresh(t *testing.T) {
+	client := newMockRedisClient()
+	rdl := NewRedisDistributedLock(client)
+
+	lock, err := rdl.TryLock(context.Background(), "auto_refresh_key", 100*time.Millisecond)
+	require.NoError(t, err)
+
+	refreshDone := make(chan error, 1)
+	go func() {
+		refreshDone <- lock.AutoRefresh(30*time.Millisecond, 50*time.Millisecond)
+	}()
+
+	time.Sleep(200 * time.Millisecond)
+	valid, err := lock.IsValid(context.Background())
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	require.NoError(t, lock.Unlock(context.Background()))
+
+	select {
+	case err := <-refreshDone:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("自动续约没有及时结束")
+	}
+}
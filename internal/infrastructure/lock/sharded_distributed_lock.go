@@ -0,0 +1,134 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	domainHash "github.com/justinwongcn/hamster/internal/domain/consistent_hash"
+	domainLock "github.com/justinwongcn/hamster/internal/domain/lock"
+)
+
+var _ domainLock.DistributedLock = &ShardedDistributedLock{}
+
+// ShardedDistributedLock 按一致性哈希分片的分布式锁
+// 每个锁键通过节点选择器路由到拥有该键的节点，由该节点的锁后端负责获取和释放，
+// 锁服务可以水平扩展到多个节点，同一个键的锁总是落在同一个节点上
+// 线程安全，支持并发访问
+type ShardedDistributedLock struct {
+	picker     domainHash.PeerPicker
+	newBackend func(peer domainHash.Peer) domainLock.DistributedLock
+	backends   map[string]domainLock.DistributedLock // 节点ID到锁后端的映射
+	mu         sync.Mutex
+}
+
+// NewShardedDistributedLock 创建按一致性哈希分片的分布式锁
+// 锁后端在节点第一次被选中时通过newBackend创建，之后同一个节点ID始终使用同一个后端
+// picker: 节点选择器，决定每个键归属的节点
+// newBackend: 为节点创建锁后端的函数
+// 返回: ShardedDistributedLock实例
+func NewShardedDistributedLock(picker domainHash.PeerPicker, newBackend func(peer domainHash.Peer) domainLock.DistributedLock) *ShardedDistributedLock {
+	return &ShardedDistributedLock{
+		picker:     picker,
+		newBackend: newBackend,
+		backends:   make(map[string]domainLock.DistributedLock),
+	}
+}
+
+// NewMemoryShardedDistributedLock 创建以内存锁为后端的分片分布式锁
+// 每个节点使用各自独立的 MemoryDistributedLock，用于单进程内模拟多节点的锁服务
+// picker: 节点选择器
+// opts: 创建每个节点的 MemoryDistributedLock 时使用的选项
+// 返回: ShardedDistributedLock实例
+func NewMemoryShardedDistributedLock(picker domainHash.PeerPicker, opts ...MemoryDistributedLockOption) *ShardedDistributedLock {
+	return NewShardedDistributedLock(picker, func(domainHash.Peer) domainLock.DistributedLock {
+		return NewMemoryDistributedLock(opts...)
+	})
+}
+
+// Route 获取键归属的节点和该节点的锁后端
+// key: 锁的键
+// 返回: 节点、锁后端和错误信息，没有可用节点时返回节点选择器的错误
+func (s *ShardedDistributedLock) Route(key string) (domainHash.Peer, domainLock.DistributedLock, error) {
+	peer, err := s.picker.PickPeer(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("选择锁 %s 的节点失败: %w", key, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	backend, ok := s.backends[peer.ID()]
+	if !ok {
+		backend = s.newBackend(peer)
+		s.backends[peer.ID()] = backend
+	}
+	return peer, backend, nil
+}
+
+// Backend 获取节点的锁后端
+// peerID: 节点ID
+// 返回: 锁后端和是否存在，节点还没有被选中过时不存在
+func (s *ShardedDistributedLock) Backend(peerID string) (domainLock.DistributedLock, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	backend, ok := s.backends[peerID]
+	return backend, ok
+}
+
+// Lock 在键归属的节点上获取锁
+// 返回的锁由该节点的后端创建，Refresh 和 Unlock 也在该节点上执行
+func (s *ShardedDistributedLock) Lock(ctx context.Context, key string, expiration time.Duration, timeout time.Duration, retryStrategy domainLock.RetryStrategy) (domainLock.Lock, error) {
+	if _, err := domainLock.NewLockKey(key); err != nil {
+		return nil, err
+	}
+	_, backend, err := s.Route(key)
+	if err != nil {
+		return nil, err
+	}
+	return backend.Lock(ctx, key, expiration, timeout, retryStrategy)
+}
+
+// TryLock 在键归属的节点上尝试获取锁（不重试）
+// 返回的锁由该节点的后端创建，Refresh 和 Unlock 也在该节点上执行
+func (s *ShardedDistributedLock) TryLock(ctx context.Context, key string, expiration time.Duration) (domainLock.Lock, error) {
+	if _, err := domainLock.NewLockKey(key); err != nil {
+		return nil, err
+	}
+	_, backend, err := s.Route(key)
+	if err != nil {
+		return nil, err
+	}
+	return backend.TryLock(ctx, key, expiration)
+}
+
+// SingleflightLock 在键归属的节点上使用singleflight优化获取锁
+// 本地goroutine的竞争由该节点的后端合并
+func (s *ShardedDistributedLock) SingleflightLock(ctx context.Context, key string, expiration time.Duration, timeout time.Duration, retryStrategy domainLock.RetryStrategy) (domainLock.Lock, error) {
+	if _, err := domainLock.NewLockKey(key); err != nil {
+		return nil, err
+	}
+	_, backend, err := s.Route(key)
+	if err != nil {
+		return nil, err
+	}
+	return backend.SingleflightLock(ctx, key, expiration, timeout, retryStrategy)
+}
+
+// Close 关闭所有实现了 Close() error 的锁后端
+// 返回: 各个后端关闭时的错误
+func (s *ShardedDistributedLock) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var errs []error
+	for _, backend := range s.backends {
+		if c, ok := backend.(interface{ Close() error }); ok {
+			if err := c.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
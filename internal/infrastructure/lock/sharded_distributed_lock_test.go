@@ -0,0 +1,126 @@
+package lock
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domainHash "github.com/justinwongcn/hamster/internal/domain/consistent_hash"
+	domainLock "github.com/justinwongcn/hamster/internal/domain/lock"
+	"github.com/justinwongcn/hamster/internal/infrastructure/consistent_hash"
+)
+
+// newTestPicker 创建包含指定节点的节点选择器
+func newTestPicker(t *testing.T, ids ...string) (*consistent_hash.SingleflightPeerPicker, map[string]domainHash.Peer) {
+	picker := consistent_hash.NewSingleflightPeerPicker(consistent_hash.NewConsistentHashMap(50, nil))
+	peers := make(map[string]domainHash.Peer, len(ids))
+	for _, id := range ids {
+		peer, err := domainHash.NewPeerInfo(id, id+":8080", 1)
+		require.NoError(t, err)
+		peers[id] = peer
+		picker.AddPeers(peer)
+	}
+	return picker, peers
+}
+
+// activeLocks 获取节点锁后端中的活跃锁数量，节点没有后端时为0
+func activeLocks(s *ShardedDistributedLock, peerID string) int64 {
+	backend, ok := s.Backend(peerID)
+	if !ok {
+		return 0
+	}
+	return backend.(*MemoryDistributedLock).GetStats().ActiveLocks()
+}
+
+// TestShardedDistributedLock_Routing 测试同一个键的锁总是落在同一个节点的后端上
+func TestShardedDistributedLock_Routing(t *testing.T) {
+	ctx := context.Background()
+	picker, _ := newTestPicker(t, "node1", "node2", "node3")
+	s := NewMemoryShardedDistributedLock(picker)
+	defer s.Close()
+
+	owners := make(map[string]string)
+	for i := 0; i < 30; i++ {
+		key := fmt.Sprintf("order:%d", i)
+		peer, backend, err := s.Route(key)
+		require.NoError(t, err)
+		owners[key] = peer.ID()
+
+		// 多次路由得到同一个节点和同一个后端
+		peer2, backend2, err := s.Route(key)
+		require.NoError(t, err)
+		assert.Equal(t, peer.ID(), peer2.ID())
+		assert.Same(t, backend, backend2)
+
+		before := activeLocks(s, peer.ID())
+		lock, err := s.TryLock(ctx, key, time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, before+1, activeLocks(s, peer.ID()))
+
+		// 同一个键在持有期间无法再次获取
+		_, err = s.TryLock(ctx, key, time.Minute)
+		assert.ErrorIs(t, err, domainLock.ErrFailedToPreemptLock)
+
+		require.NoError(t, lock.Unlock(ctx))
+		assert.Equal(t, before, activeLocks(s, peer.ID()))
+	}
+
+	// 键分布到多个节点上
+	used := make(map[string]bool)
+	for _, id := range owners {
+		used[id] = true
+	}
+	assert.Greater(t, len(used), 1)
+
+	// Lock 同样路由到归属节点
+	key := "order:0"
+	lock, err := s.Lock(ctx, key, time.Minute, time.Second, NewFixedIntervalRetryStrategy(10*time.Millisecond, 3))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), activeLocks(s, owners[key]))
+	require.NoError(t, lock.Unlock(ctx))
+
+	_, err = s.TryLock(ctx, "", time.Minute)
+	assert.ErrorIs(t, err, domainLock.ErrInvalidLockKey)
+}
+
+// TestShardedDistributedLock_RemovePeer 测试移除节点后之后的锁路由到其他节点
+func TestShardedDistributedLock_RemovePeer(t *testing.T) {
+	ctx := context.Background()
+	picker, peers := newTestPicker(t, "node1", "node2", "node3")
+	s := NewMemoryShardedDistributedLock(picker)
+	defer s.Close()
+
+	key := "user:42"
+	owner, _, err := s.Route(key)
+	require.NoError(t, err)
+	held, err := s.TryLock(ctx, key, time.Minute)
+	require.NoError(t, err)
+
+	picker.RemovePeers(peers[owner.ID()])
+
+	newOwner, _, err := s.Route(key)
+	require.NoError(t, err)
+	assert.NotEqual(t, owner.ID(), newOwner.ID())
+
+	// 新的锁落在新的归属节点上
+	lock, err := s.TryLock(ctx, key, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), activeLocks(s, newOwner.ID()))
+	require.NoError(t, lock.Unlock(ctx))
+
+	// 移除前获取的锁仍然在原节点上释放
+	assert.Equal(t, int64(1), activeLocks(s, owner.ID()))
+	require.NoError(t, held.Unlock(ctx))
+	assert.Equal(t, int64(0), activeLocks(s, owner.ID()))
+
+	// 没有可用节点时返回错误
+	for _, peer := range peers {
+		picker.RemovePeers(peer)
+	}
+	_, err = s.TryLock(ctx, key, time.Minute)
+	assert.ErrorIs(t, err, domainHash.ErrNoPeers)
+}
@@ -12,6 +12,8 @@ import (
 // Service 分布式锁服务公共接口
 type Service struct {
 	appService *appLock.DistributedLockApplicationService
+	locker     *infraLock.MemoryDistributedLock
+	config     *Config
 }
 
 // Config 分布式锁配置
@@ -31,11 +33,23 @@ type Config struct {
 	// DefaultRetryBase 默认重试基础间隔
 	DefaultRetryBase time.Duration
 
+	// RetryMaxElapsed 重试的总耗时预算，大于0时与重试策略组合，超过预算即停止重试
+	RetryMaxElapsed time.Duration
+
 	// EnableAutoRefresh 是否启用自动续约
 	EnableAutoRefresh bool
 
 	// AutoRefreshInterval 自动续约间隔
 	AutoRefreshInterval time.Duration
+
+	// ValueGenerator 锁值生成函数，为nil时使用UUID
+	ValueGenerator func() string
+
+	// CleanupInterval 后台清理过期锁的间隔，为0时不启动后台清理
+	CleanupInterval time.Duration
+
+	// MaxExpiration 允许的最大锁过期时间，为0时使用默认的24小时
+	MaxExpiration time.Duration
 }
 
 // RetryType 重试类型
@@ -45,6 +59,8 @@ const (
 	RetryTypeFixed       RetryType = "fixed"
 	RetryTypeExponential RetryType = "exponential"
 	RetryTypeLinear      RetryType = "linear"
+	// RetryTypeDecorrelated 去相关抖动重试，间隔在基础间隔和上次间隔的3倍之间随机取值
+	RetryTypeDecorrelated RetryType = "decorrelated"
 )
 
 // DefaultConfig 返回默认配置
@@ -86,6 +102,14 @@ func WithDefaultRetry(retryType RetryType, count int, base time.Duration) Option
 	}
 }
 
+// WithRetryMaxElapsed 设置重试的总耗时预算
+// 与任意重试类型组合使用，例如"最多重试5秒"，为0时只按重试次数限制
+func WithRetryMaxElapsed(maxElapsed time.Duration) Option {
+	return func(c *Config) {
+		c.RetryMaxElapsed = maxElapsed
+	}
+}
+
 // WithAutoRefresh 设置自动续约
 func WithAutoRefresh(enable bool, interval time.Duration) Option {
 	return func(c *Config) {
@@ -94,6 +118,32 @@ func WithAutoRefresh(enable bool, interval time.Duration) Option {
 	}
 }
 
+// WithValueGenerator 设置锁值生成函数
+// 可以在锁值中嵌入请求ID等信息，便于跨系统追踪；生成的值必须唯一，例如在前缀后拼接UUID
+func WithValueGenerator(fn func() string) Option {
+	return func(c *Config) {
+		c.ValueGenerator = fn
+	}
+}
+
+// WithCleanupInterval 设置后台清理过期锁的间隔
+// 设置后会定期移除已过期但未释放的锁，避免只使用一次的锁键长期占用内存，使用完毕后应调用 Close
+// interval: 清理间隔，为0时不启动后台清理
+func WithCleanupInterval(interval time.Duration) Option {
+	return func(c *Config) {
+		c.CleanupInterval = interval
+	}
+}
+
+// WithMaxExpiration 设置允许的最大锁过期时间
+// 获取锁时过期时间超过该值会返回错误，需要长期租约时可以调大
+// maxExpiration: 最大过期时间，为0时使用默认的24小时
+func WithMaxExpiration(maxExpiration time.Duration) Option {
+	return func(c *Config) {
+		c.MaxExpiration = maxExpiration
+	}
+}
+
 // NewService 创建分布式锁服务
 func NewService(options ...Option) (*Service, error) {
 	config := DefaultConfig()
@@ -111,16 +161,34 @@ func NewServiceWithConfig(config *Config) (*Service, error) {
 	}
 
 	// 创建基础设施层
-	distributedLock := infraLock.NewMemoryDistributedLock()
+	var lockOpts []infraLock.MemoryDistributedLockOption
+	if config.ValueGenerator != nil {
+		lockOpts = append(lockOpts, infraLock.MemoryDistributedLockWithValueGenerator(config.ValueGenerator))
+	}
+	if config.CleanupInterval > 0 {
+		lockOpts = append(lockOpts, infraLock.MemoryDistributedLockWithCleanupInterval(config.CleanupInterval))
+	}
+	if config.MaxExpiration > 0 {
+		lockOpts = append(lockOpts, infraLock.MemoryDistributedLockWithMaxExpiration(config.MaxExpiration))
+	}
+	distributedLock := infraLock.NewMemoryDistributedLock(lockOpts...)
 
 	// 创建应用服务
 	appService := appLock.NewDistributedLockApplicationService(distributedLock)
 
 	return &Service{
 		appService: appService,
+		locker:     distributedLock,
+		config:     config,
 	}, nil
 }
 
+// Close 关闭分布式锁服务，停止后台清理过期锁
+// 返回: 错误信息，重复关闭时返回错误
+func (s *Service) Close() error {
+	return s.locker.Close()
+}
+
 // Lock 锁信息
 type Lock struct {
 	Key       string    `json:"key"`
@@ -128,6 +196,7 @@ type Lock struct {
 	CreatedAt time.Time `json:"created_at"`
 	ExpiresAt time.Time `json:"expires_at"`
 	IsValid   bool      `json:"is_valid"`
+	Token     uint64    `json:"token"`
 }
 
 // LockOptions 加锁选项
@@ -137,6 +206,22 @@ type LockOptions struct {
 	RetryType  RetryType
 	RetryCount int
 	RetryBase  time.Duration
+	// RetryCap 最大重试间隔，仅用于 RetryTypeDecorrelated，为0时取RetryBase的10倍
+	RetryCap time.Duration
+	// RetryMaxElapsed 重试的总耗时预算，为0时只按重试次数限制
+	RetryMaxElapsed time.Duration
+}
+
+// defaultLockOptions 根据服务配置生成默认加锁选项
+func (s *Service) defaultLockOptions() LockOptions {
+	return LockOptions{
+		Expiration:      s.config.DefaultExpiration,
+		Timeout:         s.config.DefaultTimeout,
+		RetryType:       s.config.DefaultRetryType,
+		RetryCount:      s.config.DefaultRetryCount,
+		RetryBase:       s.config.DefaultRetryBase,
+		RetryMaxElapsed: s.config.RetryMaxElapsed,
+	}
 }
 
 // TryLock 尝试获取锁（不重试）
@@ -145,24 +230,19 @@ func (s *Service) TryLock(ctx context.Context, key string, options ...LockOption
 	if len(options) > 0 {
 		opts = options[0]
 	} else {
-		// 使用默认配置
-		config := DefaultConfig()
-		opts = LockOptions{
-			Expiration: config.DefaultExpiration,
-			Timeout:    config.DefaultTimeout,
-			RetryType:  config.DefaultRetryType,
-			RetryCount: config.DefaultRetryCount,
-			RetryBase:  config.DefaultRetryBase,
-		}
+		// 使用服务配置
+		opts = s.defaultLockOptions()
 	}
 
 	cmd := appLock.LockCommand{
-		Key:        key,
-		Expiration: opts.Expiration,
-		Timeout:    opts.Timeout,
-		RetryType:  string(opts.RetryType),
-		RetryCount: opts.RetryCount,
-		RetryBase:  opts.RetryBase,
+		Key:             key,
+		Expiration:      opts.Expiration,
+		Timeout:         opts.Timeout,
+		RetryType:       string(opts.RetryType),
+		RetryCount:      opts.RetryCount,
+		RetryBase:       opts.RetryBase,
+		RetryCap:        opts.RetryCap,
+		RetryMaxElapsed: opts.RetryMaxElapsed,
 	}
 
 	result, err := s.appService.TryLock(ctx, cmd)
@@ -176,6 +256,7 @@ func (s *Service) TryLock(ctx context.Context, key string, options ...LockOption
 		CreatedAt: result.CreatedAt,
 		ExpiresAt: result.ExpiresAt,
 		IsValid:   result.IsValid,
+		Token:     result.Token,
 	}, nil
 }
 
@@ -185,24 +266,19 @@ func (s *Service) Lock(ctx context.Context, key string, options ...LockOptions)
 	if len(options) > 0 {
 		opts = options[0]
 	} else {
-		// 使用默认配置
-		config := DefaultConfig()
-		opts = LockOptions{
-			Expiration: config.DefaultExpiration,
-			Timeout:    config.DefaultTimeout,
-			RetryType:  config.DefaultRetryType,
-			RetryCount: config.DefaultRetryCount,
-			RetryBase:  config.DefaultRetryBase,
-		}
+		// 使用服务配置
+		opts = s.defaultLockOptions()
 	}
 
 	cmd := appLock.LockCommand{
-		Key:        key,
-		Expiration: opts.Expiration,
-		Timeout:    opts.Timeout,
-		RetryType:  string(opts.RetryType),
-		RetryCount: opts.RetryCount,
-		RetryBase:  opts.RetryBase,
+		Key:             key,
+		Expiration:      opts.Expiration,
+		Timeout:         opts.Timeout,
+		RetryType:       string(opts.RetryType),
+		RetryCount:      opts.RetryCount,
+		RetryBase:       opts.RetryBase,
+		RetryCap:        opts.RetryCap,
+		RetryMaxElapsed: opts.RetryMaxElapsed,
 	}
 
 	result, err := s.appService.Lock(ctx, cmd)
@@ -216,13 +292,22 @@ func (s *Service) Lock(ctx context.Context, key string, options ...LockOptions)
 		CreatedAt: result.CreatedAt,
 		ExpiresAt: result.ExpiresAt,
 		IsValid:   result.IsValid,
+		Token:     result.Token,
 	}, nil
 }
 
-// Unlock 释放锁
+// Unlock 释放通过本服务获取的锁
+// 同一个键只释放最近一次获取的锁；该键没有通过本服务获取且尚未释放的锁时返回错误，
+// 锁已过期时同样返回错误，但记录仍会被移除
 func (s *Service) Unlock(ctx context.Context, key string) error {
-	// 暂时不支持释放锁，需要扩展应用服务接口
-	return fmt.Errorf("释放锁功能暂未实现")
+	return s.appService.UnlockHeld(ctx, appLock.UnlockCommand{Key: key})
+}
+
+// UnlockAll 释放通过本服务获取且尚未释放的所有锁，用于优雅退出
+// 同一个键只释放最近一次获取的锁，已过期的锁直接跳过；返回每个释放失败的锁对应的错误，全部成功时为nil。
+// 调用后记录被清空，再次调用不会重复释放
+func (s *Service) UnlockAll(ctx context.Context) []error {
+	return s.appService.UnlockAll(ctx)
 }
 
 // Refresh 续约锁
@@ -254,3 +339,32 @@ func (s *Service) StopAutoRefresh(ctx context.Context, key string) error {
 	// 暂时不支持停止自动续约，需要扩展应用服务接口
 	return fmt.Errorf("停止自动续约功能暂未实现")
 }
+
+// Stats 获取锁统计信息快照
+func (s *Service) Stats(ctx context.Context) (LockStats, error) {
+	result, err := s.appService.GetLockStats(ctx)
+	if err != nil {
+		return LockStats{}, err
+	}
+
+	return LockStats{
+		TotalLocks:   result.TotalLocks,
+		ActiveLocks:  result.ActiveLocks,
+		FailedLocks:  result.FailedLocks,
+		ExpiredLocks: result.ExpiredLocks,
+		RefreshCount: result.RefreshCount,
+		UnlockCount:  result.UnlockCount,
+		SuccessRate:  result.SuccessRate,
+	}, nil
+}
+
+// LockStats 锁统计信息
+type LockStats struct {
+	TotalLocks   int64   `json:"total_locks"`   // 成功获取锁的总次数
+	ActiveLocks  int64   `json:"active_locks"`  // 当前持有的锁数量
+	FailedLocks  int64   `json:"failed_locks"`  // 获取锁失败的次数
+	ExpiredLocks int64   `json:"expired_locks"` // 过期被清理的锁数量
+	RefreshCount int64   `json:"refresh_count"` // 续约次数
+	UnlockCount  int64   `json:"unlock_count"`  // 主动释放锁的次数
+	SuccessRate  float64 `json:"success_rate"`  // 获取锁的成功率
+}
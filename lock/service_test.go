@@ -2,6 +2,7 @@ package lock
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
@@ -56,10 +57,20 @@ func TestWithAutoRefresh(t *testing.T) {
 	assert.Equal(t, 5*time.Second, config.AutoRefreshInterval)
 }
 
+func TestWithRetryMaxElapsed(t *testing.T) {
+	config := DefaultConfig()
+	option := WithRetryMaxElapsed(5 * time.Second)
+	option(config)
+
+	assert.Equal(t, 5*time.Second, config.RetryMaxElapsed)
+	assert.Equal(t, time.Duration(0), DefaultConfig().RetryMaxElapsed)
+}
+
 func TestRetryTypes(t *testing.T) {
 	assert.Equal(t, RetryType("fixed"), RetryTypeFixed)
 	assert.Equal(t, RetryType("exponential"), RetryTypeExponential)
 	assert.Equal(t, RetryType("linear"), RetryTypeLinear)
+	assert.Equal(t, RetryType("decorrelated"), RetryTypeDecorrelated)
 }
 
 func TestNewService(t *testing.T) {
@@ -240,6 +251,57 @@ func TestService_LockWithOptions(t *testing.T) {
 	assert.True(t, lock.IsValid)
 }
 
+func TestService_LockWithDecorrelatedRetry(t *testing.T) {
+	service, err := NewService()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	key := "test_lock_decorrelated_retry"
+
+	_, err = service.TryLock(ctx, key)
+	require.NoError(t, err)
+
+	options := LockOptions{
+		Expiration: 30 * time.Second,
+		Timeout:    time.Second,
+		RetryType:  RetryTypeDecorrelated,
+		RetryCount: 3,
+		RetryBase:  10 * time.Millisecond,
+		RetryCap:   30 * time.Millisecond,
+	}
+
+	start := time.Now()
+	lock, err := service.Lock(ctx, key, options)
+	assert.Error(t, err)
+	assert.Nil(t, lock)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+
+	options.RetryCap = 5 * time.Millisecond
+	_, err = service.Lock(ctx, "test_lock_decorrelated_invalid_cap", options)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "最大重试间隔")
+}
+
+func TestService_LockWithRetryMaxElapsed(t *testing.T) {
+	service, err := NewService(
+		WithDefaultRetry(RetryTypeFixed, 1_000_000, time.Millisecond),
+		WithRetryMaxElapsed(50*time.Millisecond),
+	)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	key := "test_lock_retry_max_elapsed"
+
+	_, err = service.TryLock(ctx, key)
+	require.NoError(t, err)
+
+	start := time.Now()
+	lock, err := service.Lock(ctx, key)
+	assert.Error(t, err)
+	assert.Nil(t, lock)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+}
+
 func TestService_Unlock(t *testing.T) {
 	service, err := NewService()
 	require.NoError(t, err)
@@ -252,10 +314,16 @@ func TestService_Unlock(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, lock)
 
-	// Try to unlock (should return error as not implemented)
-	err = service.Unlock(ctx, key)
-	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "暂未实现")
+	// 释放后可以重新获取
+	require.NoError(t, service.Unlock(ctx, key))
+	lock, err = service.TryLock(ctx, key)
+	require.NoError(t, err)
+	require.NotNil(t, lock)
+	require.NoError(t, service.Unlock(ctx, key))
+
+	// 已释放或没有通过本服务获取的锁
+	assert.Error(t, service.Unlock(ctx, key))
+	assert.Error(t, service.Unlock(ctx, "never_locked"))
 }
 
 func TestService_Refresh(t *testing.T) {
@@ -386,3 +454,178 @@ func TestService_ConcurrentLocks(t *testing.T) {
 		values[lock.Value] = true
 	}
 }
+
+func TestService_Stats(t *testing.T) {
+	service, err := NewService()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	stats, err := service.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, LockStats{}, stats)
+
+	for _, key := range []string{"stats_key_1", "stats_key_2", "stats_key_3"} {
+		_, err := service.TryLock(ctx, key)
+		require.NoError(t, err)
+	}
+
+	// 锁已被占用，获取失败
+	_, err = service.TryLock(ctx, "stats_key_1")
+	assert.Error(t, err)
+
+	stats, err = service.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), stats.TotalLocks)
+	assert.Equal(t, int64(3), stats.ActiveLocks)
+	assert.Equal(t, int64(1), stats.FailedLocks)
+	assert.Equal(t, int64(0), stats.UnlockCount)
+}
+
+func TestWithValueGenerator(t *testing.T) {
+	config := DefaultConfig()
+	assert.Nil(t, config.ValueGenerator)
+
+	WithValueGenerator(func() string { return "custom" })(config)
+	require.NotNil(t, config.ValueGenerator)
+	assert.Equal(t, "custom", config.ValueGenerator())
+}
+
+func TestService_ValueGenerator(t *testing.T) {
+	var n int
+	service, err := NewService(WithValueGenerator(func() string {
+		n++
+		return fmt.Sprintf("trace-%d", n)
+	}))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	lock, err := service.TryLock(ctx, "gen_key_1")
+	require.NoError(t, err)
+	assert.Equal(t, "trace-1", lock.Value)
+
+	lock, err = service.Lock(ctx, "gen_key_2")
+	require.NoError(t, err)
+	assert.Equal(t, "trace-2", lock.Value)
+}
+
+func TestWithCleanupInterval(t *testing.T) {
+	config := DefaultConfig()
+	assert.Equal(t, time.Duration(0), config.CleanupInterval)
+
+	WithCleanupInterval(time.Second)(config)
+	assert.Equal(t, time.Second, config.CleanupInterval)
+}
+
+func TestService_CleanupInterval(t *testing.T) {
+	service, err := NewService(WithCleanupInterval(10 * time.Millisecond))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	for _, key := range []string{"cleanup_key_1", "cleanup_key_2", "cleanup_key_3"} {
+		_, err := service.TryLock(ctx, key, LockOptions{Expiration: 20 * time.Millisecond, Timeout: time.Second})
+		require.NoError(t, err)
+	}
+
+	stats, err := service.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), stats.ActiveLocks)
+
+	// 过期的锁在后台清理后从锁表中移除，无需再次访问这些键
+	assert.Eventually(t, func() bool {
+		stats, err := service.Stats(ctx)
+		return err == nil && stats.ActiveLocks == 0 && stats.ExpiredLocks == 3
+	}, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, service.Close())
+	assert.Error(t, service.Close())
+}
+
+func TestService_Close(t *testing.T) {
+	// 未启用后台清理时也可以关闭
+	service, err := NewService()
+	require.NoError(t, err)
+	assert.NoError(t, service.Close())
+}
+
+func TestWithMaxExpiration(t *testing.T) {
+	config := DefaultConfig()
+	assert.Equal(t, time.Duration(0), config.MaxExpiration)
+
+	WithMaxExpiration(48 * time.Hour)(config)
+	assert.Equal(t, 48*time.Hour, config.MaxExpiration)
+}
+
+func TestService_MaxExpiration(t *testing.T) {
+	ctx := context.Background()
+
+	// 默认上限为24小时
+	service, err := NewService()
+	require.NoError(t, err)
+	_, err = service.TryLock(ctx, "lease_key", LockOptions{Expiration: 48 * time.Hour, Timeout: time.Second})
+	assert.Error(t, err)
+
+	// 调大上限后可以获取长期租约
+	service, err = NewService(WithMaxExpiration(72 * time.Hour))
+	require.NoError(t, err)
+	lock, err := service.TryLock(ctx, "lease_key", LockOptions{Expiration: 48 * time.Hour, Timeout: time.Second})
+	require.NoError(t, err)
+	assert.Equal(t, "lease_key", lock.Key)
+
+	// 调小上限后拒绝超过上限的过期时间
+	service, err = NewService(WithMaxExpiration(time.Minute))
+	require.NoError(t, err)
+	_, err = service.TryLock(ctx, "short_key", LockOptions{Expiration: 2 * time.Minute, Timeout: time.Second})
+	assert.Error(t, err)
+	_, err = service.TryLock(ctx, "short_key", LockOptions{Expiration: time.Minute, Timeout: time.Second})
+	assert.NoError(t, err)
+}
+
+// TestService_UnlockAll 测试释放所有持有的锁后各个键可以重新获取
+func TestService_UnlockAll(t *testing.T) {
+	service, err := NewService()
+	require.NoError(t, err)
+	defer service.Close()
+
+	ctx := context.Background()
+	keys := []string{"order:1", "order:2", "order:3"}
+	for _, key := range keys {
+		_, err := service.TryLock(ctx, key)
+		require.NoError(t, err)
+	}
+	for _, key := range keys {
+		_, err := service.TryLock(ctx, key)
+		assert.Error(t, err)
+	}
+
+	assert.Empty(t, service.UnlockAll(ctx))
+	for _, key := range keys {
+		_, err := service.TryLock(ctx, key)
+		assert.NoError(t, err)
+	}
+	assert.Empty(t, service.UnlockAll(ctx))
+	assert.Empty(t, service.UnlockAll(ctx))
+}
+
+// TestService_UnlockAll_Expired 测试已过期的锁不被当作释放失败，记录被清空
+func TestService_UnlockAll_Expired(t *testing.T) {
+	service, err := NewService()
+	require.NoError(t, err)
+	defer service.Close()
+
+	ctx := context.Background()
+	_, err = service.TryLock(ctx, "order:1")
+	require.NoError(t, err)
+	_, err = service.TryLock(ctx, "order:2", LockOptions{Expiration: 10 * time.Millisecond, Timeout: time.Second})
+	require.NoError(t, err)
+	time.Sleep(20 * time.Millisecond)
+
+	assert.Empty(t, service.UnlockAll(ctx))
+	_, err = service.TryLock(ctx, "order:1")
+	assert.NoError(t, err)
+
+	// 记录已清空，只剩下刚获取的锁
+	assert.Empty(t, service.UnlockAll(ctx))
+	assert.Error(t, service.Unlock(ctx, "order:2"))
+}